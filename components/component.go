@@ -15,8 +15,11 @@ import (
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/registry"
 
+	_ "github.com/micro-plat/hydra/components/queues/mq/amqp"
+	_ "github.com/micro-plat/hydra/components/queues/mq/kafka"
 	_ "github.com/micro-plat/hydra/components/queues/mq/lmq"
 	_ "github.com/micro-plat/hydra/components/queues/mq/mqtt"
+	_ "github.com/micro-plat/hydra/components/queues/mq/nats"
 	_ "github.com/micro-plat/hydra/components/queues/mq/redis"
 	_ "github.com/micro-plat/hydra/components/queues/mq/xmq"
 )
@@ -3,6 +3,7 @@ package container
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"time"
 
@@ -21,6 +22,7 @@ type ICloser interface {
 type IContainer interface {
 	GetOrCreate(typ string, name string, creator func(conf *conf.RawConf, keys ...string) (interface{}, error), keys ...string) (interface{}, error)
 	Remove(typ, name string, keys ...string) error
+	Keys() []string
 	ICloser
 }
 
@@ -59,6 +61,10 @@ func (c *Container) GetOrCreate(typ string, name string, creator func(conf *conf
 
 	//2. 根据配置创建组件
 	key := fmt.Sprintf("%s_%s_%s_%d", typ, name, strings.Join(keys, "_"), jconf.GetVersion())
+	if jconf.HasSecret() {
+		//密钥值变更时版本号不变，使用签名区分
+		key = key + "_" + jconf.GetSignature()
+	}
 	_, obj, err := c.cache.SetIfAbsentCb(key, func(i ...interface{}) (interface{}, error) {
 		nkeys := []string{}
 		if len(i) > 1 {
@@ -74,6 +80,13 @@ func (c *Container) GetOrCreate(typ string, name string, creator func(conf *conf
 	return obj, err
 }
 
+//Keys 获取容器中所有组件的缓存key
+func (c *Container) Keys() []string {
+	keys := c.cache.Keys()
+	sort.Strings(keys)
+	return keys
+}
+
 //Close 释放组件资源
 func (c *Container) Close() error {
 	c.cache.RemoveIterCb(func(key string, v interface{}) bool {
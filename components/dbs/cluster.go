@@ -0,0 +1,242 @@
+package dbs
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/lib4go/db"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/types"
+
+	xdb "github.com/micro-plat/hydra/conf/vars/db"
+)
+
+//HealthCheckSpan 副本健康检查与连接池统计的时间间隔
+var HealthCheckSpan = time.Second * 5
+
+//SwapDelay 配置变更后关闭旧实例连接的延迟时间，等待正在执行的操作完成
+var SwapDelay = time.Minute
+
+//clusters 各数据库名称当前使用的读写分离实例
+var clusters = make(map[string]*ClusterDB)
+var clustersLk sync.Mutex
+
+var _ IDB = &ClusterDB{}
+
+//ClusterDB 读写分离的数据库，查询按权重路由到健康的副本，写入、存储过程与事务使用主库
+type ClusterDB struct {
+	name     string
+	provider string
+	maxLag   int
+	primary  *endpoint
+	replicas []*endpoint
+	log      logger.ILogger
+	closeCh  chan struct{}
+	stopOnce sync.Once
+	once     sync.Once
+}
+
+func newClusterDB(name string, c *xdb.DB) (*ClusterDB, error) {
+	primary, err := newEndpoint(name, rolePrimary, c.Provider, c.ConnString, 0, c.MaxOpen, c.MaxIdle, c.LifeTime)
+	if err != nil {
+		return nil, err
+	}
+	d := &ClusterDB{
+		name:     name,
+		provider: strings.ToLower(c.Provider),
+		maxLag:   c.MaxLag,
+		primary:  primary,
+		replicas: make([]*endpoint, 0, len(c.Replicas)),
+		log:      logger.New("db"),
+		closeCh:  make(chan struct{}),
+	}
+	for _, r := range c.Replicas {
+		replica, err := newEndpoint(name, roleReplica, c.Provider, r.ConnString, r.GetWeight(), c.MaxOpen, c.MaxIdle, c.LifeTime)
+		if err != nil {
+			d.Close()
+			return nil, fmt.Errorf("连接数据库副本%s失败:%w", getAddr(r.ConnString), err)
+		}
+		d.replicas = append(d.replicas, replica)
+	}
+	startReporter()
+	go d.healthCheck()
+	return d, nil
+}
+
+//swapCluster 记录name当前使用的实例，配置变更时停止旧实例的健康检查并在SwapDelay后关闭其连接
+func swapCluster(d *ClusterDB) {
+	clustersLk.Lock()
+	old := clusters[d.name]
+	clusters[d.name] = d
+	clustersLk.Unlock()
+	if old == nil || old == d {
+		return
+	}
+	old.stop()
+	time.AfterFunc(SwapDelay, old.Close)
+}
+
+//Primary 获取主库，写入后需立即读取(read-your-writes)时使用
+func (d *ClusterDB) Primary() IDB {
+	return d.primary
+}
+
+//getReplica 按权重选择健康的副本，无可用副本时使用主库
+func (d *ClusterDB) getReplica() *endpoint {
+	total := 0
+	for _, r := range d.replicas {
+		if r.isHealthy() {
+			total += r.weight
+		}
+	}
+	if total == 0 {
+		return d.primary
+	}
+	n := rand.Intn(total)
+	for _, r := range d.replicas {
+		if !r.isHealthy() {
+			continue
+		}
+		if n < r.weight {
+			return r
+		}
+		n -= r.weight
+	}
+	return d.primary
+}
+
+//Query 查询数据，由副本执行
+func (d *ClusterDB) Query(sql string, input map[string]interface{}) (data db.QueryRows, err error) {
+	return d.getReplica().Query(sql, input)
+}
+
+//Scalar 查询第一行第一列的值，由副本执行
+func (d *ClusterDB) Scalar(sql string, input map[string]interface{}) (data interface{}, err error) {
+	return d.getReplica().Scalar(sql, input)
+}
+
+//Execute 执行SQL语句，由主库执行
+func (d *ClusterDB) Execute(sql string, input map[string]interface{}) (row int64, err error) {
+	return d.primary.Execute(sql, input)
+}
+
+//Executes 执行SQL语句，由主库执行
+func (d *ClusterDB) Executes(sql string, input map[string]interface{}) (lastInsertID int64, affectedRow int64, err error) {
+	return d.primary.Executes(sql, input)
+}
+
+//ExecuteBatch 批量执行SQL语句，由主库执行
+func (d *ClusterDB) ExecuteBatch(sqls []string, input map[string]interface{}) (data db.QueryRows, err error) {
+	return d.primary.ExecuteBatch(sqls, input)
+}
+
+//ExecuteSP 执行存储过程，由主库执行
+func (d *ClusterDB) ExecuteSP(procName string, input map[string]interface{}, output ...interface{}) (row int64, err error) {
+	return d.primary.ExecuteSP(procName, input, output...)
+}
+
+//Begin 在主库上开启事务
+func (d *ClusterDB) Begin() (db.IDBTrans, error) {
+	return d.primary.Begin()
+}
+
+//Close 停止健康检查并关闭所有连接
+func (d *ClusterDB) Close() {
+	d.once.Do(func() {
+		d.stop()
+		d.primary.Close()
+		for _, r := range d.replicas {
+			r.Close()
+		}
+	})
+}
+
+//stop 停止健康检查
+func (d *ClusterDB) stop() {
+	d.stopOnce.Do(func() {
+		close(d.closeCh)
+	})
+}
+
+//healthCheck 定时检查副本，连接失败或复制延迟超过maxLag的副本暂停使用，恢复后重新加入
+func (d *ClusterDB) healthCheck() {
+	tk := time.NewTicker(HealthCheckSpan)
+	defer tk.Stop()
+	for {
+		select {
+		case <-d.closeCh:
+			return
+		case <-tk.C:
+			d.primary.updateStats()
+			for _, r := range d.replicas {
+				r.updateStats()
+				err := d.check(r)
+				if !r.setHealthy(err == nil) {
+					continue
+				}
+				if err != nil {
+					d.log.Warnf("数据库[%s]副本%s不可用:%v", d.name, r.addr, err)
+					continue
+				}
+				d.log.Infof("数据库[%s]副本%s已恢复", d.name, r.addr)
+			}
+		}
+	}
+}
+
+func (d *ClusterDB) check(r *endpoint) error {
+	pingSQL := "select 1"
+	if d.provider == "oracle" || d.provider == "ora" {
+		pingSQL = "select 1 from dual"
+	}
+	if _, err := r.sqlDB.Scalar(pingSQL, nil); err != nil {
+		return err
+	}
+	if d.maxLag <= 0 {
+		return nil
+	}
+	lag, err := d.getLag(r)
+	if err != nil {
+		return err
+	}
+	if lag > d.maxLag {
+		return fmt.Errorf("复制延迟%d秒超过%d秒", lag, d.maxLag)
+	}
+	return nil
+}
+
+//getLag 获取副本的复制延迟(秒)，仅支持mysql与postgres
+func (d *ClusterDB) getLag(r *endpoint) (int, error) {
+	switch d.provider {
+	case "mysql":
+		rows, err := r.sqlDB.Query("show slave status", nil)
+		if err != nil {
+			return 0, err
+		}
+		if rows.Len() == 0 {
+			return 0, fmt.Errorf("未配置主从复制")
+		}
+		for _, k := range rows.Get(0).Keys() {
+			if strings.EqualFold(k, "Seconds_Behind_Master") {
+				v := rows.Get(0).GetString(k)
+				if v == "" {
+					return 0, fmt.Errorf("主从复制已停止")
+				}
+				return types.GetInt(v), nil
+			}
+		}
+		return 0, nil
+	case "postgres":
+		//已接收的日志全部回放时无延迟，主库长时间无写入时不会因最后回放时间较早而误判
+		v, err := r.sqlDB.Scalar("select case when pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() then 0 else coalesce(extract(epoch from now() - pg_last_xact_replay_timestamp()),0) end", nil)
+		if err != nil {
+			return 0, err
+		}
+		return int(types.GetFloat64(v)), nil
+	default:
+		return 0, nil
+	}
+}
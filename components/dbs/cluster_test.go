@@ -0,0 +1,74 @@
+// +build sqlite
+
+package dbs
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/types"
+
+	xdb "github.com/micro-plat/hydra/conf/vars/db"
+)
+
+func TestClusterDB(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "primary.db")
+	replica := filepath.Join(dir, "replica.db")
+	d, err := newClusterDB("db", xdb.New("sqlite", primary, xdb.WithReplica(replica, 2)))
+	assert.Equal(t, nil, err, "1. 创建读写分离数据库")
+	defer d.Close()
+
+	for _, e := range []*endpoint{d.primary, d.replicas[0]} {
+		e.Execute("create table t(name varchar(32))", nil)
+	}
+	d.replicas[0].Execute("insert into t(name) values('replica')", nil)
+	_, err = d.Execute("insert into t(name) values('primary')", nil)
+	assert.Equal(t, nil, err, "2. 写入主库")
+
+	v, _ := d.Scalar("select name from t", nil)
+	assert.Equal(t, "replica", types.GetString(v), "3. 查询路由到副本")
+	v, _ = Primary(d).Scalar("select name from t", nil)
+	assert.Equal(t, "primary", types.GetString(v), "4. 强制使用主库")
+
+	trans, _ := d.Begin()
+	v, _ = trans.Scalar("select name from t", nil)
+	trans.Rollback()
+	assert.Equal(t, "primary", types.GetString(v), "5. 事务使用主库")
+
+	d.replicas[0].setHealthy(false)
+	v, _ = d.Scalar("select name from t", nil)
+	assert.Equal(t, "primary", types.GetString(v), "6. 副本不可用时使用主库")
+	assert.Equal(t, nil, d.check(d.replicas[0]), "7. 副本健康检查")
+}
+
+func TestSwapCluster(t *testing.T) {
+	SwapDelay = time.Millisecond * 10
+	dir := t.TempDir()
+	create := func() *ClusterDB {
+		d, err := newClusterDB("swap", xdb.New("sqlite", filepath.Join(dir, "primary.db"), xdb.WithReplica(filepath.Join(dir, "replica.db"), 1)))
+		assert.Equal(t, nil, err)
+		swapCluster(d)
+		return d
+	}
+	old := create()
+	current := create()
+	defer current.Close()
+
+	select {
+	case <-old.closeCh:
+	default:
+		t.Error("1. 配置变更后停止旧实例的健康检查")
+	}
+	time.Sleep(time.Millisecond * 100)
+	_, err := old.Scalar("select 1", nil)
+	assert.NotEqual(t, nil, err, "2. 延迟关闭旧实例的连接")
+	_, err = current.Scalar("select 1", nil)
+	assert.Equal(t, nil, err, "3. 新实例正常使用")
+
+	current.primary.updateStats()
+	assert.Equal(t, true, current.primary.open.Value() >= 1, "4. 记录连接池统计信息")
+}
@@ -0,0 +1,151 @@
+package dbs
+
+import (
+	"context"
+
+	rc "github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/lib4go/db"
+)
+
+var _ IDB = &ctxDB{}
+
+//ctxDB 每次操作前检查context是否已撤销或超时，用于处理程序启动的goroutine中访问数据库
+type ctxDB struct {
+	ctx context.Context
+	db  IDB
+}
+
+//WithContext 构建随ctx撤销的数据库操作对象，ctx撤销或超时后的操作直接返回错误；
+//请求上下文在处理程序返回后即撤销，传入请求上下文时不随其撤销，处理程序启动的goroutine仍可访问数据库
+func WithContext(ctx context.Context, d IDB) IDB {
+	if c, ok := d.(*ctxDB); ok {
+		d = c.db
+	}
+	if _, ok := rc.GetSnapshot(ctx); ok {
+		ctx = rc.Detach(ctx)
+	}
+	return &ctxDB{ctx: ctx, db: d}
+}
+
+//Query 查询数据
+func (d *ctxDB) Query(sql string, input map[string]interface{}) (data db.QueryRows, err error) {
+	if err = d.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.db.Query(sql, input)
+}
+
+//Scalar 查询第一行第一列的值
+func (d *ctxDB) Scalar(sql string, input map[string]interface{}) (data interface{}, err error) {
+	if err = d.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.db.Scalar(sql, input)
+}
+
+//Execute 执行SQL语句
+func (d *ctxDB) Execute(sql string, input map[string]interface{}) (row int64, err error) {
+	if err = d.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return d.db.Execute(sql, input)
+}
+
+//Executes 执行SQL语句，返回最后插入的编号与影响的行数
+func (d *ctxDB) Executes(sql string, input map[string]interface{}) (lastInsertID int64, affectedRow int64, err error) {
+	if err = d.ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	return d.db.Executes(sql, input)
+}
+
+//ExecuteBatch 批量执行SQL语句
+func (d *ctxDB) ExecuteBatch(sqls []string, input map[string]interface{}) (data db.QueryRows, err error) {
+	if err = d.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.db.ExecuteBatch(sqls, input)
+}
+
+//ExecuteSP 执行存储过程
+func (d *ctxDB) ExecuteSP(procName string, input map[string]interface{}, output ...interface{}) (row int64, err error) {
+	if err = d.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return d.db.ExecuteSP(procName, input, output...)
+}
+
+//Begin 开启事务，事务中的操作同样随ctx撤销
+func (d *ctxDB) Begin() (db.IDBTrans, error) {
+	if err := d.ctx.Err(); err != nil {
+		return nil, err
+	}
+	t, err := d.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &ctxTrans{ctx: d.ctx, trans: t}, nil
+}
+
+//Close 关闭数据库，由组件统一管理，此处不处理
+func (d *ctxDB) Close() {}
+
+//ctxTrans 每次操作前检查context的事务
+type ctxTrans struct {
+	ctx   context.Context
+	trans db.IDBTrans
+}
+
+//Query 查询数据
+func (t *ctxTrans) Query(sql string, input map[string]interface{}) (data db.QueryRows, err error) {
+	if err = t.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return t.trans.Query(sql, input)
+}
+
+//Scalar 查询第一行第一列的值
+func (t *ctxTrans) Scalar(sql string, input map[string]interface{}) (data interface{}, err error) {
+	if err = t.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return t.trans.Scalar(sql, input)
+}
+
+//Execute 执行SQL语句
+func (t *ctxTrans) Execute(sql string, input map[string]interface{}) (row int64, err error) {
+	if err = t.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return t.trans.Execute(sql, input)
+}
+
+//Executes 执行SQL语句，返回最后插入的编号与影响的行数
+func (t *ctxTrans) Executes(sql string, input map[string]interface{}) (lastInsertID int64, affectedRow int64, err error) {
+	if err = t.ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	return t.trans.Executes(sql, input)
+}
+
+//ExecuteBatch 批量执行SQL语句
+func (t *ctxTrans) ExecuteBatch(sqls []string, input map[string]interface{}) (data db.QueryRows, err error) {
+	if err = t.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return t.trans.ExecuteBatch(sqls, input)
+}
+
+//Rollback 回滚事务，ctx撤销后仍可回滚
+func (t *ctxTrans) Rollback() error {
+	return t.trans.Rollback()
+}
+
+//Commit 提交事务，ctx已撤销时回滚并返回错误
+func (t *ctxTrans) Commit() error {
+	if err := t.ctx.Err(); err != nil {
+		t.trans.Rollback()
+		return err
+	}
+	return t.trans.Commit()
+}
@@ -0,0 +1,30 @@
+// +build sqlite
+
+package dbs
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	rc "github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestWithContext(t *testing.T) {
+	d, err := newSQLDB("sqlite", filepath.Join(t.TempDir(), "ctx.db"), 1, 1, 60)
+	assert.Equal(t, nil, err, "1. 创建数据库")
+	defer d.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = WithContext(ctx, d).Execute("create table t(name varchar(32))", nil)
+	assert.Equal(t, context.Canceled, err, "2. ctx撤销后直接返回错误")
+
+	ctx, cancel = context.WithCancel(rc.WithSnapshot(context.Background(), rc.Snapshot{TraceID: "abc"}))
+	cancel()
+	_, err = WithContext(ctx, d).Execute("create table t(name varchar(32))", nil)
+	assert.Equal(t, nil, err, "3. 请求上下文撤销后仍可访问数据库")
+	assert.Equal(t, "abc", rc.GetTraceID(rc.Detach(ctx)), "4. 保留链路跟踪编号")
+	assert.Equal(t, nil, rc.Detach(ctx).Err(), "4. 不随ctx撤销")
+}
@@ -38,6 +38,17 @@ func (s *StandardDB) GetRegularDB(names ...string) (d IDB) {
 	return d
 }
 
+//Primary 获取主库，配置了只读副本时用于写入后立即读取(read-your-writes)，否则返回d本身
+func Primary(d IDB) IDB {
+	if c, ok := d.(*ctxDB); ok {
+		return &ctxDB{ctx: c.ctx, db: Primary(c.db)}
+	}
+	if c, ok := d.(*ClusterDB); ok {
+		return c.Primary()
+	}
+	return d
+}
+
 //GetDB 获取数据库操作对象
 func (s *StandardDB) GetDB(names ...string) (d IDB, err error) {
 	name := types.GetStringByIndex(names, 0, dbNameNode)
@@ -49,6 +60,14 @@ func (s *StandardDB) GetDB(names ...string) (d IDB, err error) {
 		if err = conf.ToStruct(&dbConf); err != nil {
 			return nil, fmt.Errorf("数据库[%s/%s]配置有误：%w", dbTypeNode, name, err)
 		}
+		if len(dbConf.Replicas) > 0 {
+			d, err := newClusterDB(name, &dbConf)
+			if err != nil {
+				return nil, err
+			}
+			swapCluster(d)
+			return d, nil
+		}
 		return db.NewDB(dbConf.Provider, dbConf.ConnString, dbConf.MaxOpen, dbConf.MaxIdle, dbConf.LifeTime)
 	})
 	if err != nil {
@@ -0,0 +1,157 @@
+package dbs
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/micro-plat/hydra/components/pkgs/metrics"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/db"
+)
+
+const (
+	rolePrimary = "primary"
+	roleReplica = "replica"
+)
+
+//endpoint 数据库节点，记录每个节点的并发数、执行时长、错误数与连接池状态
+type endpoint struct {
+	*sqlDB
+	addr         string
+	role         string
+	weight       int
+	healthy      int32
+	working      metrics.Counter
+	timer        metrics.Timer
+	errors       metrics.Meter
+	health       metrics.Gauge
+	open         metrics.Gauge
+	inUse        metrics.Gauge
+	idle         metrics.Gauge
+	waitCount    metrics.Gauge
+	waitDuration metrics.Gauge
+}
+
+func newEndpoint(name string, role string, provider string, connString string, weight int, maxOpen int, maxIdle int, lifeTime int) (*endpoint, error) {
+	sdb, err := newSQLDB(provider, connString, maxOpen, maxIdle, lifeTime)
+	if err != nil {
+		return nil, err
+	}
+	addr := getAddr(connString)
+	params := []string{"db", name, "role", role, "endpoint", addr, "host", global.LocalIP()}
+	e := &endpoint{
+		sqlDB:        sdb,
+		addr:         addr,
+		role:         role,
+		weight:       weight,
+		healthy:      1,
+		working:      metrics.GetOrRegisterCounter(metrics.MakeName("db.endpoint", metrics.WORKING, params...), metricRegistry),
+		timer:        metrics.GetOrRegisterTimer(metrics.MakeName("db.endpoint", metrics.TIMER, params...), metricRegistry),
+		errors:       metrics.GetOrRegisterMeter(metrics.MakeName("db.endpoint.error", metrics.METER, params...), metricRegistry),
+		health:       metrics.GetOrRegisterGauge(metrics.MakeName("db.endpoint.health", metrics.GAUGE, params...), metricRegistry),
+		open:         metrics.GetOrRegisterGauge(metrics.MakeName("db.endpoint.conn.open", metrics.GAUGE, params...), metricRegistry),
+		inUse:        metrics.GetOrRegisterGauge(metrics.MakeName("db.endpoint.conn.inuse", metrics.GAUGE, params...), metricRegistry),
+		idle:         metrics.GetOrRegisterGauge(metrics.MakeName("db.endpoint.conn.idle", metrics.GAUGE, params...), metricRegistry),
+		waitCount:    metrics.GetOrRegisterGauge(metrics.MakeName("db.endpoint.conn.wait", metrics.GAUGE, params...), metricRegistry),
+		waitDuration: metrics.GetOrRegisterGauge(metrics.MakeName("db.endpoint.conn.waitms", metrics.GAUGE, params...), metricRegistry),
+	}
+	e.health.Update(1)
+	e.updateStats()
+	return e, nil
+}
+
+//updateStats 更新连接池统计信息：打开、使用中、空闲的连接数，累计等待次数与等待时长(毫秒)
+func (e *endpoint) updateStats() {
+	s := e.Stats()
+	e.open.Update(int64(s.OpenConnections))
+	e.inUse.Update(int64(s.InUse))
+	e.idle.Update(int64(s.Idle))
+	e.waitCount.Update(s.WaitCount)
+	e.waitDuration.Update(s.WaitDuration.Milliseconds())
+}
+
+func (e *endpoint) isHealthy() bool {
+	return atomic.LoadInt32(&e.healthy) == 1
+}
+
+//setHealthy 设置节点健康状态，返回状态是否发生变化
+func (e *endpoint) setHealthy(healthy bool) bool {
+	v := int32(0)
+	if healthy {
+		v = 1
+	}
+	e.health.Update(int64(v))
+	return atomic.SwapInt32(&e.healthy, v) != v
+}
+
+func (e *endpoint) do(f func() error) {
+	e.working.Inc(1)
+	defer e.working.Dec(1)
+	e.timer.Time(func() {
+		if err := f(); err != nil {
+			e.errors.Mark(1)
+		}
+	})
+}
+
+//Query 查询数据
+func (e *endpoint) Query(sql string, input map[string]interface{}) (data db.QueryRows, err error) {
+	e.do(func() error {
+		data, err = e.sqlDB.Query(sql, input)
+		return err
+	})
+	return
+}
+
+//Scalar 查询第一行第一列的值
+func (e *endpoint) Scalar(sql string, input map[string]interface{}) (data interface{}, err error) {
+	e.do(func() error {
+		data, err = e.sqlDB.Scalar(sql, input)
+		return err
+	})
+	return
+}
+
+//Execute 执行SQL语句
+func (e *endpoint) Execute(sql string, input map[string]interface{}) (row int64, err error) {
+	e.do(func() error {
+		row, err = e.sqlDB.Execute(sql, input)
+		return err
+	})
+	return
+}
+
+//Executes 执行SQL语句，返回最后插入的编号与影响的行数
+func (e *endpoint) Executes(sql string, input map[string]interface{}) (lastInsertID int64, row int64, err error) {
+	e.do(func() error {
+		lastInsertID, row, err = e.sqlDB.Executes(sql, input)
+		return err
+	})
+	return
+}
+
+//ExecuteSP 执行存储过程
+func (e *endpoint) ExecuteSP(procName string, input map[string]interface{}, output ...interface{}) (row int64, err error) {
+	e.do(func() error {
+		row, err = e.sqlDB.ExecuteSP(procName, input, output...)
+		return err
+	})
+	return
+}
+
+//ExecuteBatch 批量执行SQL语句
+func (e *endpoint) ExecuteBatch(sqls []string, input map[string]interface{}) (data db.QueryRows, err error) {
+	e.do(func() error {
+		data, err = e.sqlDB.ExecuteBatch(sqls, input)
+		return err
+	})
+	return
+}
+
+//getAddr 获取连接串中的服务器地址，去掉用户名与密码
+func getAddr(connString string) string {
+	if i := strings.LastIndex(connString, "@"); i >= 0 {
+		return connString[i+1:]
+	}
+	return connString
+}
@@ -0,0 +1,89 @@
+package dbs
+
+import (
+	"sync"
+
+	"github.com/micro-plat/hydra/components/pkgs/metrics"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/server/metric"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/services"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//metricRegistry 读写分离数据库各节点的统计信息
+var metricRegistry = metrics.NewRegistry()
+
+var reporter = &metricReporter{}
+
+//metricReporter 使用首个启用了metric的服务器配置上报数据库节点统计信息
+type metricReporter struct {
+	conf     *metric.Metric
+	tp       string
+	used     bool
+	reporter metrics.IReporter
+	lk       sync.Mutex
+}
+
+func (m *metricReporter) setConf(c app.IAPPConf) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	if m.conf != nil {
+		return
+	}
+	conf, err := c.GetMetricConf()
+	if err != nil || conf.Disable {
+		return
+	}
+	m.conf = conf
+	m.tp = c.GetServerConf().GetServerType()
+	m.start()
+}
+
+func (m *metricReporter) close(c app.IAPPConf) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	if m.tp != c.GetServerConf().GetServerType() {
+		return
+	}
+	m.conf = nil
+	m.tp = ""
+	if m.reporter != nil {
+		m.reporter.Close()
+		m.reporter = nil
+	}
+}
+
+//start 存在读写分离数据库且配置了metric时开始上报
+func (m *metricReporter) start() {
+	if m.conf == nil || !m.used || m.reporter != nil {
+		return
+	}
+	r, err := metrics.InfluxDB(metricRegistry, m.conf.Cron, m.conf.Host, m.conf.DataBase, m.conf.UserName, m.conf.Password, logger.New("metric"))
+	if err != nil {
+		logger.New("db").Errorf("初始化数据库metric失败:%v", err)
+		return
+	}
+	m.reporter = r
+	go r.Run()
+}
+
+func startReporter() {
+	reporter.lk.Lock()
+	defer reporter.lk.Unlock()
+	reporter.used = true
+	reporter.start()
+}
+
+func init() {
+	global.OnReady(func() {
+		services.Def.OnStarted(func(c app.IAPPConf) error {
+			reporter.setConf(c)
+			return nil
+		})
+		services.Def.OnClosing(func(c app.IAPPConf) error {
+			reporter.close(c)
+			return nil
+		})
+	})
+}
@@ -0,0 +1,129 @@
+package dbs
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/micro-plat/lib4go/db"
+	"github.com/micro-plat/lib4go/db/tpl"
+)
+
+var _ db.IDB = &sqlDB{}
+
+//sqlDB 使用lib4go/db.DB执行语句，保留其内部的*sql.DB用于获取连接池统计信息
+type sqlDB struct {
+	*db.DB
+	pool *sql.DB
+}
+
+func newSQLDB(provider string, connString string, maxOpen int, maxIdle int, lifeTime int) (*sqlDB, error) {
+	d, err := db.NewDB(provider, connString, maxOpen, maxIdle, lifeTime)
+	pool, _ := getField(d, "db", "db").(*sql.DB)
+	if err != nil {
+		if pool != nil {
+			pool.Close()
+		}
+		return nil, err
+	}
+	return &sqlDB{DB: d, pool: pool}, nil
+}
+
+//Stats 获取连接池统计信息
+func (d *sqlDB) Stats() sql.DBStats {
+	if d.pool == nil {
+		return sql.DBStats{}
+	}
+	return d.pool.Stats()
+}
+
+//Scalar 查询第一行第一列的值
+func (d *sqlDB) Scalar(sql string, input map[string]interface{}) (data interface{}, err error) {
+	if d.pool == nil {
+		return d.DB.Scalar(sql, input)
+	}
+	return getScalar(d.pool, d.GetTPL(), sql, input)
+}
+
+//Begin 开启事务
+func (d *sqlDB) Begin() (db.IDBTrans, error) {
+	t, err := d.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	tx, _ := getField(t, "tx", "tx").(*sql.Tx)
+	return &sqlTrans{IDBTrans: t, tx: tx, tpl: d.GetTPL()}, nil
+}
+
+//sqlTrans 数据库事务
+type sqlTrans struct {
+	db.IDBTrans
+	tx  *sql.Tx
+	tpl tpl.ITPLContext
+}
+
+//Scalar 查询第一行第一列的值
+func (t *sqlTrans) Scalar(sql string, input map[string]interface{}) (data interface{}, err error) {
+	if t.tx == nil {
+		return t.IDBTrans.Scalar(sql, input)
+	}
+	return getScalar(t.tx, t.tpl, sql, input)
+}
+
+//querier 执行查询的连接池或事务
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+//getScalar 按查询语句的列顺序获取第一行第一列的值，lib4go/db的查询结果为map，无法确定第一列
+func getScalar(q querier, t tpl.ITPLContext, sql string, input map[string]interface{}) (interface{}, error) {
+	query, args := t.GetSQLContext(sql, input)
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, getDBError(err, query, args)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil || len(columns) == 0 || !rows.Next() {
+		return nil, err
+	}
+	buffer := make([]interface{}, len(columns))
+	for i := range buffer {
+		buffer[i] = new([]byte)
+	}
+	if err := rows.Scan(buffer...); err != nil {
+		return nil, err
+	}
+	v := *(buffer[0].(*[]byte))
+	if v == nil {
+		return nil, nil
+	}
+	return string(v), nil
+}
+
+//getField 依次读取未导出的字段，lib4go/db未公开内部的*sql.DB与*sql.Tx，字段不存在时返回nil
+func getField(i interface{}, names ...string) interface{} {
+	v := reflect.ValueOf(i)
+	for _, name := range names {
+		for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct || !v.CanAddr() {
+			return nil
+		}
+		f := v.FieldByName(name)
+		if !f.IsValid() {
+			return nil
+		}
+		v = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+	}
+	return v.Interface()
+}
+
+func getDBError(err error, query string, args []interface{}) error {
+	return fmt.Errorf("%w(sql:%s,args:%+v)", err, query, args)
+}
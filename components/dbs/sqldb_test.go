@@ -0,0 +1,34 @@
+// +build sqlite
+
+package dbs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestSQLDB(t *testing.T) {
+	d, err := newSQLDB("sqlite", filepath.Join(t.TempDir(), "sqldb.db"), 2, 1, 60)
+	assert.Equal(t, nil, err, "1. 创建数据库")
+	defer d.Close()
+	assert.NotEqual(t, nil, d.pool, "1. 获取连接池")
+	assert.Equal(t, 2, d.Stats().MaxOpenConnections, "1. 获取连接池统计信息")
+
+	for i := 0; i < 20; i++ {
+		v, err := d.Scalar("select 1 as z, 2 as a, 3 as m", nil)
+		assert.Equal(t, nil, err, "2. 查询第一列")
+		assert.Equal(t, "1", v, "2. 按查询语句的列顺序返回第一列")
+	}
+	v, err := d.Scalar("select 1 as z where 1=0", nil)
+	assert.Equal(t, nil, err, "3. 无数据")
+	assert.Equal(t, nil, v, "3. 无数据时返回nil")
+
+	trans, err := d.Begin()
+	assert.Equal(t, nil, err, "4. 开启事务")
+	assert.NotEqual(t, nil, trans.(*sqlTrans).tx, "4. 获取事务")
+	v, err = trans.Scalar("select 1 as z, 2 as a, 3 as m", nil)
+	assert.Equal(t, "1", v, "4. 事务中按列顺序返回第一列")
+	assert.Equal(t, nil, trans.Commit(), "4. 提交事务")
+}
@@ -1,6 +1,9 @@
 package http
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 //IClient http请求
 type IClient interface {
@@ -8,6 +11,8 @@ type IClient interface {
 	Post(url string, params string, charset ...string) (content string, status int, err error)
 	Request(method string, url string, params string, charset string, header http.Header, cookies ...*http.Cookie) (content []byte, status int, err error)
 	HRequest(method string, url string, params string, charset string, header http.Header, cookies ...*http.Cookie) (content []byte, rspHeader http.Header, status int, err error)
+	RequestByCtx(ctx context.Context, method string, url string, params string, charset string, header http.Header, cookies ...*http.Cookie) (content []byte, status int, err error)
+	HRequestByCtx(ctx context.Context, method string, url string, params string, charset string, header http.Header, cookies ...*http.Cookie) (content []byte, rspHeader http.Header, status int, err error)
 	SaveAs(method string, url string, params string, path string, charset string, header http.Header, cookies ...*http.Cookie) (status int, err error)
 	Upload(url string, params map[string]string, files map[string]string, charset string, header http.Header, cookies ...*http.Cookie) (content string, status int, err error)
 }
@@ -0,0 +1,63 @@
+package outbox
+
+import "time"
+
+type options struct {
+	db        string
+	queue     string
+	interval  time.Duration
+	batchSize int
+	maxRetry  int
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		db:        "db",
+		queue:     "queue",
+		interval:  time.Second,
+		batchSize: 100,
+		maxRetry:  10,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+//Option 配置选项
+type Option func(*options)
+
+//WithDB 设置发件箱所在的数据库节点名，默认为db
+func WithDB(name string) Option {
+	return func(o *options) {
+		o.db = name
+	}
+}
+
+//WithQueue 设置发送消息的队列节点名，默认为queue
+func WithQueue(name string) Option {
+	return func(o *options) {
+		o.queue = name
+	}
+}
+
+//WithInterval 设置检查发件箱的时间间隔，默认为1秒
+func WithInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.interval = interval
+	}
+}
+
+//WithBatchSize 设置每次发送的最大消息数，默认为100
+func WithBatchSize(size int) Option {
+	return func(o *options) {
+		o.batchSize = size
+	}
+}
+
+//WithMaxRetry 设置最大重试次数，超过后标记为发送失败，默认为10次
+func WithMaxRetry(n int) Option {
+	return func(o *options) {
+		o.maxRetry = n
+	}
+}
@@ -0,0 +1,44 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/micro-plat/hydra/components/pkgs"
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/lib4go/db"
+)
+
+//TableName 发件箱表名
+const TableName = "hydra_outbox"
+
+const (
+	//StatusWaiting 待发送
+	StatusWaiting = 0
+
+	//StatusSent 已发送
+	StatusSent = 1
+
+	//StatusFailed 超过最大重试次数，发送失败
+	StatusFailed = 2
+)
+
+//insertSQL 写入发件箱，各数据库通用
+const insertSQL = `insert into hydra_outbox(msg_key,content,request_id,status,retry_count,next_time)
+values(@msg_key,@content,@request_id,0,0,@next_time)`
+
+//Send 在数据库事务中将消息写入发件箱，事务提交后由发件箱中继服务发送到消息队列
+func Send(trans db.IDBExecuter, key string, value interface{}, requestID ...string) error {
+	rid := ""
+	if len(requestID) > 0 {
+		rid = requestID[0]
+	} else if ctx, ok := context.GetContext(); ok {
+		rid = ctx.User().GetTraceID()
+	}
+	_, err := trans.Execute(insertSQL, map[string]interface{}{
+		"msg_key":    key,
+		"content":    pkgs.GetString(value),
+		"request_id": rid,
+		"next_time":  time.Now().Unix(),
+	})
+	return err
+}
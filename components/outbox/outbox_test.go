@@ -0,0 +1,51 @@
+// +build sqlite
+
+package outbox
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/db"
+	"github.com/micro-plat/lib4go/types"
+)
+
+func TestSend(t *testing.T) {
+	xdb, err := db.NewDB("sqlite", filepath.Join(t.TempDir(), "outbox.db"), 1, 1, 60)
+	assert.Equal(t, nil, err, "1. 创建数据库")
+	texture, err := getTexture("sqlite")
+	assert.Equal(t, nil, err, "1. 获取sql")
+	for i := 0; i < 2; i++ {
+		for _, sql := range texture.createStructure {
+			_, err := xdb.Execute(sql, nil)
+			assert.Equal(t, nil, err, "1. 创建发件箱表，重复执行不报错")
+		}
+	}
+
+	trans, _ := xdb.Begin()
+	assert.Equal(t, nil, Send(trans, "order.create", map[string]interface{}{"id": 1}, "r1"), "2. 事务中写入发件箱")
+	trans.Rollback()
+	n, _ := xdb.Scalar(backlogSQL, nil)
+	assert.Equal(t, int64(0), types.GetInt64(n), "2. 事务回滚后无消息")
+
+	trans, _ = xdb.Begin()
+	Send(trans, "order.create", map[string]interface{}{"id": 2}, "r2")
+	trans.Commit()
+	rows, err := xdb.Query(texture.getWaiting, map[string]interface{}{"now": time.Now().Unix(), "size": 10})
+	assert.Equal(t, nil, err, "3. 获取待发送消息")
+	assert.Equal(t, 1, rows.Len(), "3. 事务提交后有消息")
+	assert.Equal(t, `{"id":2}`, rows.Get(0).GetString("content"), "3. 消息内容")
+	assert.Equal(t, "r2", rows.Get(0).GetString("request_id"), "3. 请求编号")
+
+	id := rows.Get(0).GetInt64("id")
+	xdb.Execute(retrySQL, map[string]interface{}{"id": id, "status": StatusWaiting, "next_time": time.Now().Add(getBackoff(1)).Unix()})
+	rows, _ = xdb.Query(texture.getWaiting, map[string]interface{}{"now": time.Now().Unix(), "size": 10})
+	assert.Equal(t, 0, rows.Len(), "4. 发送失败后延后重试")
+
+	xdb.Execute(sentSQL, map[string]interface{}{"id": id, "send_time": time.Now().Unix()})
+	n, _ = xdb.Scalar(backlogSQL, nil)
+	assert.Equal(t, int64(0), types.GetInt64(n), "5. 发送成功后无积压")
+}
@@ -0,0 +1,265 @@
+package outbox
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/micro-plat/hydra/components"
+	"github.com/micro-plat/hydra/components/pkgs/metrics"
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/services"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/types"
+
+	xdb "github.com/micro-plat/hydra/conf/vars/db"
+)
+
+//maxBackoff 重试的最大间隔
+const maxBackoff = time.Minute * 5
+
+//Relay 发件箱中继服务，由集群主节点将发件箱中的消息发送到消息队列
+type Relay struct {
+	opts     *options
+	texture  *sqltexture
+	master   int32
+	backlog  metrics.Gauge
+	reporter metrics.IReporter
+	log      logger.ILogger
+	closeCh  chan struct{}
+	once     sync.Once
+}
+
+var current *Relay
+var currentType string
+var currentLock sync.Mutex
+
+//Enable 启用消息发件箱，服务器启动后由集群主节点发送发件箱中的消息，执行hydra db install时创建发件箱表
+func Enable(opts ...Option) {
+	o := newOptions(opts...)
+	global.Installer.DB.AddHandler(func() error {
+		return install(o)
+	})
+	global.OnReady(func() {
+		services.Def.OnStarted(func(c app.IAPPConf) error {
+			currentLock.Lock()
+			defer currentLock.Unlock()
+			if current != nil {
+				return nil
+			}
+			r, err := newRelay(c, o)
+			if err != nil {
+				return err
+			}
+			current = r
+			currentType = c.GetServerConf().GetServerType()
+			return r.start(c)
+		})
+		services.Def.OnClosing(func(c app.IAPPConf) error {
+			currentLock.Lock()
+			defer currentLock.Unlock()
+			if current != nil && currentType == c.GetServerConf().GetServerType() {
+				current.Close()
+				current = nil
+			}
+			return nil
+		})
+	})
+}
+
+//install 创建发件箱表
+func install(o *options) error {
+	vc, err := app.Cache.GetVarConf()
+	if err != nil {
+		return err
+	}
+	texture, err := getTextureByConf(vc, o.db)
+	if err != nil {
+		return err
+	}
+	db, err := components.Def.DB().GetDB(o.db)
+	if err != nil {
+		return err
+	}
+	for _, sql := range texture.createStructure {
+		if _, err := db.Execute(sql, nil); err != nil {
+			return fmt.Errorf("创建发件箱表%s失败:%w", TableName, err)
+		}
+	}
+	return nil
+}
+
+func getTextureByConf(vc conf.IVarConf, name string) (*sqltexture, error) {
+	var dbConf xdb.DB
+	if _, err := vc.GetObject("db", name, &dbConf); err != nil {
+		return nil, fmt.Errorf("获取数据库[db/%s]配置失败:%w", name, err)
+	}
+	return getTexture(dbConf.Provider)
+}
+
+func newRelay(c app.IAPPConf, o *options) (*Relay, error) {
+	texture, err := getTextureByConf(c.GetVarConf(), o.db)
+	if err != nil {
+		return nil, err
+	}
+	return &Relay{
+		opts:    o,
+		texture: texture,
+		log:     logger.New("outbox"),
+		closeCh: make(chan struct{}),
+	}, nil
+}
+
+//start 监控集群变化，启动积压消息统计与中继
+func (r *Relay) start(c app.IAPPConf) error {
+	cluster, err := c.GetServerConf().GetCluster()
+	if err != nil {
+		return err
+	}
+	r.setMaster(cluster.Current().GetIndex() == 0)
+	watcher := cluster.Watch()
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-r.closeCh:
+				return
+			case <-watcher.Notify():
+				r.setMaster(cluster.Current().GetIndex() == 0)
+			}
+		}
+	}()
+
+	metric, err := c.GetMetricConf()
+	if err == nil && !metric.Disable {
+		registry := metrics.NewRegistry()
+		name := metrics.MakeName("outbox.backlog", metrics.GAUGE, "db", r.opts.db, "host", global.LocalIP())
+		r.backlog = metrics.GetOrRegisterGauge(name, registry)
+		r.reporter, err = metrics.InfluxDB(registry, metric.Cron, metric.Host, metric.DataBase, metric.UserName, metric.Password, logger.New("metric"))
+		if err != nil {
+			return fmt.Errorf("初始化发件箱metric失败:%w", err)
+		}
+		go r.reporter.Run()
+	}
+	go r.loop()
+	return nil
+}
+
+func (r *Relay) setMaster(master bool) {
+	if master {
+		atomic.StoreInt32(&r.master, 1)
+		return
+	}
+	atomic.StoreInt32(&r.master, 0)
+}
+
+func (r *Relay) loop() {
+	tk := time.NewTicker(r.opts.interval)
+	defer tk.Stop()
+	for {
+		select {
+		case <-r.closeCh:
+			return
+		case <-tk.C:
+			if atomic.LoadInt32(&r.master) == 0 {
+				continue
+			}
+			if err := r.relay(); err != nil {
+				r.log.Errorf("发送发件箱消息失败:%v", err)
+			}
+			r.updateBacklog()
+		}
+	}
+}
+
+//relay 发送到期的待发送消息，发送失败的消息按重试次数延后发送
+func (r *Relay) relay() error {
+	db, err := components.Def.DB().GetDB(r.opts.db)
+	if err != nil {
+		return err
+	}
+	queue, err := components.Def.Queue().GetQueue(r.opts.queue)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	rows, err := db.Query(r.texture.getWaiting, map[string]interface{}{
+		"now":  now.Unix(),
+		"size": r.opts.batchSize,
+	})
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		id := row.GetInt64("id")
+		rid := make([]string, 0, 1)
+		if v := row.GetString("request_id"); v != "" {
+			rid = append(rid, v)
+		}
+		err := queue.Send(row.GetString("msg_key"), row.GetString("content"), rid...)
+		if err == nil {
+			if _, err := db.Execute(sentSQL, map[string]interface{}{"id": id, "send_time": now.Unix()}); err != nil {
+				return err
+			}
+			continue
+		}
+		r.log.Warnf("发送消息%d失败:%v", id, err)
+		retry := row.GetInt("retry_count") + 1
+		status := StatusWaiting
+		if retry >= r.opts.maxRetry {
+			status = StatusFailed
+			r.log.Errorf("消息%d超过最大重试次数%d,不再发送", id, r.opts.maxRetry)
+		}
+		_, err = db.Execute(retrySQL, map[string]interface{}{
+			"id":        id,
+			"status":    status,
+			"next_time": now.Add(getBackoff(retry)).Unix(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//updateBacklog 统计待发送的消息数
+func (r *Relay) updateBacklog() {
+	if r.backlog == nil {
+		return
+	}
+	db, err := components.Def.DB().GetDB(r.opts.db)
+	if err != nil {
+		return
+	}
+	n, err := db.Scalar(backlogSQL, nil)
+	if err != nil {
+		r.log.Errorf("统计发件箱积压消息失败:%v", err)
+		return
+	}
+	r.backlog.Update(types.GetInt64(n))
+}
+
+//getBackoff 获取第retry次重试的间隔，按2的指数增长
+func getBackoff(retry int) time.Duration {
+	if retry > 8 {
+		return maxBackoff
+	}
+	d := time.Second * time.Duration(1<<uint(retry))
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
+
+//Close 停止中继服务
+func (r *Relay) Close() {
+	r.once.Do(func() {
+		close(r.closeCh)
+		if r.reporter != nil {
+			r.reporter.Close()
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"fmt"
+	"strings"
+)
+
+type sqltexture struct {
+	createStructure []string
+	getWaiting      string
+}
+
+//通用的状态变更语句，时间由程序传入
+const (
+	sentSQL = `update hydra_outbox set status=1,send_time=@send_time where id=@id`
+
+	retrySQL = `update hydra_outbox set status=@status,retry_count=retry_count+1,next_time=@next_time where id=@id`
+
+	backlogSQL = `select count(1) from hydra_outbox where status=0`
+)
+
+var textures = map[string]*sqltexture{
+	"mysql": {
+		createStructure: []string{`CREATE TABLE IF NOT EXISTS hydra_outbox (
+		id bigint not null auto_increment comment '编号',
+		msg_key varchar(128) not null comment '队列名称',
+		content text not null comment '消息内容',
+		request_id varchar(64) comment '请求编号',
+		status tinyint default 0 not null comment '状态(0待发送,1已发送,2发送失败)',
+		retry_count int default 0 not null comment '重试次数',
+		next_time bigint not null comment '下次发送时间',
+		create_time datetime default current_timestamp not null comment '创建时间',
+		send_time bigint comment '发送时间'
+		,primary key (id)
+		,index idx_outbox_status(status,next_time)
+	) ENGINE=InnoDB auto_increment = 100 DEFAULT CHARSET=utf8mb4 COMMENT='消息发件箱'`},
+		getWaiting: `select t.id,t.msg_key,t.content,t.request_id,t.retry_count
+	from hydra_outbox t
+	where t.status = 0 and t.next_time <= @now
+	order by t.id
+	limit #size`,
+	},
+	"oracle": {
+		//oracle不支持if not exists，已存在时跳过，重复执行不报错
+		createStructure: []string{`declare
+	  n number;
+	begin
+	  select count(1) into n from user_tables where table_name = 'HYDRA_OUTBOX';
+	  if n = 0 then
+	    execute immediate 'create table HYDRA_OUTBOX
+	    (
+	      id          NUMBER(20) generated by default as identity,
+	      msg_key     VARCHAR2(128) not null,
+	      content     VARCHAR2(4000) not null,
+	      request_id  VARCHAR2(64),
+	      status      NUMBER(2) default 0 not null,
+	      retry_count NUMBER(10) default 0 not null,
+	      next_time   NUMBER(20) not null,
+	      create_time DATE default sysdate not null,
+	      send_time   NUMBER(20),
+	      constraint PK_HYDRA_OUTBOX primary key (id)
+	    )';
+	  end if;
+	end;`, `declare
+	  n number;
+	begin
+	  select count(1) into n from user_indexes where index_name = 'IDX_OUTBOX_STATUS';
+	  if n = 0 then
+	    execute immediate 'create index IDX_OUTBOX_STATUS on HYDRA_OUTBOX(status,next_time)';
+	  end if;
+	end;`},
+		getWaiting: `select * from (
+	select t.id,t.msg_key,t.content,t.request_id,t.retry_count
+	from hydra_outbox t
+	where t.status = 0 and t.next_time <= @now
+	order by t.id)
+	where rownum <= #size`,
+	},
+	"postgres": {
+		createStructure: []string{`create table if not exists hydra_outbox (
+		id bigserial primary key,
+		msg_key varchar(128) not null,
+		content text not null,
+		request_id varchar(64),
+		status smallint default 0 not null,
+		retry_count int default 0 not null,
+		next_time bigint not null,
+		create_time timestamp default current_timestamp not null,
+		send_time bigint
+	)`, `create index if not exists idx_outbox_status on hydra_outbox(status,next_time)`},
+		getWaiting: `select t.id,t.msg_key,t.content,t.request_id,t.retry_count
+	from hydra_outbox t
+	where t.status = 0 and t.next_time <= @now
+	order by t.id
+	limit #size`,
+	},
+	"sqlite": {
+		createStructure: []string{`create table if not exists hydra_outbox (
+		id integer primary key autoincrement,
+		msg_key varchar(128) not null,
+		content text not null,
+		request_id varchar(64),
+		status tinyint default 0 not null,
+		retry_count int default 0 not null,
+		next_time bigint not null,
+		create_time datetime default current_timestamp not null,
+		send_time bigint
+	)`, `create index if not exists idx_outbox_status on hydra_outbox(status,next_time)`},
+		getWaiting: `select t.id,t.msg_key,t.content,t.request_id,t.retry_count
+	from hydra_outbox t
+	where t.status = 0 and t.next_time <= @now
+	order by t.id
+	limit #size`,
+	},
+}
+
+func getTexture(provider string) (*sqltexture, error) {
+	switch p := strings.ToLower(provider); p {
+	case "ora", "oci8":
+		return textures["oracle"], nil
+	case "sqlite3":
+		return textures["sqlite"], nil
+	default:
+		if t, ok := textures[p]; ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("发件箱不支持的数据库类型:%s", provider)
+	}
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"compress/gzip"
+	r "context"
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
@@ -24,8 +25,19 @@ func (c *Client) Request(method string, url string, params string, charset strin
 // Request 发送http请求, method:http请求方法包括:get,post,delete,put等 url: 请求的HTTP地址,不包括参数,params:请求参数,
 // header,http请求头多个用/n分隔,每个键值之前用=号连接
 func (c *Client) HRequest(method string, url string, params string, charset string, header http.Header, cookies ...*http.Cookie) (content []byte, rspHeader http.Header, status int, err error) {
+	return c.HRequestByCtx(r.Background(), method, url, params, charset, header, cookies...)
+}
+
+//RequestByCtx 发送http请求，请求随ctx取消，并从ctx中获取链路跟踪编号
+func (c *Client) RequestByCtx(ctx r.Context, method string, url string, params string, charset string, header http.Header, cookies ...*http.Cookie) (content []byte, status int, err error) {
+	content, _, status, err = c.HRequestByCtx(ctx, method, url, params, charset, header, cookies...)
+	return content, status, err
+}
+
+//HRequestByCtx 发送http请求并返回响应头，请求随ctx取消，并从ctx中获取链路跟踪编号
+func (c *Client) HRequestByCtx(ctx r.Context, method string, url string, params string, charset string, header http.Header, cookies ...*http.Cookie) (content []byte, rspHeader http.Header, status int, err error) {
 	method = strings.ToUpper(method)
-	req, err := http.NewRequest(method, url, encoding.GetEncodeReader([]byte(params), charset))
+	req, err := http.NewRequestWithContext(ctx, method, url, encoding.GetEncodeReader([]byte(params), charset))
 	if err != nil {
 		return
 	}
@@ -44,8 +56,8 @@ func (c *Client) HRequest(method string, url string, params string, charset stri
 		req.Header.Set(i, strings.Join(v, ","))
 	}
 
-	if ctx, ok := context.GetContext(); ok {
-		req.Header.Set(context.XRequestID, ctx.User().GetTraceID())
+	if traceID := context.GetTraceID(ctx); traceID != "" {
+		req.Header.Set(context.XRequestID, traceID)
 	}
 	response, err := c.client.Do(req)
 	if response != nil {
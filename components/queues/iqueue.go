@@ -1,6 +1,10 @@
 package queues
 
 import (
+	r "context"
+	"fmt"
+	"time"
+
 	"github.com/micro-plat/hydra/components/pkgs"
 	"github.com/micro-plat/hydra/components/queues/mq"
 	"github.com/micro-plat/hydra/context"
@@ -10,6 +14,9 @@ import (
 //IQueue 消息队列
 type IQueue interface {
 	Send(key string, value interface{}, requestID ...string) error
+	SendByCtx(ctx r.Context, key string, value interface{}) error
+	SendDelay(key string, value interface{}, delay time.Duration, requestID ...string) error
+	SendAt(key string, value interface{}, t time.Time, requestID ...string) error
 	Pop(key string) (string, error)
 	Count(key string) (int64, error)
 }
@@ -22,26 +29,54 @@ type IComponentQueue interface {
 
 //queue 对输入KEY进行封装处理
 type queue struct {
-	q mq.IMQP
+	proto string
+	q     mq.IMQP
 }
 
 func newQueue(proto string, confRaw string) (q *queue, err error) {
-	q = &queue{}
+	q = &queue{proto: proto}
 	q.q, err = mq.NewMQP(proto, confRaw)
 	return q, err
 }
 
 //Send 发送消息
 func (q *queue) Send(key string, value interface{}, requestID ...string) error {
+	return q.q.Push(global.MQConf.GetQueueName(key), q.getMessage(key, value, requestID...))
+}
+
+//SendByCtx 发送消息，从ctx中获取链路跟踪编号，用于处理程序启动的goroutine中发送消息，
+//不随ctx撤销，处理程序返回后仍可发送
+func (q *queue) SendByCtx(ctx r.Context, key string, value interface{}) error {
+	return q.Send(key, value, context.GetTraceID(ctx))
+}
+
+//SendDelay 发送延迟消息，延迟时间到达后投递到队列
+func (q *queue) SendDelay(key string, value interface{}, delay time.Duration, requestID ...string) error {
+	if delay <= 0 {
+		return q.Send(key, value, requestID...)
+	}
+	p, ok := q.q.(mq.IMQPDelay)
+	if !ok {
+		return fmt.Errorf("%s不支持延迟消息", q.proto)
+	}
+	return p.PushDelay(global.MQConf.GetQueueName(key), q.getMessage(key, value, requestID...), delay)
+}
+
+//SendAt 发送定时消息，在指定时间投递到队列
+func (q *queue) SendAt(key string, value interface{}, t time.Time, requestID ...string) error {
+	return q.SendDelay(key, value, time.Until(t), requestID...)
+}
+
+func (q *queue) getMessage(key string, value interface{}, requestID ...string) string {
 	hd := make([]string, 0, 2)
-	if len(requestID) > 0 {
+	if len(requestID) > 0 && requestID[0] != "" {
 		hd = append(hd, context.XRequestID, requestID[0])
 	} else {
 		if ctx, ok := context.GetContext(); ok {
 			hd = append(hd, context.XRequestID, ctx.User().GetTraceID())
 		}
 	}
-	return q.q.Push(global.MQConf.GetQueueName(key), pkgs.GetStringByHeader(key, value, hd...))
+	return pkgs.GetStringByHeader(key, value, hd...)
 }
 
 //Pop 从队列中获取一个消息
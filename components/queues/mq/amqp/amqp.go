@@ -0,0 +1,94 @@
+package amqp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/micro-plat/lib4go/types"
+	"github.com/rabbitmq/amqp091-go"
+
+	queueamqp "github.com/micro-plat/hydra/conf/vars/queue/amqp"
+)
+
+//Proto amqp
+const Proto = queueamqp.Proto
+
+//declare 声明持久化队列，配置了交换机时以队列名为路由键绑定到交换机
+func declare(ch *amqp091.Channel, conf *queueamqp.AMQP, queue string) error {
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if conf.Exchange == "" {
+		return nil
+	}
+	if err := ch.ExchangeDeclare(conf.Exchange, conf.GetExchangeType(), true, false, false, false, nil); err != nil {
+		return err
+	}
+	return ch.QueueBind(queue, queue, conf.Exchange, false, nil)
+}
+
+//deliverAtHeader 记录延迟消息到期时间(unix毫秒)的消息头
+const deliverAtHeader = "x-hydra-deliver-at"
+
+//delayBuckets 延迟队列的消息存活时间，从1秒开始每级翻倍(最长约18小时)。
+//每个队列最多声明len(delayBuckets)个延迟队列，超过最长存活时间的消息到期前由消费者转投到下一个延迟队列
+var delayBuckets = func() []time.Duration {
+	buckets := make([]time.Duration, 17)
+	for i := range buckets {
+		buckets[i] = time.Second << uint(i)
+	}
+	return buckets
+}()
+
+//getDelayBucket 获取不超过delay的最长存活时间，小于最短存活时间时使用最短存活时间
+func getDelayBucket(delay time.Duration) time.Duration {
+	bucket := delayBuckets[0]
+	for _, b := range delayBuckets {
+		if b > delay {
+			break
+		}
+		bucket = b
+	}
+	return bucket
+}
+
+//getRemaining 获取延迟消息距到期的剩余时间，非延迟消息或已到期时返回0
+func getRemaining(headers amqp091.Table) time.Duration {
+	at := types.GetInt64(headers[deliverAtHeader])
+	if at == 0 {
+		return 0
+	}
+	if d := time.Until(time.Unix(0, at*int64(time.Millisecond))); d > 0 {
+		return d
+	}
+	return 0
+}
+
+//declareDelay 声明延迟队列，消息在队列中存活bucket后由服务器转投到目标队列，空闲的延迟队列自动删除
+func declareDelay(ch *amqp091.Channel, queue string, bucket time.Duration) (string, error) {
+	ms := bucket.Milliseconds()
+	name := fmt.Sprintf("%s.delay.%d", queue, ms)
+	_, err := ch.QueueDeclare(name, true, false, false, false, amqp091.Table{
+		"x-message-ttl":             ms,
+		"x-expires":                 ms + time.Minute.Milliseconds(),
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": queue,
+	})
+	return name, err
+}
+
+//publishDelay 记录到期时间并发送到存活时间不超过delay的延迟队列，到期前转投到目标队列的消息由消费者再次延迟
+func publishDelay(ch *amqp091.Channel, queue string, msg amqp091.Publishing, delay time.Duration) error {
+	headers := amqp091.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[deliverAtHeader] = time.Now().Add(delay).UnixNano() / int64(time.Millisecond)
+	msg.Headers = headers
+	name, err := declareDelay(ch, queue, getDelayBucket(delay))
+	if err != nil {
+		return fmt.Errorf("声明延迟队列%s失败:%w", queue, err)
+	}
+	return ch.PublishWithContext(context.Background(), "", name, false, false, msg)
+}
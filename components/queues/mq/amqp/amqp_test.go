@@ -0,0 +1,31 @@
+package amqp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/rabbitmq/amqp091-go"
+)
+
+func TestGetDelayBucket(t *testing.T) {
+	assert.Equal(t, time.Second, getDelayBucket(time.Millisecond*10), "1. 小于最短存活时间时使用最短存活时间")
+	assert.Equal(t, time.Second*4, getDelayBucket(time.Second*4), "2. 与存活时间一致")
+	assert.Equal(t, time.Second*4, getDelayBucket(time.Second*7), "3. 使用不超过延迟时间的存活时间")
+	last := delayBuckets[len(delayBuckets)-1]
+	assert.Equal(t, last, getDelayBucket(time.Hour*24*30), "4. 超过最长存活时间时使用最长存活时间")
+
+	names := map[time.Duration]bool{}
+	for d := time.Millisecond; d < time.Hour*24*30; d = d*3/2 + time.Millisecond {
+		names[getDelayBucket(d)] = true
+	}
+	assert.Equal(t, len(delayBuckets), len(names), "5. 任意延迟时间使用的延迟队列数量固定")
+}
+
+func TestGetRemaining(t *testing.T) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	assert.Equal(t, time.Duration(0), getRemaining(nil), "1. 非延迟消息")
+	assert.Equal(t, time.Duration(0), getRemaining(amqp091.Table{deliverAtHeader: now - 1000}), "2. 已到期")
+	d := getRemaining(amqp091.Table{deliverAtHeader: now + time.Hour.Milliseconds()})
+	assert.Equal(t, true, d > time.Minute*59 && d <= time.Hour, "3. 未到期时返回剩余时间")
+}
@@ -0,0 +1,300 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/utility"
+	"github.com/rabbitmq/amqp091-go"
+
+	queueamqp "github.com/micro-plat/hydra/conf/vars/queue/amqp"
+)
+
+//ReconnectSpan 连接断开后重新连接的时间间隔
+var ReconnectSpan = time.Second * 3
+
+//channel 消费使用的通道
+type channel interface {
+	Cancel(consumer string, noWait bool) error
+	Close() error
+}
+
+//consumerQueue 队列的消费信息，每个队列使用独立的通道，预取数量与并发数一致
+type consumerQueue struct {
+	name        string
+	tag         string
+	concurrency int
+	callback    func(mq.IMQCMessage)
+	ch          channel
+	closed      bool
+	lk          sync.Mutex
+	wg          sync.WaitGroup
+}
+
+//setChannel 订阅或重新订阅后设置通道，已取消消费时关闭新通道并返回false
+func (q *consumerQueue) setChannel(ch channel) bool {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	if q.closed {
+		ch.Close()
+		return false
+	}
+	q.ch = ch
+	return true
+}
+
+//close 取消消费，wait为true时正在处理的消息处理完成后关闭通道
+func (q *consumerQueue) close(wait bool) {
+	q.lk.Lock()
+	ch := q.ch
+	q.closed = true
+	q.lk.Unlock()
+	if ch == nil {
+		return
+	}
+	if !wait {
+		ch.Close()
+		return
+	}
+	if err := ch.Cancel(q.tag, false); err != nil {
+		ch.Close()
+		return
+	}
+	go func() {
+		q.wg.Wait()
+		ch.Close()
+	}()
+}
+
+//Consumer amqp消费者
+type Consumer struct {
+	conn     *amqp091.Connection
+	queues   cmap.ConcurrentMap
+	closeCh  chan struct{}
+	done     int32
+	lk       sync.Mutex
+	once     sync.Once
+	log      logger.ILogger
+	confOpts *queueamqp.AMQP
+	dial     func(url string) (*amqp091.Connection, error)
+}
+
+//NewConsumerByConfig 创建新的Consumer
+func NewConsumerByConfig(confOpts *queueamqp.AMQP) (consumer *Consumer, err error) {
+	consumer = &Consumer{
+		queues:   cmap.New(2),
+		closeCh:  make(chan struct{}),
+		log:      logger.GetSession("mq.amqp", logger.CreateSession()),
+		confOpts: confOpts,
+		dial:     amqp091.Dial,
+	}
+	return consumer, nil
+}
+
+//Connect 连接服务器
+func (consumer *Consumer) Connect() (err error) {
+	conn, err := consumer.dial(consumer.confOpts.Address)
+	if err != nil {
+		return fmt.Errorf("连接amqp服务器失败:%w", err)
+	}
+	consumer.lk.Lock()
+	consumer.conn = conn
+	consumer.lk.Unlock()
+	go consumer.reconnect(conn.NotifyClose(make(chan *amqp091.Error, 1)))
+	return nil
+}
+
+//reconnect 连接断开后重新连接，并重新订阅所有队列
+func (consumer *Consumer) reconnect(notify chan *amqp091.Error) {
+	for {
+		select {
+		case <-consumer.closeCh:
+			return
+		case err := <-notify:
+			if consumer.isClosed() {
+				return
+			}
+			consumer.log.Warnf("amqp连接已断开:%v", err)
+		}
+		for {
+			select {
+			case <-consumer.closeCh:
+				return
+			case <-time.After(ReconnectSpan): //延迟重连
+			}
+			conn, err := consumer.dial(consumer.confOpts.Address)
+			if err != nil {
+				consumer.log.Error("amqp连接失败:", err)
+				continue
+			}
+			consumer.lk.Lock()
+			if consumer.isClosed() {
+				consumer.lk.Unlock()
+				conn.Close()
+				return
+			}
+			consumer.conn = conn
+			consumer.lk.Unlock()
+			notify = conn.NotifyClose(make(chan *amqp091.Error, 1))
+			break
+		}
+		consumer.log.Info("amqp成功连接到服务器")
+
+		//重新订阅所有消息
+		consumer.queues.IterCb(func(k string, v interface{}) bool {
+			if err := consumer.subscribe(v.(*consumerQueue)); err != nil {
+				consumer.log.Errorf("重新订阅队列%s失败:%v", k, err)
+			}
+			return true
+		})
+	}
+}
+
+//Consume 注册消费信息
+func (consumer *Consumer) Consume(queue string, concurrency int, callback func(mq.IMQCMessage)) (err error) {
+	if strings.EqualFold(queue, "") {
+		return errors.New("队列名字不能为空")
+	}
+	if callback == nil {
+		return errors.New("回调函数不能为nil")
+	}
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+	_, _, err = consumer.queues.SetIfAbsentCb(queue, func(input ...interface{}) (c interface{}, err error) {
+		q := &consumerQueue{
+			name:        input[0].(string),
+			tag:         fmt.Sprintf("%s-%s", global.LocalIP(), utility.GetGUID()[0:6]),
+			concurrency: concurrency,
+			callback:    callback,
+		}
+		if err := consumer.subscribe(q); err != nil {
+			return nil, err
+		}
+		return q, nil
+	}, queue)
+	return
+}
+
+//subscribe 创建通道，声明队列并开始消费
+func (consumer *Consumer) subscribe(q *consumerQueue) error {
+	consumer.lk.Lock()
+	conn := consumer.conn
+	consumer.lk.Unlock()
+	if conn == nil {
+		return errors.New("未连接到amqp服务器")
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	if err := ch.Qos(q.concurrency, 0, false); err != nil {
+		ch.Close()
+		return err
+	}
+	if err := declare(ch, consumer.confOpts, q.name); err != nil {
+		ch.Close()
+		return fmt.Errorf("声明队列%s失败:%w", q.name, err)
+	}
+	deliveries, err := ch.Consume(q.name, q.tag, false, false, false, false, nil)
+	if err != nil {
+		ch.Close()
+		return err
+	}
+	if !q.setChannel(ch) {
+		return nil
+	}
+	maxRetry := consumer.confOpts.GetMaxRetry()
+	publish := func(queue string, msg amqp091.Publishing, delay time.Duration) error {
+		return republish(ch, queue, msg, delay)
+	}
+	for i := 0; i < q.concurrency; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for d := range deliveries {
+				if delay := getRemaining(d.Headers); delay > 0 {
+					consumer.redelay(ch, q.name, d, delay)
+					continue
+				}
+				q.callback(&Message{d: d, queue: q.name, maxRetry: maxRetry, publish: publish})
+			}
+		}()
+	}
+	return nil
+}
+
+//republish 将处理失败的消息经延迟队列放回队列，delay为0时转入死信队列
+func republish(ch *amqp091.Channel, queue string, msg amqp091.Publishing, delay time.Duration) error {
+	if delay > 0 {
+		return publishDelay(ch, queue, msg, delay)
+	}
+	delete(msg.Headers, deliverAtHeader)
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("声明死信队列%s失败:%w", queue, err)
+	}
+	return ch.PublishWithContext(context.Background(), "", queue, false, false, msg)
+}
+
+//redelay 延迟时间超过最长存活时间的消息未到期时再次转投到延迟队列
+func (consumer *Consumer) redelay(ch *amqp091.Channel, queue string, d amqp091.Delivery, delay time.Duration) {
+	err := publishDelay(ch, queue, amqp091.Publishing{
+		Headers:      d.Headers,
+		ContentType:  d.ContentType,
+		DeliveryMode: amqp091.Persistent,
+		Body:         d.Body,
+	}, delay)
+	if err != nil {
+		consumer.log.Errorf("转投延迟消息失败(%s):%v", queue, err)
+		d.Nack(false, true)
+		return
+	}
+	d.Ack(false)
+}
+
+//UnConsume 取消注册消费，正在处理的消息处理完成后关闭通道
+func (consumer *Consumer) UnConsume(queue string) {
+	if c, ok := consumer.queues.Pop(queue); ok {
+		c.(*consumerQueue).close(true)
+	}
+}
+
+//Close 关闭当前连接
+func (consumer *Consumer) Close() {
+	consumer.once.Do(func() {
+		atomic.StoreInt32(&consumer.done, 1)
+		close(consumer.closeCh)
+	})
+	consumer.queues.RemoveIterCb(func(key string, value interface{}) bool {
+		value.(*consumerQueue).close(false)
+		return true
+	})
+	consumer.lk.Lock()
+	defer consumer.lk.Unlock()
+	if consumer.conn != nil && !consumer.conn.IsClosed() {
+		consumer.conn.Close()
+	}
+}
+
+func (consumer *Consumer) isClosed() bool {
+	return atomic.LoadInt32(&consumer.done) == 1
+}
+
+type cresolver struct {
+}
+
+func (s *cresolver) Resolve(confRaw string) (mq.IMQC, error) {
+	return NewConsumerByConfig(queueamqp.NewByRaw(confRaw))
+}
+func init() {
+	mq.RegisterConsumer(Proto, &cresolver{})
+}
@@ -0,0 +1,111 @@
+package amqp
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/rabbitmq/amqp091-go"
+
+	queueamqp "github.com/micro-plat/hydra/conf/vars/queue/amqp"
+)
+
+//testChannel 记录取消消费与关闭操作的通道
+type testChannel struct {
+	canceled int32
+	closed   int32
+}
+
+func (c *testChannel) Cancel(consumer string, noWait bool) error {
+	atomic.AddInt32(&c.canceled, 1)
+	return nil
+}
+
+func (c *testChannel) Close() error {
+	atomic.AddInt32(&c.closed, 1)
+	return nil
+}
+
+func TestConsumerQueue_Close(t *testing.T) {
+	q := &consumerQueue{tag: "t"}
+	ch := &testChannel{}
+	assert.Equal(t, true, q.setChannel(ch), "1. 设置通道")
+
+	q.wg.Add(1)
+	q.close(true)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&ch.canceled), "2. 取消消费")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&ch.closed), "2. 正在处理的消息未完成时不关闭通道")
+	q.wg.Done()
+	time.Sleep(time.Millisecond * 20)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&ch.closed), "3. 消息处理完成后关闭通道")
+
+	ch = &testChannel{}
+	assert.Equal(t, false, q.setChannel(ch), "4. 取消消费后重新订阅")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&ch.closed), "4. 取消消费后重新订阅的通道立即关闭")
+}
+
+func TestConsumerQueue_CloseWhileReconnect(t *testing.T) {
+	q := &consumerQueue{tag: "t"}
+	var wg sync.WaitGroup
+	channels := make([]*testChannel, 20)
+	for i := range channels {
+		channels[i] = &testChannel{}
+		wg.Add(1)
+		go func(ch *testChannel) {
+			defer wg.Done()
+			q.setChannel(ch)
+		}(channels[i])
+	}
+	q.close(false)
+	wg.Wait()
+	for _, ch := range channels {
+		if atomic.LoadInt32(&ch.closed) != 1 && q.ch != ch {
+			t.Fatal("1. 取消消费后重新订阅的通道未关闭")
+		}
+	}
+	assert.Equal(t, true, q.ch == nil || atomic.LoadInt32(&q.ch.(*testChannel).closed) == 1, "2. 取消消费时关闭当前通道")
+}
+
+func TestConsumer_Reconnect(t *testing.T) {
+	ReconnectSpan = time.Millisecond * 10
+	defer func() { ReconnectSpan = time.Second * 3 }()
+	consumer, _ := NewConsumerByConfig(queueamqp.New("amqp://127.0.0.1:5672/"))
+	var dials int32
+	consumer.dial = func(string) (*amqp091.Connection, error) {
+		atomic.AddInt32(&dials, 1)
+		return nil, errors.New("connection refused")
+	}
+
+	notify := make(chan *amqp091.Error, 1)
+	exited := make(chan struct{})
+	go func() {
+		consumer.reconnect(notify)
+		close(exited)
+	}()
+	notify <- amqp091.ErrClosed
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, true, atomic.LoadInt32(&dials) > 1, "1. 连接断开后持续重连")
+
+	consumer.Close()
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("2. 关闭后停止重连")
+	}
+	n := atomic.LoadInt32(&dials)
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, n, atomic.LoadInt32(&dials), "2. 关闭后不再连接")
+
+	consumer, _ = NewConsumerByConfig(queueamqp.New("amqp://127.0.0.1:5672/"))
+	consumer.dial = func(string) (*amqp091.Connection, error) {
+		t.Fatal("3. 关闭后收到断开通知不重连")
+		return nil, nil
+	}
+	consumer.Close()
+	notify = make(chan *amqp091.Error, 1)
+	notify <- amqp091.ErrClosed
+	consumer.reconnect(notify)
+}
@@ -0,0 +1,59 @@
+package amqp
+
+import (
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/lib4go/types"
+	"github.com/rabbitmq/amqp091-go"
+)
+
+//retryHeader 记录消息已重新投递次数的消息头
+const retryHeader = "x-hydra-retry"
+
+//Message amqp消息
+type Message struct {
+	d        amqp091.Delivery
+	queue    string
+	maxRetry int
+
+	//publish 向队列发送消息，delay大于0时经延迟队列转投
+	publish func(queue string, msg amqp091.Publishing, delay time.Duration) error
+}
+
+//Ack 确认消息
+func (m *Message) Ack() error {
+	return m.d.Ack(false)
+}
+
+//Nack 消息处理失败，退避后重新放入队列，超过最大投递次数时转入死信队列。
+//不使用Nack(requeue)直接放回队列，避免消息在队列头部反复投递
+func (m *Message) Nack() error {
+	retry := types.GetInt(m.d.Headers[retryHeader]) + 1
+	headers := amqp091.Table{}
+	for k, v := range m.d.Headers {
+		headers[k] = v
+	}
+	headers[retryHeader] = int32(retry)
+	msg := amqp091.Publishing{
+		Headers:      headers,
+		ContentType:  m.d.ContentType,
+		DeliveryMode: amqp091.Persistent,
+		Body:         m.d.Body,
+	}
+	queue, delay := m.queue, mq.GetRetryDelay(retry)
+	if retry > m.maxRetry {
+		queue, delay = mq.GetDeadLetterQueue(m.queue), 0
+	}
+	if err := m.publish(queue, msg, delay); err != nil {
+		//转投失败时放回队列，避免丢失消息
+		m.d.Nack(false, true)
+		return err
+	}
+	return m.d.Ack(false)
+}
+
+//GetMessage 获取消息
+func (m *Message) GetMessage() string {
+	return string(m.d.Body)
+}
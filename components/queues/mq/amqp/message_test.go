@@ -0,0 +1,71 @@
+package amqp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/rabbitmq/amqp091-go"
+)
+
+//acknowledger 记录消息的确认结果
+type acknowledger struct {
+	acked   int
+	requeue int
+}
+
+func (a *acknowledger) Ack(tag uint64, multiple bool) error {
+	a.acked++
+	return nil
+}
+
+func (a *acknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	if requeue {
+		a.requeue++
+	}
+	return nil
+}
+
+func (a *acknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+type published struct {
+	queue string
+	retry int32
+	delay time.Duration
+}
+
+func TestMessage_Nack(t *testing.T) {
+	var last published
+	var fail error
+	publish := func(queue string, msg amqp091.Publishing, delay time.Duration) error {
+		last = published{queue: queue, retry: msg.Headers[retryHeader].(int32), delay: delay}
+		return fail
+	}
+	newMsg := func(a *acknowledger, headers amqp091.Table) *Message {
+		return &Message{d: amqp091.Delivery{Acknowledger: a, Headers: headers, Body: []byte("1")}, queue: "order", maxRetry: 2, publish: publish}
+	}
+
+	a := &acknowledger{}
+	assert.Equal(t, nil, newMsg(a, nil).Nack(), "1. 首次处理失败")
+	assert.Equal(t, published{queue: "order", retry: 1, delay: mq.GetRetryDelay(1)}, last, "1. 经延迟队列放回原队列")
+	assert.Equal(t, 1, a.acked, "1. 转投后确认原消息，不直接放回队列")
+
+	a = &acknowledger{}
+	assert.Equal(t, nil, newMsg(a, amqp091.Table{retryHeader: int32(1), "x-tag": "a"}).Nack(), "2. 再次处理失败")
+	assert.Equal(t, published{queue: "order", retry: 2, delay: mq.GetRetryDelay(2)}, last, "2. 等待时间翻倍")
+
+	a = &acknowledger{}
+	assert.Equal(t, nil, newMsg(a, amqp091.Table{retryHeader: int32(2)}).Nack(), "3. 超过最大投递次数")
+	assert.Equal(t, published{queue: "order.dlq", retry: 3}, last, "3. 转入死信队列")
+	assert.Equal(t, 1, a.acked, "3. 转入死信队列后确认原消息")
+
+	fail = errors.New("通道已关闭")
+	a = &acknowledger{}
+	assert.Equal(t, fail, newMsg(a, nil).Nack(), "4. 转投失败")
+	assert.Equal(t, 0, a.acked, "4. 转投失败时不确认")
+	assert.Equal(t, 1, a.requeue, "4. 转投失败时放回队列")
+}
@@ -0,0 +1,165 @@
+package amqp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/rabbitmq/amqp091-go"
+
+	queueamqp "github.com/micro-plat/hydra/conf/vars/queue/amqp"
+)
+
+//Producer amqp消息发送
+type Producer struct {
+	conn     *amqp091.Connection
+	ch       *amqp091.Channel
+	declared map[string]bool
+	lk       sync.Mutex
+	done     bool
+	confOpts *queueamqp.AMQP
+}
+
+//NewProducerByConfig 根据配置创建消息发送器
+func NewProducerByConfig(confOpts *queueamqp.AMQP) (m *Producer, err error) {
+	m = &Producer{confOpts: confOpts}
+	if _, err = m.getChannel(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+//getChannel 获取通道，连接或通道已关闭(如服务器重启)时重新创建
+func (c *Producer) getChannel() (*amqp091.Channel, error) {
+	if c.ch != nil && !c.ch.IsClosed() {
+		return c.ch, nil
+	}
+	if c.conn == nil || c.conn.IsClosed() {
+		conn, err := amqp091.Dial(c.confOpts.Address)
+		if err != nil {
+			return nil, fmt.Errorf("连接amqp服务器失败:%w", err)
+		}
+		c.conn = conn
+	}
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	c.ch = ch
+	c.declared = make(map[string]bool)
+	return ch, nil
+}
+
+//getQueue 获取通道并声明队列
+func (c *Producer) getQueue(key string) (*amqp091.Channel, error) {
+	ch, err := c.getChannel()
+	if err != nil {
+		return nil, err
+	}
+	if c.declared[key] {
+		return ch, nil
+	}
+	if err := declare(ch, c.confOpts, key); err != nil {
+		return nil, fmt.Errorf("声明队列%s失败:%w", key, err)
+	}
+	c.declared[key] = true
+	return ch, nil
+}
+
+//Push 向队列发送持久化消息
+func (c *Producer) Push(key string, value string) error {
+	if c.done {
+		return fmt.Errorf("队列已关闭")
+	}
+	if key == "" {
+		return fmt.Errorf("队列名称不能为空")
+	}
+	if value == "" {
+		return fmt.Errorf("放入队列的数据不能为空")
+	}
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	ch, err := c.getQueue(key)
+	if err != nil {
+		return err
+	}
+	return ch.PublishWithContext(context.Background(), c.confOpts.Exchange, key, false, false, amqp091.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp091.Persistent,
+		Body:         []byte(value),
+	})
+}
+
+//PushDelay 发送延迟消息，使用固定存活时间的延迟队列与死信转投实现
+func (c *Producer) PushDelay(key string, value string, delay time.Duration) error {
+	if c.done {
+		return fmt.Errorf("队列已关闭")
+	}
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	ch, err := c.getQueue(key)
+	if err != nil {
+		return err
+	}
+	return publishDelay(ch, key, amqp091.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp091.Persistent,
+		Body:         []byte(value),
+	}, delay)
+}
+
+//Pop 移除并且返回队列中的第一个消息
+func (c *Producer) Pop(key string) (string, error) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	ch, err := c.getQueue(key)
+	if err != nil {
+		return "", err
+	}
+	d, ok, err := ch.Get(key, true)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", mq.Nil
+	}
+	return string(d.Body), nil
+}
+
+//Count 获取队列中的消息个数
+func (c *Producer) Count(key string) (int64, error) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	ch, err := c.getQueue(key)
+	if err != nil {
+		return 0, err
+	}
+	q, err := ch.QueueDeclarePassive(key, true, false, false, false, nil)
+	if err != nil {
+		return 0, err
+	}
+	return int64(q.Messages), nil
+}
+
+//Close 释放资源
+func (c *Producer) Close() error {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.done = true
+	if c.conn == nil || c.conn.IsClosed() {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+type presolver struct {
+}
+
+func (s *presolver) Resolve(confRaw string) (mq.IMQP, error) {
+	return NewProducerByConfig(queueamqp.NewByRaw(confRaw))
+}
+func init() {
+	mq.RegisterProducer(Proto, &presolver{})
+}
@@ -0,0 +1,196 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/segmentio/kafka-go"
+
+	queuekafka "github.com/micro-plat/hydra/conf/vars/queue/kafka"
+)
+
+//Consumer kafka消费者，按消费者组消费，消息处理成功后提交偏移量
+type Consumer struct {
+	confOpts *queuekafka.Kafka
+	dialer   *kafka.Dialer
+	writer   *kafka.Writer
+	queues   cmap.ConcurrentMap
+	closeCh  chan struct{}
+	done     int32
+	once     sync.Once
+	log      logger.ILogger
+}
+
+//NewConsumerByConfig 创建新的Consumer
+func NewConsumerByConfig(confOpts *queuekafka.Kafka) (consumer *Consumer, err error) {
+	consumer = &Consumer{
+		confOpts: confOpts,
+		dialer:   getDialer(confOpts),
+		writer:   &kafka.Writer{Addr: kafka.TCP(confOpts.Brokers...), RequiredAcks: kafka.RequireAll, Transport: getTransport(confOpts)},
+		queues:   cmap.New(2),
+		closeCh:  make(chan struct{}),
+		log:      logger.GetSession("mq.kafka", logger.CreateSession()),
+	}
+	return consumer, nil
+}
+
+//Connect 检查是否能连接到kafka服务器
+func (consumer *Consumer) Connect() (err error) {
+	for _, broker := range consumer.confOpts.Brokers {
+		conn, err := consumer.dialer.Dial("tcp", broker)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		consumer.log.Warnf("连接kafka服务器%s失败:%v", broker, err)
+	}
+	return fmt.Errorf("无法连接到kafka服务器:%v", consumer.confOpts.Brokers)
+}
+
+//Consume 注册消费信息，同一分区的消息由同一协程按顺序处理
+func (consumer *Consumer) Consume(queue string, concurrency int, callback func(mq.IMQCMessage)) (err error) {
+	if strings.EqualFold(queue, "") {
+		return errors.New("队列名字不能为空")
+	}
+	if callback == nil {
+		return errors.New("回调函数不能为nil")
+	}
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+	_, _, err = consumer.queues.SetIfAbsentCb(queue, func(input ...interface{}) (c interface{}, err error) {
+		queue := input[0].(string)
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:     consumer.confOpts.Brokers,
+			GroupID:     consumer.confOpts.GetGroupID(global.Def.GetPlatName(), global.Def.GetSysName()),
+			Topic:       queue,
+			Dialer:      consumer.dialer,
+			StartOffset: getStartOffset(consumer.confOpts),
+			ErrorLogger: kafka.LoggerFunc(consumer.log.Errorf),
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		workers := make([]chan *Message, concurrency)
+		for i := range workers {
+			workers[i] = make(chan *Message, 1)
+			go process(ctx, workers[i], consumer.confOpts.GetMaxRetry(), consumer.deadLetter(queue), callback)
+		}
+		go func() {
+			defer reader.Close()
+			defer func() {
+				for _, ch := range workers {
+					close(ch)
+				}
+			}()
+			for {
+				msg, err := reader.FetchMessage(ctx)
+				if err != nil {
+					if ctx.Err() != nil || consumer.isClosed() {
+						return
+					}
+					consumer.log.Errorf("从kafka中获取消息失败(%s):%v", queue, err)
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(time.Second):
+					}
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case workers[msg.Partition%len(workers)] <- newMessage(reader.CommitMessages, msg):
+				}
+			}
+		}()
+		return cancel, nil
+	}, queue)
+	return
+}
+
+//UnConsume 取消注册消费
+func (consumer *Consumer) UnConsume(queue string) {
+	if c, ok := consumer.queues.Get(queue); ok {
+		c.(context.CancelFunc)()
+	}
+	consumer.queues.Remove(queue)
+}
+
+//Close 关闭当前连接
+func (consumer *Consumer) Close() {
+	consumer.once.Do(func() {
+		atomic.StoreInt32(&consumer.done, 1)
+		close(consumer.closeCh)
+	})
+	consumer.queues.RemoveIterCb(func(key string, value interface{}) bool {
+		value.(context.CancelFunc)()
+		return true
+	})
+	consumer.writer.Close()
+}
+
+//deadLetter 将超过最大投递次数的消息转入死信主题后提交偏移量，死信主题由服务器自动创建
+func (consumer *Consumer) deadLetter(queue string) func(*Message) error {
+	return func(m *Message) error {
+		dlq := mq.GetDeadLetterQueue(queue)
+		msg := kafka.Message{Topic: dlq, Key: m.msg.Key, Value: m.msg.Value, Headers: m.msg.Headers}
+		if err := consumer.writer.WriteMessages(context.Background(), msg); err != nil {
+			consumer.log.Errorf("消息转入死信队列%s失败:%v", dlq, err)
+			return err
+		}
+		consumer.log.Warnf("消息超过最大投递次数，已转入死信队列%s(offset:%d)", dlq, m.msg.Offset)
+		return m.Ack()
+	}
+}
+
+func (consumer *Consumer) isClosed() bool {
+	return atomic.LoadInt32(&consumer.done) == 1
+}
+
+//process 按顺序处理分配给当前协程的分区消息。消息处理失败(Nack)时按mq.GetRetryDelay退避后重新投递，
+//处理成功前不处理后续消息，偏移量不会越过失败的消息，效果等同于将分区重置到失败消息的偏移量。
+//重新投递超过maxRetry次后由dead转入死信主题，转入失败时等待后重试，不跳过该消息
+func process(ctx context.Context, ch chan *Message, maxRetry int, dead func(*Message) error, callback func(mq.IMQCMessage)) {
+	for message := range ch {
+		for retry := 1; ; retry++ {
+			message.nacked = false
+			callback(message)
+			if !message.nacked {
+				break
+			}
+			if retry > maxRetry {
+				for dead(message) != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(mq.MaxRetryInterval):
+					}
+				}
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(mq.GetRetryDelay(retry)):
+			}
+		}
+	}
+}
+
+type cresolver struct {
+}
+
+func (s *cresolver) Resolve(confRaw string) (mq.IMQC, error) {
+	return NewConsumerByConfig(queuekafka.NewByRaw(confRaw))
+}
+func init() {
+	mq.RegisterConsumer(Proto, &cresolver{})
+}
@@ -0,0 +1,94 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/segmentio/kafka-go"
+)
+
+func TestProcess(t *testing.T) {
+	mq.RetryInterval = time.Millisecond * 10
+	defer func() { mq.RetryInterval = time.Second }()
+	noDead := func(*Message) error { return nil }
+	committed := make([]int64, 0, 2)
+	commit := func(_ context.Context, msgs ...kafka.Message) error {
+		for _, m := range msgs {
+			committed = append(committed, m.Offset)
+		}
+		return nil
+	}
+
+	//第1条消息前两次处理失败
+	handled := make([]int64, 0, 4)
+	fails := 2
+	callback := func(m mq.IMQCMessage) {
+		msg := m.(*Message)
+		handled = append(handled, msg.msg.Offset)
+		if msg.msg.Offset == 1 && fails > 0 {
+			fails--
+			m.Nack()
+			return
+		}
+		m.Ack()
+	}
+
+	ch := make(chan *Message, 2)
+	ch <- newMessage(commit, kafka.Message{Offset: 1, Value: []byte("1")})
+	ch <- newMessage(commit, kafka.Message{Offset: 2, Value: []byte("2")})
+	close(ch)
+	process(context.Background(), ch, 3, noDead, callback)
+	assert.Equal(t, []int64{1, 1, 1, 2}, handled, "1. 处理失败的消息重新投递，成功前不处理后续消息")
+	assert.Equal(t, []int64{1, 2}, committed, "2. 只提交处理成功的消息")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch = make(chan *Message, 1)
+	ch <- newMessage(commit, kafka.Message{Offset: 3, Value: []byte("3")})
+	done := make(chan struct{})
+	go func() {
+		process(ctx, ch, 3, noDead, func(m mq.IMQCMessage) { m.Nack() })
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("3. 取消消费后停止重新投递")
+	}
+
+	//超过最大投递次数后转入死信队列并提交
+	dead := make([]int64, 0, 1)
+	deadFails := 1
+	mq.MaxRetryInterval = time.Millisecond * 10
+	defer func() { mq.MaxRetryInterval = time.Minute }()
+	deadLetter := func(m *Message) error {
+		if deadFails > 0 {
+			deadFails--
+			return errors.New("写入失败")
+		}
+		dead = append(dead, m.msg.Offset)
+		return m.Ack()
+	}
+	handled = handled[:0]
+	committed = committed[:0]
+	ch = make(chan *Message, 2)
+	ch <- newMessage(commit, kafka.Message{Offset: 4, Value: []byte("4")})
+	ch <- newMessage(commit, kafka.Message{Offset: 5, Value: []byte("5")})
+	close(ch)
+	process(context.Background(), ch, 2, deadLetter, func(m mq.IMQCMessage) {
+		msg := m.(*Message)
+		handled = append(handled, msg.msg.Offset)
+		if msg.msg.Offset == 4 {
+			m.Nack()
+			return
+		}
+		m.Ack()
+	})
+	assert.Equal(t, []int64{4, 4, 4, 5}, handled, "4. 重新投递最大次数后不再投递")
+	assert.Equal(t, []int64{4}, dead, "5. 转入死信队列，失败时重试")
+	assert.Equal(t, []int64{4, 5}, committed, "6. 转入死信队列后提交偏移量，继续处理后续消息")
+}
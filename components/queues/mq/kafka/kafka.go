@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+
+	queuekafka "github.com/micro-plat/hydra/conf/vars/queue/kafka"
+)
+
+//Proto kafka
+const Proto = queuekafka.Proto
+
+//HeaderKey 消息键在__header__中的名称
+const HeaderKey = "Kafka-Key"
+
+func getDialer(conf *queuekafka.Kafka) *kafka.Dialer {
+	dialer := &kafka.Dialer{
+		ClientID:  global.LocalIP(),
+		Timeout:   getDialTimeout(conf),
+		DualStack: true,
+	}
+	if conf.UserName != "" {
+		dialer.SASLMechanism = plain.Mechanism{Username: conf.UserName, Password: conf.Password}
+	}
+	return dialer
+}
+
+func getTransport(conf *queuekafka.Kafka) *kafka.Transport {
+	transport := &kafka.Transport{
+		ClientID:    global.LocalIP(),
+		DialTimeout: getDialTimeout(conf),
+	}
+	if conf.UserName != "" {
+		transport.SASL = plain.Mechanism{Username: conf.UserName, Password: conf.Password}
+	}
+	return transport
+}
+
+func getDialTimeout(conf *queuekafka.Kafka) time.Duration {
+	if conf.DialTimeout > 0 {
+		return time.Second * time.Duration(conf.DialTimeout)
+	}
+	return time.Second * 10
+}
+
+func getStartOffset(conf *queuekafka.Kafka) int64 {
+	if conf.StartOffset == "last" {
+		return kafka.LastOffset
+	}
+	return kafka.FirstOffset
+}
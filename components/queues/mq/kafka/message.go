@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/micro-plat/lib4go/types"
+	"github.com/segmentio/kafka-go"
+)
+
+//Message kafka消息
+type Message struct {
+	commit  func(context.Context, ...kafka.Message) error
+	msg     kafka.Message
+	message string
+	nacked  bool
+}
+
+func newMessage(commit func(context.Context, ...kafka.Message) error, msg kafka.Message) *Message {
+	return &Message{commit: commit, msg: msg, message: envelope(msg)}
+}
+
+//Ack 消息处理成功，提交偏移量
+func (m *Message) Ack() error {
+	return m.commit(context.Background(), m.msg)
+}
+
+//Nack 消息处理失败，不提交偏移量，消费者退避后重新投递该消息，超过最大投递次数时转入死信主题
+func (m *Message) Nack() error {
+	m.nacked = true
+	return nil
+}
+
+//GetMessage 获取消息
+func (m *Message) GetMessage() string {
+	return m.message
+}
+
+//envelope 将消息键与消息头放入__header__中，消息本身已包含的头信息优先
+func envelope(msg kafka.Message) string {
+	header := make(map[string]interface{}, len(msg.Headers)+1)
+	for _, h := range msg.Headers {
+		header[h.Key] = string(h.Value)
+	}
+	if len(msg.Key) > 0 {
+		header[HeaderKey] = string(msg.Key)
+	}
+
+	//hydra发送的消息
+	input := make(map[string]interface{})
+	if err := json.Unmarshal(msg.Value, &input); err == nil {
+		if _, ok := input["__data__"]; ok {
+			if v, ok := input["__header__"].(map[string]interface{}); ok {
+				for k, v := range v {
+					header[k] = v
+				}
+			}
+			input["__header__"] = header
+			if buff, err := json.Marshal(input); err == nil {
+				return string(buff)
+			}
+		}
+	}
+
+	//其它程序发送的消息
+	out := types.NewXMap()
+	out.SetValue("__data__", msg.Value)
+	out.SetValue("__header__", header)
+	return string(out.Marshal())
+}
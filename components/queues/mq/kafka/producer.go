@@ -0,0 +1,91 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/segmentio/kafka-go"
+
+	queuekafka "github.com/micro-plat/hydra/conf/vars/queue/kafka"
+)
+
+//Producer kafka消息发送
+type Producer struct {
+	writer   *kafka.Writer
+	done     int32
+	once     sync.Once
+	confOpts *queuekafka.Kafka
+}
+
+//NewProducerByConfig 根据配置创建消息发送器
+func NewProducerByConfig(confOpts *queuekafka.Kafka) (m *Producer, err error) {
+	m = &Producer{confOpts: confOpts}
+	m.writer = &kafka.Writer{
+		Addr:         kafka.TCP(confOpts.Brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		Transport:    getTransport(confOpts),
+	}
+	return m, nil
+}
+
+//Push 向主题key发送消息，__header__中的头信息作为消息头发送
+func (c *Producer) Push(key string, value string) error {
+	if atomic.LoadInt32(&c.done) == 1 {
+		return fmt.Errorf("队列已关闭")
+	}
+	if key == "" {
+		return fmt.Errorf("队列名称不能为空")
+	}
+	if value == "" {
+		return fmt.Errorf("放入队列的数据不能为空")
+	}
+	msg := kafka.Message{Topic: key, Value: []byte(value)}
+	input := make(map[string]interface{})
+	if err := json.Unmarshal(msg.Value, &input); err == nil {
+		if v, ok := input["__header__"].(map[string]interface{}); ok {
+			for k, v := range v {
+				if k == HeaderKey {
+					msg.Key = []byte(fmt.Sprint(v))
+					continue
+				}
+				msg.Headers = append(msg.Headers, kafka.Header{Key: k, Value: []byte(fmt.Sprint(v))})
+			}
+		}
+	}
+	return c.writer.WriteMessages(context.Background(), msg)
+}
+
+//Pop 移除并且返回 key 对应的 list 的第一个元素。
+func (c *Producer) Pop(key string) (string, error) {
+	return "", fmt.Errorf("kafka不支持pop方法")
+}
+
+//Count 获取列表中的元素个数
+func (c *Producer) Count(key string) (int64, error) {
+	return 0, fmt.Errorf("kafka不支持Count方法")
+}
+
+//Close 释放资源
+func (c *Producer) Close() error {
+	atomic.StoreInt32(&c.done, 1)
+	var err error
+	c.once.Do(func() {
+		err = c.writer.Close()
+	})
+	return err
+}
+
+type presolver struct {
+}
+
+func (s *presolver) Resolve(confRaw string) (mq.IMQP, error) {
+	return NewProducerByConfig(queuekafka.NewByRaw(confRaw))
+}
+func init() {
+	mq.RegisterProducer(Proto, &presolver{})
+}
@@ -0,0 +1,79 @@
+package lmq
+
+import (
+	"sync"
+	"time"
+)
+
+//wheelTick 时间轮的刻度
+const wheelTick = time.Millisecond * 100
+
+//wheelSize 时间轮的槽数，转动一周为1分钟
+const wheelSize = 600
+
+type timerTask struct {
+	rounds int
+	key    string
+	value  string
+}
+
+//timerWheel 进程内时间轮，延迟消息到期后放入队列
+type timerWheel struct {
+	slots [wheelSize][]*timerTask
+	pos   int
+	lk    sync.Mutex
+	once  sync.Once
+}
+
+var wheel = &timerWheel{}
+
+//add 添加延迟消息，超过一周的消息记录剩余圈数
+func (w *timerWheel) add(key string, value string, delay time.Duration) {
+	w.once.Do(func() {
+		go w.run()
+	})
+	ticks := int((delay + wheelTick - 1) / wheelTick)
+	if ticks <= 0 {
+		ticks = 1
+	}
+	w.lk.Lock()
+	defer w.lk.Unlock()
+	idx := (w.pos + ticks) % wheelSize
+	w.slots[idx] = append(w.slots[idx], &timerTask{rounds: (ticks - 1) / wheelSize, key: key, value: value})
+}
+
+func (w *timerWheel) run() {
+	tk := time.NewTicker(wheelTick)
+	defer tk.Stop()
+	for range tk.C {
+		for _, task := range w.next() {
+			ch := GetOrAddQueue(task.key)
+			select {
+			case ch <- task.value:
+			default:
+				//队列已满时稍后重试
+				w.add(task.key, task.value, time.Second)
+			}
+		}
+	}
+}
+
+//next 转动一格，返回到期的消息
+func (w *timerWheel) next() []*timerTask {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+	w.pos = (w.pos + 1) % wheelSize
+	tasks := w.slots[w.pos]
+	due := make([]*timerTask, 0, len(tasks))
+	remain := tasks[:0]
+	for _, task := range tasks {
+		if task.rounds > 0 {
+			task.rounds--
+			remain = append(remain, task)
+			continue
+		}
+		due = append(due, task)
+	}
+	w.slots[w.pos] = remain
+	return due
+}
@@ -0,0 +1,41 @@
+package lmq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestTimerWheel(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay time.Duration
+		ticks int
+	}{
+		{name: "1. 延迟不足一个刻度", delay: time.Millisecond, ticks: 1},
+		{name: "2. 延迟200毫秒", delay: time.Millisecond * 200, ticks: 2},
+		{name: "3. 延迟超过时间轮一周", delay: wheelTick*wheelSize + time.Millisecond*200, ticks: wheelSize + 2},
+	}
+	for _, tt := range tests {
+		w := &timerWheel{}
+		w.once.Do(func() {})
+		w.add("delay", "hello", tt.delay)
+		for i := 1; i < tt.ticks; i++ {
+			assert.Equal(t, 0, len(w.next()), tt.name+" 未到期")
+		}
+		due := w.next()
+		assert.Equal(t, 1, len(due), tt.name+" 到期")
+	}
+}
+
+func TestPushDelay(t *testing.T) {
+	p, _ := New()
+	assert.Equal(t, nil, p.PushDelay("delay", "hello", time.Millisecond*200), "1. 添加延迟消息")
+	n, _ := p.Count("delay")
+	assert.Equal(t, int64(0), n, "2. 未到期时不在队列中")
+	time.Sleep(time.Millisecond * 500)
+	v, err := p.Pop("delay")
+	assert.Equal(t, nil, err, "3. 到期后放入队列")
+	assert.Equal(t, "hello", v, "3. 到期后放入队列")
+}
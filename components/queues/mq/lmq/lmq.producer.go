@@ -2,6 +2,7 @@ package lmq
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/micro-plat/hydra/components/queues/mq"
 	"github.com/micro-plat/lib4go/concurrent/cmap"
@@ -44,6 +45,12 @@ func (c *Producer) Push(key string, value string) error {
 	}
 }
 
+//PushDelay 添加延迟消息，由进程内时间轮在到期后放入队列
+func (c *Producer) PushDelay(key string, value string, delay time.Duration) error {
+	wheel.add(key, value, delay)
+	return nil
+}
+
 // Pop 移除并且返回 key 对应的 list 的第一个元素。
 func (c *Producer) Pop(key string) (string, error) {
 	ch := GetOrAddQueue(key)
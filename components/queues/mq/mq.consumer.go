@@ -19,6 +19,11 @@ type IMQC interface {
 	Close()
 }
 
+//IDelayMover 将到期的延迟消息移入队列，多个节点消费同一队列时仅由集群主节点移动
+type IDelayMover interface {
+	SetMaster(master bool)
+}
+
 //mqcResover 定义消息消费解析器
 type mqcResover interface {
 	Resolve(confRaw string) (IMQC, error)
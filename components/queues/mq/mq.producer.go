@@ -3,6 +3,7 @@ package mq
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 var Nil = errors.New("nil")
@@ -15,6 +16,11 @@ type IMQP interface {
 	Close() error
 }
 
+//IMQPDelay 支持延迟投递的消息生产
+type IMQPDelay interface {
+	PushDelay(key string, value string, delay time.Duration) error
+}
+
 //imqpResover 定义配置文件转换方法
 type imqpResover interface {
 	Resolve(confRaw string) (IMQP, error)
@@ -0,0 +1,29 @@
+package mq
+
+import "time"
+
+//RetryInterval 消息处理失败(Nack)后首次重新投递的等待时间，之后每次翻倍
+var RetryInterval = time.Second
+
+//MaxRetryInterval 重新投递的最大等待时间
+var MaxRetryInterval = time.Minute
+
+//DeadLetterSuffix 死信队列后缀，超过最大投递次数的消息转入"队列名.dlq"
+const DeadLetterSuffix = ".dlq"
+
+//GetRetryDelay 获取第retry次重新投递的等待时间
+func GetRetryDelay(retry int) time.Duration {
+	d := RetryInterval
+	for i := 1; i < retry && d < MaxRetryInterval; i++ {
+		d *= 2
+	}
+	if d > MaxRetryInterval {
+		return MaxRetryInterval
+	}
+	return d
+}
+
+//GetDeadLetterQueue 获取队列的死信队列名称
+func GetDeadLetterQueue(queue string) string {
+	return queue + DeadLetterSuffix
+}
@@ -0,0 +1,174 @@
+package nats
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/nats-io/nats.go"
+
+	queuenats "github.com/micro-plat/hydra/conf/vars/queue/nats"
+)
+
+//FetchWait 每次拉取消息的最长等待时间
+var FetchWait = time.Second * 2
+
+//FetchRetrySpan 拉取消息失败(如连接断开)后重试的时间间隔
+var FetchRetrySpan = time.Second
+
+//Consumer nats JetStream消费者，每个队列使用同名的持久化拉取消费者
+type Consumer struct {
+	nc       *nats.Conn
+	js       nats.JetStreamContext
+	queues   cmap.ConcurrentMap
+	closeCh  chan struct{}
+	done     int32
+	once     sync.Once
+	log      logger.ILogger
+	confOpts *queuenats.NATS
+}
+
+//NewConsumerByConfig 创建新的Consumer
+func NewConsumerByConfig(confOpts *queuenats.NATS) (consumer *Consumer, err error) {
+	consumer = &Consumer{
+		queues:   cmap.New(2),
+		closeCh:  make(chan struct{}),
+		log:      logger.GetSession("mq.nats", logger.CreateSession()),
+		confOpts: confOpts,
+	}
+	return consumer, nil
+}
+
+//Connect 连接服务器
+func (consumer *Consumer) Connect() (err error) {
+	consumer.nc, consumer.js, err = connect(consumer.confOpts)
+	return err
+}
+
+//Consume 注册消费信息，每次最多拉取并发数个消息
+func (consumer *Consumer) Consume(queue string, concurrency int, callback func(mq.IMQCMessage)) (err error) {
+	if strings.EqualFold(queue, "") {
+		return errors.New("队列名字不能为空")
+	}
+	if callback == nil {
+		return errors.New("回调函数不能为nil")
+	}
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+	_, _, err = consumer.queues.SetIfAbsentCb(queue, func(input ...interface{}) (c interface{}, err error) {
+		queue := input[0].(string)
+		if err := declare(consumer.js, consumer.confOpts, queue); err != nil {
+			return nil, err
+		}
+		//确认超时须大于重新投递的最大等待时间，否则退避期间服务器会提前重新投递
+		sub, err := consumer.js.PullSubscribe(queue, getName(queue), nats.MaxAckPending(concurrency), nats.AckWait(mq.MaxRetryInterval+time.Second*30))
+		if err != nil {
+			return nil, err
+		}
+		unconsumeCh := make(chan struct{})
+		msgChan := make(chan *nats.Msg, concurrency)
+		maxRetry := consumer.confOpts.GetMaxRetry()
+		for i := 0; i < concurrency; i++ {
+			go func() {
+				for msg := range msgChan {
+					callback(newMessage(msg, queue, maxRetry, consumer.deadLetter))
+				}
+			}()
+		}
+		go consumer.fetch(sub, queue, concurrency, msgChan, unconsumeCh)
+		return unconsumeCh, nil
+	}, queue)
+	return
+}
+
+//fetcher 拉取消息的订阅
+type fetcher interface {
+	Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error)
+	Unsubscribe() error
+}
+
+//fetch 循环拉取消息，连接断开期间拉取失败时等待客户端自动重连后继续拉取，关闭或取消消费后退出
+func (consumer *Consumer) fetch(sub fetcher, queue string, concurrency int, msgChan chan<- *nats.Msg, unconsumeCh chan struct{}) {
+	defer close(msgChan)
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case <-consumer.closeCh:
+			return
+		case <-unconsumeCh:
+			return
+		default:
+		}
+		msgs, err := sub.Fetch(concurrency, nats.MaxWait(FetchWait))
+		if err != nil && err != nats.ErrTimeout {
+			if !consumer.isClosed() {
+				consumer.log.Errorf("从nats中获取消息失败(%s):%v", queue, err)
+			}
+			select {
+			case <-consumer.closeCh:
+			case <-unconsumeCh:
+			case <-time.After(FetchRetrySpan):
+			}
+			continue
+		}
+		for _, msg := range msgs {
+			msgChan <- msg
+		}
+	}
+}
+
+//deadLetter 将超过最大投递次数的消息发送到死信队列
+func (consumer *Consumer) deadLetter(queue string, data []byte) error {
+	if err := declare(consumer.js, consumer.confOpts, queue); err != nil {
+		consumer.log.Error(err)
+		return err
+	}
+	if _, err := consumer.js.Publish(queue, data); err != nil {
+		consumer.log.Errorf("消息转入死信队列%s失败:%v", queue, err)
+		return err
+	}
+	consumer.log.Warnf("消息超过最大投递次数，已转入死信队列%s", queue)
+	return nil
+}
+
+//UnConsume 取消注册消费
+func (consumer *Consumer) UnConsume(queue string) {
+	if c, ok := consumer.queues.Pop(queue); ok {
+		close(c.(chan struct{}))
+	}
+}
+
+//Close 关闭当前连接
+func (consumer *Consumer) Close() {
+	consumer.once.Do(func() {
+		atomic.StoreInt32(&consumer.done, 1)
+		close(consumer.closeCh)
+	})
+	consumer.queues.RemoveIterCb(func(key string, value interface{}) bool {
+		close(value.(chan struct{}))
+		return true
+	})
+	if consumer.nc != nil {
+		consumer.nc.Drain()
+	}
+}
+
+func (consumer *Consumer) isClosed() bool {
+	return atomic.LoadInt32(&consumer.done) == 1
+}
+
+type cresolver struct {
+}
+
+func (s *cresolver) Resolve(confRaw string) (mq.IMQC, error) {
+	return NewConsumerByConfig(queuenats.NewByRaw(confRaw))
+}
+func init() {
+	mq.RegisterConsumer(Proto, &cresolver{})
+}
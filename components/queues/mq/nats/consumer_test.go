@@ -0,0 +1,85 @@
+package nats
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/nats-io/nats.go"
+
+	queuenats "github.com/micro-plat/hydra/conf/vars/queue/nats"
+)
+
+//testFetcher 前fails次拉取返回连接断开，之后返回一条消息，再之后等待超时
+type testFetcher struct {
+	fails        int32
+	fetched      int32
+	unsubscribed int32
+}
+
+func (f *testFetcher) Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error) {
+	n := atomic.AddInt32(&f.fetched, 1)
+	switch {
+	case n <= f.fails:
+		return nil, nats.ErrDisconnected
+	case n == f.fails+1:
+		return []*nats.Msg{{Data: []byte("1")}}, nil
+	default:
+		time.Sleep(time.Millisecond)
+		return nil, nats.ErrTimeout
+	}
+}
+
+func (f *testFetcher) Unsubscribe() error {
+	atomic.AddInt32(&f.unsubscribed, 1)
+	return nil
+}
+
+func TestConsumer_Fetch(t *testing.T) {
+	FetchRetrySpan = time.Millisecond * 10
+	defer func() { FetchRetrySpan = time.Second }()
+	consumer, _ := NewConsumerByConfig(queuenats.New("nats://127.0.0.1:4222"))
+	sub := &testFetcher{fails: 3}
+	msgChan := make(chan *nats.Msg, 1)
+	unconsumeCh := make(chan struct{})
+	go consumer.fetch(sub, "order", 1, msgChan, unconsumeCh)
+
+	select {
+	case msg := <-msgChan:
+		assert.Equal(t, "1", string(msg.Data), "1. 连接恢复后继续拉取消息")
+	case <-time.After(time.Second):
+		t.Fatal("1. 连接恢复后未拉取到消息")
+	}
+
+	consumer.Close()
+	select {
+	case _, ok := <-msgChan:
+		assert.Equal(t, false, ok, "2. 关闭后停止拉取")
+	case <-time.After(time.Second):
+		t.Fatal("2. 关闭后未停止拉取")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&sub.unsubscribed), "2. 关闭后取消订阅")
+}
+
+func TestConsumer_FetchUnConsume(t *testing.T) {
+	FetchRetrySpan = time.Hour
+	defer func() { FetchRetrySpan = time.Second }()
+	consumer, _ := NewConsumerByConfig(queuenats.New("nats://127.0.0.1:4222"))
+	sub := &testFetcher{fails: 1 << 30}
+	msgChan := make(chan *nats.Msg)
+	unconsumeCh := make(chan struct{})
+	consumer.queues.Set("order", unconsumeCh)
+	go consumer.fetch(sub, "order", 1, msgChan, unconsumeCh)
+	time.Sleep(time.Millisecond * 10)
+
+	consumer.UnConsume("order")
+	consumer.UnConsume("order")
+	select {
+	case _, ok := <-msgChan:
+		assert.Equal(t, false, ok, "1. 连接断开等待重试时取消消费，立即停止拉取")
+	case <-time.After(time.Second):
+		t.Fatal("1. 取消消费后未停止拉取")
+	}
+	consumer.Close()
+}
@@ -0,0 +1,59 @@
+package nats
+
+import (
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/nats-io/nats.go"
+)
+
+//jsMsg JetStream消息的确认操作
+type jsMsg interface {
+	Ack(opts ...nats.AckOpt) error
+	Nak(opts ...nats.AckOpt) error
+	Metadata() (*nats.MsgMetadata, error)
+}
+
+//Message nats消息
+type Message struct {
+	msg      jsMsg
+	data     []byte
+	queue    string
+	maxRetry int
+
+	//dead 将消息发送到死信队列
+	dead func(queue string, data []byte) error
+}
+
+func newMessage(msg *nats.Msg, queue string, maxRetry int, dead func(string, []byte) error) *Message {
+	return &Message{msg: msg, data: msg.Data, queue: queue, maxRetry: maxRetry, dead: dead}
+}
+
+//Ack 确认消息
+func (m *Message) Ack() error {
+	return m.msg.Ack()
+}
+
+//Nack 消息处理失败，按投递次数退避后由服务器重新投递，超过最大投递次数时转入死信队列。
+//Nak会使服务器立即重新投递，因此等待退避时间后再调用
+func (m *Message) Nack() error {
+	md, err := m.msg.Metadata()
+	if err != nil {
+		return m.msg.Nak()
+	}
+	retry := int(md.NumDelivered)
+	if retry > m.maxRetry {
+		if err := m.dead(mq.GetDeadLetterQueue(m.queue), m.data); err != nil {
+			time.AfterFunc(mq.MaxRetryInterval, func() { m.msg.Nak() })
+			return err
+		}
+		return m.msg.Ack()
+	}
+	time.AfterFunc(mq.GetRetryDelay(retry), func() { m.msg.Nak() })
+	return nil
+}
+
+//GetMessage 获取消息
+func (m *Message) GetMessage() string {
+	return string(m.data)
+}
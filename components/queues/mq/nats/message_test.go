@@ -0,0 +1,66 @@
+package nats
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/nats-io/nats.go"
+)
+
+//msg 记录消息的确认结果
+type msg struct {
+	delivered uint64
+	acked     int32
+	naked     int32
+}
+
+func (m *msg) Ack(opts ...nats.AckOpt) error {
+	atomic.AddInt32(&m.acked, 1)
+	return nil
+}
+
+func (m *msg) Nak(opts ...nats.AckOpt) error {
+	atomic.AddInt32(&m.naked, 1)
+	return nil
+}
+
+func (m *msg) Metadata() (*nats.MsgMetadata, error) {
+	return &nats.MsgMetadata{NumDelivered: m.delivered}, nil
+}
+
+func TestMessage_Nack(t *testing.T) {
+	mq.RetryInterval = time.Millisecond * 50
+	mq.MaxRetryInterval = time.Millisecond * 50
+	defer func() { mq.RetryInterval, mq.MaxRetryInterval = time.Second, time.Minute }()
+	dead := make([]string, 0, 1)
+	var fail error
+	deadLetter := func(queue string, data []byte) error {
+		dead = append(dead, queue)
+		return fail
+	}
+
+	m := &msg{delivered: 1}
+	message := &Message{msg: m, data: []byte("1"), queue: "order", maxRetry: 2, dead: deadLetter}
+	assert.Equal(t, nil, message.Nack(), "1. 首次处理失败")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&m.naked), "1. 不立即重新投递")
+	time.Sleep(time.Millisecond * 200)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&m.naked), "2. 等待退避时间后重新投递")
+
+	m = &msg{delivered: 3}
+	message = &Message{msg: m, data: []byte("1"), queue: "order", maxRetry: 2, dead: deadLetter}
+	assert.Equal(t, nil, message.Nack(), "3. 超过最大投递次数")
+	assert.Equal(t, []string{"order.dlq"}, dead, "3. 转入死信队列")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&m.acked), "3. 转入死信队列后确认原消息")
+
+	fail = errors.New("发送失败")
+	m = &msg{delivered: 3}
+	message = &Message{msg: m, data: []byte("1"), queue: "order", maxRetry: 2, dead: deadLetter}
+	assert.Equal(t, fail, message.Nack(), "4. 转入死信队列失败")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&m.acked), "4. 转入死信队列失败时不确认")
+	time.Sleep(time.Millisecond * 200)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&m.naked), "4. 等待后重新投递")
+}
@@ -0,0 +1,71 @@
+package nats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/nats-io/nats.go"
+
+	queuenats "github.com/micro-plat/hydra/conf/vars/queue/nats"
+)
+
+//Proto nats
+const Proto = queuenats.Proto
+
+//connect 连接到nats服务器，连接断开后持续自动重连
+func connect(conf *queuenats.NATS) (*nats.Conn, nats.JetStreamContext, error) {
+	opts := []nats.Option{
+		nats.Name(fmt.Sprintf("hydra-%s", global.LocalIP())),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(time.Second * 3),
+	}
+	if conf.UserName != "" {
+		opts = append(opts, nats.UserInfo(conf.UserName, conf.Password))
+	}
+	if conf.Token != "" {
+		opts = append(opts, nats.Token(conf.Token))
+	}
+	nc, err := nats.Connect(strings.Join(conf.Servers, ","), opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("连接nats服务器失败:%w", err)
+	}
+	js, err := nc.JetStream()
+	if err != nil {
+		nc.Close()
+		return nil, nil, err
+	}
+	return nc, js, nil
+}
+
+//declare 声明与队列同名的工作队列流，消息确认后从流中删除
+func declare(js nats.JetStreamContext, conf *queuenats.NATS, queue string) error {
+	name := getName(queue)
+	if _, err := js.StreamInfo(name); err == nil {
+		return nil
+	}
+	_, err := js.AddStream(&nats.StreamConfig{
+		Name:      name,
+		Subjects:  []string{queue},
+		Retention: nats.WorkQueuePolicy,
+		Storage:   nats.FileStorage,
+		Replicas:  conf.GetReplicas(),
+	})
+	if err != nil {
+		return fmt.Errorf("声明队列%s失败:%w", queue, err)
+	}
+	return nil
+}
+
+//getName 获取流及消费者名称，名称中只能包含字母、数字、下划线与中划线
+func getName(queue string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, queue)
+}
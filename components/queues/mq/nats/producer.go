@@ -0,0 +1,134 @@
+package nats
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/nats-io/nats.go"
+
+	queuenats "github.com/micro-plat/hydra/conf/vars/queue/nats"
+)
+
+//Producer nats JetStream消息发送
+type Producer struct {
+	nc       *nats.Conn
+	js       nats.JetStreamContext
+	declared map[string]bool
+	pops     map[string]*nats.Subscription
+	lk       sync.Mutex
+	done     bool
+	confOpts *queuenats.NATS
+}
+
+//NewProducerByConfig 根据配置创建消息发送器
+func NewProducerByConfig(confOpts *queuenats.NATS) (m *Producer, err error) {
+	m = &Producer{
+		confOpts: confOpts,
+		declared: make(map[string]bool),
+		pops:     make(map[string]*nats.Subscription),
+	}
+	m.nc, m.js, err = connect(confOpts)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *Producer) declare(key string) error {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	if c.declared[key] {
+		return nil
+	}
+	if err := declare(c.js, c.confOpts, key); err != nil {
+		return err
+	}
+	c.declared[key] = true
+	return nil
+}
+
+//Push 向队列发送消息
+func (c *Producer) Push(key string, value string) error {
+	if c.done {
+		return fmt.Errorf("队列已关闭")
+	}
+	if key == "" {
+		return fmt.Errorf("队列名称不能为空")
+	}
+	if value == "" {
+		return fmt.Errorf("放入队列的数据不能为空")
+	}
+	if err := c.declare(key); err != nil {
+		return err
+	}
+	_, err := c.js.Publish(key, []byte(value))
+	return err
+}
+
+//Pop 移除并且返回队列中的第一个消息
+func (c *Producer) Pop(key string) (string, error) {
+	if err := c.declare(key); err != nil {
+		return "", err
+	}
+	sub, err := c.getPopSubscription(key)
+	if err != nil {
+		return "", err
+	}
+	msgs, err := sub.Fetch(1, nats.MaxWait(time.Second))
+	if err == nats.ErrTimeout || err == nil && len(msgs) == 0 {
+		return "", mq.Nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := msgs[0].Ack(); err != nil {
+		return "", err
+	}
+	return string(msgs[0].Data), nil
+}
+
+//Count 获取队列中未确认的消息个数
+func (c *Producer) Count(key string) (int64, error) {
+	if err := c.declare(key); err != nil {
+		return 0, err
+	}
+	info, err := c.js.StreamInfo(getName(key))
+	if err != nil {
+		return 0, err
+	}
+	return int64(info.State.Msgs), nil
+}
+
+//getPopSubscription 获取队列的拉取订阅，与消费者使用相同的持久化消费者
+func (c *Producer) getPopSubscription(key string) (*nats.Subscription, error) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	if sub, ok := c.pops[key]; ok {
+		return sub, nil
+	}
+	sub, err := c.js.PullSubscribe(key, getName(key))
+	if err != nil {
+		return nil, err
+	}
+	c.pops[key] = sub
+	return sub, nil
+}
+
+//Close 释放资源
+func (c *Producer) Close() error {
+	c.done = true
+	c.nc.Close()
+	return nil
+}
+
+type presolver struct {
+}
+
+func (s *presolver) Resolve(confRaw string) (mq.IMQP, error) {
+	return NewProducerByConfig(queuenats.NewByRaw(confRaw))
+}
+func init() {
+	mq.RegisterProducer(Proto, &presolver{})
+}
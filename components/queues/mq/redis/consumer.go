@@ -3,6 +3,7 @@ package redis
 import (
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"errors"
@@ -33,6 +34,7 @@ type Consumer struct {
 	lk         sync.Mutex
 	header     []string
 	once       sync.Once
+	slave      int32
 	log        logger.ILogger
 	ConfOpts   *varredis.Redis
 }
@@ -122,11 +124,48 @@ func (consumer *Consumer) Consume(queue string, concurrency int, callback func(m
 			}
 			close(msgChan)
 		}()
+		go consumer.moveDelay(queue, unconsumeCh)
 		return unconsumeCh, nil
 	}, queue)
 	return
 }
 
+//moveDelay 主节点定时将到期的延迟消息移入队列
+func (consumer *Consumer) moveDelay(queue string, unconsumeCh chan struct{}) {
+	tk := time.NewTicker(DelayMoveSpan)
+	defer tk.Stop()
+	for {
+		select {
+		case <-consumer.closeCh:
+			return
+		case <-unconsumeCh:
+			return
+		case <-tk.C:
+			if consumer.client == nil || consumer.done || atomic.LoadInt32(&consumer.slave) == 1 {
+				continue
+			}
+			for {
+				n, err := moveDelay(consumer.client, queue)
+				if err != nil {
+					consumer.log.Errorf("移动延迟消息失败(%s):%v", queue, err)
+				}
+				if n < delayBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+//SetMaster 设置当前节点是否为集群主节点，仅主节点移动延迟消息
+func (consumer *Consumer) SetMaster(master bool) {
+	if master {
+		atomic.StoreInt32(&consumer.slave, 0)
+		return
+	}
+	atomic.StoreInt32(&consumer.slave, 1)
+}
+
 //UnConsume 取消注册消费
 func (consumer *Consumer) UnConsume(queue string) {
 	if consumer.client == nil {
@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	rds "github.com/go-redis/redis"
+	"github.com/micro-plat/hydra/components/pkgs/redis"
+	"github.com/micro-plat/lib4go/utility"
+)
+
+//DelayMoveSpan 检查到期延迟消息的时间间隔
+var DelayMoveSpan = time.Millisecond * 500
+
+//delayBatchSize 每次移动的最大消息数
+const delayBatchSize = 100
+
+//moveScript 原子地将到期的延迟消息从有序集合移入队列，成员格式为"唯一编号|消息"
+var moveScript = rds.NewScript(`
+local items = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, item in ipairs(items) do
+	redis.call('ZREM', KEYS[1], item)
+	local idx = string.find(item, '|', 1, true)
+	redis.call('RPUSH', KEYS[2], string.sub(item, idx + 1))
+end
+return #items`)
+
+//getDelayKey 获取延迟消息的有序集合名称，以队列名作为hash tag保证与队列在集群的同一个槽中
+func getDelayKey(key string) string {
+	return fmt.Sprintf("{%s}:delay", key)
+}
+
+//pushDelay 以到期时间为分值添加延迟消息
+func pushDelay(client *redis.Client, key string, value string, delay time.Duration) error {
+	member := fmt.Sprintf("%s|%s", utility.GetGUID(), value)
+	score := float64(time.Now().Add(delay).UnixNano() / int64(time.Millisecond))
+	return client.ZAdd(getDelayKey(key), rds.Z{Score: score, Member: member}).Err()
+}
+
+//moveDelay 将到期的延迟消息移入队列，返回移动的消息数
+func moveDelay(client *redis.Client, key string) (int64, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	return moveScript.Run(client, []string{getDelayKey(key), key}, now, delayBatchSize).Int64()
+}
@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/components/pkgs/redis"
+	varredis "github.com/micro-plat/hydra/conf/vars/redis"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+//getTestClient 连接测试用的redis服务器(环境变量REDIS_ADDR，默认127.0.0.1:6379)，无法连接时跳过测试
+func getTestClient(t *testing.T) *redis.Client {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:6379"
+	}
+	client, err := redis.NewByOpts(varredis.WithAddrs(addr), varredis.WithTimeout(1, 1, 1))
+	if err != nil {
+		t.Skipf("redis服务器%s不可用:%v", addr, err)
+	}
+	return client
+}
+
+func TestMoveDelay(t *testing.T) {
+	client := getTestClient(t)
+	defer client.Close()
+	queue := "hydra:test:delay"
+	client.Del(queue, getDelayKey(queue))
+	defer client.Del(queue, getDelayKey(queue))
+
+	assert.Equal(t, nil, pushDelay(client, queue, "a|1", 0), "1. 添加已到期的延迟消息")
+	assert.Equal(t, nil, pushDelay(client, queue, "a|1", 0), "1. 相同内容的消息不合并")
+	assert.Equal(t, nil, pushDelay(client, queue, "b", time.Hour), "1. 添加未到期的延迟消息")
+
+	n, err := moveDelay(client, queue)
+	assert.Equal(t, nil, err, "2. 移动到期消息")
+	assert.Equal(t, int64(2), n, "2. 只移动到期的消息")
+	values, _ := client.LRange(queue, 0, -1).Result()
+	assert.Equal(t, []string{"a|1", "a|1"}, values, "2. 移入队列时去掉唯一编号，保留消息内容")
+	count, _ := client.ZCard(getDelayKey(queue)).Result()
+	assert.Equal(t, int64(1), count, "2. 未到期的消息保留在有序集合中")
+
+	n, err = moveDelay(client, queue)
+	assert.Equal(t, nil, err, "3. 再次移动")
+	assert.Equal(t, int64(0), n, "3. 已移动的消息不重复移动")
+}
+
+func TestConsumer_MoveDelay(t *testing.T) {
+	client := getTestClient(t)
+	defer client.Close()
+	queue := "hydra:test:delay:consumer"
+	client.Del(queue, getDelayKey(queue))
+	defer client.Del(queue, getDelayKey(queue))
+
+	DelayMoveSpan = time.Millisecond * 20
+	defer func() { DelayMoveSpan = time.Millisecond * 500 }()
+	consumer, _ := NewConsumerByConfig(varredis.New(""))
+	consumer.client = client
+	consumer.SetMaster(false)
+	unconsumeCh := make(chan struct{})
+	defer close(unconsumeCh)
+	go consumer.moveDelay(queue, unconsumeCh)
+
+	for i := 0; i < delayBatchSize+1; i++ {
+		pushDelay(client, queue, "1", 0)
+	}
+	time.Sleep(time.Millisecond * 100)
+	count, _ := client.LLen(queue).Result()
+	assert.Equal(t, int64(0), count, "1. 从节点不移动延迟消息")
+
+	consumer.SetMaster(true)
+	time.Sleep(time.Millisecond * 100)
+	count, _ = client.LLen(queue).Result()
+	assert.Equal(t, int64(delayBatchSize+1), count, "2. 主节点分批移动所有到期消息")
+}
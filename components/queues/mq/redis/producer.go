@@ -1,6 +1,8 @@
 package redis
 
 import (
+	"time"
+
 	rds "github.com/go-redis/redis"
 	"github.com/micro-plat/hydra/components/pkgs/redis"
 	"github.com/micro-plat/hydra/components/queues/mq"
@@ -36,6 +38,11 @@ func (c *Producer) Push(key string, value string) error {
 	return err
 }
 
+//PushDelay 添加延迟消息，由消费队列的集群主节点在到期后移入队列
+func (c *Producer) PushDelay(key string, value string, delay time.Duration) error {
+	return pushDelay(c.client, key, value, delay)
+}
+
 // Pop 移除并且返回 key 对应的 list 的第一个元素。
 func (c *Producer) Pop(key string) (string, error) {
 	r, err := c.client.LPop(key).Result()
@@ -11,7 +11,6 @@ import (
 	"github.com/micro-plat/hydra/global"
 	npkgs "github.com/micro-plat/hydra/pkgs"
 	"github.com/micro-plat/lib4go/concurrent/cmap"
-	"github.com/micro-plat/lib4go/types"
 )
 
 var requests = cmap.New(4)
@@ -99,12 +98,8 @@ func (r *Request) RequestByCtx(ctx context.Context, service string, input interf
 	client := c.(*rpc.Client)
 	nopts := make([]rpc.RequestOption, 0, len(opts)+1)
 	nopts = append(nopts, opts...)
-	if reqid := types.GetString(ctx.Value(rc.XRequestID)); reqid != "" {
+	if reqid := rc.GetTraceID(ctx); reqid != "" {
 		nopts = append(nopts, rpc.WithTraceID(reqid))
-	} else {
-		if ctx, ok := rc.GetContext(); ok {
-			nopts = append(opts, rpc.WithTraceID(ctx.User().GetTraceID()))
-		}
 	}
 	fm := pkgs.GetString(input)
 	return client.RequestByString(ctx, rservice, fm, nopts...)
@@ -14,13 +14,16 @@ import (
 	"github.com/micro-plat/hydra/conf/server/auth/basic"
 	"github.com/micro-plat/hydra/conf/server/auth/jwt"
 	"github.com/micro-plat/hydra/conf/server/auth/ras"
+	rspcache "github.com/micro-plat/hydra/conf/server/cache"
 	"github.com/micro-plat/hydra/conf/server/header"
+	"github.com/micro-plat/hydra/conf/server/idempotency"
 	"github.com/micro-plat/hydra/conf/server/metric"
 	"github.com/micro-plat/hydra/conf/server/mqc"
 	"github.com/micro-plat/hydra/conf/server/nfs"
 	"github.com/micro-plat/hydra/conf/server/processor"
 	"github.com/micro-plat/hydra/conf/server/queue"
 	"github.com/micro-plat/hydra/conf/server/render"
+	"github.com/micro-plat/hydra/conf/server/script"
 	"github.com/micro-plat/hydra/conf/server/static"
 	"github.com/micro-plat/hydra/conf/server/task"
 	"github.com/micro-plat/hydra/conf/vars"
@@ -53,6 +56,9 @@ type IAPPConf interface {
 	GetProxyConf() (*proxy.Proxy, error)
 	GetAPMConf() (*apm.APM, error)
 	GetProcessorConf() (*processor.Processor, error)
+	GetCacheConf() (*rspcache.Cache, error)
+	GetIdempotencyConf() (*idempotency.Idempotency, error)
+	GetScriptConf() (*script.Script, error)
 
 	GetNFSConf() (*nfs.NFS, error)
 	//获取远程日志配置
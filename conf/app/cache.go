@@ -1,6 +1,7 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/micro-plat/hydra/conf/server"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/snapshot"
 	"github.com/micro-plat/lib4go/concurrent/cmap"
 )
 
@@ -157,15 +159,29 @@ func getKey(tp string, version interface{}) string {
 
 //PullAndSave 拉取注册中心的服务器配置，并缓存
 func PullAndSave() error {
+	//注册中心不可用时使用本地快照
+	rgst, err := snapshot.GetCurrent()
+	if err != nil {
+		return err
+	}
+
 	//接取配置信息
 	for _, tp := range global.Def.ServerTypes {
 		pub := server.NewServerPub(global.Def.GetPlatName(), global.Def.SysName, tp, global.Def.ClusterName)
-		conf, err := NewAPPConf(pub.GetServerPath(), registry.GetCurrent())
+		conf, err := NewAPPConf(pub.GetServerPath(), rgst)
 		if err != nil {
 			return fmt.Errorf("获取%s配置发生错误:%v", pub.GetServerPath(), err)
 		}
 		//保存配置缓存
 		Cache.Save(conf)
+
+		//保存本地快照
+		if registry.GetProto(global.Def.RegistryAddr) != registry.LocalMemory {
+			err := snapshot.Save(rgst, pub.GetServerPath(), conf.GetVarConf().GetVarPath())
+			if err != nil && !errors.Is(err, snapshot.ErrNoKey) {
+				global.Def.Log().Warnf("保存配置快照失败:%v", err)
+			}
+		}
 	}
 	return nil
 }
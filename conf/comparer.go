@@ -2,6 +2,8 @@ package conf
 
 import (
 	"reflect"
+	"sort"
+	"strings"
 )
 
 //ICompare 配置比较器
@@ -41,13 +43,17 @@ func (s *Comparer) Update(n IServerConf) {
 	s.nconf = n
 }
 
-//IsChanged 检查版本号是否发生变化
+//IsChanged 检查版本号是否发生变化，引用了密钥或多层合并的配置内容发生变化时也认为发生变化
+//(密钥值变更、本地覆盖文件变更或删除覆盖层时版本号不会增加)
 //当新配置nconf为空时，系统认为未发生变化
 func (s *Comparer) IsChanged() bool {
 	if s.nconf == nil || reflect.ValueOf(s.nconf).IsNil() {
 		return false
 	}
-	return s.oconf.GetVersion() < s.nconf.GetVersion()
+	if s.oconf.GetVersion() < s.nconf.GetVersion() {
+		return true
+	}
+	return getSignature(s.oconf) != getSignature(s.nconf)
 }
 
 //IsValueChanged 配置内容是否发生变化
@@ -81,10 +87,31 @@ func (s *Comparer) IsSubConfChanged(names ...string) (isChanged bool) {
 			return true
 		}
 
-		if o.version != n.version {
+		if o.version != n.version || isDynamic(o) && o.signature != n.signature {
 			return true
 		}
 	}
 
 	return false
 }
+
+//isDynamic 配置内容是否可能在版本号不变的情况下发生变化
+func isDynamic(c *RawConf) bool {
+	return c.HasSecret() || c.IsLayered()
+}
+
+//getSignature 获取引用了密钥或多层合并的主配置与子配置的签名
+func getSignature(c IServerConf) string {
+	signs := make([]string, 0, 1)
+	if m := c.GetMainConf(); m != nil && isDynamic(m) {
+		signs = append(signs, m.GetSignature())
+	}
+	c.Iter(func(path string, conf *RawConf) bool {
+		if isDynamic(conf) {
+			signs = append(signs, path+":"+conf.GetSignature())
+		}
+		return true
+	})
+	sort.Strings(signs)
+	return strings.Join(signs, ",")
+}
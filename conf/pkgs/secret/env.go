@@ -0,0 +1,39 @@
+package secret
+
+import (
+	"os"
+	"strings"
+)
+
+//Env 从环境变量获取密钥，路径db/main中的password对应环境变量DB_MAIN_PASSWORD
+type Env struct{}
+
+//NewEnv 构建环境变量密钥提供程序
+func NewEnv() *Env {
+	return &Env{}
+}
+
+//Get 获取以路径为前缀的所有环境变量
+func (e *Env) Get(path string) (*Secret, error) {
+	prefix := envName(path) + "_"
+	s := &Secret{Data: make(map[string]string)}
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, prefix) {
+			continue
+		}
+		v := strings.SplitN(kv[len(prefix):], "=", 2)
+		if len(v) == 2 {
+			s.Data[strings.ToLower(v[0])] = v[1]
+		}
+	}
+	return s, nil
+}
+
+func envName(path string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.Trim(path, "/")))
+}
@@ -0,0 +1,59 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+//FileRefreshSpan 重新读取密钥文件的时间间隔
+var FileRefreshSpan = time.Minute
+
+//File 从密钥目录获取密钥，路径db/main可以是目录(每个文件为一个值，与kubernetes secret挂载方式一致)或db/main.json文件
+type File struct {
+	dir     string
+	refresh time.Duration
+}
+
+//NewFile 构建密钥文件提供程序，refresh为重新读取文件的时间间隔，为0时不刷新
+func NewFile(dir string, refresh time.Duration) *File {
+	return &File{dir: dir, refresh: refresh}
+}
+
+//Get 读取密钥目录或json文件
+func (f *File) Get(path string) (*Secret, error) {
+	s := &Secret{Data: make(map[string]string), Lease: f.refresh}
+	root := filepath.Join(f.dir, filepath.FromSlash(strings.Trim(path, "/")))
+	if infos, err := ioutil.ReadDir(root); err == nil {
+		for _, info := range infos {
+			if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+				continue
+			}
+			buff, err := ioutil.ReadFile(filepath.Join(root, info.Name()))
+			if err != nil {
+				return nil, err
+			}
+			s.Data[info.Name()] = strings.TrimRight(string(buff), "\r\n")
+		}
+		return s, nil
+	}
+	buff, err := ioutil.ReadFile(root + ".json")
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("密钥%s不存在", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]interface{})
+	if err := json.Unmarshal(buff, &data); err != nil {
+		return nil, fmt.Errorf("密钥文件%s.json格式错误:%w", root, err)
+	}
+	for k, v := range data {
+		s.Data[k] = fmt.Sprint(v)
+	}
+	return s, nil
+}
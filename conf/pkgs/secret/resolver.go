@@ -0,0 +1,156 @@
+package secret
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//RefreshSpan 检查密钥租约的时间间隔
+var RefreshSpan = time.Second
+
+type entry struct {
+	secret    *Secret
+	refreshAt time.Time
+}
+
+type resolver struct {
+	provider  IProvider
+	entries   map[string]*entry
+	listeners []func()
+	log       logger.ILogging
+	lk        sync.Mutex
+	once      sync.Once
+}
+
+func newResolver(p IProvider) *resolver {
+	return &resolver{
+		provider: p,
+		entries:  make(map[string]*entry),
+		log:      logger.New("secret"),
+	}
+}
+
+func (r *resolver) setProvider(p IProvider) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	r.provider = p
+	r.entries = make(map[string]*entry)
+}
+
+func (r *resolver) onChange(f func()) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	r.listeners = append(r.listeners, f)
+}
+
+//get 获取密钥，同一路径在租约期内使用缓存的值，保证各配置中的值一致。
+//从提供程序获取密钥时不持有锁，避免远程请求阻塞其它路径的读取
+func (r *resolver) get(path string) (*Secret, error) {
+	r.lk.Lock()
+	if e, ok := r.entries[path]; ok {
+		r.lk.Unlock()
+		return e.secret, nil
+	}
+	if r.provider == nil {
+		r.provider = getDefaultProvider()
+	}
+	provider := r.provider
+	r.lk.Unlock()
+
+	s, err := provider.Get(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	if r.provider != provider {
+		return s, nil
+	}
+	if e, ok := r.entries[path]; ok {
+		return e.secret, nil
+	}
+	r.entries[path] = &entry{secret: s, refreshAt: getRefreshTime(s.Lease)}
+	if s.Lease > 0 {
+		r.once.Do(func() {
+			go r.loopRefresh()
+		})
+	}
+	return s, nil
+}
+
+func (r *resolver) loopRefresh() {
+	tk := time.NewTicker(RefreshSpan)
+	defer tk.Stop()
+	for range tk.C {
+		if r.refresh() {
+			r.notify()
+		}
+	}
+}
+
+//refresh 续约或重新获取租约即将到期的密钥，返回密钥值是否发生变化。
+//在锁外请求提供程序，完成后在锁内替换缓存
+func (r *resolver) refresh() (changed bool) {
+	r.lk.Lock()
+	provider := r.provider
+	now := time.Now()
+	due := make(map[string]*entry)
+	for path, e := range r.entries {
+		if e.secret.Lease > 0 && !now.Before(e.refreshAt) {
+			due[path] = e
+		}
+	}
+	r.lk.Unlock()
+
+	for path, e := range due {
+		n := r.refreshEntry(provider, path, e)
+		r.lk.Lock()
+		if r.provider == provider && r.entries[path] == e {
+			if !reflect.DeepEqual(n.secret.Data, e.secret.Data) {
+				r.log.Infof("密钥%s已变更", path)
+				changed = true
+			}
+			r.entries[path] = n
+		}
+		r.lk.Unlock()
+	}
+	return changed
+}
+
+//refreshEntry 续约或重新获取密钥，失败时保留原值并稍后重试
+func (r *resolver) refreshEntry(provider IProvider, path string, e *entry) *entry {
+	if renewer, ok := provider.(IRenewer); ok && e.secret.Renewable {
+		lease, err := renewer.Renew(e.secret)
+		if err == nil {
+			s := *e.secret
+			s.Lease = lease
+			return &entry{secret: &s, refreshAt: getRefreshTime(lease)}
+		}
+		r.log.Warnf("密钥%s续约失败,重新获取:%v", path, err)
+	}
+	s, err := provider.Get(path)
+	if err != nil {
+		r.log.Errorf("刷新密钥%s失败:%v", path, err)
+		return &entry{secret: e.secret, refreshAt: getRefreshTime(RefreshSpan * 3)}
+	}
+	return &entry{secret: s, refreshAt: getRefreshTime(s.Lease)}
+}
+
+func (r *resolver) notify() {
+	r.lk.Lock()
+	listeners := make([]func(), len(r.listeners))
+	copy(listeners, r.listeners)
+	r.lk.Unlock()
+	for _, f := range listeners {
+		f()
+	}
+}
+
+//getRefreshTime 在租约时长的2/3处刷新
+func getRefreshTime(lease time.Duration) time.Time {
+	return time.Now().Add(lease * 2 / 3)
+}
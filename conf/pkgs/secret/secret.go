@@ -0,0 +1,125 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+//Secret 密钥数据
+type Secret struct {
+	//Data 密钥数据，键为引用中#后的名称
+	Data map[string]string
+
+	//LeaseID 租约编号，可续约的密钥由IRenewer续约
+	LeaseID string
+
+	//Lease 租约时长，到期前重新获取或续约，为0时不刷新
+	Lease time.Duration
+
+	//Renewable 是否可续约
+	Renewable bool
+}
+
+//IProvider 密钥提供程序
+type IProvider interface {
+	//Get 获取路径对应的密钥数据
+	Get(path string) (*Secret, error)
+}
+
+//IRenewer 支持租约续约的密钥提供程序
+type IRenewer interface {
+	//Renew 续约，返回新的租约时长
+	Renew(s *Secret) (time.Duration, error)
+}
+
+//pattern 密钥引用，格式为${secret:路径#名称}
+var pattern = regexp.MustCompile(`\$\{secret:([^#}]+)#([^}]+)\}`)
+
+var prefix = []byte("${secret:")
+
+//Def 默认的密钥解析器
+var Def = newResolver(nil)
+
+//SetProvider 设置密钥提供程序，未设置时根据环境变量选择:
+//设置了VAULT_ADDR与VAULT_TOKEN使用vault，设置了HYDRA_SECRET_DIR使用密钥文件目录，否则使用环境变量
+func SetProvider(p IProvider) {
+	Def.setProvider(p)
+}
+
+//OnChange 密钥租约到期重新获取后值发生变化时的回调，用于重新加载配置
+func OnChange(f func()) {
+	Def.onChange(f)
+}
+
+//Resolve 将配置中的密钥引用替换为密钥值，json配置中的密钥值按json字符串转义
+func Resolve(text []byte) ([]byte, error) {
+	return Def.Resolve(text)
+}
+
+//skipped 是否跳过解析密钥引用
+var skipped int32
+
+//Skip 不解析配置中的密钥引用，用于只显示或导出配置、不需要密钥值的命令行
+func Skip() {
+	atomic.StoreInt32(&skipped, 1)
+}
+
+//IsSkipped 是否跳过解析密钥引用
+func IsSkipped() bool {
+	return atomic.LoadInt32(&skipped) == 1
+}
+
+//Has 配置中是否包含密钥引用
+func Has(text []byte) bool {
+	return bytes.Contains(text, prefix)
+}
+
+//Resolve 将配置中的密钥引用替换为密钥值
+func (r *resolver) Resolve(text []byte) ([]byte, error) {
+	if !Has(text) {
+		return text, nil
+	}
+	isJSON := bytes.HasPrefix(text, []byte("{")) || bytes.HasPrefix(text, []byte("["))
+	var err error
+	result := pattern.ReplaceAllFunc(text, func(ref []byte) []byte {
+		if err != nil {
+			return ref
+		}
+		m := pattern.FindSubmatch(ref)
+		s, gerr := r.get(string(m[1]))
+		if gerr != nil {
+			err = fmt.Errorf("获取密钥%s失败:%w", m[1], gerr)
+			return ref
+		}
+		v, ok := s.Data[string(m[2])]
+		if !ok {
+			err = fmt.Errorf("密钥%s中不存在%s", m[1], m[2])
+			return ref
+		}
+		if !isJSON {
+			return []byte(v)
+		}
+		buff, _ := json.Marshal(v)
+		return buff[1 : len(buff)-1]
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//getDefaultProvider 根据环境变量选择密钥提供程序
+func getDefaultProvider() IProvider {
+	if addr, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN"); addr != "" && token != "" {
+		return NewVault(addr, token)
+	}
+	if dir := os.Getenv("HYDRA_SECRET_DIR"); dir != "" {
+		return NewFile(dir, FileRefreshSpan)
+	}
+	return NewEnv()
+}
@@ -0,0 +1,165 @@
+package secret
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestResolve(t *testing.T) {
+	os.Setenv("DB_MAIN_PASSWORD", `12"3\`)
+	defer os.Unsetenv("DB_MAIN_PASSWORD")
+	r := newResolver(NewEnv())
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{name: "1. 不包含密钥引用", text: `{"provider":"mysql"}`, want: `{"provider":"mysql"}`},
+		{name: "2. json配置中的密钥值转义", text: `{"connString":"root:${secret:db/main#password}@tcp"}`, want: `{"connString":"root:12\"3\\@tcp"}`},
+		{name: "3. 非json配置", text: `pwd=${secret:db/main#password}`, want: `pwd=12"3\`},
+		{name: "4. 密钥不存在", text: `{"pwd":"${secret:db/main#user}"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := r.Resolve([]byte(tt.text))
+		assert.Equal(t, tt.wantErr, err != nil, tt.name)
+		if !tt.wantErr {
+			assert.Equal(t, tt.want, string(got), tt.name)
+		}
+	}
+	m := map[string]interface{}{}
+	got, _ := r.Resolve([]byte(`{"pwd":"${secret:db/main#password}"}`))
+	assert.Equal(t, nil, json.Unmarshal(got, &m), "5. 替换后仍是有效的json")
+	assert.Equal(t, `12"3\`, m["pwd"], "5. 替换后仍是有效的json")
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "db", "main"), 0700)
+	ioutil.WriteFile(filepath.Join(dir, "db", "main", "password"), []byte("123456\n"), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "redis.json"), []byte(`{"password":"abc","db":1}`), 0600)
+	f := NewFile(dir, 0)
+
+	s, err := f.Get("db/main")
+	assert.Equal(t, nil, err, "1. 读取密钥目录")
+	assert.Equal(t, "123456", s.Data["password"], "1. 读取密钥目录")
+	s, err = f.Get("redis")
+	assert.Equal(t, nil, err, "2. 读取json文件")
+	assert.Equal(t, "abc", s.Data["password"], "2. 读取json文件")
+	assert.Equal(t, "1", s.Data["db"], "2. 读取json文件")
+	_, err = f.Get("mq")
+	assert.NotEqual(t, nil, err, "3. 密钥不存在")
+}
+
+func TestVault(t *testing.T) {
+	renewed := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/db/main":
+			w.Write([]byte(`{"data":{"data":{"password":"123456"},"metadata":{"version":1}}}`))
+		case "/v1/database/creds/app":
+			w.Write([]byte(`{"lease_id":"database/creds/app/1","lease_duration":60,"renewable":true,"data":{"username":"u1","password":"p1"}}`))
+		case "/v1/sys/leases/renew":
+			renewed++
+			w.Write([]byte(`{"lease_id":"database/creds/app/1","lease_duration":120}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	v := NewVault(srv.URL, "token", WithRefresh(time.Minute))
+	s, err := v.Get("db/main")
+	assert.Equal(t, nil, err, "1. 读取kv密钥")
+	assert.Equal(t, "123456", s.Data["password"], "1. 读取kv密钥")
+	assert.Equal(t, time.Minute, s.Lease, "1. kv密钥使用刷新时长")
+
+	s, err = v.Get("/database/creds/app")
+	assert.Equal(t, nil, err, "2. 读取动态密钥")
+	assert.Equal(t, "u1", s.Data["username"], "2. 读取动态密钥")
+	assert.Equal(t, true, s.Renewable, "2. 动态密钥可续约")
+	lease, err := v.Renew(s)
+	assert.Equal(t, nil, err, "3. 续约")
+	assert.Equal(t, time.Second*120, lease, "3. 续约")
+	assert.Equal(t, 1, renewed, "3. 续约")
+
+	_, err = NewVault(srv.URL, "x").Get("db/main")
+	assert.NotEqual(t, nil, err, "4. 无权限")
+}
+
+type testProvider struct {
+	value string
+}
+
+func (p *testProvider) Get(path string) (*Secret, error) {
+	return &Secret{Data: map[string]string{"password": p.value}, Lease: time.Millisecond}, nil
+}
+
+func TestRefresh(t *testing.T) {
+	p := &testProvider{value: "1"}
+	r := newResolver(p)
+	buff, _ := r.Resolve([]byte("${secret:db#password}"))
+	assert.Equal(t, "1", string(buff), "1. 获取密钥")
+
+	time.Sleep(time.Millisecond * 5)
+	assert.Equal(t, false, r.refresh(), "2. 密钥未变化")
+	p.value = "2"
+	buff, _ = r.Resolve([]byte("${secret:db#password}"))
+	assert.Equal(t, "1", string(buff), "3. 租约期内使用缓存的值")
+
+	time.Sleep(time.Millisecond * 5)
+	assert.Equal(t, true, r.refresh(), "4. 租约到期后密钥已变更")
+	buff, _ = r.Resolve([]byte("${secret:db#password}"))
+	assert.Equal(t, "2", string(buff), "5. 使用新的密钥")
+}
+
+type blockProvider struct {
+	block chan struct{}
+}
+
+func (p *blockProvider) Get(path string) (*Secret, error) {
+	if path == "slow" {
+		<-p.block
+	}
+	return &Secret{Data: map[string]string{"password": path}, Lease: time.Millisecond}, nil
+}
+
+func TestRefreshUnlocked(t *testing.T) {
+	p := &blockProvider{block: make(chan struct{})}
+	r := newResolver(p)
+	close(p.block)
+	r.Resolve([]byte("${secret:slow#password}"))
+	p.block = make(chan struct{})
+
+	time.Sleep(time.Millisecond * 5)
+	done := make(chan bool)
+	go func() { done <- r.refresh() }()
+
+	resolved := make(chan []byte)
+	go func() {
+		buff, _ := r.Resolve([]byte("${secret:fast#password}"))
+		resolved <- buff
+	}()
+	select {
+	case buff := <-resolved:
+		assert.Equal(t, "fast", string(buff), "1. 刷新密钥时可获取其它密钥")
+	case <-time.After(time.Second):
+		t.Fatal("1. 刷新密钥时持有锁")
+	}
+	close(p.block)
+	assert.Equal(t, false, <-done, "2. 刷新完成，密钥未变化")
+}
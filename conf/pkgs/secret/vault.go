@@ -0,0 +1,149 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//VaultOption vault配置选项
+type VaultOption func(*Vault)
+
+//WithMount 设置kv引擎的挂载路径，默认为secret
+func WithMount(mount string) VaultOption {
+	return func(v *Vault) {
+		v.mount = strings.Trim(mount, "/")
+	}
+}
+
+//WithKVVersion 设置kv引擎版本，默认为2
+func WithKVVersion(version int) VaultOption {
+	return func(v *Vault) {
+		v.version = version
+	}
+}
+
+//WithRefresh 设置无租约的密钥(如kv引擎)重新获取的时间间隔
+func WithRefresh(refresh time.Duration) VaultOption {
+	return func(v *Vault) {
+		v.refresh = refresh
+	}
+}
+
+//WithTimeout 设置请求超时时长
+func WithTimeout(timeout time.Duration) VaultOption {
+	return func(v *Vault) {
+		v.client.Timeout = timeout
+	}
+}
+
+//Vault 从兼容HashiCorp Vault HTTP API的服务获取密钥，
+//路径db/main从kv引擎读取，以/开头的路径(如/database/creds/app)直接读取，用于动态密钥
+type Vault struct {
+	addr    string
+	token   string
+	mount   string
+	version int
+	refresh time.Duration
+	client  *http.Client
+}
+
+//NewVault 构建vault密钥提供程序
+func NewVault(addr string, token string, opts ...VaultOption) *Vault {
+	v := &Vault{
+		addr:    strings.TrimRight(addr, "/"),
+		token:   token,
+		mount:   "secret",
+		version: 2,
+		refresh: time.Minute * 5,
+		client:  &http.Client{Timeout: time.Second * 10},
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+type vaultResponse struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Errors        []string               `json:"errors"`
+}
+
+//Get 获取密钥
+func (v *Vault) Get(path string) (*Secret, error) {
+	rsp := &vaultResponse{}
+	if err := v.request(http.MethodGet, v.getURL(path), nil, rsp); err != nil {
+		return nil, err
+	}
+	data := rsp.Data
+	if v.version == 2 && !strings.HasPrefix(path, "/") {
+		data, _ = rsp.Data["data"].(map[string]interface{})
+	}
+	s := &Secret{
+		Data:      make(map[string]string, len(data)),
+		LeaseID:   rsp.LeaseID,
+		Lease:     time.Duration(rsp.LeaseDuration) * time.Second,
+		Renewable: rsp.Renewable && rsp.LeaseID != "",
+	}
+	if s.Lease <= 0 {
+		s.Lease = v.refresh
+	}
+	for k, val := range data {
+		s.Data[k] = fmt.Sprint(val)
+	}
+	return s, nil
+}
+
+//Renew 续约租约
+func (v *Vault) Renew(s *Secret) (time.Duration, error) {
+	body, _ := json.Marshal(map[string]string{"lease_id": s.LeaseID})
+	rsp := &vaultResponse{}
+	if err := v.request(http.MethodPut, v.addr+"/v1/sys/leases/renew", body, rsp); err != nil {
+		return 0, err
+	}
+	if rsp.LeaseDuration <= 0 {
+		return 0, fmt.Errorf("租约%s已过期", s.LeaseID)
+	}
+	return time.Duration(rsp.LeaseDuration) * time.Second, nil
+}
+
+func (v *Vault) getURL(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return v.addr + "/v1" + path
+	}
+	if v.version == 2 {
+		return fmt.Sprintf("%s/v1/%s/data/%s", v.addr, v.mount, path)
+	}
+	return fmt.Sprintf("%s/v1/%s/%s", v.addr, v.mount, path)
+}
+
+func (v *Vault) request(method string, url string, body []byte, out *vaultResponse) error {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	buff, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(buff, out); err != nil && resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("vault返回数据格式错误:%w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault请求失败(%d):%s", resp.StatusCode, strings.Join(out.Errors, ","))
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package security
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//AESGCMMode aes-256-gcm加密模式
+const AESGCMMode = "aes-gcm"
+
+//KeyEnvName 密钥环境变量，格式为"密钥编号:base64编码的32字节密钥"，设置后作为当前密钥
+const KeyEnvName = "HYDRA_ENCRYPT_KEY"
+
+//KeyFileEnvName 密钥文件路径环境变量
+const KeyFileEnvName = "HYDRA_ENCRYPT_KEYFILE"
+
+//AESGCM aes-256-gcm加密，密文格式为"密钥编号:base64(nonce+密文)"，可保留旧密钥用于解密以实现密钥轮换
+type AESGCM struct {
+	keys    map[string][]byte
+	current string
+	lk      sync.RWMutex
+}
+
+//NewAESGCM 构建aes-256-gcm加密提供程序
+func NewAESGCM() *AESGCM {
+	return &AESGCM{keys: make(map[string][]byte)}
+}
+
+//AddKey 添加密钥，current为true时作为加密使用的当前密钥
+func (a *AESGCM) AddKey(kid string, key []byte, current bool) error {
+	if kid == "" || strings.Contains(kid, ":") {
+		return fmt.Errorf("密钥编号[%s]不能为空且不能包含':'", kid)
+	}
+	if len(key) != 32 {
+		return fmt.Errorf("密钥[%s]长度必须为32字节,当前为%d字节", kid, len(key))
+	}
+	a.lk.Lock()
+	defer a.lk.Unlock()
+	a.keys[kid] = key
+	if current || a.current == "" {
+		a.current = kid
+	}
+	return nil
+}
+
+//HasKey 是否已配置密钥
+func (a *AESGCM) HasKey() bool {
+	a.lk.RLock()
+	defer a.lk.RUnlock()
+	return a.current != ""
+}
+
+//Mode 加密模式
+func (a *AESGCM) Mode() string {
+	return AESGCMMode
+}
+
+//Encrypt 使用当前密钥加密
+func (a *AESGCM) Encrypt(input []byte) (string, error) {
+	a.lk.RLock()
+	kid, key := a.current, a.keys[a.current]
+	a.lk.RUnlock()
+	if kid == "" {
+		return "", fmt.Errorf("未配置加密密钥,请设置环境变量%s或密钥文件", KeyEnvName)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	v := gcm.Seal(nonce, nonce, input, []byte(kid))
+	return kid + ":" + base64.RawURLEncoding.EncodeToString(v), nil
+}
+
+//Decrypt 根据密文中的密钥编号选择密钥解密
+func (a *AESGCM) Decrypt(data string) ([]byte, error) {
+	i := strings.Index(data, ":")
+	if i < 0 {
+		return nil, fmt.Errorf("密文格式错误,缺少密钥编号")
+	}
+	kid := data[:i]
+	a.lk.RLock()
+	key, ok := a.keys[kid]
+	a.lk.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未找到密钥[%s]", kid)
+	}
+	src, err := base64.RawURLEncoding.DecodeString(data[i+1:])
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(src) < gcm.NonceSize() {
+		return nil, fmt.Errorf("密文长度错误")
+	}
+	return gcm.Open(make([]byte, 0, len(src)), src[:gcm.NonceSize()], src[gcm.NonceSize():], []byte(kid))
+}
+
+//IsCurrent 密文是否使用当前密钥加密
+func (a *AESGCM) IsCurrent(data string) bool {
+	a.lk.RLock()
+	defer a.lk.RUnlock()
+	return a.current != "" && strings.HasPrefix(data, a.current+":")
+}
+
+//LoadKeyFile 加载密钥文件，每行格式为"密钥编号=base64编码的32字节密钥"，最后一个密钥作为当前密钥
+func (a *AESGCM) LoadKeyFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("密钥文件%s格式错误:%s", path, line)
+		}
+		if err := a.addEncodedKey(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]), true); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (a *AESGCM) addEncodedKey(kid string, encoded string, current bool) error {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("密钥[%s]不是有效的base64编码:%w", kid, err)
+	}
+	return a.AddKey(kid, key, current)
+}
+
+//loadDefaultKeys 从密钥文件与环境变量加载密钥，环境变量中的密钥作为当前密钥
+func (a *AESGCM) loadDefaultKeys() error {
+	path := os.Getenv(KeyFileEnvName)
+	if path == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, ".hydra", "encrypt.keys")
+		}
+	}
+	if path != "" {
+		if err := a.LoadKeyFile(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if v := os.Getenv(KeyEnvName); v != "" {
+		kv := strings.SplitN(v, ":", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("环境变量%s格式错误,应为'密钥编号:base64密钥'", KeyEnvName)
+		}
+		return a.addEncodedKey(kv[0], kv[1], true)
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,76 @@
+package security
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestAESGCM(t *testing.T) {
+	p := NewAESGCM()
+	assert.Equal(t, nil, p.AddKey("k1", bytes.Repeat([]byte{1}, 32), true), "1. 添加密钥")
+	assert.NotEqual(t, nil, p.AddKey("k2", []byte("short"), true), "2. 密钥长度错误")
+
+	v, err := p.Encrypt([]byte("taosy"))
+	assert.Equal(t, nil, err, "3. 加密")
+	assert.Equal(t, true, strings.HasPrefix(v, "k1:"), "3. 密文包含密钥编号")
+	buff, err := p.Decrypt(v)
+	assert.Equal(t, nil, err, "4. 解密")
+	assert.Equal(t, "taosy", string(buff), "4. 解密")
+
+	assert.Equal(t, nil, p.AddKey("k2", bytes.Repeat([]byte{2}, 32), true), "5. 轮换密钥")
+	assert.Equal(t, false, p.IsCurrent(v), "5. 旧密文不是当前密钥加密")
+	buff, err = p.Decrypt(v)
+	assert.Equal(t, nil, err, "6. 旧密钥可解密")
+	assert.Equal(t, "taosy", string(buff), "6. 旧密钥可解密")
+
+	_, err = p.Decrypt("k1:" + base64.RawURLEncoding.EncodeToString(bytes.Repeat([]byte{3}, 32)))
+	assert.NotEqual(t, nil, err, "7. 密文被修改")
+	_, err = p.Decrypt("k3:" + v[3:])
+	assert.NotEqual(t, nil, err, "8. 密钥不存在")
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "encrypt.keys")
+	ioutil.WriteFile(path, []byte("#密钥\nk1="+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))+
+		"\nk2="+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))+"\n"), 0600)
+	p := NewAESGCM()
+	assert.Equal(t, nil, p.LoadKeyFile(path), "1. 加载密钥文件")
+	v, _ := p.Encrypt([]byte("taosy"))
+	assert.Equal(t, true, strings.HasPrefix(v, "k2:"), "2. 最后一个密钥作为当前密钥")
+}
+
+func TestReEncrypt(t *testing.T) {
+	p := NewAESGCM()
+	p.AddKey("k1", bytes.Repeat([]byte{1}, 32), true)
+	RegisterProvider(p)
+	SetDefault(AESGCMMode)
+	defer func() {
+		RegisterProvider(defAESGCM)
+		SetDefault("")
+	}()
+
+	v, changed, err := ReEncrypt([]byte(legacy))
+	assert.Equal(t, nil, err, "1. des密文重新加密")
+	assert.Equal(t, true, changed, "1. des密文重新加密")
+	assert.Equal(t, true, strings.HasPrefix(v, "encrypt:aes-gcm:k1:"), "1. 使用当前密钥加密")
+
+	_, changed, _ = ReEncrypt([]byte(v))
+	assert.Equal(t, false, changed, "2. 已使用当前密钥加密")
+
+	p.AddKey("k2", bytes.Repeat([]byte{2}, 32), true)
+	n, changed, err := ReEncrypt([]byte(v))
+	assert.Equal(t, nil, err, "3. 轮换密钥后重新加密")
+	assert.Equal(t, true, changed, "3. 轮换密钥后重新加密")
+	buff, err := Decrypt([]byte(n))
+	assert.Equal(t, nil, err, "4. 解密")
+	assert.Equal(t, "taosytaosytaosytaosytaosytaosytaosy", string(buff), "4. 解密")
+
+	_, changed, _ = ReEncrypt([]byte("taosy"))
+	assert.Equal(t, false, changed, "5. 未加密数据")
+}
@@ -0,0 +1,32 @@
+package security
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/micro-plat/lib4go/security/des"
+)
+
+const confKey = "@-hydra*"
+const confIV = "#*---iv*"
+const mode = "cbc/pkcs5"
+
+//desProvider 使用内置密钥的des加密，仅用于解密旧版本配置
+type desProvider struct{}
+
+func (desProvider) Mode() string {
+	return mode
+}
+
+//Encrypt 内置密钥已公开，不允许用于加密新的配置
+func (desProvider) Encrypt(input []byte) (string, error) {
+	return "", errors.New("des加密仅用于解密旧版本配置,请配置aes-gcm密钥")
+}
+
+func (desProvider) Decrypt(cipher string) ([]byte, error) {
+	src, err := hex.DecodeString(cipher)
+	if err != nil {
+		return nil, err
+	}
+	return des.DecryptBytes(src, confKey, []byte(confIV), mode)
+}
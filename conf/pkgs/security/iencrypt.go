@@ -1,16 +1,34 @@
 package security
 
 type IEncrypt interface {
-	Encrypt(input []byte) string
+	Encrypt(input []byte) (string, error)
+}
+
+//IEncryptProvider 配置加密提供程序，密文格式为"encrypt:加密模式:密文"
+type IEncryptProvider interface {
+	//Mode 加密模式，写入密文头，解密时根据加密模式选择提供程序
+	Mode() string
+
+	//Encrypt 加密，返回不含密文头的密文
+	Encrypt(input []byte) (string, error)
+
+	//Decrypt 解密不含密文头的密文
+	Decrypt(cipher string) ([]byte, error)
+}
+
+//IKeyRotation 支持密钥轮换的加密提供程序
+type IKeyRotation interface {
+	//IsCurrent 密文是否使用当前密钥加密
+	IsCurrent(cipher string) bool
 }
 
 type ConfEncrypt struct {
 	EnableEncryption bool `json:"-"`
 }
 
-func (c ConfEncrypt) Encrypt(input []byte) string {
+func (c ConfEncrypt) Encrypt(input []byte) (string, error) {
 	if c.EnableEncryption {
-		return Encrypt(input)
+		return EncryptBy(input)
 	}
-	return string(input)
+	return string(input), nil
 }
@@ -1,37 +1,159 @@
 package security
 
 import (
-	"encoding/hex"
 	"fmt"
-
-	"github.com/micro-plat/lib4go/security/des"
+	"strings"
+	"sync"
 )
 
-const confKey = "@-hydra*"
-const confIV = "#*---iv*"
 const hd = "encrypt"
-const mode = "cbc/pkcs5"
 
-//Encrypt 对加密内容进行des加密，并增加加密头
+var providers = map[string]IEncryptProvider{}
+var defMode string
+var providerLock sync.RWMutex
+
+//defAESGCM 从密钥文件与环境变量加载密钥的aes-256-gcm加密
+var defAESGCM = NewAESGCM()
+var loadOnce sync.Once
+var loadErr error
+
+//RegisterProvider 注册加密提供程序，可用于接入外部密钥管理服务
+func RegisterProvider(p IEncryptProvider) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+	providers[p.Mode()] = p
+}
+
+//SetDefault 设置加密使用的提供程序，未设置时使用aes-gcm
+func SetDefault(mode string) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+	defMode = mode
+}
+
+//GetDefault 获取加密使用的提供程序，未配置密钥时返回错误，不再使用内置密钥加密
+func GetDefault() (IEncryptProvider, error) {
+	if err := loadKeys(); err != nil {
+		return nil, err
+	}
+	providerLock.RLock()
+	defer providerLock.RUnlock()
+	mode := defMode
+	if mode == "" {
+		mode = AESGCMMode
+	}
+	p, ok := providers[mode]
+	if !ok {
+		return nil, fmt.Errorf("不支持的加密模式:%s", mode)
+	}
+	if p == IEncryptProvider(defAESGCM) && !defAESGCM.HasKey() {
+		return nil, fmt.Errorf("未配置加密密钥,请通过环境变量%s或密钥文件(%s)配置", KeyEnvName, KeyFileEnvName)
+	}
+	return p, nil
+}
+
+func getProvider(mode string) (IEncryptProvider, error) {
+	if err := loadKeys(); err != nil {
+		return nil, err
+	}
+	providerLock.RLock()
+	defer providerLock.RUnlock()
+	p, ok := providers[mode]
+	if !ok {
+		return nil, fmt.Errorf("不支持的加密模式:%s", mode)
+	}
+	return p, nil
+}
+
+func loadKeys() error {
+	loadOnce.Do(func() {
+		if err := defAESGCM.loadDefaultKeys(); err != nil {
+			loadErr = fmt.Errorf("加载配置加密密钥失败:%w", err)
+		}
+	})
+	return loadErr
+}
+
+//Encrypt 使用默认的加密提供程序加密，并增加加密头
 func Encrypt(input []byte) string {
-	v, _ := des.EncryptBytes(input, confKey, []byte(confIV), mode)
-	return fmt.Sprintf("%s:%s:%s", hd, mode, hex.EncodeToString(v))
+	v, err := EncryptBy(input)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+//EncryptBy 使用默认的加密提供程序加密，并增加加密头
+func EncryptBy(input []byte) (string, error) {
+	p, err := GetDefault()
+	if err != nil {
+		return "", err
+	}
+	v, err := p.Encrypt(input)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s:%s", hd, p.Mode(), v), nil
 }
 
 //Decrypt 检查是否包含加密头，报含则根据加密头数据解密数据
 func Decrypt(data []byte) ([]byte, error) {
-	lheader := len(hd)
-	lmode := len(mode)
-	if len(data) <= lheader+lmode+2 {
+	if !isEncrypted(data) {
 		return data, nil
 	}
-	if string(data[0:lheader]) != hd {
-		return data, nil
+	mode, cipher, ok := parse(data)
+	if !ok {
+		return nil, fmt.Errorf("密文格式错误")
 	}
-	mode := string(data[lheader+1 : lheader+lmode+1])
-	src := make([]byte, (len(data)-lheader-lmode-2)/2)
-	if _, err := hex.Decode(src, data[(lheader+lmode+2):]); err != nil {
+	p, err := getProvider(mode)
+	if err != nil {
 		return nil, err
 	}
-	return des.DecryptBytes(src, confKey, []byte(confIV), mode)
+	return p.Decrypt(cipher)
+}
+
+//ReEncrypt 使用默认的加密提供程序及当前密钥重新加密，未加密或已使用当前密钥加密时changed为false
+func ReEncrypt(data []byte) (result string, changed bool, err error) {
+	if !isEncrypted(data) {
+		return string(data), false, nil
+	}
+	mode, cipher, ok := parse(data)
+	if !ok {
+		return "", false, fmt.Errorf("密文格式错误")
+	}
+	p, err := GetDefault()
+	if err != nil {
+		return "", false, err
+	}
+	if r, ok := p.(IKeyRotation); p.Mode() == mode && (!ok || r.IsCurrent(cipher)) {
+		return string(data), false, nil
+	}
+	input, err := Decrypt(data)
+	if err != nil {
+		return "", false, err
+	}
+	result, err = EncryptBy(input)
+	return result, err == nil, err
+}
+
+func isEncrypted(data []byte) bool {
+	return len(data) > len(hd)+2 && string(data[:len(hd)]) == hd
+}
+
+//parse 解析密文头，返回加密模式与密文
+func parse(data []byte) (mode string, cipher string, ok bool) {
+	if string(data[:len(hd)+1]) != hd+":" {
+		return "", "", false
+	}
+	v := string(data[len(hd)+1:])
+	i := strings.Index(v, ":")
+	if i <= 0 {
+		return "", "", false
+	}
+	return v[:i], v[i+1:], true
+}
+
+func init() {
+	RegisterProvider(desProvider{})
+	RegisterProvider(defAESGCM)
 }
@@ -1,13 +1,32 @@
 package security
 
 import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/micro-plat/lib4go/assert"
 )
 
+//legacy 旧版本使用内置des密钥加密的配置
+const legacy = "encrypt:cbc/pkcs5:47b17dd320c67986a839e86c4da057a95ff57a008f168817daf12500e475dfccf032844585f9723c"
+
+//withKey 使用测试密钥替换默认的aes-gcm加密提供程序
+func withKey() func() {
+	p := NewAESGCM()
+	p.AddKey("k1", bytes.Repeat([]byte{1}, 32), true)
+	RegisterProvider(p)
+	return func() {
+		RegisterProvider(defAESGCM)
+	}
+}
+
 func BenchmarkEncrypt(b *testing.B) {
+	defer withKey()()
 	b.ResetTimer()
 	var input = []byte("taosytaosytaosytaosytaosytaosytaosy")
 	for i := 0; i < b.N; i++ {
@@ -16,7 +35,7 @@ func BenchmarkEncrypt(b *testing.B) {
 }
 
 func BenchmarkDecrypt(b *testing.B) {
-	var input = []byte("encrypt:cbc/pkcs5:47b17dd320c67986a839e86c4da057a95ff57a008f168817daf12500e475dfccf032844585f9723c")
+	var input = []byte(legacy)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		Decrypt(input)
@@ -24,6 +43,7 @@ func BenchmarkDecrypt(b *testing.B) {
 }
 
 func Test_encrypt(t *testing.T) {
+	defer withKey()()
 	tests := []struct {
 		name  string
 		input []byte
@@ -33,17 +53,18 @@ func Test_encrypt(t *testing.T) {
 	}
 	for _, tt := range tests {
 		got := Encrypt(tt.input)
-		list := strings.Split(got, ":")
+		list := strings.SplitN(got, ":", 3)
 		assert.Equal(t, len(list), 3, tt.name+",len")
 		if len(list) >= 2 {
 			assert.Equal(t, list[0], hd, tt.name+".hd")
-			assert.Equal(t, list[1], mode, tt.name+",mode")
+			assert.Equal(t, list[1], AESGCMMode, tt.name+",mode")
 
 		}
 	}
 }
 
 func Test_decrypt(t *testing.T) {
+	defer withKey()()
 	input := []byte{}
 	nildata := Encrypt(input)
 	input1 := []byte("encryptapsytsetetapsytsetetapsytsetetapsytsete")
@@ -60,6 +81,7 @@ func Test_decrypt(t *testing.T) {
 		{name: "3. conf-decrypt-不是由hd开头数据解密", data: []byte("nildatanildatanildatanildata"), want: []byte("nildatanildatanildatanildata"), wantErr: false},
 		{name: "4. conf-decrypt-错误数据解密", data: []byte("encryptnildatanildatanildatanildata"), want: nil, wantErr: true},
 		{name: "5. conf-decrypt-正确数据数据解密", data: []byte(data1), want: []byte("encryptapsytsetetapsytsetetapsytsetetapsytsete"), wantErr: false},
+		{name: "6. conf-decrypt-旧版本des密文解密", data: []byte(legacy), want: []byte("taosytaosytaosytaosytaosytaosytaosy"), wantErr: false},
 	}
 	for _, tt := range tests {
 		got, err := Decrypt(tt.data)
@@ -67,3 +89,29 @@ func Test_decrypt(t *testing.T) {
 		assert.Equal(t, tt.want, got, tt.name+",res")
 	}
 }
+
+func TestEncryptWithoutKey(t *testing.T) {
+	_, err := EncryptBy([]byte("taosy"))
+	assert.NotEqual(t, nil, err, "1. 未配置密钥不允许加密")
+
+	SetDefault(mode)
+	defer SetDefault("")
+	_, err = EncryptBy([]byte("taosy"))
+	assert.NotEqual(t, nil, err, "2. des仅用于解密")
+}
+
+func TestLoadKeysError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "encrypt.keys")
+	ioutil.WriteFile(path, []byte("k1"), 0600)
+	os.Setenv(KeyFileEnvName, path)
+	loadOnce, loadErr = sync.Once{}, nil
+	defer func() {
+		os.Unsetenv(KeyFileEnvName)
+		loadOnce, loadErr = sync.Once{}, nil
+	}()
+
+	_, err := Decrypt([]byte(legacy))
+	assert.NotEqual(t, nil, err, "1. 密钥文件格式错误时返回错误")
+	_, err = EncryptBy([]byte("taosy"))
+	assert.NotEqual(t, nil, err, "2. 密钥文件格式错误时返回错误")
+}
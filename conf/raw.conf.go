@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 
+	"github.com/micro-plat/hydra/conf/pkgs/secret"
 	"github.com/micro-plat/lib4go/security/md5"
 	"github.com/micro-plat/lib4go/types"
 )
@@ -26,6 +27,8 @@ var EmptyRawConf = &RawConf{
 //RawConf json配置文件
 type RawConf struct {
 	raw       []byte
+	origin    []byte
+	sources   map[string]string
 	version   int32
 	signature string
 	types.XMap
@@ -46,13 +49,18 @@ func NewByMap(data map[string]interface{}, version int32) (c *RawConf, err error
 	return c, nil
 }
 
-//NewByText 初始化RawConf
+//NewByText 初始化RawConf，配置中的密钥引用${secret:路径#名称}替换为密钥值，
+//调用secret.Skip后保留引用不解析
 func NewByText(message []byte, version int32) (c *RawConf, err error) {
-	c = &RawConf{
-		raw:       message,
-		signature: md5.EncryptBytes(message),
-		version:   version,
+	c = &RawConf{version: version}
+	if secret.Has(message) && !secret.IsSkipped() {
+		c.origin = message
+		if message, err = secret.Resolve(message); err != nil {
+			return nil, err
+		}
 	}
+	c.raw = message
+	c.signature = md5.EncryptBytes(message)
 	switch {
 	case bytes.HasPrefix(message, []byte("<?xml")):
 		c.XMap, err = types.NewXMapByXML(string(message))
@@ -62,11 +70,44 @@ func NewByText(message []byte, version int32) (c *RawConf, err error) {
 	return c, err
 }
 
+//NewByLayers 根据多层合并后的配置初始化RawConf，sources为各配置项(多级以.分隔)的来源层
+func NewByLayers(message []byte, version int32, sources map[string]string) (c *RawConf, err error) {
+	c, err = NewByText(message, version)
+	if err != nil {
+		return nil, err
+	}
+	c.sources = sources
+	return c, nil
+}
+
 //GetRaw 获取原串
 func (j *RawConf) GetRaw() []byte {
 	return j.raw
 }
 
+//GetOrigin 获取未替换密钥引用的原串，用于显示或导出配置
+func (j *RawConf) GetOrigin() []byte {
+	if j.origin != nil {
+		return j.origin
+	}
+	return j.raw
+}
+
+//HasSecret 配置中是否包含密钥引用
+func (j *RawConf) HasSecret() bool {
+	return j.origin != nil
+}
+
+//IsLayered 配置是否由多层合并而成
+func (j *RawConf) IsLayered() bool {
+	return j.sources != nil
+}
+
+//GetSources 获取各配置项的来源层，非多层合并的配置返回nil
+func (j *RawConf) GetSources() map[string]string {
+	return j.sources
+}
+
 //GetVersion 获取当前配置的版本号
 func (j *RawConf) GetVersion() int32 {
 	return j.version
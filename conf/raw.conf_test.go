@@ -2,6 +2,7 @@ package conf
 
 import (
 	"encoding/json"
+	"os"
 	"reflect"
 	"testing"
 
@@ -125,3 +126,17 @@ func TestRawConf_GetRaw(t *testing.T) {
 		assert.Equal(t, tt.want, got, tt.name)
 	}
 }
+
+func TestNewByTextWithSecret(t *testing.T) {
+	os.Setenv("DB_TEST_PASSWORD", "123456")
+	defer os.Unsetenv("DB_TEST_PASSWORD")
+	text := []byte(`{"connString":"root:${secret:db/test#password}@tcp"}`)
+	c, err := NewByText(text, 1)
+	assert.Equal(t, nil, err, "1. 解析密钥引用")
+	assert.Equal(t, "root:123456@tcp", c.GetString("connString"), "2. 使用密钥值")
+	assert.Equal(t, true, c.HasSecret(), "3. 包含密钥引用")
+	assert.Equal(t, string(text), string(c.GetOrigin()), "4. 原串不包含密钥值")
+
+	_, err = NewByText([]byte(`{"connString":"${secret:db/test#user}"}`), 1)
+	assert.NotEqual(t, nil, err, "5. 密钥不存在")
+}
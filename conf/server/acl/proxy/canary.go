@@ -0,0 +1,174 @@
+package proxy
+
+import (
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"net/http"
+	"strings"
+)
+
+const (
+	//StickyUser 按用户进行粘性分配
+	StickyUser = "user"
+
+	//StickyIP 按客户端IP进行粘性分配
+	StickyIP = "ip"
+
+	//StickyHeaderPrefix 按请求头进行粘性分配,如:header:X-User-Id
+	StickyHeaderPrefix = "header:"
+
+	//StickyCookiePrefix 按cookie进行粘性分配,如:cookie:uid
+	StickyCookiePrefix = "cookie:"
+)
+
+//IRequest 灰度规则匹配时使用的请求信息
+type IRequest interface {
+	GetHeader(name string) string
+	GetCookie(name string) string
+	GetUser() string
+	GetClientIP() string
+}
+
+//Weight 上游集群的流量占比
+type Weight struct {
+	Upcluster string `json:"upcluster" valid:"required" toml:"upcluster,omitempty" label:"上游集群"`
+	Percent   int    `json:"percent" valid:"range(0|100)" toml:"percent,omitempty" label:"流量百分比"`
+}
+
+//Rule 灰度规则,请求满足所有匹配条件时,转到指定集群或按流量占比选择集群
+type Rule struct {
+	Name      string    `json:"name" valid:"required" toml:"name,omitempty" label:"灰度规则名称"`
+	Header    string    `json:"header,omitempty" toml:"header,omitempty"`
+	Cookie    string    `json:"cookie,omitempty" toml:"cookie,omitempty"`
+	User      bool      `json:"user,omitempty" toml:"user,omitempty"`
+	Values    []string  `json:"values,omitempty" toml:"values,omitempty"`
+	Upcluster string    `json:"upcluster,omitempty" toml:"upcluster,omitempty"`
+	Weights   []*Weight `json:"weights,omitempty" toml:"weights,omitempty"`
+	Sticky    string    `json:"sticky,omitempty" toml:"sticky,omitempty"`
+}
+
+//NewRule 构建灰度规则
+func NewRule(name string, opts ...RuleOption) *Rule {
+	r := &Rule{Name: name}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+//check 检查规则配置是否正确
+func (r *Rule) check() error {
+	if r.Upcluster == "" && len(r.Weights) == 0 {
+		return fmt.Errorf("灰度规则%s未指定上游集群", r.Name)
+	}
+	total := 0
+	for _, w := range r.Weights {
+		total += w.Percent
+	}
+	if total > 100 {
+		return fmt.Errorf("灰度规则%s的流量百分比之和不能超过100:%d", r.Name, total)
+	}
+	switch {
+	case r.Sticky == "", r.Sticky == StickyUser, r.Sticky == StickyIP,
+		strings.HasPrefix(r.Sticky, StickyHeaderPrefix), strings.HasPrefix(r.Sticky, StickyCookiePrefix):
+		return nil
+	}
+	return fmt.Errorf("灰度规则%s的粘性分配方式不支持:%s", r.Name, r.Sticky)
+}
+
+//Match 检查请求是否满足规则的匹配条件
+func (r *Rule) Match(req IRequest) bool {
+	if r.Header != "" && !r.matchValue(req.GetHeader(r.Header)) {
+		return false
+	}
+	if r.Cookie != "" && !r.matchValue(req.GetCookie(r.Cookie)) {
+		return false
+	}
+	if r.User && !r.matchValue(req.GetUser()) {
+		return false
+	}
+	return true
+}
+
+//Select 选择上游集群,未命中流量占比时返回空
+func (r *Rule) Select(req IRequest) string {
+	if r.Upcluster != "" {
+		return r.Upcluster
+	}
+	bucket := r.getBucket(req)
+	total := 0
+	for _, w := range r.Weights {
+		total += w.Percent
+		if bucket < total {
+			return w.Upcluster
+		}
+	}
+	return ""
+}
+
+func (r *Rule) matchValue(v string) bool {
+	if len(r.Values) == 0 {
+		return v != ""
+	}
+	for _, value := range r.Values {
+		if strings.EqualFold(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+//getBucket 获取请求所在的分桶[0,100),设置粘性分配时相同的key始终分配到相同的分桶
+func (r *Rule) getBucket(req IRequest) int {
+	key := r.getStickyKey(req)
+	if key == "" {
+		return rand.Intn(100)
+	}
+	return int(crc32.ChecksumIEEE([]byte(r.Name+"|"+key)) % 100)
+}
+
+func (r *Rule) getStickyKey(req IRequest) string {
+	switch {
+	case r.Sticky == StickyUser:
+		return req.GetUser()
+	case r.Sticky == StickyIP:
+		return req.GetClientIP()
+	case strings.HasPrefix(r.Sticky, StickyHeaderPrefix):
+		return req.GetHeader(strings.TrimPrefix(r.Sticky, StickyHeaderPrefix))
+	case strings.HasPrefix(r.Sticky, StickyCookiePrefix):
+		return req.GetCookie(strings.TrimPrefix(r.Sticky, StickyCookiePrefix))
+	}
+	return ""
+}
+
+//HTTPRequest 基于http.Request的灰度规则请求信息
+type HTTPRequest struct {
+	Request  *http.Request
+	User     string
+	ClientIP string
+}
+
+//GetHeader 获取请求头
+func (h *HTTPRequest) GetHeader(name string) string {
+	return h.Request.Header.Get(name)
+}
+
+//GetCookie 获取cookie
+func (h *HTTPRequest) GetCookie(name string) string {
+	c, err := h.Request.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return c.Value
+}
+
+//GetUser 获取用户信息
+func (h *HTTPRequest) GetUser() string {
+	return h.User
+}
+
+//GetClientIP 获取客户端IP
+func (h *HTTPRequest) GetClientIP() string {
+	return h.ClientIP
+}
@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func newRequest(header map[string]string, cookie map[string]string, user string) *HTTPRequest {
+	r, _ := http.NewRequest(http.MethodGet, "/order/query", nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	for k, v := range cookie {
+		r.AddCookie(&http.Cookie{Name: k, Value: v})
+	}
+	return &HTTPRequest{Request: r, User: user, ClientIP: "192.168.0.1"}
+}
+
+func TestRule_Match(t *testing.T) {
+	tests := []struct {
+		name string
+		rule *Rule
+		req  *HTTPRequest
+		want bool
+	}{
+		{name: "1. 未设置匹配条件", rule: NewRule("r1", WithUpcluster("b")), req: newRequest(nil, nil, ""), want: true},
+		{name: "2. 请求头匹配", rule: NewRule("r1", WithHeader("X-Canary", "on")), req: newRequest(map[string]string{"X-Canary": "on"}, nil, ""), want: true},
+		{name: "3. 请求头不匹配", rule: NewRule("r1", WithHeader("X-Canary", "on")), req: newRequest(map[string]string{"X-Canary": "off"}, nil, ""), want: false},
+		{name: "4. 请求头存在即匹配", rule: NewRule("r1", WithHeader("X-Canary")), req: newRequest(map[string]string{"X-Canary": "any"}, nil, ""), want: true},
+		{name: "5. cookie匹配", rule: NewRule("r1", WithCookie("beta", "1")), req: newRequest(nil, map[string]string{"beta": "1"}, ""), want: true},
+		{name: "6. cookie不存在", rule: NewRule("r1", WithCookie("beta", "1")), req: newRequest(nil, nil, ""), want: false},
+		{name: "7. 用户匹配", rule: NewRule("r1", WithUser("u1", "u2")), req: newRequest(nil, nil, "u2"), want: true},
+		{name: "8. 用户不匹配", rule: NewRule("r1", WithUser("u1", "u2")), req: newRequest(nil, nil, "u3"), want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.rule.Match(tt.req), tt.name)
+	}
+}
+
+func TestRule_Select(t *testing.T) {
+	assert.Equal(t, "b", NewRule("r1", WithUpcluster("b")).Select(newRequest(nil, nil, "")), "1. 指定上游集群")
+	assert.Equal(t, "b", NewRule("r1", WithWeight("b", 100)).Select(newRequest(nil, nil, "")), "2. 全部流量转到上游集群")
+	assert.Equal(t, "", NewRule("r1", WithWeight("b", 0)).Select(newRequest(nil, nil, "")), "3. 流量百分比为0")
+
+	//粘性分配时相同的key始终分配到相同的集群
+	rule := NewRule("r1", WithWeight("b", 50), WithSticky("header:X-Uid"))
+	hits := 0
+	for i := 0; i < 200; i++ {
+		req := newRequest(map[string]string{"X-Uid": string(rune('a' + i%26))}, nil, "")
+		first := rule.Select(req)
+		for j := 0; j < 5; j++ {
+			assert.Equal(t, first, rule.Select(req), "4. 粘性分配")
+		}
+		if first == "b" {
+			hits++
+		}
+	}
+	assert.Equal(t, true, hits > 0 && hits < 200, "5. 按百分比分配")
+}
+
+func TestRule_check(t *testing.T) {
+	assert.Equal(t, nil, NewRule("r1", WithUpcluster("b")).check(), "1. 正确的规则")
+	assert.NotEqual(t, nil, NewRule("r1").check(), "2. 未指定上游集群")
+	assert.NotEqual(t, nil, NewRule("r1", WithWeight("b", 60), WithWeight("c", 50)).check(), "3. 百分比超过100")
+	assert.NotEqual(t, nil, NewRule("r1", WithWeight("b", 60), WithSticky("session")).check(), "4. 不支持的粘性分配方式")
+}
@@ -2,30 +2,150 @@ package proxy
 
 import (
 	"fmt"
+	"net"
 	"net/url"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/lib4go/logger"
 )
 
 var clusters = cmap.New(2)
 
+//UpCluster 上游集群，轮询选择节点并跳过被摘除的节点
 type UpCluster struct {
-	c    conf.ICluster
-	name string
+	c       conf.ICluster
+	name    string
+	health  *Health
+	nodes   cmap.ConcurrentMap
+	checker *healthChecker
+	lock    sync.RWMutex
+	log     logger.ILogging
+}
+
+func newUpCluster(name string, c conf.ICluster) *UpCluster {
+	return &UpCluster{
+		c:     c,
+		name:  name,
+		nodes: cmap.New(4),
+		log:   logger.New("proxy.upcluster"),
+	}
 }
 
 //Next 获取下一个可用的上游地址
 func (c *UpCluster) Next() (u *url.URL, err error) {
+	now := time.Now()
+	for i := 0; i < c.c.Len(); i++ {
+		node, ok := c.c.Next()
+		if !ok {
+			break
+		}
+		addr := net.JoinHostPort(node.GetHost(), node.GetPort())
+		if !c.getNode(addr).available(now) {
+			continue
+		}
+		path := fmt.Sprintf("http://%s", addr)
+		url, err := url.Parse(path)
+		if err != nil {
+			return nil, fmt.Errorf("集群的服务器地址不合法:%s %s", path, err)
+		}
+		return url, nil
+	}
+	return nil, fmt.Errorf("集群%s无可用服务器", c.name)
+}
+
+//Report 上报节点的请求结果，连续出现服务器错误或连接错误的节点将被暂时摘除
+func (c *UpCluster) Report(u *url.URL, err error) {
+	n := c.getNode(u.Host)
+	if err == nil {
+		n.success()
+		return
+	}
+	if n.fail(c.getHealth(), err) {
+		c.log.Warnf("集群%s的节点%s连续请求失败,暂时摘除:%v", c.name, u.Host, err)
+	}
+}
+
+//GetName 获取上游集群名称
+func (c *UpCluster) GetName() string {
+	return c.name
+}
+
+//GetStatus 获取集群所有节点的健康状态
+func (c *UpCluster) GetStatus() []*NodeStatus {
+	now := time.Now()
+	addrs := c.getAddrs()
+	list := make([]*NodeStatus, 0, len(addrs))
+	for _, addr := range addrs {
+		s := c.getNode(addr).get(now)
+		s.Addr = addr
+		list = append(list, s)
+	}
+	return list
+}
 
-	node, ok := c.c.Next()
-	if !ok {
-		return nil, fmt.Errorf("集群%s无可用服务器", c.name)
+//setHealth 更新健康检查配置，配置了检查路径时启动主动健康检查
+func (c *UpCluster) setHealth(h *Health) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.health = h
+	if h != nil && h.Path != "" && c.checker == nil {
+		c.checker = newHealthChecker(c)
+		go c.checker.run()
 	}
-	path := fmt.Sprintf("http://%s:%s", node.GetHost(), node.GetPort())
-	url, err := url.Parse(path)
-	if err != nil {
-		return nil, fmt.Errorf("集群的服务器地址不合法:%s %s", path, err)
+}
+
+func (c *UpCluster) getHealth() *Health {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.health
+}
+
+func (c *UpCluster) getNode(addr string) *nodeHealth {
+	_, n, _ := c.nodes.SetIfAbsentCb(addr, func(...interface{}) (interface{}, error) {
+		return &nodeHealth{status: NodeStatus{Addr: addr}}, nil
+	})
+	return n.(*nodeHealth)
+}
+
+func (c *UpCluster) getAddrs() []string {
+	addrs := make([]string, 0, c.c.Len())
+	c.c.Iter(func(node conf.ICNode) bool {
+		addrs = append(addrs, net.JoinHostPort(node.GetHost(), node.GetPort()))
+		return true
+	})
+	sort.Strings(addrs)
+	return addrs
+}
+
+func (c *UpCluster) close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.checker != nil {
+		c.checker.close()
+		c.checker = nil
 	}
-	return url, nil
+}
+
+//GetUpClusterStatus 获取所有上游集群节点的健康状态
+func GetUpClusterStatus() map[string][]*NodeStatus {
+	status := make(map[string][]*NodeStatus)
+	clusters.IterCb(func(k string, v interface{}) bool {
+		status[k] = v.(*UpCluster).GetStatus()
+		return true
+	})
+	return status
+}
+
+func init() {
+	global.Def.AddCloser(func() {
+		clusters.RemoveIterCb(func(k string, v interface{}) bool {
+			v.(*UpCluster).close()
+			return true
+		})
+	})
 }
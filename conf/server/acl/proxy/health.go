@@ -0,0 +1,180 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/lib4go/logger"
+)
+
+const (
+	defHealthInterval = 10
+	defHealthTimeout  = 3
+	defMaxFails       = 5
+	defEjection       = 30
+	maxEjectionTimes  = 10
+)
+
+//Health 上游集群节点健康检查配置
+type Health struct {
+	Path     string `json:"path,omitempty" toml:"path,omitempty" label:"主动健康检查路径"`
+	Interval int    `json:"interval,omitempty" toml:"interval,omitempty" label:"主动健康检查间隔(秒)"`
+	Timeout  int    `json:"timeout,omitempty" toml:"timeout,omitempty" label:"主动健康检查超时时长(秒)"`
+	MaxFails int    `json:"maxFails,omitempty" toml:"maxFails,omitempty" label:"连续失败次数"`
+	Ejection int    `json:"ejection,omitempty" toml:"ejection,omitempty" label:"节点摘除时长(秒)"`
+}
+
+//NewHealth 构建健康检查配置
+func NewHealth(opts ...HealthOption) *Health {
+	h := &Health{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *Health) getInterval() time.Duration {
+	if h == nil || h.Interval <= 0 {
+		return time.Second * defHealthInterval
+	}
+	return time.Second * time.Duration(h.Interval)
+}
+
+func (h *Health) getTimeout() time.Duration {
+	if h == nil || h.Timeout <= 0 {
+		return time.Second * defHealthTimeout
+	}
+	return time.Second * time.Duration(h.Timeout)
+}
+
+func (h *Health) getMaxFails() int {
+	if h == nil || h.MaxFails <= 0 {
+		return defMaxFails
+	}
+	return h.MaxFails
+}
+
+func (h *Health) getEjection() time.Duration {
+	if h == nil || h.Ejection <= 0 {
+		return time.Second * defEjection
+	}
+	return time.Second * time.Duration(h.Ejection)
+}
+
+//NodeStatus 上游节点的健康状态
+type NodeStatus struct {
+	Addr         string    `json:"addr"`
+	Healthy      bool      `json:"healthy"`
+	Fails        int       `json:"fails"`
+	Ejections    int       `json:"ejections"`
+	EjectedUntil time.Time `json:"ejected_until,omitempty"`
+	LastError    string    `json:"last_error,omitempty"`
+	LastCheck    time.Time `json:"last_check,omitempty"`
+}
+
+//nodeHealth 上游节点健康状态管理，连续失败达到上限后摘除节点，摘除时长随摘除次数递增
+type nodeHealth struct {
+	lock   sync.Mutex
+	status NodeStatus
+}
+
+func (n *nodeHealth) available(now time.Time) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return !now.Before(n.status.EjectedUntil)
+}
+
+func (n *nodeHealth) success() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.status.Fails = 0
+	n.status.Ejections = 0
+	n.status.EjectedUntil = time.Time{}
+	n.status.LastError = ""
+}
+
+//fail 记录失败,返回节点是否被摘除
+func (n *nodeHealth) fail(h *Health, err error) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.status.Fails++
+	n.status.LastError = err.Error()
+	if n.status.Fails < h.getMaxFails() {
+		return false
+	}
+	if n.status.Ejections < maxEjectionTimes {
+		n.status.Ejections++
+	}
+	n.status.Fails = 0
+	n.status.EjectedUntil = time.Now().Add(h.getEjection() * time.Duration(n.status.Ejections))
+	return true
+}
+
+func (n *nodeHealth) get(now time.Time) *NodeStatus {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	s := n.status
+	s.Healthy = !now.Before(s.EjectedUntil)
+	return &s
+}
+
+//healthChecker 主动健康检查
+type healthChecker struct {
+	c       *UpCluster
+	closeCh chan struct{}
+	log     logger.ILogging
+}
+
+func newHealthChecker(c *UpCluster) *healthChecker {
+	return &healthChecker{c: c, closeCh: make(chan struct{}), log: logger.New("proxy.health")}
+}
+
+func (h *healthChecker) run() {
+	for {
+		health := h.c.getHealth()
+		select {
+		case <-h.closeCh:
+			return
+		case <-time.After(health.getInterval()):
+			if health == nil || health.Path == "" {
+				continue
+			}
+			h.check(health)
+		}
+	}
+}
+
+func (h *healthChecker) check(health *Health) {
+	client := &http.Client{Timeout: health.getTimeout()}
+	for _, addr := range h.c.getAddrs() {
+		url := fmt.Sprintf("http://%s%s", addr, health.Path)
+		err := func() error {
+			resp, err := client.Get(url)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("健康检查返回状态码:%d", resp.StatusCode)
+			}
+			return nil
+		}()
+		n := h.c.getNode(addr)
+		n.lock.Lock()
+		n.status.LastCheck = time.Now()
+		n.lock.Unlock()
+		if err == nil {
+			n.success()
+			continue
+		}
+		if n.fail(health, err) {
+			h.log.Warnf("集群%s的节点%s健康检查失败,暂时摘除:%v", h.c.name, addr, err)
+		}
+	}
+}
+
+func (h *healthChecker) close() {
+	close(h.closeCh)
+}
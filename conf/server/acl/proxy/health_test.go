@@ -0,0 +1,102 @@
+package proxy
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+type tNode struct {
+	conf.ICNode
+	host string
+}
+
+func (n *tNode) GetHost() string { return n.host }
+func (n *tNode) GetPort() string { return "8080" }
+
+type tCluster struct {
+	conf.ICluster
+	nodes []conf.ICNode
+	index int
+}
+
+func (c *tCluster) Len() int { return len(c.nodes) }
+func (c *tCluster) Next() (conf.ICNode, bool) {
+	c.index++
+	return c.nodes[c.index%len(c.nodes)], true
+}
+func (c *tCluster) Iter(f func(conf.ICNode) bool) {
+	for _, n := range c.nodes {
+		if !f(n) {
+			return
+		}
+	}
+}
+
+func TestUpCluster_Report(t *testing.T) {
+	up := newUpCluster("b", &tCluster{nodes: []conf.ICNode{&tNode{host: "192.168.0.1"}, &tNode{host: "192.168.0.2"}}})
+	up.setHealth(NewHealth(WithOutlier(2, 60)))
+	bad, _ := url.Parse("http://192.168.0.1:8080")
+
+	//未达到连续失败次数时不摘除
+	up.Report(bad, fmt.Errorf("connection refused"))
+	hosts := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		u, err := up.Next()
+		assert.Equal(t, nil, err, "1. 获取节点")
+		hosts[u.Host] = true
+	}
+	assert.Equal(t, 2, len(hosts), "1. 未摘除节点")
+
+	//达到连续失败次数后摘除节点
+	up.Report(bad, fmt.Errorf("connection refused"))
+	for i := 0; i < 4; i++ {
+		u, err := up.Next()
+		assert.Equal(t, nil, err, "2. 获取节点")
+		assert.Equal(t, "192.168.0.2:8080", u.Host, "2. 跳过已摘除的节点")
+	}
+	status := up.GetStatus()
+	assert.Equal(t, 2, len(status), "3. 节点状态")
+	assert.Equal(t, false, status[0].Healthy, "3. 节点状态")
+	assert.Equal(t, true, status[1].Healthy, "3. 节点状态")
+
+	//所有节点均被摘除
+	good, _ := url.Parse("http://192.168.0.2:8080")
+	up.Report(good, fmt.Errorf("status:502"))
+	up.Report(good, fmt.Errorf("status:502"))
+	_, err := up.Next()
+	assert.NotEqual(t, nil, err, "4. 无可用节点")
+
+	//请求成功后恢复节点
+	up.Report(good, nil)
+	u, err := up.Next()
+	assert.Equal(t, nil, err, "5. 恢复节点")
+	assert.Equal(t, "192.168.0.2:8080", u.Host, "5. 恢复节点")
+}
+
+func TestNodeHealth_fail(t *testing.T) {
+	h := NewHealth(WithOutlier(1, 10))
+	n := &nodeHealth{}
+	assert.Equal(t, true, n.fail(h, fmt.Errorf("err")), "1. 首次摘除")
+	first := n.status.EjectedUntil
+	assert.Equal(t, true, n.fail(h, fmt.Errorf("err")), "2. 再次摘除")
+	assert.Equal(t, true, n.status.EjectedUntil.After(first), "2. 摘除时长递增")
+	assert.Equal(t, 2, n.status.Ejections, "2. 摘除次数")
+}
+
+func TestGetUpClusterStatus(t *testing.T) {
+	up := newUpCluster("status", &tCluster{nodes: []conf.ICNode{&tNode{host: "192.168.0.3"}}})
+	clusters.Set("status", up)
+	defer clusters.Remove("status")
+	up.setHealth(NewHealth(WithOutlier(1, 60)))
+	bad, _ := url.Parse("http://192.168.0.3:8080")
+	up.Report(bad, fmt.Errorf("connection refused"))
+
+	status := GetUpClusterStatus()["status"]
+	assert.Equal(t, 1, len(status), "1. 获取集群节点状态")
+	assert.Equal(t, "192.168.0.3:8080", status[0].Addr, "1. 获取集群节点状态")
+	assert.Equal(t, false, status[0].Healthy, "2. 节点已摘除")
+}
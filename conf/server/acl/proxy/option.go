@@ -0,0 +1,99 @@
+package proxy
+
+//Option 配置选项
+type Option func(*Proxy)
+
+//WithScript 设置选择上游集群的tengo脚本
+func WithScript(script string) Option {
+	return func(a *Proxy) {
+		a.Script = script
+	}
+}
+
+//WithRuleList 设置灰度规则
+func WithRuleList(list ...*Rule) Option {
+	return func(a *Proxy) {
+		a.Rules = append(a.Rules, list...)
+	}
+}
+
+//RuleOption 灰度规则配置选项
+type RuleOption func(*Rule)
+
+//WithHeader 请求头的值在values中时匹配,未指定values时请求头不为空即匹配
+func WithHeader(name string, values ...string) RuleOption {
+	return func(a *Rule) {
+		a.Header = name
+		a.Values = append(a.Values, values...)
+	}
+}
+
+//WithCookie cookie的值在values中时匹配,未指定values时cookie不为空即匹配
+func WithCookie(name string, values ...string) RuleOption {
+	return func(a *Rule) {
+		a.Cookie = name
+		a.Values = append(a.Values, values...)
+	}
+}
+
+//WithUser 用户在values中时匹配,未指定values时已登录用户即匹配
+func WithUser(values ...string) RuleOption {
+	return func(a *Rule) {
+		a.User = true
+		a.Values = append(a.Values, values...)
+	}
+}
+
+//WithUpcluster 匹配的请求全部转到指定集群
+func WithUpcluster(name string) RuleOption {
+	return func(a *Rule) {
+		a.Upcluster = name
+	}
+}
+
+//WithWeight 设置转到指定集群的流量百分比
+func WithWeight(upcluster string, percent int) RuleOption {
+	return func(a *Rule) {
+		a.Weights = append(a.Weights, &Weight{Upcluster: upcluster, Percent: percent})
+	}
+}
+
+//WithSticky 设置粘性分配方式:user,ip,header:name,cookie:name
+func WithSticky(sticky string) RuleOption {
+	return func(a *Rule) {
+		a.Sticky = sticky
+	}
+}
+
+//WithHealth 设置上游集群节点健康检查
+func WithHealth(opts ...HealthOption) Option {
+	return func(a *Proxy) {
+		a.Health = NewHealth(opts...)
+	}
+}
+
+//HealthOption 健康检查配置选项
+type HealthOption func(*Health)
+
+//WithCheckPath 设置主动健康检查路径与检查间隔(秒)
+func WithCheckPath(path string, interval int) HealthOption {
+	return func(a *Health) {
+		a.Path = path
+		a.Interval = interval
+	}
+}
+
+//WithCheckTimeout 设置主动健康检查超时时长(秒)
+func WithCheckTimeout(second int) HealthOption {
+	return func(a *Health) {
+		a.Timeout = second
+	}
+}
+
+//WithOutlier 设置连续失败次数上限与节点摘除时长(秒)
+func WithOutlier(maxFails int, ejection int) HealthOption {
+	return func(a *Health) {
+		a.MaxFails = maxFails
+		a.Ejection = ejection
+	}
+}
@@ -1,9 +1,12 @@
 package proxy
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 
+	"github.com/asaskevich/govalidator"
 	"github.com/micro-plat/hydra/conf"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/registry"
@@ -21,25 +24,35 @@ const (
 	upclusterName = "upcluster"
 )
 
-//Proxy 代理设置
+//Proxy 代理设置,配置内容为tengo脚本，或包含灰度规则与脚本的json
 type Proxy struct {
+	Script string  `json:"script,omitempty" toml:"script,omitempty" label:"上游集群选择脚本"`
+	Rules  []*Rule `json:"rules,omitempty" toml:"rules,omitempty" label:"灰度规则"`
+	Health *Health `json:"health,omitempty" toml:"health,omitempty" label:"上游节点健康检查"`
+
 	//Disable 禁用
 	Disable bool `json:"-"`
 	c       conf.IServerConf
 	tengo   *tgo.VM
 }
 
+//New 构建代理配置
+func New(opts ...Option) *Proxy {
+	p := &Proxy{Rules: []*Rule{}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
 //Check 检查当前是否需要转到上游服务器处理
-func (g *Proxy) Check() (*UpCluster, bool, error) {
+func (g *Proxy) Check(req IRequest) (*UpCluster, bool, error) {
 
-	//执行脚本，检查当前请求是否需要转到上游服务器
-	result, err := g.tengo.Run()
+	//根据灰度规则或执行脚本，检查当前请求是否需要转到上游服务器
+	upstream, err := g.getUpstream(req)
 	if err != nil {
 		return nil, false, err
 	}
-
-	//获取脚本执行结果
-	upstream := result.GetString(upclusterName)
 	if upstream == "" || upstream == g.c.GetClusterName() {
 		return nil, false, nil
 	}
@@ -50,18 +63,41 @@ func (g *Proxy) Check() (*UpCluster, bool, error) {
 		if err != nil {
 			return nil, err
 		}
-		return &UpCluster{c: up, name: upstream}, nil
+		return newUpCluster(upstream, up), nil
 	})
 	if err != nil {
 		return nil, false, err
 	}
-	return cluster.(*UpCluster), true, nil
+	upcluster := cluster.(*UpCluster)
+	upcluster.setHealth(g.Health)
+	return upcluster, true, nil
 
 }
 
+//getUpstream 获取上游集群名称，灰度规则优先，未匹配规则时执行脚本
+func (g *Proxy) getUpstream(req IRequest) (string, error) {
+	for _, rule := range g.Rules {
+		if rule.Match(req) {
+			return rule.Select(req), nil
+		}
+	}
+	if g.tengo == nil {
+		return "", nil
+	}
+
+	//执行脚本
+	result, err := g.tengo.Run()
+	if err != nil {
+		return "", err
+	}
+
+	//获取脚本执行结果
+	return result.GetString(upclusterName), nil
+}
+
 //GetConf 获取Proxy
 func GetConf(cnf conf.IServerConf) (*Proxy, error) {
-	script, err := cnf.GetSubConf(registry.Join(ParNodeName, SubNodeName))
+	raw, err := cnf.GetSubConf(registry.Join(ParNodeName, SubNodeName))
 	if errors.Is(err, conf.ErrNoSetting) {
 		return &Proxy{Disable: true}, nil
 	}
@@ -69,10 +105,33 @@ func GetConf(cnf conf.IServerConf) (*Proxy, error) {
 		return nil, fmt.Errorf("acl.proxy配置有误:%v", err)
 	}
 
-	proxy := &Proxy{c: cnf}
-	proxy.tengo, err = tgo.New(string(script.GetRaw()), tgo.WithModule(global.GetTGOModules()...))
-	if err != nil {
-		return nil, fmt.Errorf("acl.proxy脚本错误:%v", err)
+	proxy := &Proxy{}
+	text := bytes.TrimSpace(raw.GetRaw())
+	if bytes.HasPrefix(text, []byte("{")) {
+		if err := json.Unmarshal(text, proxy); err != nil {
+			return nil, fmt.Errorf("acl.proxy配置有误:%v", err)
+		}
+		if b, err := govalidator.ValidateStruct(proxy); !b {
+			return nil, fmt.Errorf("acl.proxy配置数据有误:%v", err)
+		}
+	} else {
+		proxy.Script = string(text)
+	}
+	for _, rule := range proxy.Rules {
+		if err := rule.check(); err != nil {
+			return nil, fmt.Errorf("acl.proxy配置数据有误:%v", err)
+		}
+	}
+	if len(proxy.Rules) == 0 && proxy.Script == "" {
+		return &Proxy{Disable: true}, nil
+	}
+
+	proxy.c = cnf
+	if proxy.Script != "" {
+		proxy.tengo, err = tgo.New(proxy.Script, tgo.WithModule(global.GetTGOModules()...))
+		if err != nil {
+			return nil, fmt.Errorf("acl.proxy脚本错误:%v", err)
+		}
 	}
 	return proxy, nil
 }
@@ -0,0 +1,89 @@
+/*
+根据请求路径缓存响应结果，缓存内容包括状态码、内容类型与响应内容。
+缓存键由请求路径、查询参数、指定的请求头与用户信息组成，支持通过ETag/If-None-Match返回304。
+启用stale后，缓存过期的一段时间内仅允许一个请求刷新缓存，其它请求直接返回过期内容。
+*/
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/conf/pkgs/security"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+)
+
+const (
+	//TypeNodeName cache配置节点名
+	TypeNodeName = "cache"
+
+	//DefCacheName 默认使用的缓存组件名称
+	DefCacheName = "cache"
+)
+
+//Cache 响应缓存配置
+type Cache struct {
+	security.ConfEncrypt
+	Name    string          `json:"name,omitempty" toml:"name,omitempty" label:"缓存组件名称"`
+	Rules   []*Rule         `json:"rules,omitempty" valid:"required" toml:"rules,omitempty" label:"响应缓存规则"`
+	Disable bool            `json:"disable,omitempty" toml:"disable,omitempty"`
+	p       *conf.PathMatch `json:"-"`
+	rules   cmap.ConcurrentMap
+}
+
+//New 构建响应缓存配置
+func New(opts ...Option) *Cache {
+	c := &Cache{
+		Name:  DefCacheName,
+		Rules: []*Rule{},
+		rules: cmap.New(8),
+	}
+	for _, f := range opts {
+		f(c)
+	}
+	paths := make([]string, 0, len(c.Rules)+1)
+	for _, v := range c.Rules {
+		c.rules.Set(v.Path, v)
+		paths = append(paths, v.Path)
+	}
+	c.p = conf.NewPathMatch(paths...)
+	return c
+}
+
+//GetRule 获取请求路径对应的缓存规则
+func (c *Cache) GetRule(path string) (bool, *Rule) {
+	ok, path := c.p.Match(path)
+	if !ok {
+		return false, nil
+	}
+	rule, ok := c.rules.Get(path)
+	if !ok {
+		panic("从缓存中未找到cache规则")
+	}
+	return true, rule.(*Rule)
+}
+
+//GetConf 获取响应缓存配置
+func GetConf(cnf conf.IServerConf) (*Cache, error) {
+	c := &Cache{}
+	_, err := cnf.GetSubObject(TypeNodeName, c)
+	if errors.Is(err, conf.ErrNoSetting) || len(c.Rules) == 0 {
+		return &Cache{Disable: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("绑定cache配置有误:%v", err)
+	}
+	if b, err := govalidator.ValidateStruct(c); !b {
+		return nil, fmt.Errorf("cache配置数据有误:%v %+v", err, c)
+	}
+
+	nc := New(WithRuleList(c.Rules...))
+	nc.Disable = c.Disable
+	if c.Name != "" {
+		nc.Name = c.Name
+	}
+	return nc, nil
+}
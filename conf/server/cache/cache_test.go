@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestCache_GetRule(t *testing.T) {
+	c := New(WithRuleList(NewRule("/order/*", 60), NewRule("/product/list", 10, WithQuery())))
+	tests := []struct {
+		name   string
+		path   string
+		enable bool
+		rule   string
+	}{
+		{name: "1. 模糊匹配路径", path: "/order/query", enable: true, rule: "/order/*"},
+		{name: "2. 精确匹配路径", path: "/product/list", enable: true, rule: "/product/list"},
+		{name: "3. 未配置的路径", path: "/user/login", enable: false},
+	}
+	for _, tt := range tests {
+		enable, rule := c.GetRule(tt.path)
+		assert.Equal(t, tt.enable, enable, tt.name)
+		if enable {
+			assert.Equal(t, tt.rule, rule.Path, tt.name)
+		}
+	}
+}
+
+func TestRule_GetKey(t *testing.T) {
+	header := func(h string) string { return map[string]string{"X-Tenant": "t1"}[h] }
+	q1 := url.Values{"b": []string{"2"}, "a": []string{"1"}}
+	q2 := url.Values{"a": []string{"1"}, "b": []string{"2"}}
+	q3 := url.Values{"a": []string{"2"}}
+
+	plain := NewRule("/order", 10)
+	assert.Equal(t, plain.GetKey("GET", "/order", q1, header, "u1"), plain.GetKey("GET", "/order", q3, header, "u2"), "1. 未启用参数与用户时缓存键相同")
+
+	query := NewRule("/order", 10, WithQuery())
+	assert.Equal(t, query.GetKey("GET", "/order", q1, header, ""), query.GetKey("GET", "/order", q2, header, ""), "2. 参数顺序不影响缓存键")
+	assert.NotEqual(t, query.GetKey("GET", "/order", q1, header, ""), query.GetKey("GET", "/order", q3, header, ""), "3. 参数不同缓存键不同")
+
+	user := NewRule("/order", 10, WithUser(), WithHeaders("X-Tenant"))
+	assert.NotEqual(t, user.GetKey("GET", "/order", nil, header, "u1"), user.GetKey("GET", "/order", nil, header, "u2"), "4. 用户不同缓存键不同")
+	assert.NotEqual(t, user.GetKey("GET", "/order", nil, header, "u1"), user.GetKey("GET", "/order", nil, func(string) string { return "" }, "u1"), "5. 请求头不同缓存键不同")
+}
+
+func TestRule_GetStoreExpire(t *testing.T) {
+	assert.Equal(t, 10, NewRule("/order", 10).GetStoreExpire(), "1. 未启用stale")
+	assert.Equal(t, false, NewRule("/order", 10).AllowStale(), "2. 未启用stale")
+	assert.Equal(t, 40, NewRule("/order", 10, WithStale(30)).GetStoreExpire(), "3. 启用stale")
+}
@@ -0,0 +1,70 @@
+package cache
+
+//Option 配置选项
+type Option func(*Cache)
+
+//WithRuleList 设置缓存规则
+func WithRuleList(list ...*Rule) Option {
+	return func(a *Cache) {
+		a.Rules = append(a.Rules, list...)
+	}
+}
+
+//WithCacheName 设置使用的缓存组件名称
+func WithCacheName(name string) Option {
+	return func(a *Cache) {
+		a.Name = name
+	}
+}
+
+//WithDisable 关闭
+func WithDisable() Option {
+	return func(a *Cache) {
+		a.Disable = true
+	}
+}
+
+//WithEnable 开启
+func WithEnable() Option {
+	return func(a *Cache) {
+		a.Disable = false
+	}
+}
+
+//WithEnableEncryption 启用加密设置
+func WithEnableEncryption() Option {
+	return func(a *Cache) {
+		a.EnableEncryption = true
+	}
+}
+
+//RuleOption Rule配置选项
+type RuleOption func(*Rule)
+
+//WithQuery 使用查询参数作为缓存键
+func WithQuery() RuleOption {
+	return func(a *Rule) {
+		a.Query = true
+	}
+}
+
+//WithHeaders 使用指定的请求头作为缓存键
+func WithHeaders(h ...string) RuleOption {
+	return func(a *Rule) {
+		a.Headers = append(a.Headers, h...)
+	}
+}
+
+//WithUser 使用当前用户作为缓存键
+func WithUser() RuleOption {
+	return func(a *Rule) {
+		a.User = true
+	}
+}
+
+//WithStale 缓存过期后的指定时长(秒)内，允许返回过期内容并由单个请求刷新缓存
+func WithStale(second int) RuleOption {
+	return func(a *Rule) {
+		a.Stale = second
+	}
+}
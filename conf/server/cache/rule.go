@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+//Rule 按请求路径设定的缓存规则
+type Rule struct {
+	Path    string   `json:"path" valid:"ascii,required" toml:"path,omitempty" label:"缓存路径"`
+	Expire  int      `json:"expire" valid:"required" toml:"expire,omitempty" label:"缓存时长(秒)"`
+	Query   bool     `json:"query,omitempty" toml:"query,omitempty"`
+	Headers []string `json:"headers,omitempty" toml:"headers,omitempty"`
+	User    bool     `json:"user,omitempty" toml:"user,omitempty"`
+	Stale   int      `json:"stale,omitempty" toml:"stale,omitempty"`
+}
+
+//NewRule 构建缓存规则
+func NewRule(path string, expire int, opts ...RuleOption) *Rule {
+	r := &Rule{
+		Path:   path,
+		Expire: expire,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+//GetExpire 获取缓存有效时长
+func (r *Rule) GetExpire() time.Duration {
+	return time.Second * time.Duration(r.Expire)
+}
+
+//AllowStale 是否允许在刷新缓存时返回过期内容
+func (r *Rule) AllowStale() bool {
+	return r.Stale > 0
+}
+
+//GetStoreExpire 获取缓存组件中的保存时长(秒),包含允许返回过期内容的时长
+func (r *Rule) GetStoreExpire() int {
+	if r.Stale > 0 {
+		return r.Expire + r.Stale
+	}
+	return r.Expire
+}
+
+//GetKey 根据请求路径、参数、头与用户信息构建缓存键
+func (r *Rule) GetKey(method string, path string, query url.Values, header func(string) string, user string) string {
+	parts := make([]string, 0, 4+len(r.Headers))
+	parts = append(parts, method, path)
+	if r.Query {
+		parts = append(parts, sortQuery(query))
+	}
+	for _, h := range r.Headers {
+		parts = append(parts, fmt.Sprintf("%s=%s", strings.ToLower(h), header(h)))
+	}
+	if r.User {
+		parts = append(parts, "user="+user)
+	}
+	buff := md5.Sum([]byte(strings.Join(parts, "|")))
+	return fmt.Sprintf("hydra:rspcache:%s", hex.EncodeToString(buff[:]))
+}
+
+func sortQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	list := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vs := append([]string{}, query[k]...)
+		sort.Strings(vs)
+		list = append(list, fmt.Sprintf("%s=%s", k, strings.Join(vs, ",")))
+	}
+	return strings.Join(list, "&")
+}
@@ -0,0 +1,96 @@
+/*
+根据请求头中的幂等键对写请求进行去重，首次请求执行期间锁定幂等键，执行完成后保存响应状态码、响应头与响应内容。
+有效期内的重试请求直接返回已保存的响应结果，首次请求未完成时的重复请求返回409。
+*/
+
+package idempotency
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/conf/pkgs/security"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+)
+
+const (
+	//TypeNodeName idempotency配置节点名
+	TypeNodeName = "idempotency"
+
+	//DefHeader 默认的幂等键请求头
+	DefHeader = "Idempotency-Key"
+
+	//DefCacheName 默认使用的缓存组件名称
+	DefCacheName = "cache"
+)
+
+//Idempotency 幂等配置
+type Idempotency struct {
+	security.ConfEncrypt
+	Header  string          `json:"header,omitempty" toml:"header,omitempty" label:"幂等键请求头"`
+	Cache   string          `json:"cache,omitempty" toml:"cache,omitempty" label:"缓存组件名称"`
+	Rules   []*Rule         `json:"rules,omitempty" valid:"required" toml:"rules,omitempty" label:"幂等规则"`
+	Disable bool            `json:"disable,omitempty" toml:"disable,omitempty"`
+	p       *conf.PathMatch `json:"-"`
+	rules   cmap.ConcurrentMap
+}
+
+//New 构建幂等配置
+func New(opts ...Option) *Idempotency {
+	c := &Idempotency{
+		Header: DefHeader,
+		Cache:  DefCacheName,
+		Rules:  []*Rule{},
+		rules:  cmap.New(8),
+	}
+	for _, f := range opts {
+		f(c)
+	}
+	paths := make([]string, 0, len(c.Rules)+1)
+	for _, v := range c.Rules {
+		c.rules.Set(v.Path, v)
+		paths = append(paths, v.Path)
+	}
+	c.p = conf.NewPathMatch(paths...)
+	return c
+}
+
+//GetRule 获取请求路径对应的幂等规则
+func (c *Idempotency) GetRule(path string) (bool, *Rule) {
+	ok, path := c.p.Match(path)
+	if !ok {
+		return false, nil
+	}
+	rule, ok := c.rules.Get(path)
+	if !ok {
+		panic("从缓存中未找到idempotency规则")
+	}
+	return true, rule.(*Rule)
+}
+
+//GetConf 获取幂等配置
+func GetConf(cnf conf.IServerConf) (*Idempotency, error) {
+	c := &Idempotency{}
+	_, err := cnf.GetSubObject(TypeNodeName, c)
+	if errors.Is(err, conf.ErrNoSetting) || len(c.Rules) == 0 {
+		return &Idempotency{Disable: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("绑定idempotency配置有误:%v", err)
+	}
+	if b, err := govalidator.ValidateStruct(c); !b {
+		return nil, fmt.Errorf("idempotency配置数据有误:%v %+v", err, c)
+	}
+
+	nc := New(WithRuleList(c.Rules...))
+	nc.Disable = c.Disable
+	if c.Header != "" {
+		nc.Header = c.Header
+	}
+	if c.Cache != "" {
+		nc.Cache = c.Cache
+	}
+	return nc, nil
+}
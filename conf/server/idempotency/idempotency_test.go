@@ -0,0 +1,41 @@
+package idempotency
+
+import (
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestIdempotency_GetRule(t *testing.T) {
+	c := New(WithRuleList(NewRule("/order/*", WithRequired(), WithExpire(600)), NewRule("/pay")))
+	assert.Equal(t, DefHeader, c.Header, "1. 默认幂等键请求头")
+
+	enable, rule := c.GetRule("/order/create")
+	assert.Equal(t, true, enable, "2. 模糊匹配路径")
+	assert.Equal(t, true, rule.Required, "2. 模糊匹配路径")
+	assert.Equal(t, 600, rule.GetExpire(), "2. 模糊匹配路径")
+	assert.Equal(t, defTimeout, rule.GetTimeout(), "2. 模糊匹配路径")
+
+	enable, rule = c.GetRule("/pay")
+	assert.Equal(t, true, enable, "3. 精确匹配路径")
+	assert.Equal(t, defExpire, rule.GetExpire(), "3. 精确匹配路径")
+
+	enable, _ = c.GetRule("/user/login")
+	assert.Equal(t, false, enable, "4. 未配置的路径")
+}
+
+func TestRule_GetKey(t *testing.T) {
+	r := NewRule("/pay")
+	assert.Equal(t, r.GetKey("POST", "/pay", "u1", "k1"), r.GetKey("POST", "/pay", "u1", "k1"), "1. 相同请求的键相同")
+	assert.NotEqual(t, r.GetKey("POST", "/pay", "u1", "k1"), r.GetKey("POST", "/pay", "u2", "k1"), "2. 不同用户的键不同")
+	assert.NotEqual(t, r.GetKey("POST", "/pay", "u1", "k1"), r.GetKey("PUT", "/pay", "u1", "k1"), "3. 不同请求方法的键不同")
+}
+
+func TestIsMutating(t *testing.T) {
+	for _, m := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+		assert.Equal(t, true, IsMutating(m), m)
+	}
+	for _, m := range []string{"GET", "HEAD", "OPTIONS"} {
+		assert.Equal(t, false, IsMutating(m), m)
+	}
+}
@@ -0,0 +1,77 @@
+package idempotency
+
+//Option 配置选项
+type Option func(*Idempotency)
+
+//WithRuleList 设置幂等规则
+func WithRuleList(list ...*Rule) Option {
+	return func(a *Idempotency) {
+		a.Rules = append(a.Rules, list...)
+	}
+}
+
+//WithHeader 设置幂等键请求头
+func WithHeader(header string) Option {
+	return func(a *Idempotency) {
+		a.Header = header
+	}
+}
+
+//WithCacheName 设置使用的缓存组件名称
+func WithCacheName(name string) Option {
+	return func(a *Idempotency) {
+		a.Cache = name
+	}
+}
+
+//WithDisable 关闭
+func WithDisable() Option {
+	return func(a *Idempotency) {
+		a.Disable = true
+	}
+}
+
+//WithEnable 开启
+func WithEnable() Option {
+	return func(a *Idempotency) {
+		a.Disable = false
+	}
+}
+
+//WithEnableEncryption 启用加密设置
+func WithEnableEncryption() Option {
+	return func(a *Idempotency) {
+		a.EnableEncryption = true
+	}
+}
+
+//RuleOption Rule配置选项
+type RuleOption func(*Rule)
+
+//WithExpire 设置响应结果保存时长(秒)
+func WithExpire(second int) RuleOption {
+	return func(a *Rule) {
+		a.Expire = second
+	}
+}
+
+//WithTimeout 设置首次请求执行期间幂等键的锁定时长(秒)
+func WithTimeout(second int) RuleOption {
+	return func(a *Rule) {
+		a.Timeout = second
+	}
+}
+
+//WithRequired 请求必须包含幂等键
+func WithRequired() RuleOption {
+	return func(a *Rule) {
+		a.Required = true
+	}
+}
+
+//WithDLock 使用分布式锁锁定幂等键
+func WithDLock() RuleOption {
+	return func(a *Rule) {
+		a.DLock = true
+	}
+}
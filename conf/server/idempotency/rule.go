@@ -0,0 +1,62 @@
+package idempotency
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+)
+
+const (
+	defExpire  = 86400
+	defTimeout = 60
+)
+
+//Rule 按请求路径设定的幂等规则
+type Rule struct {
+	Path     string `json:"path" valid:"ascii,required" toml:"path,omitempty" label:"幂等请求路径"`
+	Expire   int    `json:"expire,omitempty" toml:"expire,omitempty" label:"响应结果保存时长(秒)"`
+	Timeout  int    `json:"timeout,omitempty" toml:"timeout,omitempty" label:"幂等键锁定时长(秒)"`
+	Required bool   `json:"required,omitempty" toml:"required,omitempty"`
+	DLock    bool   `json:"dlock,omitempty" toml:"dlock,omitempty"`
+}
+
+//NewRule 构建幂等规则
+func NewRule(path string, opts ...RuleOption) *Rule {
+	r := &Rule{Path: path}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+//GetExpire 获取响应结果保存时长(秒)
+func (r *Rule) GetExpire() int {
+	if r.Expire <= 0 {
+		return defExpire
+	}
+	return r.Expire
+}
+
+//GetTimeout 获取首次请求执行期间幂等键的锁定时长(秒)
+func (r *Rule) GetTimeout() int {
+	if r.Timeout <= 0 {
+		return defTimeout
+	}
+	return r.Timeout
+}
+
+//IsMutating 是否是需要进行幂等处理的请求方法
+func IsMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+//GetKey 根据请求方法、路径、用户与幂等键构建缓存键
+func (r *Rule) GetKey(method string, path string, user string, key string) string {
+	buff := md5.Sum([]byte(fmt.Sprintf("%s|%s|%s|%s", method, path, user, key)))
+	return fmt.Sprintf("hydra:idempotency:%s", hex.EncodeToString(buff[:]))
+}
@@ -0,0 +1,83 @@
+package script
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/types"
+)
+
+const (
+	//paramsName 脚本中修改请求参数的变量名
+	paramsName = "params"
+
+	//headersName 脚本中修改请求头或响应头的变量名
+	headersName = "headers"
+
+	//resultName 脚本中直接返回响应的变量名
+	resultName = "result"
+)
+
+//Hook 按请求路径执行的钩子脚本
+type Hook struct {
+	Path   string `json:"path" valid:"ascii,required" toml:"path,omitempty" label:"请求路径"`
+	Phase  string `json:"phase" valid:"in(before|after),required" toml:"phase,omitempty" label:"执行阶段"`
+	Script string `json:"script" valid:"required" toml:"script,omitempty" label:"tengo脚本"`
+	vm     *VM
+}
+
+//NewHook 构建钩子脚本
+func NewHook(phase string, path string, script string) *Hook {
+	return &Hook{Path: path, Phase: phase, Script: script}
+}
+
+//Result 钩子脚本执行结果
+type Result struct {
+	//Params 需修改的请求参数，值为nil时删除参数
+	Params map[string]interface{}
+
+	//Headers 需修改的请求头或响应头
+	Headers map[string]string
+
+	//Abort 是否直接返回响应
+	Abort       bool
+	Status      int
+	Content     string
+	ContentType string
+}
+
+func (h *Hook) compile(timeout time.Duration, maxAllocs int64, modules ...global.TGOModule) (err error) {
+	h.vm, err = NewVM(h.Script, timeout, maxAllocs, []string{paramsName, headersName, resultName}, modules...)
+	return err
+}
+
+//Run 执行钩子脚本，脚本通过当前goroutine获取请求上下文，调用前需绑定
+func (h *Hook) Run() (*Result, error) {
+	if h.vm == nil {
+		return nil, fmt.Errorf("脚本未编译")
+	}
+	values, err := h.vm.Run()
+	if err != nil {
+		return nil, err
+	}
+	r := &Result{}
+	if params, ok := values[paramsName].(map[string]interface{}); ok {
+		r.Params = params
+	}
+	if headers, ok := values[headersName].(map[string]interface{}); ok {
+		r.Headers = make(map[string]string, len(headers))
+		for k, v := range headers {
+			r.Headers[k] = types.GetString(v)
+		}
+	}
+	if result, ok := values[resultName].([]interface{}); ok && len(result) >= 2 {
+		r.Abort = true
+		r.Status = types.GetInt(result[0])
+		r.Content = types.GetString(result[1])
+		if len(result) > 2 {
+			r.ContentType = types.GetString(result[2])
+		}
+	}
+	return r, nil
+}
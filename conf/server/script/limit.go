@@ -0,0 +1,328 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/d5/tengo/v2"
+	"github.com/d5/tengo/v2/parser"
+	"github.com/d5/tengo/v2/token"
+)
+
+//maxObjectLen 脚本中字符串、字节数组的最大长度及数组的最大元素数。
+//tengo的长度限制(tengo.MaxStringLen、tengo.MaxBytesLen)为进程级变量，默认2GB，修改后会影响其它tengo脚本，
+//因此只在钩子脚本中检查：+与+=替换为addName函数，可生成大对象的内置函数与标准库函数执行前按参数估算结果长度
+const maxObjectLen = 4 * 1024 * 1024
+
+//addName 替换+运算的全局函数名，不是合法的标识符，脚本中无法定义或修改
+const addName = "$add"
+
+var errArrayLimit = errors.New("exceeding array size limit")
+
+//limit 按参数估算结果长度，超过maxObjectLen时返回err
+type limit struct {
+	getLen func(args ...tengo.Object) int
+	err    error
+}
+
+//limitedBuiltins 执行前检查结果长度的内置函数，以同名全局变量替换
+var limitedBuiltins = map[string]limit{
+	"bytes": {getLen: func(args ...tengo.Object) int {
+		if len(args) > 0 {
+			if n, ok := args[0].(*tengo.Int); ok {
+				return getLen(n.Value)
+			}
+		}
+		return 0
+	}, err: tengo.ErrBytesLimit},
+	"format": {getLen: getFormatLen, err: tengo.ErrStringLimit},
+}
+
+//limitedFuncs 标准库中可由较小的参数生成大字符串的函数，按参数估算结果长度
+var limitedFuncs = map[string]map[string]func(args ...tengo.Object) int{
+	"text": {
+		"repeat": func(args ...tengo.Object) int {
+			if len(args) != 2 {
+				return 0
+			}
+			s, _ := tengo.ToString(args[0])
+			n, _ := tengo.ToInt(args[1])
+			if n > maxObjectLen {
+				return n
+			}
+			return len(s) * n
+		},
+		"pad_left":  getArgLen(1),
+		"pad_right": getArgLen(1),
+		"join": func(args ...tengo.Object) int {
+			if len(args) != 2 {
+				return 0
+			}
+			var elems []tengo.Object
+			switch arr := args[0].(type) {
+			case *tengo.Array:
+				elems = arr.Value
+			case *tengo.ImmutableArray:
+				elems = arr.Value
+			}
+			sep, _ := tengo.ToString(args[1])
+			n := len(sep) * len(elems)
+			for _, e := range elems {
+				s, _ := tengo.ToString(e)
+				n += len(s)
+			}
+			return n
+		},
+		"replace": func(args ...tengo.Object) int {
+			if len(args) != 4 {
+				return 0
+			}
+			s, _ := tengo.ToString(args[0])
+			olds, _ := tengo.ToString(args[1])
+			news, _ := tengo.ToString(args[2])
+			n, _ := tengo.ToInt(args[3])
+			count := utf8.RuneCountInString(s) + 1
+			if olds != "" {
+				count = strings.Count(s, olds)
+			}
+			if n >= 0 && n < count {
+				count = n
+			}
+			return len(s) + count*(len(news)-len(olds))
+		},
+	},
+}
+
+//newLimitedModule 复制标准库模块，执行前按参数估算结果长度，执行后检查结果长度
+func newLimitedModule(name string, attrs map[string]tengo.Object) map[string]tengo.Object {
+	limited := make(map[string]tengo.Object, len(attrs))
+	for k, v := range attrs {
+		limited[k] = v
+		if f, ok := v.(*tengo.UserFunction); ok {
+			limited[k] = &tengo.UserFunction{Name: f.Name, Value: limitFunc(f.Value, limitedFuncs[name][k], tengo.ErrStringLimit)}
+		}
+	}
+	return limited
+}
+
+//limitFunc 执行前按参数估算结果长度，执行后检查结果长度，超过maxObjectLen时返回错误
+func limitFunc(f tengo.CallableFunc, getLen func(args ...tengo.Object) int, limitErr error) tengo.CallableFunc {
+	return func(args ...tengo.Object) (tengo.Object, error) {
+		if getLen != nil && getLen(args...) > maxObjectLen {
+			return nil, limitErr
+		}
+		res, err := f(args...)
+		if err != nil {
+			return nil, err
+		}
+		return res, checkLen(res)
+	}
+}
+
+//limitAdd 执行+运算，结果长度超过maxObjectLen时返回错误
+func limitAdd(args ...tengo.Object) (tengo.Object, error) {
+	if len(args) != 2 {
+		return nil, tengo.ErrWrongNumArguments
+	}
+	left, right := args[0], args[1]
+	switch l := left.(type) {
+	case *tengo.String:
+		n := len(l.Value)
+		if r, ok := right.(*tengo.String); ok {
+			n += len(r.Value)
+		} else {
+			n += len(right.String())
+		}
+		if n > maxObjectLen {
+			return nil, tengo.ErrStringLimit
+		}
+	case *tengo.Bytes:
+		if r, ok := right.(*tengo.Bytes); ok && len(l.Value)+len(r.Value) > maxObjectLen {
+			return nil, tengo.ErrBytesLimit
+		}
+	case *tengo.Array:
+		if r, ok := right.(*tengo.Array); ok && len(l.Value)+len(r.Value) > maxObjectLen {
+			return nil, errArrayLimit
+		}
+	}
+	res, err := left.BinaryOp(token.Add, right)
+	if err == tengo.ErrInvalidOperator {
+		return nil, fmt.Errorf("invalid operation: %s + %s", left.TypeName(), right.TypeName())
+	}
+	return res, err
+}
+
+//checkLen 检查函数返回的字符串与字节数组长度
+func checkLen(obj tengo.Object) error {
+	switch v := obj.(type) {
+	case *tengo.String:
+		if len(v.Value) > maxObjectLen {
+			return tengo.ErrStringLimit
+		}
+	case *tengo.Bytes:
+		if len(v.Value) > maxObjectLen {
+			return tengo.ErrBytesLimit
+		}
+	}
+	return nil
+}
+
+//getArgLen 以第i个参数作为结果长度
+func getArgLen(i int) func(args ...tengo.Object) int {
+	return func(args ...tengo.Object) int {
+		if len(args) <= i {
+			return 0
+		}
+		n, _ := tengo.ToInt(args[i])
+		return n
+	}
+}
+
+//getFormatLen 以格式字符串中的最大宽度、精度作为结果长度，格式中包含*时以参数中的最大整数作为结果长度
+func getFormatLen(args ...tengo.Object) int {
+	if len(args) == 0 {
+		return 0
+	}
+	format, ok := args[0].(*tengo.String)
+	if !ok {
+		return 0
+	}
+	max, n := 0, 0
+	for _, c := range format.Value {
+		if c >= '0' && c <= '9' && n <= maxObjectLen {
+			n = n*10 + int(c-'0')
+			continue
+		}
+		if n > max {
+			max = n
+		}
+		n = 0
+	}
+	if n > max {
+		max = n
+	}
+	if strings.Contains(format.Value, "*") {
+		for _, arg := range args[1:] {
+			if v, ok := arg.(*tengo.Int); ok && getLen(v.Value) > max {
+				max = getLen(v.Value)
+			}
+		}
+	}
+	return max
+}
+
+//getLen 将整数转换为长度，超过maxObjectLen时返回maxObjectLen+1
+func getLen(n int64) int {
+	if n > maxObjectLen {
+		return maxObjectLen + 1
+	}
+	return int(n)
+}
+
+//rewriteAdd 将脚本中的+运算替换为addName函数调用，a += b替换为a = addName(a, b)，
+//左侧表达式中的索引、选择器会计算两次
+func rewriteAdd(file *parser.File) {
+	for _, s := range file.Stmts {
+		rewriteStmt(s)
+	}
+}
+
+func rewriteStmt(stmt parser.Stmt) {
+	switch s := stmt.(type) {
+	case *parser.AssignStmt:
+		rewriteExprs(s.LHS)
+		rewriteExprs(s.RHS)
+		if s.Token == token.AddAssign && len(s.LHS) == 1 && len(s.RHS) == 1 {
+			s.Token = token.Assign
+			s.RHS[0] = newAddCall(s.LHS[0], s.RHS[0], s.TokenPos)
+		}
+	case *parser.BlockStmt:
+		if s != nil {
+			for _, v := range s.Stmts {
+				rewriteStmt(v)
+			}
+		}
+	case *parser.ExportStmt:
+		s.Result = rewriteExpr(s.Result)
+	case *parser.ExprStmt:
+		s.Expr = rewriteExpr(s.Expr)
+	case *parser.ForInStmt:
+		s.Iterable = rewriteExpr(s.Iterable)
+		rewriteStmt(s.Body)
+	case *parser.ForStmt:
+		rewriteStmt(s.Init)
+		s.Cond = rewriteExpr(s.Cond)
+		rewriteStmt(s.Post)
+		rewriteStmt(s.Body)
+	case *parser.IfStmt:
+		rewriteStmt(s.Init)
+		s.Cond = rewriteExpr(s.Cond)
+		rewriteStmt(s.Body)
+		rewriteStmt(s.Else)
+	case *parser.IncDecStmt:
+		s.Expr = rewriteExpr(s.Expr)
+	case *parser.ReturnStmt:
+		s.Result = rewriteExpr(s.Result)
+	}
+}
+
+func rewriteExprs(exprs []parser.Expr) {
+	for i, e := range exprs {
+		exprs[i] = rewriteExpr(e)
+	}
+}
+
+func rewriteExpr(expr parser.Expr) parser.Expr {
+	switch e := expr.(type) {
+	case *parser.ArrayLit:
+		rewriteExprs(e.Elements)
+	case *parser.BinaryExpr:
+		e.LHS = rewriteExpr(e.LHS)
+		e.RHS = rewriteExpr(e.RHS)
+		if e.Token == token.Add {
+			return newAddCall(e.LHS, e.RHS, e.TokenPos)
+		}
+	case *parser.CallExpr:
+		e.Func = rewriteExpr(e.Func)
+		rewriteExprs(e.Args)
+	case *parser.CondExpr:
+		e.Cond = rewriteExpr(e.Cond)
+		e.True = rewriteExpr(e.True)
+		e.False = rewriteExpr(e.False)
+	case *parser.ErrorExpr:
+		e.Expr = rewriteExpr(e.Expr)
+	case *parser.FuncLit:
+		rewriteStmt(e.Body)
+	case *parser.ImmutableExpr:
+		e.Expr = rewriteExpr(e.Expr)
+	case *parser.IndexExpr:
+		e.Expr = rewriteExpr(e.Expr)
+		e.Index = rewriteExpr(e.Index)
+	case *parser.MapLit:
+		for _, v := range e.Elements {
+			v.Value = rewriteExpr(v.Value)
+		}
+	case *parser.ParenExpr:
+		e.Expr = rewriteExpr(e.Expr)
+	case *parser.SelectorExpr:
+		e.Expr = rewriteExpr(e.Expr)
+	case *parser.SliceExpr:
+		e.Expr = rewriteExpr(e.Expr)
+		e.Low = rewriteExpr(e.Low)
+		e.High = rewriteExpr(e.High)
+	case *parser.UnaryExpr:
+		e.Expr = rewriteExpr(e.Expr)
+	}
+	return expr
+}
+
+func newAddCall(lhs parser.Expr, rhs parser.Expr, pos parser.Pos) *parser.CallExpr {
+	return &parser.CallExpr{
+		Func:   &parser.Ident{Name: addName, NamePos: pos},
+		LParen: pos,
+		Args:   []parser.Expr{lhs, rhs},
+		RParen: pos,
+	}
+}
@@ -0,0 +1,49 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/d5/tengo/v2"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func runVM(t *testing.T, src string) (map[string]interface{}, error) {
+	vm, err := NewVM(src, 0, 0, []string{"r"})
+	assert.Equal(t, nil, err, "编译脚本")
+	return vm.Run()
+}
+
+func TestVM_Limit(t *testing.T) {
+	maxStringLen, maxBytesLen := tengo.MaxStringLen, tengo.MaxBytesLen
+	_, err := runVM(t, `s := "hydra"; for i := 0; i < 30; i++ { s += s }`)
+	assert.NotEqual(t, nil, err, "1. 拼接字符串超过长度限制")
+	assert.Equal(t, maxStringLen, tengo.MaxStringLen, "1. 不修改tengo的全局字符串长度限制")
+	assert.Equal(t, maxBytesLen, tengo.MaxBytesLen, "1. 不修改tengo的全局字节数组长度限制")
+
+	values, err := runVM(t, `
+		f := func(a, b) { return a + b }
+		m := {k: "a"}
+		m.k += "b"
+		a := [1]
+		a[0] += 1
+		r := [f("x", "y"), m.k + 1, a[0], 1 + 2.5, [1] + [2]]`)
+	assert.Equal(t, nil, err, "2. 长度限制内正常执行+运算")
+	assert.Equal(t, []interface{}{"xy", "ab1", int64(2), 3.5, []interface{}{int64(1), int64(2)}}, values["r"], "2. 长度限制内正常执行+运算")
+
+	_, err = runVM(t, `r := 1 + "a"`)
+	assert.NotEqual(t, nil, err, "3. 不支持的+运算")
+
+	cases := map[string]string{
+		"4. 函数中拼接字符串超过长度限制": `f := func() { s := "hydra"; for i := 0; i < 30; i++ { s = s + s } }; f()`,
+		"5. 拼接数组超过长度限制":     `a := [1]; for i := 0; i < 30; i++ { a += a }`,
+		"6. 字节数组超过长度限制":     `r := bytes(1024 * 1024 * 1024)`,
+		"7. 格式化宽度超过长度限制":    `r := format("%01000000000d", 1)`,
+		"8. 填充字符串超过长度限制":    `text := import("text"); r := text.pad_left("a", 1024 * 1024 * 1024)`,
+		"9. 连接字符串超过长度限制":    `text := import("text"); s := text.repeat("a", 1024 * 1024); r := text.join([s, s, s, s, s], "")`,
+		"10. 替换字符串超过长度限制":   `text := import("text"); s := text.repeat("a", 1024 * 1024); r := text.replace(s, "a", "aaaaa", -1)`,
+	}
+	for name, src := range cases {
+		_, err = runVM(t, src)
+		assert.NotEqual(t, nil, err, name)
+	}
+}
@@ -0,0 +1,60 @@
+package script
+
+//Option 配置选项
+type Option func(*Script)
+
+//WithBefore 添加业务处理前执行的脚本
+func WithBefore(path string, script string) Option {
+	return func(a *Script) {
+		a.Hooks = append(a.Hooks, NewHook(PhaseBefore, path, script))
+	}
+}
+
+//WithAfter 添加业务处理后执行的脚本
+func WithAfter(path string, script string) Option {
+	return func(a *Script) {
+		a.Hooks = append(a.Hooks, NewHook(PhaseAfter, path, script))
+	}
+}
+
+//WithTimeout 设置脚本执行超时时长(毫秒)，不包含阻塞的缓存、消息队列调用
+func WithTimeout(ms int) Option {
+	return func(a *Script) {
+		a.Timeout = ms
+	}
+}
+
+//WithMaxAllocs 设置脚本最大对象分配数，为创建对象的次数，不是占用的内存
+func WithMaxAllocs(n int64) Option {
+	return func(a *Script) {
+		a.MaxAllocs = n
+	}
+}
+
+//WithCaches 设置脚本可访问的缓存组件，*表示所有
+func WithCaches(names ...string) Option {
+	return func(a *Script) {
+		a.Caches = append(a.Caches, names...)
+	}
+}
+
+//WithQueues 设置脚本可访问的消息队列组件，*表示所有
+func WithQueues(names ...string) Option {
+	return func(a *Script) {
+		a.Queues = append(a.Queues, names...)
+	}
+}
+
+//WithDisable 关闭
+func WithDisable() Option {
+	return func(a *Script) {
+		a.Disable = true
+	}
+}
+
+//WithEnable 开启
+func WithEnable() Option {
+	return func(a *Script) {
+		a.Disable = false
+	}
+}
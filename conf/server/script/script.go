@@ -0,0 +1,159 @@
+/*
+请求钩子脚本，按请求路径在业务处理前(before)或业务处理后(after)执行tengo脚本。
+脚本通过全局变量返回处理结果：
+params 修改请求参数(仅before)，值为undefined时删除参数；
+headers 修改请求头(before)或响应头(after)；
+result 直接返回响应[状态码,内容,内容类型]，before中设置时不再执行业务处理。
+脚本在沙箱中执行，不能访问文件与进程，执行时长、对象分配数与字符串长度受限，只能调用白名单中的缓存与消息队列组件。
+对象分配数为创建对象的次数，不是占用的内存；执行超时不能中断阻塞的缓存、消息队列调用，其时长由组件自身的超时设置控制。
+*/
+package script
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+)
+
+const (
+	//TypeNodeName script配置节点名
+	TypeNodeName = "script"
+
+	//PhaseBefore 业务处理前执行
+	PhaseBefore = "before"
+
+	//PhaseAfter 业务处理后执行
+	PhaseAfter = "after"
+
+	//defTimeout 默认脚本执行超时时长(毫秒)
+	defTimeout = 100
+
+	//defMaxAllocs 默认脚本最大对象分配数
+	defMaxAllocs = 100000
+)
+
+//Script 请求钩子脚本配置
+type Script struct {
+	Hooks     []*Hook  `json:"hooks,omitempty" valid:"required" toml:"hooks,omitempty" label:"钩子脚本"`
+	Timeout   int      `json:"timeout,omitempty" toml:"timeout,omitempty" label:"脚本执行超时时长(毫秒)"`
+	MaxAllocs int64    `json:"maxAllocs,omitempty" toml:"maxAllocs,omitempty" label:"脚本最大对象分配数"`
+	Caches    []string `json:"caches,omitempty" toml:"caches,omitempty" label:"脚本可访问的缓存组件"`
+	Queues    []string `json:"queues,omitempty" toml:"queues,omitempty" label:"脚本可访问的消息队列组件"`
+	Disable   bool     `json:"disable,omitempty" toml:"disable,omitempty"`
+	matches   map[string]*conf.PathMatch
+	hooks     cmap.ConcurrentMap
+}
+
+//New 构建请求钩子脚本配置
+func New(opts ...Option) *Script {
+	c := &Script{
+		Hooks: []*Hook{},
+	}
+	for _, f := range opts {
+		f(c)
+	}
+	return c
+}
+
+//GetTimeout 获取脚本执行超时时长
+func (c *Script) GetTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return time.Duration(defTimeout) * time.Millisecond
+	}
+	return time.Duration(c.Timeout) * time.Millisecond
+}
+
+//GetMaxAllocs 获取脚本最大对象分配数
+func (c *Script) GetMaxAllocs() int64 {
+	if c.MaxAllocs <= 0 {
+		return defMaxAllocs
+	}
+	return c.MaxAllocs
+}
+
+//AllowCache 脚本是否可访问指定的缓存组件
+func (c *Script) AllowCache(name string) bool {
+	return contains(c.Caches, name)
+}
+
+//AllowQueue 脚本是否可访问指定的消息队列组件
+func (c *Script) AllowQueue(name string) bool {
+	return contains(c.Queues, name)
+}
+
+//GetHook 获取请求路径在指定阶段执行的钩子脚本
+func (c *Script) GetHook(phase string, path string) (*Hook, bool) {
+	m, ok := c.matches[phase]
+	if !ok {
+		return nil, false
+	}
+	ok, pattern := m.Match(path)
+	if !ok {
+		return nil, false
+	}
+	hook, ok := c.hooks.Get(phase + ":" + pattern)
+	if !ok {
+		panic("从缓存中未找到script钩子")
+	}
+	return hook.(*Hook), true
+}
+
+//compile 编译所有钩子脚本，并按阶段构建路径匹配
+func (c *Script) compile() error {
+	c.hooks = cmap.New(4)
+	paths := make(map[string][]string)
+	modules := make([]global.TGOModule, 0, len(global.GetNamedTGOModules())+len(global.GetHookTGOModules()))
+	modules = append(append(modules, global.GetNamedTGOModules()...), global.GetHookTGOModules()...)
+	for _, h := range c.Hooks {
+		key := h.Phase + ":" + h.Path
+		if _, ok := c.hooks.Get(key); ok {
+			return fmt.Errorf("路径%s重复配置了%s脚本", h.Path, h.Phase)
+		}
+		if err := h.compile(c.GetTimeout(), c.GetMaxAllocs(), modules...); err != nil {
+			return fmt.Errorf("%s %s脚本错误:%v", h.Path, h.Phase, err)
+		}
+		c.hooks.Set(key, h)
+		paths[h.Phase] = append(paths[h.Phase], h.Path)
+	}
+	c.matches = make(map[string]*conf.PathMatch, len(paths))
+	for phase, list := range paths {
+		c.matches[phase] = conf.NewPathMatch(list...)
+	}
+	return nil
+}
+
+//GetConf 获取请求钩子脚本配置
+func GetConf(cnf conf.IServerConf) (*Script, error) {
+	c := &Script{}
+	_, err := cnf.GetSubObject(TypeNodeName, c)
+	if errors.Is(err, conf.ErrNoSetting) || len(c.Hooks) == 0 {
+		return &Script{Disable: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("绑定script配置有误:%v", err)
+	}
+	if b, err := govalidator.ValidateStruct(c); !b {
+		return nil, fmt.Errorf("script配置数据有误:%v %+v", err, c)
+	}
+	if c.Disable {
+		return c, nil
+	}
+	if err := c.compile(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func contains(list []string, name string) bool {
+	for _, v := range list {
+		if v == name || v == "*" {
+			return true
+		}
+	}
+	return false
+}
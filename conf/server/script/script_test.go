@@ -0,0 +1,97 @@
+package script
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/tgo"
+)
+
+func TestScript_GetHook(t *testing.T) {
+	c := New(WithBefore("/order/*", `params := {"source": "hook"}`), WithAfter("/order/*", `headers := {"X-Hook": "after"}`), WithBefore("/pay", `result := [403, "forbidden"]`))
+	assert.Equal(t, nil, c.compile(), "1. 编译脚本")
+
+	hook, ok := c.GetHook(PhaseBefore, "/order/create")
+	assert.Equal(t, true, ok, "2. 模糊匹配前置脚本")
+	r, err := hook.Run()
+	assert.Equal(t, nil, err, "2. 模糊匹配前置脚本")
+	assert.Equal(t, "hook", r.Params["source"], "2. 模糊匹配前置脚本")
+	assert.Equal(t, false, r.Abort, "2. 模糊匹配前置脚本")
+
+	hook, ok = c.GetHook(PhaseAfter, "/order/create")
+	assert.Equal(t, true, ok, "3. 模糊匹配后置脚本")
+	r, _ = hook.Run()
+	assert.Equal(t, "after", r.Headers["X-Hook"], "3. 模糊匹配后置脚本")
+
+	hook, ok = c.GetHook(PhaseBefore, "/pay")
+	assert.Equal(t, true, ok, "4. 直接返回响应")
+	r, _ = hook.Run()
+	assert.Equal(t, true, r.Abort, "4. 直接返回响应")
+	assert.Equal(t, 403, r.Status, "4. 直接返回响应")
+	assert.Equal(t, "forbidden", r.Content, "4. 直接返回响应")
+
+	_, ok = c.GetHook(PhaseAfter, "/pay")
+	assert.Equal(t, false, ok, "5. 未配置的阶段")
+	_, ok = c.GetHook(PhaseBefore, "/user/login")
+	assert.Equal(t, false, ok, "6. 未配置的路径")
+
+	c = New(WithBefore("/pay", `a := 1`), WithBefore("/pay", `b := 1`))
+	assert.NotEqual(t, nil, c.compile(), "7. 重复配置的脚本")
+}
+
+func TestScript_Sandbox(t *testing.T) {
+	c := New(WithTimeout(20), WithBefore("/loop", `for {}`))
+	assert.Equal(t, nil, c.compile())
+	hook, _ := c.GetHook(PhaseBefore, "/loop")
+	_, err := hook.Run()
+	assert.NotEqual(t, nil, err, "1. 执行超时")
+	assert.Equal(t, true, strings.Contains(err.Error(), "超时"), "1. 执行超时")
+
+	c = New(WithMaxAllocs(100), WithBefore("/alloc", `a := []; for i := 0; i < 1000; i++ { a = append(a, {}) }`))
+	assert.Equal(t, nil, c.compile())
+	hook, _ = c.GetHook(PhaseBefore, "/alloc")
+	_, err = hook.Run()
+	assert.NotEqual(t, nil, err, "2. 超过对象分配数")
+
+	c = New(WithBefore("/os", `os := import("os"); os.exit(1)`))
+	assert.NotEqual(t, nil, c.compile(), "3. 不能使用os模块")
+
+	c = New(WithBefore("/text", `text := import("text"); params := {"name": text.to_upper("hydra")}`))
+	assert.Equal(t, nil, c.compile(), "4. 可使用安全的标准库")
+	hook, _ = c.GetHook(PhaseBefore, "/text")
+	r, err := hook.Run()
+	assert.Equal(t, nil, err, "4. 可使用安全的标准库")
+	assert.Equal(t, "HYDRA", r.Params["name"], "4. 可使用安全的标准库")
+
+	m := tgo.NewModule("demo").Add("hello", tgo.FuncARS(func() string { return "hello" }))
+	vm, err := NewVM(`demo := import("demo"); result := [200, demo.hello()]`, 0, 0, []string{"result"}, global.TGOModule{Name: "demo", Module: m})
+	assert.Equal(t, nil, err, "5. 使用自定义模块")
+	values, err := vm.Run()
+	assert.Equal(t, nil, err, "5. 使用自定义模块")
+	assert.Equal(t, []interface{}{int64(200), "hello"}, values["result"], "5. 使用自定义模块")
+
+	c = New(WithBefore("/fmt", `fmt := import("fmt"); fmt.println("hydra")`))
+	assert.NotEqual(t, nil, c.compile(), "6. 不能使用fmt模块")
+
+	c = New(WithBefore("/repeat", `text := import("text"); params := {"name": text.repeat("hydra", 1024 * 1024)}`))
+	assert.Equal(t, nil, c.compile())
+	hook, _ = c.GetHook(PhaseBefore, "/repeat")
+	_, err = hook.Run()
+	assert.NotEqual(t, nil, err, "7. 超过字符串长度限制")
+
+	c = New(WithBefore("/concat", `s := "hydra"; for i := 0; i < 30; i++ { s += s }`))
+	assert.Equal(t, nil, c.compile())
+	hook, _ = c.GetHook(PhaseBefore, "/concat")
+	_, err = hook.Run()
+	assert.NotEqual(t, nil, err, "8. 拼接字符串超过长度限制")
+}
+
+func TestScript_Allow(t *testing.T) {
+	c := New(WithCaches("cache"), WithQueues("*"))
+	assert.Equal(t, true, c.AllowCache("cache"), "1. 白名单中的缓存")
+	assert.Equal(t, false, c.AllowCache("redis"), "2. 白名单外的缓存")
+	assert.Equal(t, true, c.AllowQueue("queue"), "3. 允许所有消息队列")
+	assert.Equal(t, false, New().AllowQueue("queue"), "4. 默认不允许访问组件")
+}
@@ -0,0 +1,131 @@
+package script
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/d5/tengo/v2"
+	"github.com/d5/tengo/v2/parser"
+	"github.com/d5/tengo/v2/stdlib"
+	"github.com/micro-plat/hydra/global"
+)
+
+//safeModules 脚本可使用的标准库，不包含可访问文件与进程的os模块及输出到标准输出的fmt模块
+var safeModules = []string{"math", "text", "times", "rand", "json", "base64", "hex", "enum"}
+
+//maxConstObjects 脚本中常量对象的最大数量
+const maxConstObjects = 10000
+
+//VM 沙箱虚拟机，在调用方goroutine中执行脚本，使脚本可通过当前goroutine获取请求上下文，
+//并限制执行时长、对象分配数与字符串长度。
+//对象分配数为脚本创建对象的次数，不是占用的内存，单个对象的大小由字符串长度限制(maxObjectLen)约束；
+//超时后在执行下一条指令时中止，不能中断正在执行的宿主函数，如阻塞的缓存、消息队列调用，其时长由组件自身的超时设置控制
+type VM struct {
+	bytecode    *tengo.Bytecode
+	globalsSize int
+	outputs     map[string]int
+	funcs       map[int]tengo.Object
+	timeout     time.Duration
+	maxAllocs   int64
+}
+
+//NewVM 编译脚本，outputs为执行后需获取的全局变量，timeout、maxAllocs小于等于0时不限制
+func NewVM(src string, timeout time.Duration, maxAllocs int64, outputs []string, modules ...global.TGOModule) (*VM, error) {
+	if maxAllocs <= 0 {
+		maxAllocs = -1
+	}
+	if len(src) > maxObjectLen {
+		return nil, fmt.Errorf("脚本长度超过限制:%d", len(src))
+	}
+	fileSet := parser.NewFileSet()
+	srcFile := fileSet.AddFile("(script)", -1, len(src))
+	file, err := parser.NewParser(srcFile, []byte(src), nil).ParseFile()
+	if err != nil {
+		return nil, err
+	}
+	rewriteAdd(file)
+
+	symbolTable := tengo.NewSymbolTable()
+	for idx, fn := range tengo.GetAllBuiltinFunctions() {
+		symbolTable.DefineBuiltin(idx, fn.Name)
+	}
+	moduleMap := stdlib.GetModuleMap(safeModules...)
+	for _, name := range safeModules {
+		if m := moduleMap.GetBuiltinModule(name); m != nil {
+			moduleMap.AddBuiltinModule(name, newLimitedModule(name, m.Attrs))
+		}
+	}
+	for _, m := range modules {
+		moduleMap.AddBuiltinModule(m.Name, m.Module.Objects())
+	}
+
+	c := tengo.NewCompiler(srcFile, symbolTable, nil, moduleMap, nil)
+
+	//NewCompiler会重新定义所有内置函数，需在其后以同名全局变量替换需限制长度的内置函数
+	funcs := map[int]tengo.Object{
+		symbolTable.Define(addName).Index: &tengo.UserFunction{Name: "add", Value: limitAdd},
+	}
+	for _, fn := range tengo.GetAllBuiltinFunctions() {
+		if l, ok := limitedBuiltins[fn.Name]; ok {
+			funcs[symbolTable.Define(fn.Name).Index] = &tengo.UserFunction{Name: fn.Name, Value: limitFunc(fn.Value, l.getLen, l.err)}
+		}
+	}
+	c.EnableFileImport(false)
+	if err := c.Compile(file); err != nil {
+		return nil, err
+	}
+	bytecode := c.Bytecode()
+	bytecode.RemoveDuplicates()
+	if cnt := bytecode.CountObjects(); cnt > maxConstObjects {
+		return nil, fmt.Errorf("常量对象数超过限制:%d", cnt)
+	}
+
+	vm := &VM{
+		bytecode:    bytecode,
+		globalsSize: symbolTable.MaxSymbols() + 1,
+		outputs:     make(map[string]int),
+		funcs:       funcs,
+		timeout:     timeout,
+		maxAllocs:   maxAllocs,
+	}
+	for _, name := range outputs {
+		if symbol, _, ok := symbolTable.Resolve(name, false); ok && symbol.Scope == tengo.ScopeGlobal {
+			vm.outputs[name] = symbol.Index
+		}
+	}
+	return vm, nil
+}
+
+//Run 执行脚本，返回输出变量的值，超时、超过对象分配数或字符串长度时返回错误
+func (v *VM) Run() (map[string]interface{}, error) {
+	globals := make([]tengo.Object, v.globalsSize)
+	for idx, fn := range v.funcs {
+		globals[idx] = fn
+	}
+	vm := tengo.NewVM(v.bytecode, globals, v.maxAllocs)
+
+	var timeout int32
+	if v.timeout > 0 {
+		timer := time.AfterFunc(v.timeout, func() {
+			atomic.StoreInt32(&timeout, 1)
+			vm.Abort()
+		})
+		defer timer.Stop()
+	}
+	err := vm.Run()
+	if atomic.LoadInt32(&timeout) == 1 {
+		return nil, fmt.Errorf("脚本执行超时(%v)", v.timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(v.outputs))
+	for name, idx := range v.outputs {
+		if obj := globals[idx]; obj != nil && obj != tengo.UndefinedValue {
+			values[name] = tengo.ToInterface(obj)
+		}
+	}
+	return values, nil
+}
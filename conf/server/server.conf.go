@@ -129,22 +129,30 @@ func (c *ServerConf) Close() error {
 	return nil
 }
 
-//load 加载配置
+//load 加载配置，按平台、系统、集群、本地覆盖文件、环境变量逐层合并
 func (c *ServerConf) load() (err error) {
 
-	//获取主配置
-	mainPath := c.GetServerPath()
-	conf, err := getValue(c.registry, mainPath)
+	//获取各层配置
+	layers, err := c.loadLayers()
 	if err != nil {
 		return err
 	}
-	c.mainConf = conf
-	c.mainVersion = conf.GetVersion()
 
-	//获取子配置
-	c.subConfs, err = c.getSubConf(c.GetServerPath())
+	//获取主配置
+	c.mainConf, err = mergeLayers(layers, func(l *layer) *layerValue { return l.main })
 	if err != nil {
-		return err
+		return fmt.Errorf("%s配置有误:%w", c.GetServerPath(), err)
+	}
+	c.mainVersion = c.mainConf.GetVersion()
+
+	//获取子配置
+	c.subConfs = make(map[string]conf.RawConf)
+	for _, name := range getSubNames(layers) {
+		sub, err := mergeLayers(layers, func(l *layer) *layerValue { return l.subs[name] })
+		if err != nil {
+			return fmt.Errorf("%s配置有误:%w", c.GetSubConfPath(name), err)
+		}
+		c.subConfs[name] = *sub
 	}
 
 	//获取所有集群名称
@@ -155,53 +163,19 @@ func (c *ServerConf) load() (err error) {
 	return nil
 }
 
-func (c *ServerConf) getSubConf(path string) (map[string]conf.RawConf, error) {
-	confs, _, err := c.registry.GetChildren(path)
-	if err != nil {
-		return nil, err
-	}
-
-	values := make(map[string]conf.RawConf)
-	for _, p := range confs {
-		currentPath := registry.Join(path, p)
-		value, err := getValue(c.registry, currentPath)
-		if err != nil {
-			return nil, err
-		}
-
-		children, err := c.getSubConf(currentPath)
-		if err != nil {
-			return nil, err
-		}
-		for k, v := range children {
-			values[registry.Join(p, k)] = v
-		}
-		if len(children) == 0 {
-			values[p] = *value
-		}
-	}
-
-	return values, nil
-}
-func getValue(registry registry.IRegistry, path string) (*conf.RawConf, error) {
-
+//getData 获取节点值并解密
+func getData(registry registry.IRegistry, path string) ([]byte, int32, error) {
 	data, version, err := registry.GetValue(path)
 	if err != nil {
-		return nil, fmt.Errorf("获取配置出错 %s %w", path, err)
+		return nil, 0, fmt.Errorf("获取配置出错 %s %w", path, err)
 	}
 
 	rdata, err := security.Decrypt(data)
 	if err != nil {
-		return nil, fmt.Errorf("%s[%s]解密子配置失败:%w", path, data, err)
+		return nil, 0, fmt.Errorf("%s[%s]解密子配置失败:%w", path, data, err)
 	}
 	if len(rdata) == 0 {
 		rdata = []byte("{}")
 	}
-
-	childConf, err := conf.NewByText(rdata, version)
-	if err != nil {
-		err = fmt.Errorf("%s[%s]配置有误:%w", path, data, err)
-		return nil, err
-	}
-	return childConf, nil
+	return rdata, version, nil
 }
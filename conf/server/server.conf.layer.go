@@ -0,0 +1,349 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/registry"
+)
+
+//配置层，按以下顺序逐层覆盖
+const (
+	//LayerPlat 平台默认配置 /平台/conf/服务器类型
+	LayerPlat = "plat"
+
+	//LayerSystem 系统配置 /平台/系统/服务器类型/conf
+	LayerSystem = "system"
+
+	//LayerCluster 集群配置 /平台/系统/服务器类型/集群/conf
+	LayerCluster = "cluster"
+
+	//LayerFile 节点本地覆盖文件
+	LayerFile = "file"
+
+	//LayerEnv 环境变量 HYDRA_CONF_服务器类型_配置项，仅用于主配置
+	LayerEnv = "env"
+)
+
+//OverrideFileEnvName 节点本地覆盖文件路径的环境变量
+const OverrideFileEnvName = "HYDRA_CONF_OVERRIDE"
+
+//mainNode 本地覆盖文件中主配置的名称
+const mainNode = "main"
+
+//GetOverrideFile 获取节点本地覆盖文件路径，文件格式为{"服务器类型":{"main":{...},"子配置名":{...}}}
+func GetOverrideFile() string {
+	if p := os.Getenv(OverrideFileEnvName); p != "" {
+		return p
+	}
+	return "./conf.override.json"
+}
+
+//GetLayerPaths 获取平台层与系统层的主配置路径
+func GetLayerPaths(platName string, sysName string, serverType string) []string {
+	return []string{
+		registry.Join(platName, "conf", serverType),
+		registry.Join(platName, sysName, serverType, "conf"),
+	}
+}
+
+type layerValue struct {
+	data    []byte
+	version int32
+}
+
+//layer 一层配置
+type layer struct {
+	name string
+	main *layerValue
+	subs map[string]*layerValue
+}
+
+//loadLayers 加载平台、系统、集群、本地覆盖文件、环境变量各层配置
+func (c *ServerConf) loadLayers() ([]*layer, error) {
+	layers := make([]*layer, 0, 5)
+	paths := append(GetLayerPaths(c.GetPlatName(), c.GetSysName(), c.GetServerType()), c.GetServerPath())
+	for i, name := range []string{LayerPlat, LayerSystem, LayerCluster} {
+		l, err := c.loadRegistryLayer(name, paths[i], name == LayerCluster)
+		if err != nil {
+			return nil, err
+		}
+		if l != nil {
+			layers = append(layers, l)
+		}
+	}
+	l, err := loadFileLayer(GetOverrideFile(), c.GetServerType())
+	if err != nil {
+		return nil, err
+	}
+	if l != nil {
+		layers = append(layers, l)
+	}
+	if l := loadEnvLayer(c.GetServerType()); l != nil {
+		layers = append(layers, l)
+	}
+	return layers, nil
+}
+
+func (c *ServerConf) loadRegistryLayer(name string, path string, required bool) (*layer, error) {
+	if !required {
+		ok, err := c.registry.Exists(path)
+		if err != nil || !ok {
+			return nil, err
+		}
+	}
+	data, version, err := getData(c.registry, path)
+	if err != nil {
+		return nil, err
+	}
+	subs := make(map[string]*layerValue)
+	if err := c.getSubData(path, "", subs); err != nil {
+		return nil, err
+	}
+	return &layer{name: name, main: &layerValue{data: data, version: version}, subs: subs}, nil
+}
+
+//getSubData 获取所有子配置，只保留叶子节点
+func (c *ServerConf) getSubData(path string, prefix string, values map[string]*layerValue) error {
+	children, _, err := c.registry.GetChildren(path)
+	if err != nil {
+		return err
+	}
+	for _, p := range children {
+		currentPath := registry.Join(path, p)
+		name := getSubName(registry.Join(prefix, p))
+		grandChildren, _, err := c.registry.GetChildren(currentPath)
+		if err != nil {
+			return err
+		}
+		if len(grandChildren) > 0 {
+			if err := c.getSubData(currentPath, name, values); err != nil {
+				return err
+			}
+			continue
+		}
+		data, version, err := getData(c.registry, currentPath)
+		if err != nil {
+			return err
+		}
+		values[name] = &layerValue{data: data, version: version}
+	}
+	return nil
+}
+
+func loadFileLayer(path string, serverType string) (*layer, error) {
+	buff, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	all := make(map[string]map[string]json.RawMessage)
+	if err := json.Unmarshal(buff, &all); err != nil {
+		return nil, fmt.Errorf("本地覆盖文件%s格式有误:%w", path, err)
+	}
+	confs, ok := all[serverType]
+	if !ok {
+		return nil, nil
+	}
+	l := &layer{name: LayerFile, subs: make(map[string]*layerValue)}
+	for name, v := range confs {
+		value := &layerValue{data: getRawMessage(v)}
+		if name == mainNode {
+			l.main = value
+			continue
+		}
+		l.subs[getSubName(name)] = value
+	}
+	return l, nil
+}
+
+func loadEnvLayer(serverType string) *layer {
+	prefix := "HYDRA_CONF_" + strings.ToUpper(serverType) + "_"
+	values := make(map[string]interface{})
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, prefix) {
+			continue
+		}
+		v := strings.SplitN(kv[len(prefix):], "=", 2)
+		if len(v) != 2 || v[0] == "" {
+			continue
+		}
+		values[v[0]] = unmarshal([]byte(v[1]))
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	buff, _ := json.Marshal(values)
+	return &layer{name: LayerEnv, main: &layerValue{data: buff}, subs: map[string]*layerValue{}}
+}
+
+//mergeLayers 逐层合并配置，json对象按配置项合并，其它内容整体覆盖；只有集群层时与未分层前保持一致
+func mergeLayers(layers []*layer, get func(l *layer) *layerValue) (*conf.RawConf, error) {
+	values := make([]*layerValue, 0, len(layers))
+	names := make([]string, 0, len(layers))
+	for _, l := range layers {
+		if v := get(l); v != nil {
+			values = append(values, v)
+			names = append(names, l.name)
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	if len(values) == 1 && names[0] == LayerCluster {
+		return conf.NewByText(values[0].data, values[0].version)
+	}
+
+	var merged interface{}
+	sources := make(map[string]string)
+	for i, v := range values {
+		value := unmarshal(v.data)
+		if names[i] == LayerEnv {
+			value = matchKeys(merged, value)
+		}
+		merged = merge(merged, value, names[i], "", sources)
+	}
+	buff, err := marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	return conf.NewByLayers(buff, getLayerVersion(values, names), sources)
+}
+
+//getLayerVersion 根据注册中心各层的名称与版本号计算合并后的版本号，任意一层创建、删除或更新时版本号发生变化，
+//版本号不保证递增，比较配置是否变化时以签名为准
+func getLayerVersion(values []*layerValue, names []string) int32 {
+	h := fnv.New32a()
+	for i, v := range values {
+		if names[i] != LayerFile && names[i] != LayerEnv {
+			fmt.Fprintf(h, "%s:%d;", names[i], v.version)
+		}
+	}
+	return int32(h.Sum32() & math.MaxInt32)
+}
+
+//merge 将src合并到dst，并记录每个配置项的来源层
+func merge(dst interface{}, src interface{}, layer string, path string, sources map[string]string) interface{} {
+	dmap, ok1 := dst.(map[string]interface{})
+	smap, ok2 := src.(map[string]interface{})
+	if !ok1 || !ok2 {
+		for k := range sources {
+			if k == path || path == "" || strings.HasPrefix(k, path+".") {
+				delete(sources, k)
+			}
+		}
+		mark(src, layer, path, sources)
+		return src
+	}
+	for k, v := range smap {
+		dmap[k] = merge(dmap[k], v, layer, joinKey(path, k), sources)
+	}
+	return dmap
+}
+
+func mark(v interface{}, layer string, path string, sources map[string]string) {
+	if m, ok := v.(map[string]interface{}); ok {
+		for k, v := range m {
+			mark(v, layer, joinKey(path, k), sources)
+		}
+		return
+	}
+	sources[path] = layer
+}
+
+//matchKeys 环境变量名为大写，匹配已有的配置项名称
+func matchKeys(dst interface{}, src interface{}) interface{} {
+	dmap, ok1 := dst.(map[string]interface{})
+	smap, ok2 := src.(map[string]interface{})
+	if !ok2 {
+		return src
+	}
+	result := make(map[string]interface{}, len(smap))
+	for k, v := range smap {
+		name := strings.ToLower(k)
+		if ok1 {
+			for dk := range dmap {
+				if strings.EqualFold(dk, k) {
+					name = dk
+					break
+				}
+			}
+		}
+		result[name] = v
+	}
+	return result
+}
+
+func joinKey(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+//unmarshal 转换为json对象，数字保持原样，非json内容作为字符串
+func unmarshal(data []byte) interface{} {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return string(data)
+	}
+	return value
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	if s, ok := v.(string); ok {
+		return []byte(s), nil
+	}
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buffer.Bytes(), "\n"), nil
+}
+
+func getRawMessage(v json.RawMessage) []byte {
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		return []byte(s)
+	}
+	return v
+}
+
+//getSubName 获取子配置名称，与registry.Join拼接的名称一致：一级节点不带"/"，如router，多级节点以"/"开头，如/acl/proxy
+func getSubName(name string) string {
+	name = strings.Trim(name, "/")
+	if strings.Contains(name, "/") {
+		return "/" + name
+	}
+	return name
+}
+
+//getSubNames 获取所有层的子配置名称
+func getSubNames(layers []*layer) []string {
+	names := make([]string, 0, 1)
+	exists := make(map[string]bool)
+	for _, l := range layers {
+		for name := range l.subs {
+			if !exists[name] {
+				exists[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
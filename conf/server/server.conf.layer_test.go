@@ -0,0 +1,62 @@
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestLayers(t *testing.T) {
+	os.Setenv(OverrideFileEnvName, filepath.Join(t.TempDir(), "conf.override.json"))
+	defer os.Unsetenv(OverrideFileEnvName)
+	r := localmemory.NewLocalMemory()
+	r.CreatePersistentNode("/hydra/test/api/t/conf", `{"address":":8080","header":{"a":"1"}}`)
+	r.CreatePersistentNode("/hydra/test/api/t/conf/router", `{"routers":[]}`)
+	r.CreatePersistentNode("/hydra/test/api/t/conf/acl/proxy", `{"script":"a"}`)
+
+	c, err := NewServerConf("hydra", "test", "api", "t", r)
+	assert.Equal(t, nil, err, "1. 只有集群层")
+	assert.Equal(t, false, c.GetMainConf().IsLayered(), "1. 只有集群层时与未分层前一致")
+	assert.Equal(t, `{"address":":8080","header":{"a":"1"}}`, string(c.GetMainConf().GetRaw()), "1. 只有集群层时与未分层前一致")
+	assert.Equal(t, true, c.Has("router", registry.Join("acl", "proxy")), "1. 子配置名称与未分层前一致")
+
+	r.CreatePersistentNode("/hydra/conf/api", `{"address":":9090","status":"start","rTimeout":30,"header":{"b":"2"}}`)
+	r.CreatePersistentNode("/hydra/conf/api/metric", `{"host":"http://127.0.0.1"}`)
+	r.CreatePersistentNode("/hydra/test/api/conf", `{"rTimeout":60}`)
+	c, err = NewServerConf("hydra", "test", "api", "t", r)
+	assert.Equal(t, nil, err, "2. 平台、系统、集群层")
+	main := c.GetMainConf()
+	assert.Equal(t, ":8080", main.GetString("address"), "2. 集群层覆盖平台层")
+	assert.Equal(t, "start", main.GetString("status"), "2. 使用平台层默认值")
+	assert.Equal(t, 60, main.GetInt("rTimeout"), "2. 系统层覆盖平台层")
+	assert.Equal(t, map[string]string{"address": LayerCluster, "status": LayerPlat, "rTimeout": LayerSystem,
+		"header.a": LayerCluster, "header.b": LayerPlat}, main.GetSources(), "2. 配置项来源")
+	assert.Equal(t, true, c.Has("metric", "router"), "2. 合并子配置")
+
+	ioutil.WriteFile(os.Getenv(OverrideFileEnvName), []byte(`{"api":{"main":{"address":":7070"},"router":{"routers":[{"path":"/a"}]},"acl/proxy":{"script":"b"}}}`), 0600)
+	os.Setenv("HYDRA_CONF_API_RTIMEOUT", "90")
+	defer os.Unsetenv("HYDRA_CONF_API_RTIMEOUT")
+	n, err := NewServerConf("hydra", "test", "api", "t", r)
+	assert.Equal(t, nil, err, "3. 本地覆盖文件与环境变量")
+	assert.Equal(t, ":7070", n.GetMainConf().GetString("address"), "3. 本地覆盖文件")
+	assert.Equal(t, 90, n.GetMainConf().GetInt("rTimeout"), "3. 环境变量")
+	assert.Equal(t, LayerEnv, n.GetMainConf().GetSources()["rTimeout"], "3. 环境变量")
+	router, _ := n.GetSubConf("router")
+	assert.Equal(t, LayerFile, router.GetSources()["routers"], "3. 本地覆盖子配置")
+	proxy, _ := n.GetSubConf(registry.Join("acl", "proxy"))
+	assert.Equal(t, "b", proxy.GetString("script"), "3. 本地覆盖多级子配置")
+	assert.Equal(t, c.GetVersion(), n.GetVersion(), "4. 本地覆盖不改变版本号")
+
+	r.Update("/hydra/conf/api", `{"status":"stop"}`)
+	n, _ = NewServerConf("hydra", "test", "api", "t", r)
+	assert.NotEqual(t, c.GetVersion(), n.GetVersion(), "5. 平台层变更后版本号变化")
+
+	r.Delete("/hydra/test/api/conf")
+	d, _ := NewServerConf("hydra", "test", "api", "t", r)
+	assert.NotEqual(t, n.GetVersion(), d.GetVersion(), "6. 删除系统层后版本号变化")
+}
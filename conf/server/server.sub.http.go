@@ -11,11 +11,14 @@ import (
 	"github.com/micro-plat/hydra/conf/server/auth/basic"
 	"github.com/micro-plat/hydra/conf/server/auth/jwt"
 	"github.com/micro-plat/hydra/conf/server/auth/ras"
+	"github.com/micro-plat/hydra/conf/server/cache"
 	"github.com/micro-plat/hydra/conf/server/header"
+	"github.com/micro-plat/hydra/conf/server/idempotency"
 	"github.com/micro-plat/hydra/conf/server/metric"
 	"github.com/micro-plat/hydra/conf/server/nfs"
 	"github.com/micro-plat/hydra/conf/server/processor"
 	"github.com/micro-plat/hydra/conf/server/render"
+	"github.com/micro-plat/hydra/conf/server/script"
 	"github.com/micro-plat/hydra/conf/server/static"
 )
 
@@ -36,6 +39,9 @@ type HttpSub struct {
 	apm       *Loader
 	processor *Loader
 	fs        *Loader
+	cache     *Loader
+	idem      *Loader
+	script    *Loader
 }
 
 func NewHttpSub(cnf conf.IServerConf) *HttpSub {
@@ -55,6 +61,9 @@ func NewHttpSub(cnf conf.IServerConf) *HttpSub {
 	s.apm = GetLoader(cnf, s.getAPMFunc())
 	s.processor = GetLoader(cnf, s.getProcessorFunc())
 	s.fs = GetLoader(cnf, s.getNFSFunc())
+	s.cache = GetLoader(cnf, s.getCacheFunc())
+	s.idem = GetLoader(cnf, s.getIdempotencyFunc())
+	s.script = GetLoader(cnf, s.getScriptFunc())
 	return s
 }
 
@@ -163,6 +172,27 @@ func (s HttpSub) getNFSFunc() func(cnf conf.IServerConf) (interface{}, error) {
 	}
 }
 
+//getCacheFunc 获取响应缓存配置信息
+func (s HttpSub) getCacheFunc() func(cnf conf.IServerConf) (interface{}, error) {
+	return func(cnf conf.IServerConf) (interface{}, error) {
+		return cache.GetConf(cnf)
+	}
+}
+
+//getIdempotencyFunc 获取幂等配置信息
+func (s HttpSub) getIdempotencyFunc() func(cnf conf.IServerConf) (interface{}, error) {
+	return func(cnf conf.IServerConf) (interface{}, error) {
+		return idempotency.GetConf(cnf)
+	}
+}
+
+//getScriptFunc 获取请求钩子脚本配置信息
+func (s HttpSub) getScriptFunc() func(cnf conf.IServerConf) (interface{}, error) {
+	return func(cnf conf.IServerConf) (interface{}, error) {
+		return script.GetConf(cnf)
+	}
+}
+
 //GetHeaderConf 获取响应头配置
 func (s *HttpSub) GetHeaderConf() (header.Headers, error) {
 	headerObj, err := s.header.GetConf()
@@ -302,3 +332,30 @@ func (s *HttpSub) GetNFSConf() (*nfs.NFS, error) {
 	}
 	return f.(*nfs.NFS), nil
 }
+
+//GetCacheConf 获取响应缓存配置
+func (s *HttpSub) GetCacheConf() (*cache.Cache, error) {
+	c, err := s.cache.GetConf()
+	if err != nil {
+		return nil, err
+	}
+	return c.(*cache.Cache), nil
+}
+
+//GetIdempotencyConf 获取幂等配置
+func (s *HttpSub) GetIdempotencyConf() (*idempotency.Idempotency, error) {
+	c, err := s.idem.GetConf()
+	if err != nil {
+		return nil, err
+	}
+	return c.(*idempotency.Idempotency), nil
+}
+
+//GetScriptConf 获取请求钩子脚本配置
+func (s *HttpSub) GetScriptConf() (*script.Script, error) {
+	c, err := s.script.GetConf()
+	if err != nil {
+		return nil, err
+	}
+	return c.(*script.Script), nil
+}
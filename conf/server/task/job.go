@@ -0,0 +1,220 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/utility"
+)
+
+//JobNodeName 一次性任务节点名，与conf节点同级
+const JobNodeName = "jobs"
+
+const (
+	//JobWaiting 等待执行
+	JobWaiting = "waiting"
+
+	//JobRunning 执行中
+	JobRunning = "running"
+
+	//JobSuccess 执行成功
+	JobSuccess = "success"
+
+	//JobFailed 重试次数用完后仍执行失败
+	JobFailed = "failed"
+
+	//JobCanceled 已撤销
+	JobCanceled = "canceled"
+)
+
+//ErrJobNotWaiting 任务已开始执行或已结束
+var ErrJobNotWaiting = errors.New("任务不是等待执行状态")
+
+//Job 在指定时间执行一次的任务，失败后按退避时间重试
+type Job struct {
+	ID       string                 `json:"id"`
+	Service  string                 `json:"service"`
+	Input    map[string]interface{} `json:"input,omitempty"`
+	Header   map[string]string      `json:"header,omitempty"`
+	At       int64                  `json:"at"`
+	Status   string                 `json:"status"`
+	Retry    int                    `json:"retry"`
+	MaxRetry int                    `json:"max_retry"`
+	NextTime int64                  `json:"next_time"`
+	Error    string                 `json:"error,omitempty"`
+	Owner    string                 `json:"owner,omitempty"`
+	Created  int64                  `json:"created"`
+	Finished int64                  `json:"finished,omitempty"`
+}
+
+//JobOption 一次性任务配置选项
+type JobOption func(*Job)
+
+//WithJobHeader 设置任务的请求头
+func WithJobHeader(kv ...string) JobOption {
+	return func(j *Job) {
+		if j.Header == nil {
+			j.Header = make(map[string]string)
+		}
+		for i := 0; i+1 < len(kv); i += 2 {
+			j.Header[kv[i]] = kv[i+1]
+		}
+	}
+}
+
+//WithMaxRetry 设置失败后的最大重试次数
+func WithMaxRetry(n int) JobOption {
+	return func(j *Job) {
+		j.MaxRetry = n
+	}
+}
+
+//NewJob 构建在at时执行的一次性任务
+func NewJob(at time.Time, service string, input map[string]interface{}, opts ...JobOption) *Job {
+	now := time.Now().Unix()
+	j := &Job{
+		ID:       utility.GetGUID(),
+		Service:  service,
+		Input:    input,
+		At:       at.Unix(),
+		Status:   JobWaiting,
+		MaxRetry: 3,
+		NextTime: at.Unix(),
+		Created:  now,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+//IsFinished 是否已结束
+func (j *Job) IsFinished() bool {
+	return j.Status == JobSuccess || j.Status == JobFailed || j.Status == JobCanceled
+}
+
+//IsDue 是否到达执行时间，执行中的任务超过执行时限视为节点异常退出，可重新执行
+func (j *Job) IsDue(now time.Time) bool {
+	return !j.IsFinished() && j.NextTime <= now.Unix()
+}
+
+//GetTask 获取用于执行的任务
+func (j *Job) GetTask() *Task {
+	t := NewTask(CronExecuteNow, j.Service, WithInput(j.Input))
+	t.Header = j.Header
+	return t
+}
+
+//JobStore 一次性任务存储，任务保存在注册中心，由集群的主节点执行
+type JobStore struct {
+	r    registry.IRegistry
+	path string
+}
+
+//NewJobStore 构建一次性任务存储
+func NewJobStore(r registry.IRegistry, c conf.IServerPub) *JobStore {
+	return &JobStore{r: r, path: registry.Join(c.GetServerRoot(), c.GetClusterName(), JobNodeName)}
+}
+
+//GetPath 获取任务的存储路径
+func (s *JobStore) GetPath() string {
+	return s.path
+}
+
+//Create 保存新任务
+func (s *JobStore) Create(j *Job) error {
+	if j.Service == "" {
+		return fmt.Errorf("任务服务不能为空")
+	}
+	buff, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return s.r.CreatePersistentNode(registry.Join(s.path, j.ID), string(buff))
+}
+
+//Get 获取任务及节点版本号
+func (s *JobStore) Get(id string) (*Job, int32, error) {
+	buff, version, err := s.r.GetValue(registry.Join(s.path, id))
+	if err != nil {
+		return nil, 0, fmt.Errorf("任务%s不存在:%w", id, err)
+	}
+	j := &Job{}
+	if err := json.Unmarshal(buff, j); err != nil {
+		return nil, 0, fmt.Errorf("任务%s格式有误:%w", id, err)
+	}
+	return j, version, nil
+}
+
+//List 获取所有任务
+func (s *JobStore) List() ([]*Job, error) {
+	jobs := make([]*Job, 0, 1)
+	ok, err := s.r.Exists(s.path)
+	if err != nil || !ok {
+		return jobs, err
+	}
+	ids, _, err := s.r.GetChildren(s.path)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		j, _, err := s.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+//Update 节点版本号与version一致时更新任务
+func (s *JobStore) Update(j *Job, version int32) error {
+	buff, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return s.r.UpdateIfVersion(registry.Join(s.path, j.ID), string(buff), version)
+}
+
+//Claim 将任务修改为执行中，执行时限为timeout，返回修改后的任务及节点版本号。
+//修改时校验节点版本号，并写入唯一的执行标识，读取后按执行标识确认任务由当前节点执行，
+//任务已被其它节点修改时返回registry.ErrVersionConflict
+func (s *JobStore) Claim(j *Job, version int32, timeout time.Duration) (*Job, int32, error) {
+	j.Status = JobRunning
+	j.Owner = utility.GetGUID()
+	j.NextTime = time.Now().Add(timeout).Unix()
+	if err := s.Update(j, version); err != nil {
+		return nil, 0, err
+	}
+	claimed, version, err := s.Get(j.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if claimed.Status != JobRunning || claimed.Owner != j.Owner {
+		return nil, 0, fmt.Errorf("任务%s%w", j.ID, registry.ErrVersionConflict)
+	}
+	return claimed, version, nil
+}
+
+//Cancel 撤销等待执行的任务
+func (s *JobStore) Cancel(id string) error {
+	j, version, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if j.Status != JobWaiting {
+		return fmt.Errorf("%s %w:%s", id, ErrJobNotWaiting, j.Status)
+	}
+	j.Status = JobCanceled
+	j.Finished = time.Now().Unix()
+	return s.Update(j, version)
+}
+
+//Delete 删除任务
+func (s *JobStore) Delete(id string) error {
+	return s.r.Delete(registry.Join(s.path, id))
+}
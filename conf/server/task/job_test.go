@@ -0,0 +1,67 @@
+package task
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestJob(t *testing.T) {
+	at := time.Now().Add(time.Hour)
+	j := NewJob(at, "/order/remind", map[string]interface{}{"id": 1}, WithMaxRetry(5), WithJobHeader("X-Tag", "a"))
+	assert.Equal(t, JobWaiting, j.Status, "1. 新任务等待执行")
+	assert.Equal(t, 5, j.MaxRetry, "1. 最大重试次数")
+	assert.Equal(t, false, j.IsDue(time.Now()), "2. 未到执行时间")
+	assert.Equal(t, true, j.IsDue(at), "3. 到达执行时间")
+
+	tk := j.GetTask()
+	assert.Equal(t, true, tk.IsImmediately(), "4. 立即执行")
+	assert.Equal(t, "/order/remind", tk.Service, "4. 执行的服务")
+	assert.Equal(t, "a", tk.Header["X-Tag"], "4. 请求头")
+
+	j.Status = JobCanceled
+	assert.Equal(t, false, j.IsDue(at), "5. 已撤销的任务不执行")
+}
+
+//testPub 提供任务存储路径
+type testPub struct {
+	conf.IServerPub
+}
+
+func (testPub) GetServerRoot() string {
+	return "/hydra/test/cron"
+}
+
+func (testPub) GetClusterName() string {
+	return "t"
+}
+
+func TestJobStore_Claim(t *testing.T) {
+	store := NewJobStore(localmemory.NewLocalMemory(), testPub{})
+	job := NewJob(time.Now(), "/order/remind", nil)
+	assert.Equal(t, nil, store.Create(job), "1. 保存任务")
+	_, version, err := store.Get(job.ID)
+	assert.Equal(t, nil, err, "1. 获取任务")
+
+	a, _, _ := store.Get(job.ID)
+	claimed, cversion, err := store.Claim(a, version, time.Minute)
+	assert.Equal(t, nil, err, "2. 修改为执行中")
+	assert.Equal(t, JobRunning, claimed.Status, "2. 修改为执行中")
+	assert.NotEqual(t, "", claimed.Owner, "2. 写入执行标识")
+	assert.NotEqual(t, version, cversion, "2. 返回修改后的版本号")
+
+	//其它节点使用修改前的版本号，在同一秒内修改
+	b, _, _ := store.Get(job.ID)
+	_, _, err = store.Claim(b, version, time.Minute)
+	assert.Equal(t, true, errors.Is(err, registry.ErrVersionConflict), "3. 已被其它节点修改")
+	current, _, _ := store.Get(job.ID)
+	assert.Equal(t, claimed.Owner, current.Owner, "3. 执行标识未被覆盖")
+
+	claimed.Status = JobSuccess
+	assert.Equal(t, nil, store.Update(claimed, cversion), "4. 使用修改后的版本号保存执行结果")
+}
@@ -16,3 +16,22 @@ func WithEnable() Option {
 		a.Disable = false
 	}
 }
+
+//WithInput 设置任务的输入参数
+func WithInput(input map[string]interface{}) Option {
+	return func(a *Task) {
+		a.Input = input
+	}
+}
+
+//WithHeader 设置任务的请求头
+func WithHeader(kv ...string) Option {
+	return func(a *Task) {
+		if a.Header == nil {
+			a.Header = make(map[string]string)
+		}
+		for i := 0; i+1 < len(kv); i += 2 {
+			a.Header[kv[i]] = kv[i+1]
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/registry"
+)
+
+//DynamicNodeName 动态任务节点名，与conf节点同级，任务变化不会引起服务器重启
+const DynamicNodeName = "tasks"
+
+//GetDynamicPath 获取集群动态任务的存储路径
+func GetDynamicPath(c conf.IServerPub) string {
+	return registry.Join(c.GetServerRoot(), c.GetClusterName(), DynamicNodeName)
+}
+
+//Store 动态任务存储，任务保存在注册中心，集群中的所有节点共享
+type Store struct {
+	r    registry.IRegistry
+	path string
+}
+
+//NewStore 构建动态任务存储
+func NewStore(r registry.IRegistry, c conf.IServerPub) *Store {
+	return &Store{r: r, path: GetDynamicPath(c)}
+}
+
+//GetPath 获取动态任务的存储路径
+func (s *Store) GetPath() string {
+	return s.path
+}
+
+//Save 保存任务，已存在时更新
+func (s *Store) Save(t *Task) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	buff, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	path := registry.Join(s.path, t.GetUNQ())
+	ok, err := s.r.Exists(path)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return s.r.Update(path, string(buff))
+	}
+	return s.r.CreatePersistentNode(path, string(buff))
+}
+
+//Get 获取任务
+func (s *Store) Get(name string) (*Task, error) {
+	buff, _, err := s.r.GetValue(registry.Join(s.path, name))
+	if err != nil {
+		return nil, err
+	}
+	t := &Task{}
+	if err := json.Unmarshal(buff, t); err != nil {
+		return nil, fmt.Errorf("任务%s格式有误:%w", name, err)
+	}
+	return t, nil
+}
+
+//List 获取所有任务，Disable为true的任务已暂停
+func (s *Store) List() (map[string]*Task, error) {
+	tasks := make(map[string]*Task)
+	ok, err := s.r.Exists(s.path)
+	if err != nil || !ok {
+		return tasks, err
+	}
+	names, _, err := s.r.GetChildren(s.path)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t, err := s.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		tasks[name] = t
+	}
+	return tasks, nil
+}
+
+//Pause 暂停任务
+func (s *Store) Pause(name string) error {
+	return s.setDisable(name, true)
+}
+
+//Resume 恢复任务
+func (s *Store) Resume(name string) error {
+	return s.setDisable(name, false)
+}
+
+//Delete 删除任务
+func (s *Store) Delete(name string) error {
+	path := registry.Join(s.path, name)
+	ok, err := s.r.Exists(path)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("任务%s不存在", name)
+	}
+	return s.r.Delete(path)
+}
+
+func (s *Store) setDisable(name string, disable bool) error {
+	path := registry.Join(s.path, name)
+	buff, version, err := s.r.GetValue(path)
+	if err != nil {
+		return fmt.Errorf("任务%s不存在:%w", name, err)
+	}
+	t := &Task{}
+	if err := json.Unmarshal(buff, t); err != nil {
+		return fmt.Errorf("任务%s格式有误:%w", name, err)
+	}
+	t.Disable = disable
+	nbuff, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return s.r.UpdateIfVersion(path, string(nbuff), version)
+}
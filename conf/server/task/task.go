@@ -1,6 +1,7 @@
 package task
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/asaskevich/govalidator"
@@ -18,6 +19,12 @@ type Task struct {
 	Cron    string `json:"cron,omitempty" valid:"ascii,required" toml:"cron,omitempty" label:"任务名称"`
 	Service string `json:"service,omitempty" valid:"ascii,spath,required" toml:"service,omitempty" label:"任务服务"`
 	Disable bool   `json:"disable,omitempty" toml:"disable,omitempty"`
+
+	//Input 任务执行时的输入参数，作为请求的form传入服务
+	Input map[string]interface{} `json:"input,omitempty" toml:"input,omitempty"`
+
+	//Header 任务执行时的请求头
+	Header map[string]string `json:"header,omitempty" toml:"header,omitempty"`
 }
 
 //NewTask 创建任务信息
@@ -33,9 +40,13 @@ func NewTask(cron string, service string, opts ...Option) *Task {
 	return t
 }
 
-//GetUNQ 获取任务的唯一标识
+//GetUNQ 获取任务的唯一标识，包含输入参数时相同服务与cron表达式的任务按输入参数区分
 func (t *Task) GetUNQ() string {
-	return md5.Encrypt(fmt.Sprintf("%s(%s)", t.Service, t.Cron))
+	if len(t.Input) == 0 {
+		return md5.Encrypt(fmt.Sprintf("%s(%s)", t.Service, t.Cron))
+	}
+	input, _ := json.Marshal(t.Input)
+	return md5.Encrypt(fmt.Sprintf("%s(%s)%s", t.Service, t.Cron, input))
 }
 
 //IsImmediately 是否立即
@@ -8,11 +8,27 @@ const TypeNodeName = "db"
 //DB 数据库配置
 type DB struct {
 	security.ConfEncrypt
-	Provider   string `json:"provider" valid:"required"`
+	Provider   string     `json:"provider" valid:"required"`
+	ConnString string     `json:"connString" valid:"required" label:"连接字符串"`
+	MaxOpen    int        `json:"maxOpen" valid:"required" label:"最大打开连接数"`
+	MaxIdle    int        `json:"maxIdle" valid:"required" label:"最大空闲连接数"`
+	LifeTime   int        `json:"lifeTime" valid:"required" label:"单个连接时长(秒)"`
+	Replicas   []*Replica `json:"replicas,omitempty" label:"只读副本"`
+	MaxLag     int        `json:"maxLag,omitempty" label:"副本最大延迟(秒)"`
+}
+
+//Replica 只读副本，查询按权重路由到健康的副本
+type Replica struct {
 	ConnString string `json:"connString" valid:"required" label:"连接字符串"`
-	MaxOpen    int    `json:"maxOpen" valid:"required" label:"最大打开连接数"`
-	MaxIdle    int    `json:"maxIdle" valid:"required" label:"最大空闲连接数"`
-	LifeTime   int    `json:"lifeTime" valid:"required" label:"单个连接时长(秒)"`
+	Weight     int    `json:"weight,omitempty" label:"权重"`
+}
+
+//GetWeight 获取副本权重，未设置时为1
+func (r *Replica) GetWeight() int {
+	if r.Weight <= 0 {
+		return 1
+	}
+	return r.Weight
 }
 
 //New 构建DB连接信息
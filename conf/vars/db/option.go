@@ -12,6 +12,20 @@ func WithConnect(maxOpen int, maxIdle int, lifeTime int) Option {
 	}
 }
 
+//WithReplica 添加只读副本，查询语句按权重路由到副本，写入与事务使用主库
+func WithReplica(connString string, weight int) Option {
+	return func(a *DB) {
+		a.Replicas = append(a.Replicas, &Replica{ConnString: connString, Weight: weight})
+	}
+}
+
+//WithMaxLag 设置副本的最大复制延迟(秒)，超过后暂停使用该副本，仅支持mysql与postgres
+func WithMaxLag(second int) Option {
+	return func(a *DB) {
+		a.MaxLag = second
+	}
+}
+
 //WithEnableEncryption 启用加密设置
 func WithEnableEncryption() Option {
 	return func(a *DB) {
@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/micro-plat/hydra/conf/vars/db"
+)
+
+//Postgres postgres数据库连接信息
+type Postgres = db.DB
+
+//New 构建postgres连接信息
+func New(connString string, opts ...db.Option) *Postgres {
+	return db.New("postgres", connString, opts...)
+}
+
+//NewBy 构建postgres连接信息
+func NewBy(uName string, pwd string, serverIP string, dbName string, opts ...db.Option) *Postgres {
+	return New(fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", uName, pwd, serverIP, dbName), opts...)
+}
@@ -0,0 +1,20 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/micro-plat/hydra/conf/vars/db"
+)
+
+//SQLite sqlite数据库连接信息
+type SQLite = db.DB
+
+//New 构建sqlite连接信息
+func New(connString string, opts ...db.Option) *SQLite {
+	return db.New("sqlite", connString, opts...)
+}
+
+//NewBy 根据数据库文件路径构建sqlite连接信息
+func NewBy(path string, opts ...db.Option) *SQLite {
+	return New(fmt.Sprintf("file:%s?_busy_timeout=5000", path), opts...)
+}
@@ -0,0 +1,37 @@
+package amqp
+
+import "encoding/json"
+
+//Option 配置选项
+type Option func(*AMQP)
+
+//WithExchange 设置交换机，队列以队列名为路由键绑定到交换机
+func WithExchange(name string, kind string) Option {
+	return func(a *AMQP) {
+		a.Exchange = name
+		a.ExchangeType = kind
+	}
+}
+
+//WithMaxRetry 设置消息处理失败后的最大重新投递次数，超过后转入死信队列
+func WithMaxRetry(n int) Option {
+	return func(a *AMQP) {
+		a.MaxRetry = n
+	}
+}
+
+//WithRaw 通过json原串初始化
+func WithRaw(raw string) Option {
+	return func(o *AMQP) {
+		if err := json.Unmarshal([]byte(raw), o); err != nil {
+			panic(err)
+		}
+	}
+}
+
+//WithEnableEncryption 启用加密设置
+func WithEnableEncryption() Option {
+	return func(a *AMQP) {
+		a.EnableEncryption = true
+	}
+}
@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+
+	"github.com/micro-plat/hydra/conf/vars/queue"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//Proto kafka
+const Proto = "kafka"
+
+//Kafka kafka消息队列配置
+type Kafka struct {
+	*queue.Queue
+	Brokers     []string `json:"brokers"  toml:"brokers" valid:"required" label:"集群地址"`
+	GroupID     string   `json:"group_id,omitempty"  toml:"group_id,omitempty"`
+	StartOffset string   `json:"start_offset,omitempty"  toml:"start_offset,omitempty" valid:"in(first|last)"`
+	DialTimeout int      `json:"dial_timeout,omitempty" toml:"dial_timeout,omitempty"`
+	UserName    string   `json:"userName,omitempty"  toml:"userName,omitempty" `
+	Password    string   `json:"password,omitempty"  toml:"password,omitempty" `
+}
+
+//New 构建kafka消息队列配置
+func New(brokers string, opts ...Option) *Kafka {
+	r := &Kafka{
+		Queue:   &queue.Queue{Proto: Proto},
+		Brokers: types.Split(brokers, ","),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+//NewByRaw 通过json原串初始化
+func NewByRaw(raw string) *Kafka {
+	org := New("", WithRaw(raw))
+	if b, err := govalidator.ValidateStruct(org); !b {
+		panic(fmt.Errorf("kafka配置数据有误:%v %+v", err, org))
+	}
+	return org
+}
+
+//GetGroupID 获取消费者组，未配置时使用平台名与系统名
+func (k *Kafka) GetGroupID(platName string, sysName string) string {
+	if k.GroupID != "" {
+		return k.GroupID
+	}
+	return strings.Trim(fmt.Sprintf("%s_%s", platName, sysName), "_")
+}
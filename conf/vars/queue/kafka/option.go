@@ -0,0 +1,58 @@
+package kafka
+
+import "encoding/json"
+
+//Option 配置选项
+type Option func(*Kafka)
+
+//WithGroupID 设置消费者组
+func WithGroupID(groupID string) Option {
+	return func(a *Kafka) {
+		a.GroupID = groupID
+	}
+}
+
+//WithStartOffset 设置消费者组首次消费的位置:first从最早的消息开始,last从最新的消息开始
+func WithStartOffset(offset string) Option {
+	return func(a *Kafka) {
+		a.StartOffset = offset
+	}
+}
+
+//WithUP 设置SASL/PLAIN用户名密码
+func WithUP(userName string, password string) Option {
+	return func(a *Kafka) {
+		a.UserName = userName
+		a.Password = password
+	}
+}
+
+//WithDialTimeout 设置连接超时(秒)
+func WithDialTimeout(timeout int) Option {
+	return func(a *Kafka) {
+		a.DialTimeout = timeout
+	}
+}
+
+//WithMaxRetry 设置消息处理失败后的最大重新投递次数，超过后转入死信队列
+func WithMaxRetry(n int) Option {
+	return func(a *Kafka) {
+		a.MaxRetry = n
+	}
+}
+
+//WithRaw 通过json原串初始化
+func WithRaw(raw string) Option {
+	return func(o *Kafka) {
+		if err := json.Unmarshal([]byte(raw), o); err != nil {
+			panic(err)
+		}
+	}
+}
+
+//WithEnableEncryption 启用加密设置
+func WithEnableEncryption() Option {
+	return func(a *Kafka) {
+		a.EnableEncryption = true
+	}
+}
@@ -0,0 +1,52 @@
+package nats
+
+import (
+	"fmt"
+
+	"github.com/asaskevich/govalidator"
+
+	"github.com/micro-plat/hydra/conf/vars/queue"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//Proto nats
+const Proto = "nats"
+
+//NATS nats JetStream消息队列配置
+type NATS struct {
+	*queue.Queue
+	Servers  []string `json:"servers"  toml:"servers" valid:"required" label:"服务器地址"`
+	UserName string   `json:"userName,omitempty"  toml:"userName,omitempty" `
+	Password string   `json:"password,omitempty"  toml:"password,omitempty" `
+	Token    string   `json:"token,omitempty"  toml:"token,omitempty" `
+	Replicas int      `json:"replicas,omitempty"  toml:"replicas,omitempty"`
+}
+
+//New 构建nats配置,servers为逗号分隔的服务器地址
+func New(servers string, opts ...Option) *NATS {
+	r := &NATS{
+		Servers: types.Split(servers, ","),
+		Queue:   &queue.Queue{Proto: Proto},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+//NewByRaw 通过json原串初始化
+func NewByRaw(raw string) *NATS {
+	org := New("", WithRaw(raw))
+	if b, err := govalidator.ValidateStruct(org); !b {
+		panic(fmt.Errorf("nats配置数据有误:%v %+v", err, org))
+	}
+	return org
+}
+
+//GetReplicas 获取流的副本数，默认为1
+func (n *NATS) GetReplicas() int {
+	if n.Replicas <= 0 {
+		return 1
+	}
+	return n.Replicas
+}
@@ -0,0 +1,51 @@
+package nats
+
+import "encoding/json"
+
+//Option 配置选项
+type Option func(*NATS)
+
+//WithUP 设置用户名密码
+func WithUP(userName string, password string) Option {
+	return func(a *NATS) {
+		a.UserName = userName
+		a.Password = password
+	}
+}
+
+//WithToken 设置认证令牌
+func WithToken(token string) Option {
+	return func(a *NATS) {
+		a.Token = token
+	}
+}
+
+//WithReplicas 设置自动创建的流的副本数
+func WithReplicas(replicas int) Option {
+	return func(a *NATS) {
+		a.Replicas = replicas
+	}
+}
+
+//WithMaxRetry 设置消息处理失败后的最大重新投递次数，超过后转入死信队列
+func WithMaxRetry(n int) Option {
+	return func(a *NATS) {
+		a.MaxRetry = n
+	}
+}
+
+//WithRaw 通过json原串初始化
+func WithRaw(raw string) Option {
+	return func(o *NATS) {
+		if err := json.Unmarshal([]byte(raw), o); err != nil {
+			panic(err)
+		}
+	}
+}
+
+//WithEnableEncryption 启用加密设置
+func WithEnableEncryption() Option {
+	return func(a *NATS) {
+		a.EnableEncryption = true
+	}
+}
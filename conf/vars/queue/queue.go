@@ -5,9 +5,22 @@ import "github.com/micro-plat/hydra/conf/pkgs/security"
 //TypeNodeName 分类节点名
 const TypeNodeName = "queue"
 
+//DefaultMaxRetry 默认最大重新投递次数
+const DefaultMaxRetry = 5
+
 //Queue 消息队列配置
 type Queue struct {
 	security.ConfEncrypt
-	Proto string `json:"proto,omitempty"`
-	Raw   []byte `json:"-"`
+	Proto    string `json:"proto,omitempty"`
+	MaxRetry int    `json:"max_retry,omitempty" toml:"max_retry,omitempty" label:"最大重新投递次数"`
+	Raw      []byte `json:"-"`
+}
+
+//GetMaxRetry 获取消息处理失败后的最大重新投递次数，超过后转入死信队列。
+//仅kafka、amqp、nats支持，未配置时为DefaultMaxRetry
+func (q *Queue) GetMaxRetry() int {
+	if q == nil || q.MaxRetry <= 0 {
+		return DefaultMaxRetry
+	}
+	return q.MaxRetry
 }
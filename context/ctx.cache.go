@@ -9,14 +9,22 @@ import (
 // var ctxMap sync.Map
 var ctxMap = cmap.New(6)
 
+//GoroutineCache 是否按goroutine编号缓存请求上下文，用于兼容Current、GetContext，
+//设置为false时减少每个请求的锁开销，需通过IContext.Context()与GetSnapshot获取请求信息
+var GoroutineCache = true
+
 //Cache 将当前上下文配置保存到当前线程编号对应的缓存
+//
+//Deprecated: 请求上下文快照已存入IContext.Context()，使用GetSnapshot获取
 func Cache(s IContext) string {
 	tid := global.RID.GetXRequestID()
 	ctxMap.SetIfAbsent(tid, s)
 	return tid
 }
 
-//Current 从缓存中获取请求上下文配置
+//Current 从缓存中获取请求上下文配置，处理程序启动的goroutine中无法获取
+//
+//Deprecated: 使用GetSnapshot(ctx.Context())获取请求信息
 func Current(g ...string) IContext {
 	if v, ok := GetContext(g...); ok {
 		return v
@@ -24,7 +32,9 @@ func Current(g ...string) IContext {
 	panic("未获取到当前线程的请求上下文")
 }
 
-//GetContext 获取当前context
+//GetContext 获取当前context，处理程序启动的goroutine中无法获取
+//
+//Deprecated: 使用GetSnapshot(ctx.Context())获取请求信息
 func GetContext(g ...string) (IContext, bool) {
 	gid := types.GetStringByIndex(g, 0)
 	if gid == "" {
@@ -0,0 +1,96 @@
+package context
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/types"
+)
+
+type snapshotKey struct{}
+
+//Snapshot 请求上下文快照，请求上下文对象会被回收重用，处理程序启动的goroutine通过快照获取请求信息
+type Snapshot struct {
+	//TraceID 链路跟踪编号
+	TraceID string
+
+	//LogName 日志名称
+	LogName string
+
+	//Deadline 请求超时时间
+	Deadline time.Time
+}
+
+//Log 获取与请求使用同一链路跟踪编号的日志组件
+func (s Snapshot) Log() logger.ILogger {
+	return logger.GetSession(s.LogName, s.TraceID)
+}
+
+//WithSnapshot 将请求上下文快照存入context.Context
+func WithSnapshot(parent context.Context, s Snapshot) context.Context {
+	return context.WithValue(parent, snapshotKey{}, s)
+}
+
+//GetSnapshot 从context.Context中获取请求上下文快照
+func GetSnapshot(ctx context.Context) (Snapshot, bool) {
+	if ctx == nil {
+		return Snapshot{}, false
+	}
+	s, ok := ctx.Value(snapshotKey{}).(Snapshot)
+	return s, ok
+}
+
+//Detach 返回保留ctx中的值但不随ctx撤销或超时的context，用于处理程序返回后仍需继续执行的goroutine
+func Detach(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return detached{parent: ctx}
+}
+
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
+
+func (d detached) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
+
+//GetTraceID 获取链路跟踪编号，依次从请求上下文快照、X-Request-Id值及当前goroutine缓存的请求上下文中获取
+func GetTraceID(ctx context.Context) string {
+	if s, ok := GetSnapshot(ctx); ok {
+		return s.TraceID
+	}
+	if ctx != nil {
+		if id := types.GetString(ctx.Value(XRequestID)); id != "" {
+			return id
+		}
+	}
+	if c, ok := GetContext(); ok {
+		return c.User().GetTraceID()
+	}
+	return ""
+}
+
+//Bind 将请求上下文绑定到当前goroutine，用于tengo脚本等无法传递context.Context的调用，返回解除绑定的函数
+func Bind(c IContext) func() {
+	if GoroutineCache {
+		return func() {}
+	}
+	ctxMap.Set(global.RID.GetXRequestID(), c)
+	return Del
+}
@@ -52,12 +52,19 @@ func NewCtx(c context.IInnerContext, tp string) *Ctx {
 		panic(err)
 	}
 	ctx.user = NewUser(c, ctx.meta)
-	context.Cache(ctx)
+	if context.GoroutineCache {
+		context.Cache(ctx)
+	}
 	ctx.request = NewRequest(c, ctx.appConf, ctx.meta)
 	ctx.log = logger.GetSession(ctx.appConf.GetServerConf().GetServerName(), ctx.User().GetTraceID())
 	ctx.response = NewResponse(c, ctx.appConf, ctx.log, ctx.meta)
 	timeout := time.Duration(ctx.appConf.GetServerConf().GetMainConf().GetInt("", 30))
-	ctx.ctx, ctx.cancelFunc = r.WithTimeout(r.WithValue(r.Background(), "X-Request-Id", ctx.user.GetTraceID()), time.Second*timeout)
+	snapshot := context.Snapshot{
+		TraceID:  ctx.user.GetTraceID(),
+		LogName:  ctx.appConf.GetServerConf().GetServerName(),
+		Deadline: time.Now().Add(time.Second * timeout),
+	}
+	ctx.ctx, ctx.cancelFunc = r.WithDeadline(context.WithSnapshot(r.WithValue(r.Background(), "X-Request-Id", snapshot.TraceID), snapshot), snapshot.Deadline)
 	ctx.tracer = newTracer(c.GetURL().Path, ctx.log, ctx.appConf)
 	return ctx
 }
@@ -77,7 +84,8 @@ func (c *Ctx) Response() context.IResponse {
 	return c.response
 }
 
-//Context 处理程序退出，超时等
+//Context 处理程序退出，超时等，包含请求上下文快照，可通过context.GetSnapshot获取，
+//处理程序返回后撤销，启动的goroutine需继续执行时使用context.Detach
 func (c *Ctx) Context() r.Context {
 	return c.ctx
 }
@@ -125,7 +133,9 @@ func (c *Ctx) Invoke(service string) *pkgs.Rspns {
 
 //Close 关闭并释放所有资源
 func (c *Ctx) Close() {
-	context.Del() //从当前请求上下文中删除
+	if context.GoroutineCache {
+		context.Del() //从当前请求上下文中删除
+	}
 	c.appConf = nil
 	c.cancelFunc()
 	c.cancelFunc = nil
@@ -10,6 +10,16 @@ import (
 
 //GetTGOModules 获取tgo的模块配置信息
 func GetTGOModules() []*tgo.Module {
+	named := getNamedTGOModules()
+	modules := make([]*tgo.Module, 0, len(named))
+	for _, m := range named {
+		modules = append(modules, m.Module)
+	}
+	return modules
+}
+
+//getNamedTGOModules 获取带名称的tgo模块，请求钩子脚本按名称注册模块
+func getNamedTGOModules() []global.TGOModule {
 	request := tgo.NewModule("request").
 		Add("getString", tgo.FuncASRS(func(input string) string { ctx := Current(); return ctx.Request().GetString(input) })).
 		Add("getPath", tgo.FuncARS(func() string { ctx := Current(); return ctx.Request().Path().GetRequestPath() })).
@@ -44,11 +54,19 @@ func GetTGOModules() []*tgo.Module {
 	users := tgo.NewModule("user").
 		Add("getUserInfo", internal.IASANY(getUserInfo))
 
-	return []*tgo.Module{request, response, app, types, users}
+	return []global.TGOModule{
+		{Name: "request", Module: request},
+		{Name: "response", Module: response},
+		{Name: "app", Module: app},
+		{Name: "types", Module: types},
+		{Name: "user", Module: users},
+	}
 }
 
 func init() {
-	global.AddTGOModules(GetTGOModules()...)
+	for _, m := range getNamedTGOModules() {
+		global.AddTGOModule(m.Name, m.Module)
+	}
 }
 func getClusterNameBy(n string) string {
 	ctx := Current()
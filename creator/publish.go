@@ -2,7 +2,6 @@ package creator
 
 import (
 	"encoding/json"
-	"fmt"
 	"reflect"
 
 	"github.com/micro-plat/hydra/conf/pkgs/security"
@@ -35,7 +34,7 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 		return err
 	}
 	confs := make(map[string]interface{})
-	cache := types.XMap{}
+	txn := newPubTxn(r, types.XMap{})
 	//加入server配置
 	for tp, subs := range c.data {
 		pub := server.NewServerPub(platName, systemName, tp, clusterName)
@@ -45,7 +44,7 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 			return err
 		}
 		//先发布main节点配置
-		if err := publish(r, path, value, cache); err != nil {
+		if err := txn.add(path, value); err != nil {
 			return err
 		}
 		confs[path] = value
@@ -60,7 +59,7 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 			if err != nil {
 				return err
 			}
-			if err := publish(r, path, value, cache); err != nil {
+			if err := txn.add(path, value); err != nil {
 				return err
 			}
 			confs[path] = value
@@ -77,7 +76,7 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 				return err
 			}
 			confs[path] = value
-			if err := publish(r, path, value, cache); err != nil {
+			if err := txn.add(path, value); err != nil {
 				return err
 			}
 		}
@@ -86,44 +85,22 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 	//加入项目未配置的导入配置项
 	for k, v := range input {
 		if _, ok := confs[k]; !ok {
-			if err := publish(r, k, v, cache); err != nil {
+			if err := txn.add(k, v); err != nil {
 				return err
 			}
 		}
 	}
 
-	return nil
+	//所有配置在同一事务中发布
+	return txn.commit()
 }
 
 func publish(r registry.IRegistry, path string, v interface{}, input types.XMap) error {
-
-	delete := false
-	if b, _ := r.Exists(path); b {
-		buff, _, err := r.GetValue(path)
-		if err != nil {
-			return err
-		}
-		if !checkCover(path, string(buff), v) { //不覆盖配置则退出
-			return nil
-		}
-		delete = true
-	}
-
-	value, err := getJSON(path, v, input) //获取节点值
-	if err != nil {
+	txn := newPubTxn(r, input)
+	if err := txn.add(path, v); err != nil {
 		return err
 	}
-
-	if delete { //覆盖值之前先删除节点
-		if err := deleteAll(r, path); err != nil {
-			return err
-		}
-	}
-
-	if err := r.CreatePersistentNode(path, value); err != nil {
-		return fmt.Errorf("创建配置节点%s %s出错:%w", path, value, err)
-	}
-	return nil
+	return txn.commit()
 }
 
 func deleteAll(r registry.IRegistry, path string) error {
@@ -184,7 +161,7 @@ func getJSON(path string, v interface{}, input types.XMap) (value string, err er
 
 	switch en := v.(type) {
 	case security.IEncrypt:
-		return en.Encrypt(buff), nil
+		return en.Encrypt(buff)
 	default:
 		return string(buff), nil
 	}
@@ -0,0 +1,114 @@
+package creator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//pubTxn 收集待发布的配置节点，最后在同一个事务中提交，避免配置只发布一部分
+type pubTxn struct {
+	r        registry.IRegistry
+	input    types.XMap
+	paths    []string
+	values   map[string]string
+	versions map[string]int32
+	covered  []string
+}
+
+func newPubTxn(r registry.IRegistry, input types.XMap) *pubTxn {
+	return &pubTxn{
+		r:        r,
+		input:    input,
+		paths:    make([]string, 0, 8),
+		values:   make(map[string]string),
+		versions: make(map[string]int32),
+		covered:  make([]string, 0, 1),
+	}
+}
+
+//add 添加待发布的节点，节点已存在时确认是否覆盖，上级节点已被覆盖时直接覆盖
+func (p *pubTxn) add(path string, v interface{}) error {
+	exists, _ := p.r.Exists(path)
+	version := registry.AnyVersion
+	if exists {
+		buff, ver, err := p.r.GetValue(path)
+		if err != nil {
+			return err
+		}
+		if !p.isCovered(path) && !checkCover(path, string(buff), v) { //不覆盖配置则退出
+			return nil
+		}
+		version = ver
+	}
+
+	value, err := getJSON(path, v, p.input) //获取节点值
+	if err != nil {
+		return err
+	}
+	p.paths = append(p.paths, path)
+	p.values[path] = value
+	if exists {
+		p.versions[path] = version
+		p.covered = append(p.covered, path)
+	}
+	return nil
+}
+
+//commit 删除被覆盖节点下未重新发布的子节点，并创建或更新所有待发布的节点
+func (p *pubTxn) commit() error {
+	ops := make([]registry.Op, 0, len(p.paths))
+	deleting := make(map[string]bool)
+	for _, path := range p.covered {
+		list, err := getAllPath(p.r, path)
+		if err != nil {
+			return err
+		}
+		for _, v := range list {
+			if deleting[v] || p.isPublished(v) {
+				continue
+			}
+			deleting[v] = true
+			ops = append(ops, registry.DeleteOp(v, registry.AnyVersion))
+		}
+	}
+	for _, path := range p.paths {
+		if version, ok := p.versions[path]; ok {
+			ops = append(ops, registry.UpdateOp(path, p.values[path], version))
+			continue
+		}
+		ops = append(ops, registry.CreateOp(path, p.values[path]))
+	}
+
+	err := p.r.Txn(ops...)
+	if errors.Is(err, registry.ErrVersionConflict) {
+		return fmt.Errorf("配置已被其它程序修改,请重新发布:%w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("发布配置节点出错:%w", err)
+	}
+	return nil
+}
+
+//isCovered 上级节点是否已被覆盖
+func (p *pubTxn) isCovered(path string) bool {
+	for _, c := range p.covered {
+		if strings.HasPrefix(path, c+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+//isPublished 节点或其子节点是否待发布
+func (p *pubTxn) isPublished(path string) bool {
+	for _, v := range p.paths {
+		if v == path || strings.HasPrefix(v, path+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package creator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/types"
+)
+
+func TestPubTxn(t *testing.T) {
+	defer func() { coverAll, ignoreAll = false, false }()
+	r := localmemory.NewLocalMemory()
+
+	txn := newPubTxn(r, types.XMap{})
+	assert.Equal(t, nil, txn.add("/hydra/api/conf", map[string]interface{}{"address": ":8080"}))
+	assert.Equal(t, nil, txn.add("/hydra/api/conf/router", map[string]interface{}{"path": "/a"}))
+	assert.Equal(t, nil, txn.add("/hydra/api/conf/old", map[string]interface{}{"path": "/b"}))
+	assert.Equal(t, nil, txn.commit(), "1. 发布新节点并创建上级节点")
+	v, _, _ := r.GetValue("/hydra/api/conf/router")
+	assert.Equal(t, `{"path":"/a"}`, string(v), "1. 发布新节点并创建上级节点")
+
+	coverAll, ignoreAll = false, true
+	txn = newPubTxn(r, types.XMap{})
+	assert.Equal(t, nil, txn.add("/hydra/api/conf", map[string]interface{}{"address": ":9090"}))
+	assert.Equal(t, nil, txn.commit(), "2. 不覆盖已存在的节点")
+	v, _, _ = r.GetValue("/hydra/api/conf")
+	assert.Equal(t, `{"address":":8080"}`, string(v), "2. 不覆盖已存在的节点")
+
+	coverAll, ignoreAll = true, false
+	txn = newPubTxn(r, types.XMap{})
+	assert.Equal(t, nil, txn.add("/hydra/api/conf", map[string]interface{}{"address": ":9090"}))
+	assert.Equal(t, nil, txn.add("/hydra/api/conf/router", map[string]interface{}{"path": "/c"}))
+	assert.Equal(t, nil, txn.commit(), "3. 覆盖已存在的节点")
+	v, _, _ = r.GetValue("/hydra/api/conf/router")
+	assert.Equal(t, `{"path":"/c"}`, string(v), "3. 覆盖已存在的节点")
+	ok, _ := r.Exists("/hydra/api/conf/old")
+	assert.Equal(t, false, ok, "4. 删除被覆盖节点下未重新发布的子节点")
+
+	txn = newPubTxn(r, types.XMap{})
+	assert.Equal(t, nil, txn.add("/hydra/api/conf", map[string]interface{}{"address": ":7070"}))
+	assert.Equal(t, nil, txn.add("/hydra/api/conf/acl", map[string]interface{}{"white": true}))
+	assert.Equal(t, nil, r.Update("/hydra/api/conf", `{"address":":6060"}`))
+	err := txn.commit()
+	assert.Equal(t, true, errors.Is(err, registry.ErrVersionConflict), "5. 发布期间配置被其它程序修改")
+	v, _, _ = r.GetValue("/hydra/api/conf")
+	assert.Equal(t, `{"address":":6060"}`, string(v), "5. 发布期间配置被其它程序修改")
+	ok, _ = r.Exists("/hydra/api/conf/acl")
+	assert.Equal(t, false, ok, "5. 发布期间配置被其它程序修改")
+}
@@ -12,10 +12,13 @@ import (
 	"github.com/micro-plat/hydra/conf/server/auth/basic"
 	"github.com/micro-plat/hydra/conf/server/auth/jwt"
 	"github.com/micro-plat/hydra/conf/server/auth/ras"
+	"github.com/micro-plat/hydra/conf/server/cache"
 	"github.com/micro-plat/hydra/conf/server/header"
+	"github.com/micro-plat/hydra/conf/server/idempotency"
 	"github.com/micro-plat/hydra/conf/server/nfs"
 	"github.com/micro-plat/hydra/conf/server/processor"
 	"github.com/micro-plat/hydra/conf/server/render"
+	"github.com/micro-plat/hydra/conf/server/script"
 	"github.com/micro-plat/hydra/conf/server/static"
 )
 
@@ -96,6 +99,24 @@ func (b *httpBuilder) Limit(opts ...limiter.Option) *httpBuilder {
 	return b
 }
 
+//Cache 响应缓存配置
+func (b *httpBuilder) Cache(opts ...cache.Option) *httpBuilder {
+	b.BaseBuilder[cache.TypeNodeName] = cache.New(opts...)
+	return b
+}
+
+//Idempotency 幂等配置
+func (b *httpBuilder) Idempotency(opts ...idempotency.Option) *httpBuilder {
+	b.BaseBuilder[idempotency.TypeNodeName] = idempotency.New(opts...)
+	return b
+}
+
+//Script 请求钩子脚本配置，按请求路径在业务处理前后执行tengo脚本
+func (b *httpBuilder) Script(opts ...script.Option) *httpBuilder {
+	b.BaseBuilder[script.TypeNodeName] = script.New(opts...)
+	return b
+}
+
 //Proxy 代理配置
 func (b *httpBuilder) Proxy(script string) *httpBuilder {
 	path := fmt.Sprintf("%s/%s", proxy.ParNodeName, proxy.SubNodeName)
@@ -103,6 +124,13 @@ func (b *httpBuilder) Proxy(script string) *httpBuilder {
 	return b
 }
 
+//Canary 灰度配置,按灰度规则选择上游集群,未匹配规则时执行脚本
+func (b *httpBuilder) Canary(opts ...proxy.Option) *httpBuilder {
+	path := fmt.Sprintf("%s/%s", proxy.ParNodeName, proxy.SubNodeName)
+	b.BaseBuilder[path] = proxy.New(opts...)
+	return b
+}
+
 //Render 响应渲染配置
 func (b *httpBuilder) Render(script string) *httpBuilder {
 	b.BaseBuilder[render.TypeNodeName] = script
@@ -2,8 +2,11 @@ package creator
 
 import (
 	"github.com/micro-plat/hydra/conf/vars/queue"
+	queueamqp "github.com/micro-plat/hydra/conf/vars/queue/amqp"
+	queuekafka "github.com/micro-plat/hydra/conf/vars/queue/kafka"
 	queuelmq "github.com/micro-plat/hydra/conf/vars/queue/lmq"
 	queuemqtt "github.com/micro-plat/hydra/conf/vars/queue/mqtt"
+	queuenats "github.com/micro-plat/hydra/conf/vars/queue/nats"
 	"github.com/micro-plat/hydra/conf/vars/queue/queueredis"
 )
 
@@ -29,6 +32,21 @@ func (c *Varqueue) MQTT(nodeName string, address string, opts ...queuemqtt.Optio
 	return c.Custom(nodeName, queuemqtt.New(address, opts...))
 }
 
+//Kafka 添加Kafka,brokers为逗号分隔的服务器地址
+func (c *Varqueue) Kafka(nodeName string, brokers string, opts ...queuekafka.Option) vars {
+	return c.Custom(nodeName, queuekafka.New(brokers, opts...))
+}
+
+//AMQP 添加RabbitMQ等AMQP 0-9-1协议的消息队列
+func (c *Varqueue) AMQP(nodeName string, address string, opts ...queueamqp.Option) vars {
+	return c.Custom(nodeName, queueamqp.New(address, opts...))
+}
+
+//NATS 添加NATS JetStream,servers为逗号分隔的服务器地址
+func (c *Varqueue) NATS(nodeName string, servers string, opts ...queuenats.Option) vars {
+	return c.Custom(nodeName, queuenats.New(servers, opts...))
+}
+
 //LMQ 添加本地内存作为消息队列
 func (c *Varqueue) LMQ(nodeName string) vars {
 	return c.Custom(nodeName, queuelmq.New())
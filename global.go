@@ -100,7 +100,9 @@ func Server(tp string) app.IAPPConf {
 	panic(fmt.Errorf("[%s]服务器未启动:%w", tp, err))
 }
 
-//CurrentContext 获取当前请求上下文
+//CurrentContext 获取当前请求上下文，处理程序启动的goroutine中无法获取
+//
+//Deprecated: 使用context.GetSnapshot(ctx.Context())获取请求信息
 func CurrentContext() context.IContext {
 	return context.Current()
 }
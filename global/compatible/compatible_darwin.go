@@ -1,44 +1,49 @@
-// Package compatible darwin (mac os x) version
-package compatible
-
-import (
-	"errors"
-	"os"
-	"os/exec"
-	"strconv"
-	"strings"
-	"syscall"
-)
-
-var errUnsupportedSystem = errors.New("Unsupported system")
-var errRootPrivileges = errors.New("You must have root user privileges. Possibly using 'sudo' command should help")
-
-//CheckPrivileges 检查是否有管理员权限
-func CheckPrivileges() error {
-	if output, err := exec.Command("id", "-g").Output(); err == nil {
-		if gid, parseErr := strconv.ParseUint(strings.TrimSpace(string(output)), 10, 32); parseErr == nil {
-			if gid == 0 {
-				return nil
-			}
-			return errRootPrivileges
-		}
-	}
-	return errUnsupportedSystem
-}
-
-//CmdsRunNotifySignals  hydra/cmds/run/notify.Signal
-var CmdsRunNotifySignals = []os.Signal{os.Interrupt, os.Kill, syscall.SIGTERM, syscall.SIGUSR2}
-
-//CmdsUpdateProcessSignal CmdsUpdateProcessSignal
-var CmdsUpdateProcessSignal = syscall.SIGUSR2
-
-//AppClose AppClose
-func AppClose() {
-	parent := syscall.Getpid()
-	syscall.Kill(parent, syscall.SIGTERM)
-}
-
-const (
-	SUCCESS = "\033[32m\t\t\t\t\t[OK]\033[0m"     // Show colored "OK"
-	FAILED  = "\033[31m\t\t\t\t\t[FAILED]\033[0m" // Show colored "FAILED"
-)
+// Package compatible darwin (mac os x) version
+package compatible
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var errUnsupportedSystem = errors.New("Unsupported system")
+var errRootPrivileges = errors.New("You must have root user privileges. Possibly using 'sudo' command should help")
+
+//CheckPrivileges 检查是否有管理员权限
+func CheckPrivileges() error {
+	if output, err := exec.Command("id", "-g").Output(); err == nil {
+		if gid, parseErr := strconv.ParseUint(strings.TrimSpace(string(output)), 10, 32); parseErr == nil {
+			if gid == 0 {
+				return nil
+			}
+			return errRootPrivileges
+		}
+	}
+	return errUnsupportedSystem
+}
+
+//CmdsRunNotifySignals  hydra/cmds/run/notify.Signal
+var CmdsRunNotifySignals = []os.Signal{os.Interrupt, os.Kill, syscall.SIGTERM, syscall.SIGUSR2}
+
+//CmdsUpdateProcessSignal CmdsUpdateProcessSignal
+var CmdsUpdateProcessSignal = syscall.SIGUSR2
+
+//AppClose AppClose
+func AppClose() {
+	parent := syscall.Getpid()
+	syscall.Kill(parent, syscall.SIGTERM)
+}
+
+//AppExec 使用指定的可执行文件替换当前进程，进程号不变，成功时不返回
+func AppExec(path string, args []string) error {
+	return syscall.Exec(path, args, os.Environ())
+}
+
+const (
+	SUCCESS = "\033[32m\t\t\t\t\t[OK]\033[0m"     // Show colored "OK"
+	FAILED  = "\033[31m\t\t\t\t\t[FAILED]\033[0m" // Show colored "FAILED"
+)
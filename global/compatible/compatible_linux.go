@@ -38,6 +38,11 @@ func AppClose() {
 	syscall.Kill(parent, syscall.SIGTERM)
 }
 
+//AppExec 使用指定的可执行文件替换当前进程，进程号不变，成功时不返回
+func AppExec(path string, args []string) error {
+	return syscall.Exec(path, args, os.Environ())
+}
+
 const (
 	SUCCESS = "\033[32m\t\t\t\t\t[OK]\033[0m"     // Show colored "OK"
 	FAILED  = "\033[31m\t\t\t\t\t[FAILED]\033[0m" // Show colored "FAILED"
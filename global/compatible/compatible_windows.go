@@ -4,6 +4,7 @@ package compatible
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"syscall"
 )
 
@@ -41,6 +42,13 @@ func AppClose() {
 	}
 }
 
+//AppExec 启动指定的可执行文件作为新进程，windows不支持替换当前进程，调用方需随后退出
+func AppExec(path string, args []string) error {
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Start()
+}
+
 const (
 	SUCCESS = "\t\t\t\t\t[OK]"     // Show colored "OK"
 	FAILED  = "\t\t\t\t\t[FAILED]" // Show colored "FAILED"
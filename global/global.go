@@ -61,6 +61,9 @@ type global struct {
 	//TracePort 性能跟踪端口，当Trace为web时候可用，指定pprof的端口
 	TracePort string
 
+	//AdminPort 管理服务端口，未设置时不启用管理服务
+	AdminPort string
+
 	//IPMask 设置获取本地IP的掩码
 	IPMask string
 
@@ -161,6 +164,11 @@ func (m *global) GetTracePort() string {
 	return m.TracePort
 }
 
+//GetAdminPort 获取管理服务端口
+func (m *global) GetAdminPort() string {
+	return m.AdminPort
+}
+
 //ClosingNotify 获取系统关闭通知
 func (m *global) ClosingNotify() chan struct{} {
 	return m.close
@@ -61,6 +61,9 @@ type IGlobal interface {
 	//GetTracePort 当Trace为web时候，需要设置TracePort
 	GetTracePort() string
 
+	//GetAdminPort 管理服务端口，为空时不启用管理服务
+	GetAdminPort() string
+
 	//Log 获取日志组件
 	Log() logger.ILogger
 
@@ -4,14 +4,44 @@ import (
 	"github.com/micro-plat/lib4go/tgo"
 )
 
+//TGOModule 带名称的TGO模块，tgo.Module未提供获取模块名称的方法，由注册方提供
+type TGOModule struct {
+	Name   string
+	Module *tgo.Module
+}
+
 var modules []*tgo.Module = make([]*tgo.Module, 0, 10)
+var namedModules []TGOModule = make([]TGOModule, 0, 10)
 
 //AddTGOModules 添加TGO模块
 func AddTGOModules(m ...*tgo.Module) {
 	modules = append(modules, m...)
 }
 
+//AddTGOModule 添加带名称的TGO模块，可同时用于tgo.New与请求钩子脚本
+func AddTGOModule(name string, m *tgo.Module) {
+	modules = append(modules, m)
+	namedModules = append(namedModules, TGOModule{Name: name, Module: m})
+}
+
 //GetTGOModules 获取TGO模块
 func GetTGOModules() []*tgo.Module {
 	return modules
 }
+
+//GetNamedTGOModules 获取通过AddTGOModule添加的带名称的TGO模块
+func GetNamedTGOModules() []TGOModule {
+	return namedModules
+}
+
+var hookModules []TGOModule = make([]TGOModule, 0, 2)
+
+//AddHookTGOModule 添加仅供请求钩子脚本使用的TGO模块，如调用缓存、消息队列等组件
+func AddHookTGOModule(name string, m *tgo.Module) {
+	hookModules = append(hookModules, TGOModule{Name: name, Module: m})
+}
+
+//GetHookTGOModules 获取请求钩子脚本使用的TGO模块
+func GetHookTGOModules() []TGOModule {
+	return hookModules
+}
@@ -25,10 +25,16 @@ func init() {
 				},
 				{
 					Name:   "encrypt",
-					Usage:  "-使用内置加密方法加密配置数据",
+					Usage:  "-加密配置数据，已配置密钥时使用aes-gcm加密，否则使用内置des加密",
 					Action: encrypt,
 					Flags:  getEncryptFlags(),
 				},
+				{
+					Name:   "reencrypt",
+					Usage:  "-使用当前密钥重新加密注册中心中的加密配置，用于密钥轮换",
+					Action: reencryptNow,
+					Flags:  getReEncryptFlags(),
+				},
 				{
 					Name:   "export",
 					Usage:  "-使用内置加密方法加密配置数据",
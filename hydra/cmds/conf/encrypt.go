@@ -12,7 +12,10 @@ func encrypt(c *cli.Context) (err error) {
 		return fmt.Errorf("未指定加密的内容")
 	}
 
-	cipherData := security.Encrypt([]byte(orgData))
+	cipherData, err := security.EncryptBy([]byte(orgData))
+	if err != nil {
+		return err
+	}
 	fmt.Println("原始内容：")
 	fmt.Println(orgData)
 	fmt.Println("加密结果：")
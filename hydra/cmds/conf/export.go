@@ -9,6 +9,7 @@ import (
 	logs "github.com/lib4dev/cli/logger"
 	"github.com/micro-plat/hydra/conf"
 	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/pkgs/secret"
 	"github.com/micro-plat/hydra/conf/pkgs/security"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/hydra/cmds/pkgs"
@@ -20,6 +21,7 @@ import (
 func exportNow(c *cli.Context) (err error) {
 	//1. 绑定应用程序参数
 	global.Current().Log().Pause()
+	secret.Skip()
 	if err := global.Def.Bind(c); err != nil {
 		cli.ShowCommandHelp(c, c.Command.Name)
 		return err
@@ -90,12 +92,17 @@ func (s *export) getMainConf() error {
 		if err != nil {
 			return err
 		}
-		s.getNodes(sc.GetServerConf().GetServerPath(), sc.GetServerConf().GetMainConf(), s.confs)
+		if err := s.getNodes(sc.GetServerConf().GetServerPath(), sc.GetServerConf().GetMainConf(), s.confs); err != nil {
+			return err
+		}
 		sc.GetServerConf().Iter(func(path string, v *conf.RawConf) bool {
 			npath := sc.GetServerConf().GetSubConfPath(path)
-			s.getNodes(npath, v, s.confs)
-			return true
+			err = s.getNodes(npath, v, s.confs)
+			return err == nil
 		})
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -110,20 +117,25 @@ func (s *export) getVarConf() error {
 	}
 	sc.GetVarConf().Iter(func(path string, v *conf.RawConf) bool {
 		npath := sc.GetVarConf().GetVarPath(path)
-		s.getNodes(npath, v, s.confs)
-		return true
+		err = s.getNodes(npath, v, s.confs)
+		return err == nil
 	})
-	return nil
+	return err
 }
 
-func (s *export) getNodes(path string, v *conf.RawConf, input map[string]interface{}) {
+func (s *export) getNodes(path string, v *conf.RawConf, input map[string]interface{}) error {
 	if s.encrypt {
-		input[path] = security.Encrypt(v.GetRaw())
-		return
+		cipher, err := security.EncryptBy(v.GetOrigin())
+		if err != nil {
+			return err
+		}
+		input[path] = cipher
+		return nil
 	}
 	t := make(map[string]interface{})
-	json.Unmarshal(v.GetRaw(), &t)
+	json.Unmarshal(v.GetOrigin(), &t)
 	input[path] = t
+	return nil
 }
 
 func (s *export) writeConf(path string) error {
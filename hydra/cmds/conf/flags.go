@@ -27,6 +27,7 @@ func getInstallFlags() []cli.Flag {
 }
 
 var extNode string
+var showEffective bool
 
 //getShowFlags 获取运行时的参数
 func getShowFlags() []cli.Flag {
@@ -36,6 +37,11 @@ func getShowFlags() []cli.Flag {
 		Destination: &extNode,
 		Usage:       `-扩展节点名称`,
 	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "effective,e",
+		Destination: &showEffective,
+		Usage:       `-显示多层合并后的生效配置及每个配置项的来源(plat、system、cluster、file、env)`,
+	})
 	flags = append(flags, cli.BoolFlag{
 		Name:        "debug,d",
 		Destination: &global.FlagVal.IsDebug,
@@ -61,6 +67,18 @@ func getEncryptFlags() []cli.Flag {
 	return flags
 }
 
+//getReEncryptFlags 获取重新加密时的参数
+func getReEncryptFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, cli.StringFlag{
+		Name:        "data",
+		Destination: &orgData,
+		Usage:       `-需要重新加密的密文，未指定时重新加密注册中心中的所有加密配置`,
+	})
+	flags = append(flags, global.ConfCli.GetFlags()...)
+	return flags
+}
+
 var coverConfIfExists = false
 var confEncrypt = false
 var confExportPath string
@@ -0,0 +1,70 @@
+package conf
+
+import (
+	"fmt"
+
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/conf/pkgs/security"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/migrate"
+	"github.com/urfave/cli"
+)
+
+func reencryptNow(c *cli.Context) (err error) {
+	//1. 重新加密指定的密文
+	if len(orgData) > 0 {
+		v, _, err := security.ReEncrypt([]byte(orgData))
+		if err != nil {
+			return err
+		}
+		fmt.Println(v)
+		return nil
+	}
+
+	//2. 绑定应用程序参数
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return err
+	}
+
+	//3. 重新加密平台下的所有加密配置
+	rgst, err := registry.GetRegistry(global.Current().GetRegistryAddr(), global.Current().Log())
+	if err != nil {
+		return err
+	}
+	return reencryptConf(rgst, registry.Join(global.Current().GetPlatName()))
+}
+
+//reencryptConf 使用当前加密方式与密钥重新加密根节点下的所有加密配置，节点已被修改时不更新
+func reencryptConf(rgst registry.IRegistry, root string) error {
+	s, err := migrate.Export(rgst, root)
+	if err != nil {
+		return err
+	}
+	count := 0
+	for path, data := range s.Nodes {
+		v, changed, err := security.ReEncrypt([]byte(data))
+		if err != nil {
+			return fmt.Errorf("%s重新加密失败:%w", path, err)
+		}
+		if !changed {
+			continue
+		}
+		current, version, err := rgst.GetValue(path)
+		if err != nil {
+			return err
+		}
+		if string(current) != data {
+			return fmt.Errorf("%s重新加密失败:%w", path, registry.ErrVersionConflict)
+		}
+		if err := rgst.UpdateIfVersion(path, v, version); err != nil {
+			return fmt.Errorf("%s重新加密失败:%w", path, err)
+		}
+		logs.Log.Infof("重新加密:%s", path)
+		count++
+	}
+	logs.Log.Infof("共重新加密%d个节点", count)
+	return nil
+}
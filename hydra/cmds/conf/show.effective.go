@@ -0,0 +1,78 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/server"
+)
+
+//printEffective 打印多层合并后的生效配置，每个配置项显示来源层
+func (s *show) printEffective() error {
+	for _, tp := range s.types {
+		sc, err := app.NewAPPConfBy(s.plat, s.sysName, tp, s.cluster, s.rgst)
+		if err != nil {
+			return err
+		}
+		s.print(sc.GetServerConf().GetServerPath())
+		s.printEffectiveConf("main", sc.GetServerConf().GetMainConf())
+		names := make([]string, 0, 1)
+		subs := make(map[string]*conf.RawConf)
+		sc.GetServerConf().Iter(func(path string, v *conf.RawConf) bool {
+			names = append(names, path)
+			subs[path] = v
+			return true
+		})
+		sort.Strings(names)
+		for _, name := range names {
+			s.printEffectiveConf(name, subs[name])
+		}
+	}
+	return nil
+}
+
+func (s *show) printEffectiveConf(name string, v *conf.RawConf) {
+	fmt.Printf("  [%s]\n", name)
+	values := make(map[string]interface{})
+	var data interface{}
+	if err := json.Unmarshal(v.GetOrigin(), &data); err != nil {
+		values[""] = string(v.GetOrigin())
+	} else {
+		flatten(data, "", values)
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	sources := v.GetSources()
+	for _, k := range keys {
+		source, ok := sources[k]
+		if !ok {
+			source = server.LayerCluster
+		}
+		buff, _ := json.Marshal(values[k])
+		if k == "" {
+			k = "(value)"
+		}
+		fmt.Printf("    %s = %s\t(%s)\n", k, buff, source)
+	}
+}
+
+//flatten 将配置转换为以.分隔的配置项
+func flatten(v interface{}, path string, values map[string]interface{}) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		values[path] = v
+		return
+	}
+	for k, v := range m {
+		if path != "" {
+			k = path + "." + k
+		}
+		flatten(v, k, values)
+	}
+}
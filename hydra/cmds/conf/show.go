@@ -8,6 +8,7 @@ import (
 
 	"github.com/micro-plat/hydra/conf"
 	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/pkgs/secret"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/hydra/cmds/pkgs"
 	"github.com/micro-plat/hydra/registry"
@@ -19,6 +20,7 @@ import (
 func showNow(c *cli.Context) (err error) {
 	//1. 绑定应用程序参数
 	global.Current().Log().Pause()
+	secret.Skip()
 	if err := global.Def.Bind(c); err != nil {
 		cli.ShowCommandHelp(c, c.Command.Name)
 		return err
@@ -74,6 +76,9 @@ func newShow(addr string, plat string, sysName string, types []string, cluster s
 func (s *show) Show() error {
 
 	s.rgst = registry.GetCurrent()
+	if showEffective {
+		return s.printEffective()
+	}
 	if err := s.printMainConf(); err != nil {
 		return err
 	}
@@ -175,7 +180,7 @@ func getNode(path string, p string, node map[string]interface{}) {
 func (s *show) getNodes(path string, v *conf.RawConf, input map[string]interface{}) {
 	li := strings.SplitN(strings.Trim(path, "/"), "/", 2)
 	if len(li) == 1 {
-		input[li[0]] = v.GetRaw()
+		input[li[0]] = v.GetOrigin()
 		return
 	}
 	if len(li) > 1 {
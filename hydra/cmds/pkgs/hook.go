@@ -0,0 +1,29 @@
+package pkgs
+
+import "github.com/micro-plat/hydra/hydra/servers"
+
+var startedHooks = make([]func(s *servers.RspServers, err error), 0, 1)
+
+//OnStarted 注册服务器启动后执行的函数，err为服务器启动失败的原因
+func OnStarted(h func(s *servers.RspServers, err error)) {
+	startedHooks = append(startedHooks, h)
+}
+
+func doStarted(s *servers.RspServers, err error) {
+	for _, h := range startedHooks {
+		h(s, err)
+	}
+}
+
+var exitedHooks = make([]func(), 0, 1)
+
+//OnExited 注册服务器关闭并释放所有资源后执行的函数
+func OnExited(h func()) {
+	exitedHooks = append(exitedHooks, h)
+}
+
+func doExited() {
+	for _, h := range exitedHooks {
+		h()
+	}
+}
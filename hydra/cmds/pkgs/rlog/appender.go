@@ -136,13 +136,7 @@ func (w writeHandler) Write(p []byte) (n int, err error) {
 }
 
 //Registry 注册日志组件
-func Registry(platName string, addr string) error {
-
-	//初始化注册中心
-	registry, err := registry.GetRegistry(addr, global.Def.Log())
-	if err != nil {
-		return err
-	}
+func Registry(platName string, registry registry.IRegistry) error {
 
 	//获取远程配置
 	layout, err := rlog.GetConfByAddr(registry, platName)
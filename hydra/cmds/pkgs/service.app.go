@@ -9,6 +9,7 @@ import (
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/hydra/cmds/pkgs/service"
 	"github.com/micro-plat/hydra/hydra/servers"
+	"github.com/micro-plat/hydra/hydra/servers/admin"
 	"github.com/urfave/cli"
 )
 
@@ -74,4 +75,5 @@ type ServiceApp struct {
 	c      *cli.Context
 	server *servers.RspServers
 	trace  itrace
+	admin  *admin.Server
 }
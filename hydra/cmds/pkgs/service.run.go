@@ -6,12 +6,18 @@ import (
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/hydra/cmds/pkgs/rlog"
 	"github.com/micro-plat/hydra/hydra/servers"
+	"github.com/micro-plat/hydra/hydra/servers/admin"
 	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/snapshot"
 	"github.com/micro-plat/lib4go/logger"
 	"github.com/urfave/cli"
 )
 
 func (p *ServiceApp) run() (err error) {
+	//启用管理服务时接管日志输出器，用于动态调整日志级别
+	if global.Def.GetAdminPort() != "" {
+		admin.HookLogger()
+	}
 	if p.c.Bool("nostd") {
 		logger.RemoveStdoutAppender()
 	}
@@ -25,8 +31,13 @@ func (p *ServiceApp) run() (err error) {
 		logger.AddWriteThread(99)
 	}
 
-	//2. 注册远程日志组件
-	if err := rlog.Registry(global.Def.PlatName, global.Def.RegistryAddr); err != nil {
+	//2. 注册远程日志组件,注册中心不可用时使用本地快照
+	rgst, err := snapshot.GetCurrent()
+	if err != nil {
+		logs.Log.Error(err)
+		return err
+	}
+	if err := rlog.Registry(global.Def.PlatName, rgst); err != nil {
 		logs.Log.Error(err)
 		return err
 	}
@@ -50,8 +61,18 @@ func (p *ServiceApp) run() (err error) {
 	p.server = servers.NewRspServers(globalData.GetRegistryAddr(),
 		globalData.GetPlatName(), globalData.GetSysName(),
 		globalData.GetServerTypes(), globalData.GetClusterName())
-	if err := p.server.Start(); err != nil {
+	err = p.server.Start()
+	doStarted(p.server, err)
+	if err != nil {
 		return err
 	}
+
+	//6. 启动管理服务
+	if globalData.GetAdminPort() != "" {
+		p.admin = admin.New(globalData.GetAdminPort(), p.server)
+		if err := p.admin.Start(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -32,6 +32,10 @@ func (p *ServiceApp) Stop(s service.Service) (err error) {
 		p.server.Shutdown()
 	}
 
+	if p.admin != nil {
+		p.admin.Stop()
+	}
+
 	if p.trace != nil {
 		p.trace.Stop()
 	}
@@ -48,5 +52,8 @@ func (p *ServiceApp) Stop(s service.Service) (err error) {
 
 	globalLogger.Info(global.AppName, "已安全退出")
 
+	//执行退出后的操作，如升级后重启
+	doExited()
+
 	return nil
 }
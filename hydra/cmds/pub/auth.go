@@ -0,0 +1,108 @@
+package pub
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+//sshAuth 服务器登录认证信息，所有服务器共用
+type sshAuth struct {
+	methods []ssh.AuthMethod
+	hostKey ssh.HostKeyCallback
+	agent   net.Conn
+}
+
+//newAuth 构建登录认证信息，依次使用私钥、ssh-agent、密码登录
+func newAuth(keyFile string, useAgent bool, pwd string, knownHosts string, insecure bool) (a *sshAuth, err error) {
+	a = &sshAuth{methods: make([]ssh.AuthMethod, 0, 3)}
+	if keyFile != "" {
+		signer, err := loadPrivateKey(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		a.methods = append(a.methods, ssh.PublicKeys(signer))
+	}
+	if useAgent {
+		sock := os.Getenv("SSH_AUTH_SOCK")
+		if sock == "" {
+			return nil, fmt.Errorf("未找到ssh-agent，环境变量SSH_AUTH_SOCK为空")
+		}
+		if a.agent, err = net.Dial("unix", sock); err != nil {
+			return nil, fmt.Errorf("连接ssh-agent失败:%w", err)
+		}
+		a.methods = append(a.methods, ssh.PublicKeysCallback(agent.NewClient(a.agent).Signers))
+	}
+	if pwd != "" {
+		a.methods = append(a.methods, ssh.Password(pwd))
+	}
+	if len(a.methods) == 0 {
+		a.Close()
+		return nil, fmt.Errorf("未指定登录方式，请使用--key、--agent或--pwd")
+	}
+	if a.hostKey, err = getHostKeyCallback(knownHosts, insecure); err != nil {
+		a.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+//Close 关闭ssh-agent连接
+func (a *sshAuth) Close() error {
+	if a.agent != nil {
+		return a.agent.Close()
+	}
+	return nil
+}
+
+func loadPrivateKey(path string) (ssh.Signer, error) {
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取私钥文件%s失败:%w", path, err)
+	}
+	signer, err := ssh.ParsePrivateKey(buff)
+	var perr *ssh.PassphraseMissingError
+	if errors.As(err, &perr) {
+		return nil, fmt.Errorf("私钥文件%s已加密，请将私钥加入ssh-agent后使用--agent登录", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("私钥文件%s格式有误:%w", path, err)
+	}
+	return signer, nil
+}
+
+//getHostKeyCallback 根据known_hosts文件验证服务器公钥
+func getHostKeyCallback(path string, insecure bool) (ssh.HostKeyCallback, error) {
+	if insecure {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("获取用户目录失败:%w", err)
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("加载known_hosts文件%s失败，可通过--known-hosts指定或使用--insecure跳过验证:%w", path, err)
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var kerr *knownhosts.KeyError
+		if !errors.As(err, &kerr) {
+			return err
+		}
+		if len(kerr.Want) == 0 {
+			return fmt.Errorf("服务器%s不在%s中，请先通过ssh-keyscan添加服务器公钥", hostname, path)
+		}
+		return fmt.Errorf("服务器%s的公钥与%s中记录的不一致，可能存在中间人攻击", hostname, path)
+	}, nil
+}
@@ -12,18 +12,18 @@ var cmdMkdir cmd = "mkdir @path"
 var cmdRm cmd = "rm -rf @path"
 var cmdRunScript cmd = `sh @path @temp_file @bin_name @project_path "@install_params"`
 
-func (c cmd) CMD(path string) string {
+func (c cmd) CMD(s *sshClient, path string) string {
 	ps := make([]interface{}, 0, 12)
 	ps = append(ps, "name")
 	ps = append(ps, global.AppName)
 	ps = append(ps, "path")
 	ps = append(ps, path)
 	ps = append(ps, "temp_file")
-	ps = append(ps, client.tmpFile)
+	ps = append(ps, s.tmpFile)
 	ps = append(ps, "bin_name")
-	ps = append(ps, client.localPath)
+	ps = append(ps, s.localPath)
 	ps = append(ps, "project_path")
-	ps = append(ps, client.projectPath)
+	ps = append(ps, s.projectPath)
 	ps = append(ps, "install_params")
 	ps = append(ps, runInstall)
 
@@ -0,0 +1,121 @@
+package pub
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+//result 单台服务器的发布结果
+type result struct {
+	host    string
+	err     error
+	skipped bool
+	span    time.Duration
+}
+
+//deploy 按批次并行发布，前一批全部成功后再发布下一批，有失败时剩余服务器不再发布
+func deploy(clients []*sshClient, batch int, f func(s *sshClient) error) []*result {
+	if batch <= 0 {
+		batch = 1
+	}
+	results := make([]*result, len(clients))
+	failed := false
+	for start := 0; start < len(clients); start += batch {
+		end := start + batch
+		if end > len(clients) {
+			end = len(clients)
+		}
+		if failed {
+			for i := start; i < end; i++ {
+				results[i] = &result{host: clients[i].host, skipped: true}
+			}
+			continue
+		}
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				begin := time.Now()
+				err := f(clients[i])
+				results[i] = &result{host: clients[i].host, err: err, span: time.Since(begin)}
+			}(i)
+		}
+		wg.Wait()
+		for i := start; i < end; i++ {
+			failed = failed || results[i].err != nil
+		}
+	}
+	return results
+}
+
+//report 输出每台服务器的发布结果，有失败时返回错误
+func report(w io.Writer, results []*result) error {
+	success, failed, skipped := 0, 0, 0
+	fmt.Fprintln(w, "发布结果：")
+	for _, r := range results {
+		switch {
+		case r.skipped:
+			skipped++
+			fmt.Fprintf(w, "  [未执行] %s\n", r.host)
+		case r.err != nil:
+			failed++
+			fmt.Fprintf(w, "  [失败] %s %s %v\n", r.host, r.span.Round(time.Millisecond), r.err)
+		default:
+			success++
+			fmt.Fprintf(w, "  [成功] %s %s\n", r.host, r.span.Round(time.Millisecond))
+		}
+	}
+	fmt.Fprintf(w, "共%d台，成功%d台，失败%d台，未执行%d台\n", len(results), success, failed, skipped)
+	if failed > 0 {
+		return fmt.Errorf("%d台服务器发布失败", failed)
+	}
+	return nil
+}
+
+//prefixWriter 为每行输出增加服务器前缀，多台服务器并行发布时输出不会交错
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	buf    bytes.Buffer
+}
+
+var writeLock sync.Mutex
+
+func newPrefixWriter(w io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{w: w, prefix: prefix}
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	p.buf.Write(b)
+	lines := make([]string, 0, 1)
+	for {
+		i := bytes.IndexByte(p.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		lines = append(lines, p.prefix+string(p.buf.Next(i+1)))
+	}
+	if len(lines) > 0 {
+		if _, err := io.WriteString(p.w, strings.Join(lines, "")); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+//Flush 输出缓存中不完整的行
+func (p *prefixWriter) Flush() {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	if p.buf.Len() > 0 {
+		io.WriteString(p.w, p.prefix+p.buf.String()+"\n")
+		p.buf.Reset()
+	}
+}
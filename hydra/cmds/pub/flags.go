@@ -6,10 +6,18 @@ import (
 
 var runInstall = ""
 var pwd string
+var keyFile string
+var useAgent bool
+var knownHosts string
+var insecure bool
+var inventory string
+var cluster string
+var batch int
+var dryRun bool
 
 //getFlags 获取运行时的参数
 func getFlags() []cli.Flag {
-	flags := make([]cli.Flag, 0, 1)
+	flags := make([]cli.Flag, 0, 10)
 	flags = append(flags, cli.StringFlag{
 		Name:        "install,i",
 		Destination: &runInstall,
@@ -19,7 +27,47 @@ func getFlags() []cli.Flag {
 		Name:        "pwd",
 		Destination: &pwd,
 		Usage:       `-远程服务器密码`,
-		Required:    true,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "key,k",
+		Destination: &keyFile,
+		Usage:       `-登录私钥文件，如~/.ssh/id_rsa`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "agent",
+		Destination: &useAgent,
+		Usage:       `-使用ssh-agent(SSH_AUTH_SOCK)中的密钥登录`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "known-hosts",
+		Destination: &knownHosts,
+		Usage:       `-用于验证服务器公钥的known_hosts文件，默认为~/.ssh/known_hosts`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "insecure",
+		Destination: &insecure,
+		Usage:       `-不验证服务器公钥`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "inventory,f",
+		Destination: &inventory,
+		Usage:       `-主机清单文件，按集群分组，格式:{"集群名":["userName@ip[:port]:/path"]}`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "cluster,c",
+		Destination: &cluster,
+		Usage:       `-发布到主机清单中的指定集群，多个集群以逗号分隔，未指定时发布到所有集群`,
+	})
+	flags = append(flags, cli.IntFlag{
+		Name:        "batch,b",
+		Destination: &batch,
+		Value:       1,
+		Usage:       `-每批同时发布的服务器数，前一批全部成功后再发布下一批`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "dry-run",
+		Destination: &dryRun,
+		Usage:       `-只显示将要上传的文件与执行的命令，不连接服务器`,
 	})
 	return flags
 }
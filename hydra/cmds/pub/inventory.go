@@ -0,0 +1,80 @@
+package pub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+//Inventory 主机清单，按集群分组，格式:{"集群名":["userName@ip[:port]:/path"]}
+type Inventory map[string][]string
+
+//LoadInventory 加载主机清单文件
+func LoadInventory(path string) (Inventory, error) {
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取主机清单%s失败:%w", path, err)
+	}
+	inv := make(Inventory)
+	if err := json.Unmarshal(buff, &inv); err != nil {
+		return nil, fmt.Errorf("主机清单%s格式有误:%w", path, err)
+	}
+	return inv, nil
+}
+
+//GetHosts 获取指定集群的服务器，多个集群以逗号分隔，未指定时返回所有集群的服务器
+func (i Inventory) GetHosts(clusters string) ([]string, error) {
+	names := make([]string, 0, len(i))
+	if clusters == "" {
+		for name := range i {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range strings.Split(clusters, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if _, ok := i[name]; !ok {
+			return nil, fmt.Errorf("主机清单中未配置集群:%s", name)
+		}
+		names = append(names, name)
+	}
+	hosts := make([]string, 0, len(names))
+	for _, name := range names {
+		hosts = append(hosts, i[name]...)
+	}
+	return hosts, nil
+}
+
+//getHosts 获取命令行与主机清单中的所有服务器，去除重复项
+func getHosts(args []string, inventory string, clusters string) ([]string, error) {
+	all := append([]string{}, args...)
+	if inventory != "" {
+		inv, err := LoadInventory(inventory)
+		if err != nil {
+			return nil, err
+		}
+		hosts, err := inv.GetHosts(clusters)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, hosts...)
+	} else if clusters != "" {
+		return nil, fmt.Errorf("指定集群时须通过--inventory指定主机清单")
+	}
+	hosts := make([]string, 0, len(all))
+	exists := make(map[string]bool)
+	for _, h := range all {
+		if h = strings.TrimSpace(h); h != "" && !exists[h] {
+			exists[h] = true
+			hosts = append(hosts, h)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("未指定远程服务器信息，格式:userName@ip[:port]:/path，或通过--inventory指定主机清单")
+	}
+	return hosts, nil
+}
@@ -1,6 +1,10 @@
 package pub
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/lib4dev/cli/cmds"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/global/compatible"
@@ -10,10 +14,11 @@ import (
 func init() {
 	cmds.RegisterFunc(func() cli.Command {
 		return cli.Command{
-			Name:   "pub",
-			Usage:  "远程发布服务，将当前应用发布到远程服务器，并启动",
-			Flags:  getFlags(),
-			Action: doPub,
+			Name:      "pub",
+			Usage:     "远程发布服务，将当前应用发布到远程服务器，并启动",
+			ArgsUsage: "[userName@ip[:port]:/path...]",
+			Flags:     getFlags(),
+			Action:    doPub,
 		}
 	})
 }
@@ -26,42 +31,83 @@ func doPub(c *cli.Context) (err error) {
 		return err
 	}
 
-	//2.绑定请求参数
-	if err = client.Bind(c.Args().Get(0), global.AppName, pwd); err != nil {
+	//2.获取所有远程服务器
+	hosts, err := getHosts(c.Args(), inventory, cluster)
+	if err != nil {
 		return err
 	}
 
-	//3.登录到远程服务器
-	if err = client.Login(); err != nil {
+	//3.构建登录认证信息
+	var auth *sshAuth
+	if !dryRun {
+		if auth, err = newAuth(keyFile, useAgent, pwd, knownHosts, insecure); err != nil {
+			return err
+		}
+		defer auth.Close()
+	}
+
+	//4.绑定请求参数
+	clients := make([]*sshClient, 0, len(hosts))
+	for _, host := range hosts {
+		s, err := newSSHClient(host, global.AppName, auth)
+		if err != nil {
+			return err
+		}
+		s.progress = len(hosts) == 1
+		clients = append(clients, s)
+	}
+
+	//5.预览或发布到所有服务器
+	f := publish
+	if dryRun {
+		f = preview
+	}
+	return report(os.Stdout, deploy(clients, batch, f))
+}
+
+//publish 发布到一台服务器
+func publish(s *sshClient) error {
+	if w, ok := s.out.(*prefixWriter); ok {
+		defer w.Flush()
+	}
+
+	//1.登录到远程服务器
+	if err := s.Login(); err != nil {
 		return err
 	}
 
-	//4.切换工作目录
-	defer client.Close()
-	if err := client.GoWorkDir(); err != nil {
+	//2.切换工作目录
+	defer s.Close()
+	if err := s.GoWorkDir(); err != nil {
 		return err
 	}
 
-	//5. 上传文件
-	if err := client.UploadFile(); err != nil {
+	//3. 上传文件
+	if err := s.UploadFile(); err != nil {
 		return err
 	}
 
-	//6.上传脚本
-	path, err := client.UploadScript()
+	//4.上传脚本
+	path, err := s.UploadScript()
 	if err != nil {
 		return err
 	}
 
-	//7. 执行脚本
-	if err := client.ExecScript(path); err != nil {
+	//5. 执行脚本
+	if err := s.ExecScript(path); err != nil {
 		return err
 	}
 
-	//8.删除文件
-	if err := client.RmWorkDir(); err != nil {
+	//6.删除文件
+	return s.RmWorkDir()
+}
+
+//preview 显示将要上传的文件与执行的命令
+func preview(s *sshClient) error {
+	steps, err := s.Plan()
+	if err != nil {
 		return err
 	}
-
+	fmt.Fprintf(s.output(), "%s\n", strings.Join(steps, "\n"))
 	return nil
 }
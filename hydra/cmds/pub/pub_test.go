@@ -0,0 +1,265 @@
+package pub
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+//testServer 进程内ssh服务，支持sftp子系统，命令只记录不执行(mkdir除外)
+type testServer struct {
+	addr     string
+	hostKey  ssh.Signer
+	listener net.Listener
+	lock     sync.Mutex
+	cmds     []string
+}
+
+func newTestServer(t *testing.T, userKey ssh.PublicKey) *testServer {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	hostKey, err := ssh.NewSignerFromKey(priv)
+	assert.Equal(t, nil, err)
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), userKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+	}
+	config.AddHostKey(hostKey)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err)
+	s := &testServer{addr: l.Addr().String(), hostKey: hostKey, listener: l}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, config)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for ch := range chans {
+		channel, requests, err := ch.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer channel.Close()
+			for req := range requests {
+				switch req.Type {
+				case "subsystem":
+					req.Reply(true, nil)
+					server, _ := sftp.NewServer(channel)
+					server.Serve()
+					return
+				case "exec":
+					req.Reply(true, nil)
+					cmd := string(req.Payload[4:])
+					s.lock.Lock()
+					s.cmds = append(s.cmds, cmd)
+					s.lock.Unlock()
+					if strings.HasPrefix(cmd, "mkdir ") {
+						os.MkdirAll(strings.TrimPrefix(cmd, "mkdir "), 0755)
+					}
+					channel.Write([]byte("done\n"))
+					channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+					return
+				default:
+					req.Reply(false, nil)
+				}
+			}
+		}()
+	}
+}
+
+func (s *testServer) knownHosts(t *testing.T, dir string, key ssh.PublicKey) string {
+	p := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(s.addr)}, key)
+	assert.Equal(t, nil, ioutil.WriteFile(p, []byte(line+"\n"), 0600))
+	return p
+}
+
+func newUserKey(t *testing.T, dir string) (string, ed25519.PrivateKey, ssh.PublicKey) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	buff, err := x509.MarshalPKCS8PrivateKey(priv)
+	assert.Equal(t, nil, err)
+	p := filepath.Join(dir, "id_ed25519")
+	assert.Equal(t, nil, ioutil.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: buff}), 0600))
+	signer, _ := ssh.NewSignerFromKey(priv)
+	return p, priv, signer.PublicKey()
+}
+
+func TestPublish(t *testing.T) {
+	dir := t.TempDir()
+	keyPath, priv, userKey := newUserKey(t, dir)
+	server := newTestServer(t, userKey)
+	defer server.listener.Close()
+
+	binName := "pub_test_bin"
+	assert.Equal(t, nil, ioutil.WriteFile(binName, []byte("hydra"), 0644))
+	defer os.Remove(binName)
+	host := "root@" + server.addr + ":/home/app"
+
+	//1. 私钥登录，服务器公钥在known_hosts中
+	known := server.knownHosts(t, dir, server.hostKey.PublicKey())
+	auth, err := newAuth(keyPath, false, "", known, false)
+	assert.Equal(t, nil, err)
+	s, err := newSSHClient(host, binName, auth)
+	assert.Equal(t, nil, err)
+	err = publish(s)
+	assert.Equal(t, nil, err)
+	buff, err := ioutil.ReadFile(path.Join(s.tmpPath, binName))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hydra", string(buff))
+	script, _ := getScript()
+	_, err = os.Stat(path.Join(s.tmpPath, script))
+	assert.Equal(t, nil, err)
+	os.RemoveAll(s.tmpPath)
+	assert.Equal(t, []string{
+		cmdMkdir.CMD(s, s.tmpPath),
+		cmdCD.CMD(s, s.tmpPath),
+		cmdRunScript.CMD(s, path.Join(s.tmpPath, script)),
+		cmdRm.CMD(s, s.tmpPath),
+	}, server.cmds)
+
+	//2. 服务器公钥不在known_hosts中
+	_, other, _ := ed25519.GenerateKey(rand.Reader)
+	otherKey, _ := ssh.NewSignerFromKey(other)
+	known = server.knownHosts(t, dir, otherKey.PublicKey())
+	auth, err = newAuth(keyPath, false, "", known, false)
+	assert.Equal(t, nil, err)
+	s, _ = newSSHClient(host, binName, auth)
+	err = s.Login()
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, true, strings.Contains(err.Error(), "不一致"))
+
+	//3. 使用ssh-agent登录，不验证服务器公钥
+	keyring := agent.NewKeyring()
+	assert.Equal(t, nil, keyring.Add(agent.AddedKey{PrivateKey: priv}))
+	sock := filepath.Join(dir, "agent.sock")
+	l, err := net.Listen("unix", sock)
+	assert.Equal(t, nil, err)
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+	os.Setenv("SSH_AUTH_SOCK", sock)
+	defer os.Unsetenv("SSH_AUTH_SOCK")
+	auth, err = newAuth("", true, "", "", true)
+	assert.Equal(t, nil, err)
+	defer auth.Close()
+	s, _ = newSSHClient(host, binName, auth)
+	err = s.Login()
+	assert.Equal(t, nil, err)
+	s.Close()
+
+	//4. 未指定登录方式
+	_, err = newAuth("", false, "", "", true)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestDeploy(t *testing.T) {
+	clients := make([]*sshClient, 0, 5)
+	for _, h := range []string{"a", "b", "c", "d", "e"} {
+		clients = append(clients, &sshClient{host: h})
+	}
+
+	//1. 全部成功
+	var lock sync.Mutex
+	done := make([]string, 0, 5)
+	results := deploy(clients, 2, func(s *sshClient) error {
+		lock.Lock()
+		defer lock.Unlock()
+		done = append(done, s.host)
+		return nil
+	})
+	assert.Equal(t, 5, len(done))
+	assert.Equal(t, nil, report(ioutil.Discard, results))
+
+	//2. 第二批有失败，后续服务器不再发布
+	results = deploy(clients, 2, func(s *sshClient) error {
+		if s.host == "c" {
+			return os.ErrPermission
+		}
+		return nil
+	})
+	assert.Equal(t, nil, results[0].err)
+	assert.Equal(t, nil, results[1].err)
+	assert.Equal(t, os.ErrPermission, results[2].err)
+	assert.Equal(t, nil, results[3].err)
+	assert.Equal(t, true, results[4].skipped)
+	out := &bytes.Buffer{}
+	assert.NotEqual(t, nil, report(out, results))
+	assert.Equal(t, true, strings.Contains(out.String(), "共5台，成功3台，失败1台，未执行1台"))
+}
+
+func TestPreview(t *testing.T) {
+	binName := "pub_test_bin"
+	assert.Equal(t, nil, ioutil.WriteFile(binName, []byte("hydra"), 0644))
+	defer os.Remove(binName)
+
+	out := &bytes.Buffer{}
+	s, err := newSSHClient("root@192.168.0.1:2222:/home/app", binName, nil)
+	assert.Equal(t, nil, err)
+	s.out = newPrefixWriter(out, "[x] ")
+	assert.Equal(t, nil, preview(s))
+	assert.Equal(t, true, strings.Contains(out.String(), "[x] 登录 root@192.168.0.1:2222\n"))
+	assert.Equal(t, true, strings.Contains(out.String(), "[x] 上传 pub_test_bin(5字节)"))
+	assert.Equal(t, true, strings.Contains(out.String(), "[x] 执行 "+cmdRunScript.CMD(s, path.Join(s.tmpPath, "pub.sh"))))
+
+	//本地文件不存在
+	s, _ = newSSHClient("root@192.168.0.1:/home/app", "pub_test_none", nil)
+	assert.NotEqual(t, nil, preview(s))
+}
+
+func TestInventory(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "hosts.json")
+	ioutil.WriteFile(p, []byte(`{"prod":["root@192.168.0.1:/app","root@192.168.0.2:/app"],"test":["root@192.168.1.1:/app"]}`), 0644)
+
+	hosts, err := getHosts(nil, p, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"root@192.168.0.1:/app", "root@192.168.0.2:/app", "root@192.168.1.1:/app"}, hosts)
+
+	hosts, err = getHosts([]string{"root@192.168.1.1:/app"}, p, "test, prod")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"root@192.168.1.1:/app", "root@192.168.0.1:/app", "root@192.168.0.2:/app"}, hosts)
+
+	_, err = getHosts(nil, p, "dev")
+	assert.NotEqual(t, nil, err)
+
+	_, err = getHosts(nil, "", "prod")
+	assert.NotEqual(t, nil, err)
+}
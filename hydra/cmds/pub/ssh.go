@@ -3,9 +3,10 @@ package pub
 import (
 	"fmt"
 	"io"
- 	"net"
+	"net"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,12 +15,15 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
-var client = &sshClient{}
-
 type sshClient struct {
+	host        string
 	ip          string
+	port        int
 	userName    string
 	pwd         string
+	auth        *sshAuth
+	out         io.Writer
+	progress    bool
 	client      *ssh.Client
 	tmpDir      string
 	tmpPath     string
@@ -28,28 +32,45 @@ type sshClient struct {
 	projectPath string
 }
 
+//newSSHClient 构建服务器客户端，host格式为userName@ip[:port]:/path
+func newSSHClient(host string, localpath string, auth *sshAuth) (*sshClient, error) {
+	s := &sshClient{auth: auth}
+	if err := s.Bind(host, localpath, pwd); err != nil {
+		return nil, err
+	}
+	s.out = newPrefixWriter(os.Stdout, fmt.Sprintf("[%s] ", s.host))
+	return s, nil
+}
+
 func (s *sshClient) Bind(host string, localpath string, pwd string) error {
 	if host == "" {
 		return fmt.Errorf("未指定远程服务器信息")
 	}
-	if pwd == "" {
-		return fmt.Errorf("未指远程服务器登录密码")
-	}
 	if !strings.Contains(host, ":") {
-		return fmt.Errorf("%s 服务器信息应包含远程目录,格式:userName@ip:/path", host)
+		return fmt.Errorf("%s 服务器信息应包含远程目录,格式:userName@ip[:port]:/path", host)
 	}
 	if !strings.Contains(host, "@") {
-		return fmt.Errorf("%s 服务器信息应包含远程服务器ip地址,格式:userName@ip:/path", host)
+		return fmt.Errorf("%s 服务器信息应包含远程服务器ip地址,格式:userName@ip[:port]:/path", host)
 	}
 	paths := strings.Split(host, ":")
+	s.port = 22
+	if len(paths) == 3 {
+		port, err := strconv.Atoi(paths[1])
+		if err != nil || port <= 0 || port > 65535 {
+			return fmt.Errorf("%s 端口号有误,格式:userName@ip[:port]:/path", host)
+		}
+		s.port = port
+		paths = []string{paths[0], paths[2]}
+	}
 	if len(paths) != 2 || paths[1] == "" || paths[0] == "" {
-		return fmt.Errorf("%s 远程路径有误,格式:userName@ip:/path", host)
+		return fmt.Errorf("%s 远程路径有误,格式:userName@ip[:port]:/path", host)
 	}
 
 	hosts := strings.Split(paths[0], "@")
 	if len(hosts) != 2 || hosts[1] == "" || hosts[0] == "" {
-		return fmt.Errorf("%s 远程服务有误,格式:userName@ip:/path", host)
+		return fmt.Errorf("%s 远程服务有误,格式:userName@ip[:port]:/path", host)
 	}
+	s.host = host
 	s.userName = hosts[0]
 	s.ip = hosts[1]
 	s.localPath = localpath
@@ -63,18 +84,28 @@ func (s *sshClient) Bind(host string, localpath string, pwd string) error {
 
 //登录到服务器
 func (s *sshClient) Login() (err error) {
-	if s.ip == "" || s.userName == "" || s.pwd == "" {
-		return fmt.Errorf("服务器ip,用户名，密码不能为空")
+	if s.ip == "" || s.userName == "" {
+		return fmt.Errorf("服务器ip,用户名不能为空")
+	}
+	auth := []ssh.AuthMethod{}
+	hostKey := ssh.InsecureIgnoreHostKey()
+	if s.auth != nil {
+		auth = s.auth.methods
+		hostKey = s.auth.hostKey
+	} else if s.pwd != "" {
+		auth = append(auth, ssh.Password(s.pwd))
+	}
+	if len(auth) == 0 {
+		return fmt.Errorf("未指定服务器登录方式")
 	}
+
 	//通过ssh连接到远程服务器
-	address := fmt.Sprintf("%s:22", s.ip)
+	address := net.JoinHostPort(s.ip, strconv.Itoa(s.port))
 	s.client, err = ssh.Dial("tcp", address, &ssh.ClientConfig{
-		User: s.userName,
-		Auth: []ssh.AuthMethod{ssh.Password(s.pwd)},
-		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
-			return nil
-		},
-		Timeout: 30 * time.Second,
+		User:            s.userName,
+		Auth:            auth,
+		HostKeyCallback: hostKey,
+		Timeout:         30 * time.Second,
 	})
 	return err
 }
@@ -89,11 +120,18 @@ func (s *sshClient) run(cmd string) error {
 		return err
 	}
 	defer session.Close()
-	session.Stdout = os.Stdout
-	session.Stderr = os.Stderr
+	session.Stdout = s.output()
+	session.Stderr = s.output()
 	return session.Run(cmd)
 }
 
+func (s *sshClient) output() io.Writer {
+	if s.out == nil {
+		return os.Stdout
+	}
+	return s.out
+}
+
 //获取当前文件名
 func (s *sshClient) GetFileName() string {
 	return path.Base(s.localPath)
@@ -139,12 +177,18 @@ func (s *sshClient) UploadFile() error {
 			}
 		}
 		idx++
-		percent := Progress(1024 * 100 * idx / int(fileInfo.Size()))
-		percent.Show()
-		dstFile.Write(buffer[:n])
+		if _, err := dstFile.Write(buffer[:n]); err != nil {
+			return fmt.Errorf("上传文件出错%w", err)
+		}
+		if s.progress && fileInfo.Size() > 0 {
+			percent := Progress(1024 * 100 * idx / int(fileInfo.Size()))
+			percent.Show()
+		}
 	}
 
-	fmt.Print("\n")
+	if s.progress {
+		fmt.Print("\n")
+	}
 	return nil
 }
 
@@ -168,8 +212,8 @@ func (s *sshClient) UploadScript() (string, error) {
 	defer dstFile.Close()
 	dstFile.Write([]byte(script))
 
-	if err := ftpclient.Chmod(path.Join(s.tmpPath, p), 755); err != nil {
-		return "", fmt.Errorf("更改脚本文件权限失败%w", e)
+	if err := ftpclient.Chmod(path.Join(s.tmpPath, p), 0755); err != nil {
+		return "", fmt.Errorf("更改脚本文件权限失败%w", err)
 	}
 
 	return p, nil
@@ -178,26 +222,46 @@ func (s *sshClient) UploadScript() (string, error) {
 //GoWorkDir 转到工作目录
 func (s *sshClient) GoWorkDir() (err error) {
 
-	if err := s.run(cmdMkdir.CMD(s.tmpPath)); err != nil {
+	if err := s.run(cmdMkdir.CMD(s, s.tmpPath)); err != nil {
 		return err
 	}
 
-	return s.run(cmdCD.CMD(s.tmpPath))
+	return s.run(cmdCD.CMD(s, s.tmpPath))
 }
 
 //ExecScript
 func (s *sshClient) ExecScript(p string) error {
 	scriptPath := path.Join(s.tmpPath, p)
-	return s.run(cmdRunScript.CMD(scriptPath))
+	return s.run(cmdRunScript.CMD(s, scriptPath))
 }
 
 func (s *sshClient) Close() error {
-	return nil
+	if s.client == nil {
+		return nil
+	}
+	return s.client.Close()
 }
 
 //删除工作目录
 func (s *sshClient) RmWorkDir() (err error) {
-	return s.run(cmdRm.CMD(s.tmpPath))
+	return s.run(cmdRm.CMD(s, s.tmpPath))
 }
 
- 
\ No newline at end of file
+//Plan 获取发布时将要上传的文件与执行的命令
+func (s *sshClient) Plan() ([]string, error) {
+	info, err := os.Stat(s.localPath)
+	if err != nil {
+		return nil, fmt.Errorf("本地文件%s不可用:%w", s.localPath, err)
+	}
+	p, script := getScript()
+	address := net.JoinHostPort(s.ip, strconv.Itoa(s.port))
+	return []string{
+		fmt.Sprintf("登录 %s@%s", s.userName, address),
+		fmt.Sprintf("执行 %s", cmdMkdir.CMD(s, s.tmpPath)),
+		fmt.Sprintf("执行 %s", cmdCD.CMD(s, s.tmpPath)),
+		fmt.Sprintf("上传 %s(%d字节) -> %s", s.localPath, info.Size(), path.Join(s.tmpPath, s.localPath)),
+		fmt.Sprintf("上传 %s(%d字节) -> %s", p, len(script), path.Join(s.tmpPath, p)),
+		fmt.Sprintf("执行 %s", cmdRunScript.CMD(s, path.Join(s.tmpPath, p))),
+		fmt.Sprintf("执行 %s", cmdRm.CMD(s, s.tmpPath)),
+	}, nil
+}
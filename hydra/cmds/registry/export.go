@@ -0,0 +1,91 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/global"
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/migrate"
+	"github.com/urfave/cli"
+)
+
+func exportNow(c *cli.Context) (err error) {
+	//1. 绑定应用程序参数
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return err
+	}
+
+	//2. 导出节点
+	rgst, err := r.GetRegistry(global.Current().GetRegistryAddr(), global.Current().Log())
+	if err != nil {
+		return err
+	}
+	s, err := migrate.Export(rgst, getRoot())
+	if err != nil {
+		return err
+	}
+
+	//3. 写入文件
+	if !coverIfExists {
+		if _, err := os.Stat(filePath); err == nil {
+			return fmt.Errorf("文件:%s已经存在", filePath)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return fmt.Errorf("创建文件夹%s失败:%v", filePath, err)
+	}
+	buff, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filePath, buff, 0644); err != nil {
+		return err
+	}
+	logs.Log.Infof("导出%d个节点到文件:%s(checksum:%s)", len(s.Nodes), filePath, s.Checksum)
+	return nil
+}
+
+func importNow(c *cli.Context) (err error) {
+	//1. 绑定应用程序参数
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return err
+	}
+
+	//2. 读取节点文件
+	buff, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("读取节点文件错误:%+v", err)
+	}
+	s := &migrate.Snapshot{}
+	if err := json.Unmarshal(buff, s); err != nil {
+		return fmt.Errorf("节点文件格式错误:%+v", err)
+	}
+
+	//3. 导入并校验
+	rgst, err := r.GetRegistry(global.Current().GetRegistryAddr(), global.Current().Log())
+	if err != nil {
+		return err
+	}
+	if err := migrate.Import(rgst, s, coverIfExists); err != nil {
+		return err
+	}
+	if err := migrate.Verify(rgst, s); err != nil {
+		return err
+	}
+	logs.Log.Infof("导入%d个节点到注册中心:%s(checksum:%s)", len(s.Nodes), global.Current().GetRegistryAddr(), s.Checksum)
+	return nil
+}
+
+//getRoot 获取平台根节点
+func getRoot() string {
+	return r.Join(global.Current().GetPlatName())
+}
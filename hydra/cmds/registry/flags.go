@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/hydra/cmds/pkgs"
+	"github.com/urfave/cli"
+)
+
+var coverIfExists = false
+var filePath string
+var fromAddr string
+var toAddr string
+var interval = 60
+
+//getExportFlags 获取导出节点时的参数
+func getExportFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, cli.StringFlag{
+		Name:        "out,o",
+		Destination: &filePath,
+		Value:       "./registry.json",
+		Usage:       `-节点文件导出地址`,
+	})
+	flags = append(flags, coverFlag, debugFlag)
+	return flags
+}
+
+//getImportFlags 获取导入节点时的参数
+func getImportFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, cli.StringFlag{
+		Name:        "file,f",
+		Destination: &filePath,
+		Usage:       `-导入的节点文件`,
+		Required:    true,
+	})
+	flags = append(flags, coverFlag, debugFlag)
+	return flags
+}
+
+//getMigrateFlags 获取迁移节点时的参数
+func getMigrateFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, fromFlag, toFlag, coverFlag, debugFlag)
+	return flags
+}
+
+//getMirrorFlags 获取同步节点时的参数
+func getMirrorFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, fromFlag, toFlag, debugFlag)
+	flags = append(flags, cli.IntFlag{
+		Name:        "interval,i",
+		Destination: &interval,
+		Value:       60,
+		Usage:       `-全量校对的时间间隔(秒)`,
+	})
+	return flags
+}
+
+var coverFlag = cli.BoolFlag{
+	Name:        "cover,v",
+	Destination: &coverIfExists,
+	Usage:       `-节点已存在时是否覆盖`,
+}
+
+var fromFlag = cli.StringFlag{
+	Name:        "from",
+	Destination: &fromAddr,
+	Usage:       `-源注册中心地址，默认为registry参数指定的地址`,
+}
+
+var toFlag = cli.StringFlag{
+	Name:        "to",
+	Destination: &toAddr,
+	Usage:       `-目标注册中心地址。格式：proto://host。如：redis://ip1,ip2`,
+	Required:    true,
+}
+
+var debugFlag = cli.BoolFlag{
+	Name:        "debug,d",
+	Destination: &global.FlagVal.IsDebug,
+	Usage:       `-调试模式，平台名称将增加_debug后缀`,
+}
@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/global"
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/migrate"
+	"github.com/micro-plat/lib4go/types"
+	"github.com/urfave/cli"
+)
+
+func migrateNow(c *cli.Context) (err error) {
+	//1. 绑定应用程序参数
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return err
+	}
+
+	//2. 复制节点并校验
+	from, to, err := getRegistries()
+	if err != nil {
+		return err
+	}
+	s, err := migrate.Migrate(from, to, getRoot(), coverIfExists)
+	if err != nil {
+		return err
+	}
+	logs.Log.Infof("迁移%d个节点到注册中心:%s(checksum:%s)", len(s.Nodes), toAddr, s.Checksum)
+	return nil
+}
+
+func mirrorNow(c *cli.Context) (err error) {
+	//1. 绑定应用程序参数
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return err
+	}
+
+	//2. 启动同步
+	from, to, err := getRegistries()
+	if err != nil {
+		return err
+	}
+	m := migrate.NewMirror(from, to, getRoot(), time.Duration(interval)*time.Second, global.Current().Log())
+	if err := m.Start(); err != nil {
+		return err
+	}
+	defer m.Close()
+	logs.Log.Infof("开始同步节点:%s => %s", fromAddr, toAddr)
+
+	//3. 等待退出信号
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	<-ch
+	return nil
+}
+
+//getRegistries 获取源注册中心与目标注册中心
+func getRegistries() (r.IRegistry, r.IRegistry, error) {
+	fromAddr = types.GetString(fromAddr, global.Current().GetRegistryAddr())
+	from, err := r.CreateRegistry(fromAddr, global.Current().Log())
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := r.CreateRegistry(toAddr, global.Current().Log())
+	if err != nil {
+		return nil, nil, err
+	}
+	return from, to, nil
+}
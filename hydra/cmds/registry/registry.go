@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"github.com/lib4dev/cli/cmds"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	cmds.RegisterFunc(func() cli.Command {
+		return cli.Command{
+			Name:  "registry",
+			Usage: "注册中心, 导出、导入、迁移注册中心节点",
+			Subcommands: []cli.Command{
+				{
+					Name:   "export",
+					Usage:  "-导出平台下的所有永久节点到文件",
+					Action: exportNow,
+					Flags:  getExportFlags(),
+				},
+				{
+					Name:   "import",
+					Usage:  "-将导出的节点文件导入到注册中心",
+					Action: importNow,
+					Flags:  getImportFlags(),
+				},
+				{
+					Name:   "migrate",
+					Usage:  "-将平台下的所有永久节点复制到另一个注册中心",
+					Action: migrateNow,
+					Flags:  getMigrateFlags(),
+				},
+				{
+					Name:   "mirror",
+					Usage:  "-监控源注册中心的节点变化并持续同步到另一个注册中心",
+					Action: mirrorNow,
+					Flags:  getMirrorFlags(),
+				},
+			},
+		}
+	})
+}
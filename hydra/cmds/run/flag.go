@@ -24,6 +24,11 @@ func getFlags() []cli.Flag {
 		Destination: &global.Def.TracePort,
 		Usage:       `-性能分析服务端口号。用于trace为web模式时的端口号。默认：19999`,
 	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "aport,ap",
+		Destination: &global.Def.AdminPort,
+		Usage:       `-管理服务端口号。设置后启用管理服务，使用服务器的basic或apikey认证配置进行访问验证`,
+	})
 	flags = append(flags, cli.StringFlag{
 		Name:        "mask,msk",
 		Destination: &global.FlagVal.IPMask,
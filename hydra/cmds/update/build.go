@@ -1,6 +1,8 @@
 package update
 
 import (
+	"crypto/ed25519"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -33,9 +35,32 @@ func doBuild(c *cli.Context) (err error) {
 		return nil
 	}
 
-	if err := Archive(path, p, url); err != nil {
+	var key ed25519.PrivateKey
+	switch {
+	case keyPath != "":
+		if key, err = LoadPrivateKey(keyPath); err != nil {
+			return err
+		}
+	case insecure:
+		logs.Log.Warn("未指定签名私钥，生成的安装包无法通过签名验证")
+	default:
+		return fmt.Errorf("未指定签名私钥,请通过--key指定,或使用--insecure生成未签名的安装包")
+	}
+
+	if err := Archive(path, p, url, key); err != nil {
 		return err
 	}
 	logs.Log.Infof("文件已生成到%s%s", p, compatible.SUCCESS)
 	return nil
 }
+
+//生成签名密钥
+func doKeygen(c *cli.Context) (err error) {
+	pub, key, err := GenerateKey(keyDir)
+	if err != nil {
+		return err
+	}
+	logs.Log.Infof("私钥已生成到%s,请妥善保管%s", key, compatible.SUCCESS)
+	logs.Log.Infof("公钥已生成到%s,请分发到各服务器%s", pub, compatible.SUCCESS)
+	return nil
+}
@@ -7,6 +7,14 @@ import (
 
 var url string
 var coverIfExists = false
+var keyPath string
+var pubKey string
+var insecure bool
+var keyDir string
+var batch int
+var healthWait int
+var healthURL string
+var timeout int
 
 //getInstallFlags 获取运行时的参数
 func getInstallFlags() []cli.Flag {
@@ -17,6 +25,16 @@ func getInstallFlags() []cli.Flag {
 		Destination: &url,
 		Usage:       `应用下载地址`,
 	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "pubkey,k",
+		Destination: &pubKey,
+		Usage:       `-受信任的签名公钥，base64编码的公钥或公钥文件路径，默认使用环境变量HYDRA_UPDATE_PUBKEY或~/.hydra/update.pub`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "insecure",
+		Destination: &insecure,
+		Usage:       `-不验证更新包签名，仅检查校验值`,
+	})
 	return flags
 }
 
@@ -34,5 +52,62 @@ func getBuildFlags() []cli.Flag {
 		Destination: &coverIfExists,
 		Usage:       `-文件已存在是否删除`,
 	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "key,k",
+		Destination: &keyPath,
+		Usage:       `-签名私钥文件，由update keygen生成`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "insecure",
+		Destination: &insecure,
+		Usage:       `-不指定签名私钥，生成未签名的安装包`,
+	})
+	return flags
+}
+
+//getKeygenFlags 获取生成签名密钥的参数
+func getKeygenFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:        "out,o",
+			Value:       ".",
+			Destination: &keyDir,
+			Usage:       `-密钥保存目录`,
+		},
+	}
+}
+
+//getRollingFlags 获取滚动升级的参数
+func getRollingFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, cli.StringFlag{
+		Name:        "url,u",
+		Required:    true,
+		Destination: &url,
+		Usage:       `应用下载地址`,
+	})
+	flags = append(flags, cli.IntFlag{
+		Name:        "batch,b",
+		Value:       1,
+		Destination: &batch,
+		Usage:       `-每批同时升级的节点数`,
+	})
+	flags = append(flags, cli.IntFlag{
+		Name:        "wait,w",
+		Value:       30,
+		Destination: &healthWait,
+		Usage:       `-节点重启后等待健康检查的时间(秒)`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "health",
+		Destination: &healthURL,
+		Usage:       `-健康检查地址，以/开头时作为各http服务器的请求路径，未指定时检查各服务器端口是否可访问`,
+	})
+	flags = append(flags, cli.IntFlag{
+		Name:        "timeout,t",
+		Value:       600,
+		Destination: &timeout,
+		Usage:       `-单个节点升级的超时时间(秒)，超时后停止升级并回滚`,
+	})
 	return flags
 }
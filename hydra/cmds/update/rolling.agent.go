@@ -0,0 +1,289 @@
+package update
+
+import (
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	nurl "net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/hydra/servers"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/osext"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//RollingCheckSpan 节点检查滚动升级计划的时间间隔
+var RollingCheckSpan = time.Second * 5
+
+//HealthTimeout 健康检查请求的超时时间
+var HealthTimeout = time.Second * 5
+
+//agent 滚动升级节点代理，领取升级名额、安装更新包、重启后进行健康检查
+type agent struct {
+	r         registry.IRegistry
+	planPath  string
+	nodesPath string
+	node      string
+	servers   *servers.RspServers
+	log       logger.ILogging
+}
+
+//startAgent 服务器启动后检查是否处于升级中，并开始监控升级计划
+func startAgent(s *servers.RspServers, startErr error) {
+	if registry.GetProto(global.Def.RegistryAddr) == registry.LocalMemory {
+		return
+	}
+	log := global.Def.Log()
+	r, err := registry.GetRegistry(global.Def.RegistryAddr, log)
+	if err != nil {
+		log.Warnf("滚动升级代理未启动:%v", err)
+		return
+	}
+	a := &agent{
+		r:         r,
+		planPath:  GetPlanPath(global.Def.PlatName, global.Def.SysName, global.Def.ClusterName),
+		nodesPath: GetNodesPath(global.Def.PlatName, global.Def.SysName, global.Def.ClusterName),
+		node:      getNodeName(),
+		servers:   s,
+		log:       log,
+	}
+	if err := a.register(); err != nil {
+		log.Warnf("滚动升级代理未启动:%v", err)
+		return
+	}
+	a.checkStartup(startErr)
+	go a.loop()
+}
+
+//register 发布当前节点及版本号
+func (a *agent) register() error {
+	buff, _ := json.Marshal(map[string]string{"version": global.Version, "ip": global.LocalIP()})
+	path := registry.Join(a.nodesPath, a.node)
+
+	//重启前的临时节点可能尚未过期
+	if ok, _ := a.r.Exists(path); ok {
+		a.r.Delete(path)
+	}
+	return a.r.CreateTempNode(path, string(buff))
+}
+
+//checkStartup 升级后重启的节点，启动失败或健康检查失败时回滚
+func (a *agent) checkStartup(startErr error) {
+	plan, _, err := getPlan(a.r, a.planPath)
+	if err != nil || plan == nil || plan.Status != PlanRunning {
+		return
+	}
+	if _, ok := plan.Upgrading[a.node]; !ok {
+		return
+	}
+	if startErr != nil {
+		a.fail(fmt.Errorf("启动失败:%w", startErr), true)
+		return
+	}
+	if global.Version != plan.Version {
+		a.fail(fmt.Errorf("版本号%s与升级版本%s不一致", global.Version, plan.Version), false)
+		return
+	}
+	go func() {
+		select {
+		case <-time.After(time.Duration(plan.HealthWait) * time.Second):
+		case <-global.Current().ClosingNotify():
+			return
+		}
+		if err := a.health(plan.HealthURL); err != nil {
+			a.fail(err, true)
+			return
+		}
+		if _, err := updatePlan(a.r, a.planPath, func(p *Plan) bool {
+			if _, ok := p.Upgrading[a.node]; !ok {
+				return false
+			}
+			p.Complete(a.node)
+			return true
+		}); err != nil {
+			a.log.Errorf("修改升级计划失败:%v", err)
+			return
+		}
+		a.log.Infof("升级到%s完成,健康检查通过", global.Version)
+	}()
+}
+
+//health 所有服务器均已启动且服务地址可访问，指定了完整的健康检查地址时该地址需返回成功
+func (a *agent) health(healthURL string) error {
+	started := a.servers.GetServers()
+	for _, tp := range global.Def.ServerTypes {
+		s, ok := started[tp]
+		if !ok {
+			return fmt.Errorf("健康检查失败,%s服务器未启动", tp)
+		}
+		i, ok := s.(servers.IInspectServer)
+		if !ok {
+			continue
+		}
+		if err := probe(types.GetString(i.Inspect()["address"]), healthURL); err != nil {
+			return fmt.Errorf("健康检查失败,%s服务器%w", tp, err)
+		}
+	}
+	if strings.HasPrefix(healthURL, "http://") || strings.HasPrefix(healthURL, "https://") {
+		if err := probeHTTP(healthURL, true); err != nil {
+			return fmt.Errorf("健康检查失败,%w", err)
+		}
+	}
+	return nil
+}
+
+//probe 检查服务地址，http服务器请求健康检查路径，rpc服务器检查端口是否可连接，其它服务器不检查
+func probe(address string, healthURL string) error {
+	u, err := nurl.Parse(address)
+	if err != nil {
+		return fmt.Errorf("地址%s有误:%w", address, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if strings.HasPrefix(healthURL, "/") {
+			return probeHTTP(u.Scheme+"://"+u.Host+healthURL, true)
+		}
+		return probeHTTP(u.Scheme+"://"+u.Host, false)
+	case "tcp":
+		conn, err := net.DialTimeout("tcp", u.Host, HealthTimeout)
+		if err != nil {
+			return fmt.Errorf("无法连接%s:%w", address, err)
+		}
+		conn.Close()
+	}
+	return nil
+}
+
+//probeHTTP 请求地址，success为true时要求返回2xx，否则服务器未返回5xx即可
+func probeHTTP(addr string, success bool) error {
+	client := &http.Client{
+		Timeout:   HealthTimeout,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	resp, err := client.Get(addr)
+	if err != nil {
+		return fmt.Errorf("请求%s失败:%w", addr, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError || success && resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("请求%s返回%d", addr, resp.StatusCode)
+	}
+	return nil
+}
+
+func (a *agent) loop() {
+	tk := time.NewTicker(RollingCheckSpan)
+	defer tk.Stop()
+	for {
+		select {
+		case <-global.Current().ClosingNotify():
+			return
+		case <-tk.C:
+			if err := a.check(); err != nil {
+				a.log.Errorf("滚动升级失败:%v", err)
+			}
+		}
+	}
+}
+
+//check 计划停止时回滚已升级的节点，计划执行中时领取名额并升级
+func (a *agent) check() error {
+	plan, _, err := getPlan(a.r, a.planPath)
+	if err != nil || plan == nil {
+		return err
+	}
+	if plan.NeedRollback(a.node) {
+		return a.rollback(plan.Error)
+	}
+	if plan.Status != PlanRunning || global.Version == plan.Version || plan.Has(a.node) {
+		return nil
+	}
+	acquired := false
+	if _, err := updatePlan(a.r, a.planPath, func(p *Plan) bool {
+		acquired = global.Version != p.Version && p.Acquire(a.node)
+		return acquired
+	}); err != nil || !acquired {
+		return err
+	}
+	a.log.Infof("开始滚动升级:%s->%s", global.Version, plan.Version)
+	if err := a.install(plan); err != nil {
+		a.fail(err, false)
+		return err
+	}
+	a.log.Info("更新包已安装，重启服务")
+	appRestart()
+	return nil
+}
+
+func (a *agent) install(plan *Plan) error {
+	pub, err := LoadPublicKey("")
+	if err != nil {
+		return err
+	}
+	pkg, err := GetPackage(plan.URL)
+	if err != nil {
+		return err
+	}
+	if pkg.Version != plan.Version {
+		return fmt.Errorf("更新包版本号%s与升级版本%s不一致", pkg.Version, plan.Version)
+	}
+	_, err = pkg.Install(pub, a.log)
+	return err
+}
+
+//fail 当前节点升级失败，停止升级，已安装新版本时回滚
+func (a *agent) fail(err error, installed bool) {
+	a.log.Errorf("升级失败,准备回滚:%v", err)
+	if _, uerr := updatePlan(a.r, a.planPath, func(p *Plan) bool {
+		if _, ok := p.Upgrading[a.node]; !ok {
+			return false
+		}
+		p.Fail(a.node, err)
+		return true
+	}); uerr != nil {
+		a.log.Errorf("修改升级计划失败:%v", uerr)
+	}
+	if installed {
+		a.rollback(err.Error())
+	}
+}
+
+//rollback 恢复升级前的应用目录并重启
+func (a *agent) rollback(reason string) error {
+	if _, err := updatePlan(a.r, a.planPath, func(p *Plan) bool {
+		if contains(p.RolledBack, a.node) {
+			return false
+		}
+		p.RolledBack = append(p.RolledBack, a.node)
+		return true
+	}); err != nil {
+		return err
+	}
+	a.log.Warnf("升级已停止(%s),回滚当前节点", reason)
+	u, err := newUpdater(nil, "")
+	if err != nil {
+		return err
+	}
+	u.needRollback = true
+	if err := u.Rollback(); err != nil {
+		return fmt.Errorf("回滚失败:%w", err)
+	}
+	appRestart()
+	return nil
+}
+
+//getNodeName 获取节点名称，由IP与应用目录组成，重启后保持不变
+func getNodeName() string {
+	path, _ := osext.Executable()
+	sum := md5.Sum([]byte(filepath.Dir(path)))
+	return fmt.Sprintf("%s_%s", global.LocalIP(), hex.EncodeToString(sum[:])[:8])
+}
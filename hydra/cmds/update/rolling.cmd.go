@@ -0,0 +1,137 @@
+package update
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/urfave/cli"
+)
+
+//doRolling 发布滚动升级计划并等待所有节点升级完成
+func doRolling(c *cli.Context) (err error) {
+	//1. 绑定应用程序参数
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		logs.Log.Error(err)
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return nil
+	}
+
+	//2. 获取安装包信息，滚动升级的安装包必须签名
+	pkg, err := GetPackage(url)
+	if err != nil {
+		return err
+	}
+	if pkg.Sign == "" {
+		return fmt.Errorf("安装包未签名,请使用update build --key重新打包")
+	}
+
+	//3. 发布升级计划
+	r, err := registry.GetRegistry(global.Def.RegistryAddr, global.Def.Log())
+	if err != nil {
+		return err
+	}
+	path := GetPlanPath(global.Def.PlatName, global.Def.SysName, global.Def.ClusterName)
+	if err := publishPlan(r, path, NewPlan(url, pkg.Version, batch, healthWait, healthURL, timeout)); err != nil {
+		return err
+	}
+	logs.Log.Infof("已发布升级计划:%s(版本:%s,每批%d个节点)", path, pkg.Version, batch)
+
+	//4. 等待升级完成
+	return waitPlan(r, path)
+}
+
+func publishPlan(r registry.IRegistry, path string, plan *Plan) error {
+	old, version, err := getPlan(r, path)
+	if err != nil {
+		return err
+	}
+	if old != nil && old.Status == PlanRunning {
+		return fmt.Errorf("集群正在升级到%s,请等待完成", old.Version)
+	}
+	buff, err := json.Marshal(plan)
+	if err != nil {
+		return err
+	}
+	ok, err := r.Exists(path)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return r.CreatePersistentNode(path, string(buff))
+	}
+	return r.UpdateIfVersion(path, string(buff), version)
+}
+
+//waitPlan 检查升级超时的节点，所有节点升级完成或升级停止时退出
+func waitPlan(r registry.IRegistry, path string) error {
+	nodesPath := GetNodesPath(global.Def.PlatName, global.Def.SysName, global.Def.ClusterName)
+	last := ""
+	for {
+		time.Sleep(RollingCheckSpan)
+		plan, err := updatePlan(r, path, func(p *Plan) bool {
+			expired := p.GetExpired(time.Now())
+			for _, node := range expired {
+				p.Fail(node, fmt.Errorf("升级超时"))
+			}
+			return len(expired) > 0
+		})
+		if err != nil {
+			return err
+		}
+		if plan == nil {
+			return fmt.Errorf("升级计划已被删除")
+		}
+		if plan.Status == PlanHalted {
+			logs.Log.Errorf("升级已停止并回滚:%s", plan.Error)
+			return fmt.Errorf("%s", plan.Error)
+		}
+		total, upgraded, err := getNodes(r, nodesPath, plan.Version)
+		if err != nil {
+			return err
+		}
+		if current := fmt.Sprintf("%d/%d", upgraded, total); current != last {
+			last = current
+			logs.Log.Infof("升级进度:%s,升级中:%d", current, len(plan.Upgrading))
+		}
+		if total > 0 && upgraded == total && len(plan.Upgrading) == 0 {
+			if _, err := updatePlan(r, path, func(p *Plan) bool {
+				p.Status = PlanDone
+				return true
+			}); err != nil {
+				return err
+			}
+			logs.Log.Infof("所有节点已升级到%s", plan.Version)
+			return nil
+		}
+	}
+}
+
+//getNodes 获取节点总数及已升级到version的节点数
+func getNodes(r registry.IRegistry, path string, version string) (total int, upgraded int, err error) {
+	ok, err := r.Exists(path)
+	if err != nil || !ok {
+		return 0, 0, err
+	}
+	names, _, err := r.GetChildren(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, name := range names {
+		buff, _, err := r.GetValue(registry.Join(path, name))
+		if err != nil {
+			continue
+		}
+		total++
+		info := map[string]string{}
+		json.Unmarshal(buff, &info)
+		if info["version"] == version {
+			upgraded++
+		}
+	}
+	return total, upgraded, nil
+}
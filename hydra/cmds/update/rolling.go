@@ -0,0 +1,183 @@
+package update
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/micro-plat/hydra/registry"
+)
+
+const (
+	//PlanRunning 升级中
+	PlanRunning = "running"
+
+	//PlanHalted 健康检查失败或超时，已停止升级并回滚
+	PlanHalted = "halted"
+
+	//PlanDone 所有节点升级完成
+	PlanDone = "done"
+)
+
+//Plan 滚动升级计划，保存在注册中心，各节点按批次领取并升级
+type Plan struct {
+	URL        string           `json:"url"`
+	Version    string           `json:"version"`
+	Batch      int              `json:"batch"`
+	HealthWait int              `json:"health_wait"`
+	HealthURL  string           `json:"health_url,omitempty"`
+	Timeout    int              `json:"timeout"`
+	Status     string           `json:"status"`
+	Upgrading  map[string]int64 `json:"upgrading"`
+	Done       []string         `json:"done"`
+	Failed     []string         `json:"failed"`
+	RolledBack []string         `json:"rolled_back"`
+	Error      string           `json:"error,omitempty"`
+	Created    int64            `json:"created"`
+}
+
+//NewPlan 构建滚动升级计划，healthURL为节点重启后的健康检查地址，以/开头时作为各http服务器的请求路径
+func NewPlan(url string, version string, batch int, healthWait int, healthURL string, timeout int) *Plan {
+	if batch <= 0 {
+		batch = 1
+	}
+	return &Plan{
+		URL:        url,
+		Version:    version,
+		Batch:      batch,
+		HealthWait: healthWait,
+		HealthURL:  healthURL,
+		Timeout:    timeout,
+		Status:     PlanRunning,
+		Upgrading:  make(map[string]int64),
+		Done:       make([]string, 0, 1),
+		Failed:     make([]string, 0, 1),
+		RolledBack: make([]string, 0, 1),
+		Created:    time.Now().Unix(),
+	}
+}
+
+//Acquire 领取升级名额，同时升级的节点数不超过Batch
+func (p *Plan) Acquire(node string) bool {
+	if p.Status != PlanRunning || len(p.Upgrading) >= p.Batch || p.Has(node) {
+		return false
+	}
+	p.Upgrading[node] = time.Now().Unix()
+	return true
+}
+
+//Complete 节点升级并通过健康检查
+func (p *Plan) Complete(node string) {
+	delete(p.Upgrading, node)
+	p.Done = append(p.Done, node)
+}
+
+//Fail 节点升级失败，停止升级
+func (p *Plan) Fail(node string, err error) {
+	delete(p.Upgrading, node)
+	p.Failed = append(p.Failed, node)
+	p.Halt(fmt.Errorf("%s:%w", node, err))
+}
+
+//Halt 停止升级，已升级的节点回滚
+func (p *Plan) Halt(err error) {
+	if p.Status == PlanRunning {
+		p.Status = PlanHalted
+		p.Error = err.Error()
+	}
+}
+
+//Has 节点是否已领取、完成或失败
+func (p *Plan) Has(node string) bool {
+	if _, ok := p.Upgrading[node]; ok {
+		return true
+	}
+	return contains(p.Done, node) || contains(p.Failed, node)
+}
+
+//NeedRollback 计划已停止且节点已升级尚未回滚
+func (p *Plan) NeedRollback(node string) bool {
+	if p.Status != PlanHalted || contains(p.RolledBack, node) {
+		return false
+	}
+	_, upgrading := p.Upgrading[node]
+	return upgrading || contains(p.Done, node)
+}
+
+//GetExpired 获取升级超时的节点
+func (p *Plan) GetExpired(now time.Time) []string {
+	nodes := make([]string, 0, 1)
+	for node, start := range p.Upgrading {
+		if p.Timeout > 0 && now.Unix()-start > int64(p.Timeout) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+//GetPlanPath 获取集群滚动升级计划的路径
+func GetPlanPath(platName string, sysName string, clusterName string) string {
+	return registry.Join(platName, sysName, "upgrade", clusterName)
+}
+
+//GetNodesPath 获取参与滚动升级的节点路径
+func GetNodesPath(platName string, sysName string, clusterName string) string {
+	return registry.Join(GetPlanPath(platName, sysName, clusterName), "nodes")
+}
+
+//getPlan 获取升级计划及版本号，计划不存在时返回nil
+func getPlan(r registry.IRegistry, path string) (*Plan, int32, error) {
+	ok, err := r.Exists(path)
+	if err != nil || !ok {
+		return nil, 0, err
+	}
+	buff, version, err := r.GetValue(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(buff) == 0 {
+		return nil, version, nil
+	}
+	p := &Plan{}
+	if err := json.Unmarshal(buff, p); err != nil {
+		return nil, 0, fmt.Errorf("升级计划%s格式有误:%w", path, err)
+	}
+	if p.Upgrading == nil {
+		p.Upgrading = make(map[string]int64)
+	}
+	return p, version, nil
+}
+
+//updatePlan 获取最新的升级计划并修改，版本号冲突时重试。
+//依赖注册中心的UpdateIfVersion按数据版本号原子更新(zookeeper使用节点版本号)，保证多个节点不会同时领取同一名额
+func updatePlan(r registry.IRegistry, path string, f func(p *Plan) bool) (*Plan, error) {
+	for i := 0; i < 10; i++ {
+		p, version, err := getPlan(r, path)
+		if err != nil || p == nil {
+			return p, err
+		}
+		if !f(p) {
+			return p, nil
+		}
+		buff, err := json.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		err = r.UpdateIfVersion(path, string(buff), version)
+		if errors.Is(err, registry.ErrVersionConflict) {
+			continue
+		}
+		return p, err
+	}
+	return nil, fmt.Errorf("修改升级计划%s失败:%w", path, registry.ErrVersionConflict)
+}
@@ -1,6 +1,10 @@
 package update
 
 import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -8,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/micro-plat/lib4go/security/crc32"
 
@@ -20,9 +25,14 @@ import (
 	"github.com/micro-plat/lib4go/sysinfo/pipes"
 )
 
+//DownloadTimeout 下载package信息与更新包的超时时间
+var DownloadTimeout = time.Minute * 5
+
+var client = &http.Client{Timeout: DownloadTimeout}
+
 //GetPackage 获取文件package信息
 func GetPackage(url string) (*Package, error) {
-	resp, err := http.Get(url)
+	resp, err := client.Get(url)
 	if err != nil || resp == nil {
 		err = fmt.Errorf("无法下载package更新包:%s %v", url, err)
 		return nil, err
@@ -61,6 +71,8 @@ type Package struct {
 	URL     string `json:"url,omitempty" valid:"url,required"`
 	Version string `json:"version" valid:"ascii,required"`
 	CRC32   uint32 `json:"crc32" valid:"required"`
+	SHA256  string `json:"sha256,omitempty"`
+	Sign    string `json:"sign,omitempty"`
 }
 
 //NewPackage 构建CRON任务
@@ -72,6 +84,36 @@ func NewPackage(url string, version string, crc32 uint32) *Package {
 	}
 }
 
+//SignBy 使用私钥对版本号、下载地址与更新包的sha256签名
+func (p *Package) SignBy(key ed25519.PrivateKey) {
+	p.Sign = base64.StdEncoding.EncodeToString(ed25519.Sign(key, p.getSignData()))
+}
+
+//Verify 验证更新包内容与签名，pub为nil时仅检查校验值
+func (p *Package) Verify(pub ed25519.PublicKey, buff []byte) error {
+	if p.CRC32 > 0 {
+		if v := crc32.Encrypt(buff); v != p.CRC32 {
+			return fmt.Errorf("文件校验值有误当前[%d]%d", v, p.CRC32)
+		}
+	}
+	if pub == nil {
+		return nil
+	}
+	sum := sha256.Sum256(buff)
+	if p.SHA256 != hex.EncodeToString(sum[:]) {
+		return fmt.Errorf("更新包sha256与package不一致")
+	}
+	sign, err := base64.StdEncoding.DecodeString(p.Sign)
+	if err != nil || !ed25519.Verify(pub, p.getSignData(), sign) {
+		return fmt.Errorf("更新包签名验证失败")
+	}
+	return nil
+}
+
+func (p *Package) getSignData() []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%s", p.Version, p.URL, p.SHA256))
+}
+
 //Check 是否需要更新
 func (p *Package) Check() (bool, error) {
 	if p.Version >= global.Version {
@@ -80,10 +122,20 @@ func (p *Package) Check() (bool, error) {
 	return false, fmt.Errorf("更新的版本号%s不能低于当前应用的版本号%s", p.Version, global.Version)
 }
 
-//Update 更新当前服务
-func (p *Package) Update(logger logger.ILogging, closeFunc func()) (err error) {
+//Update 更新当前服务，pub为受信任的公钥，为nil时不验证签名
+func (p *Package) Update(pub ed25519.PublicKey, logger logger.ILogging, closeFunc func()) (err error) {
+	if _, err = p.Install(pub, logger); err != nil {
+		return err
+	}
+	logger.Info("更新成功，停止所有服务，并准备重启")
+	closeFunc()
+	return restart()
+}
+
+//Install 下载并验证更新包后替换当前应用目录，失败时自动回滚
+func (p *Package) Install(pub ed25519.PublicKey, logger logger.ILogging) (u *updater, err error) {
 	logger.Info("开始下载更新包:", p.URL)
-	resp, err := http.Get(p.URL)
+	resp, err := client.Get(p.URL)
 	if err != nil || resp == nil {
 		err = fmt.Errorf("无法下载更新包:%s", p.URL)
 		return
@@ -97,23 +149,21 @@ func (p *Package) Update(logger logger.ILogging, closeFunc func()) (err error) {
 		err = fmt.Errorf("无法读取更新包长度:%d", resp.ContentLength)
 		return
 	}
-	updater, err := newUpdater(p.CRC32, filepath.Base(p.URL))
+	u, err = newUpdater(func(buff []byte) error { return p.Verify(pub, buff) }, filepath.Base(p.URL))
 	if err != nil {
 		err = fmt.Errorf("无法创建updater:%v", err)
 		return
 	}
-	err = updater.Apply(resp.Body)
+	err = u.Apply(resp.Body)
 	if err != nil {
-		if err1 := updater.Rollback(); err1 != nil {
+		if err1 := u.Rollback(); err1 != nil {
 			err = fmt.Errorf("更新失败%+v,回滚失败%v", err, err1)
 			return
 		}
 		err = fmt.Errorf("更新失败,已回滚(err:%v)", err)
 		return
 	}
-	logger.Info("更新成功，停止所有服务，并准备重启")
-	closeFunc()
-	return restart()
+	return u, nil
 }
 
 //Decode 将package信息保存到文件
@@ -135,8 +185,8 @@ func (p *Package) Decode(path string) error {
 	return nil
 }
 
-//Archive 生成压缩文件
-func Archive(source string, destination string, url string) (err error) {
+//Archive 生成压缩文件，key不为nil时对更新包签名
+func Archive(source string, destination string, url string, key ed25519.PrivateKey) (err error) {
 
 	//创建压缩包
 	rpath := filepath.Join(destination, filepath.Base(source)+".zip")
@@ -163,6 +213,11 @@ func Archive(source string, destination string, url string) (err error) {
 
 	//生成pkg文件
 	pkg := NewPackage(url, v, crc32.Encrypt(buff))
+	sum := sha256.Sum256(buff)
+	pkg.SHA256 = hex.EncodeToString(sum[:])
+	if key != nil {
+		pkg.SignBy(key)
+	}
 	if err := pkg.Decode(filepath.Join(destination, "package.json")); err != nil {
 		return err
 	}
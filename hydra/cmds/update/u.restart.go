@@ -1,13 +1,49 @@
 package update
 
 import (
+	"fmt"
 	"os"
 	"strings"
+	"sync/atomic"
 
+	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/global/compatible"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/osext"
 	"github.com/micro-plat/lib4go/ps"
 )
 
+//restarting 服务退出后是否重新启动
+var restarting int32
+
+//appExec 启动可执行文件，测试时替换
+var appExec = compatible.AppExec
+
+//appRestart 关闭所有服务并释放资源，退出后使用已安装的可执行文件重新启动
+func appRestart() {
+	atomic.StoreInt32(&restarting, 1)
+	compatible.AppClose()
+}
+
+//doRestart 服务退出后以相同参数启动可执行文件。
+//linux、darwin下替换当前进程，进程号不变，由systemd等托管时无需额外配置；
+//windows下启动新进程后当前进程退出，作为windows服务运行时需由服务管理器的恢复选项重启
+func doRestart() {
+	if atomic.LoadInt32(&restarting) == 0 {
+		return
+	}
+	path, err := osext.Executable()
+	if err != nil {
+		global.Def.Log().Errorf("重启失败,无法获取可执行文件:%v", err)
+		return
+	}
+	global.Def.Log().Info("重新启动:", path)
+	logger.Close()
+	if err := appExec(path, os.Args); err != nil {
+		fmt.Fprintf(os.Stderr, "重启失败:%v\n", err)
+	}
+}
+
 //Restart 重启当前服务
 func restart() (err error) {
 	var name string
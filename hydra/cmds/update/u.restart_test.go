@@ -0,0 +1,30 @@
+package update
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/micro-plat/hydra/global/compatible"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/osext"
+)
+
+func TestDoRestart(t *testing.T) {
+	var path string
+	var args []string
+	appExec = func(p string, a []string) error {
+		path, args = p, a
+		return nil
+	}
+	defer func() { appExec = compatible.AppExec; atomic.StoreInt32(&restarting, 0) }()
+
+	doRestart()
+	assert.Equal(t, "", path, "1. 未升级时退出不重启")
+
+	atomic.StoreInt32(&restarting, 1)
+	doRestart()
+	exe, _ := osext.Executable()
+	assert.Equal(t, exe, path, "2. 升级后使用已安装的可执行文件重启")
+	assert.Equal(t, os.Args, args, "2. 重启时保持启动参数")
+}
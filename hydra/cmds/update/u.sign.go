@@ -0,0 +1,79 @@
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//PubKeyEnv 受信任的更新包签名公钥，值为base64编码的公钥或公钥文件路径
+const PubKeyEnv = "HYDRA_UPDATE_PUBKEY"
+
+//PubKeyFile 未指定公钥时使用的默认公钥文件
+var PubKeyFile = filepath.Join(getHomeDir(), ".hydra", "update.pub")
+
+//GenerateKey 生成更新包签名密钥，私钥保存为update.key，公钥保存为update.pub
+func GenerateKey(dir string) (pubPath string, keyPath string, err error) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+	keyPath = filepath.Join(dir, "update.key")
+	pubPath = filepath.Join(dir, "update.pub")
+	if err := ioutil.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(key)), 0600); err != nil {
+		return "", "", fmt.Errorf("保存私钥%s失败:%w", keyPath, err)
+	}
+	if err := ioutil.WriteFile(pubPath, []byte(base64.StdEncoding.EncodeToString(pub)), 0644); err != nil {
+		return "", "", fmt.Errorf("保存公钥%s失败:%w", pubPath, err)
+	}
+	return pubPath, keyPath, nil
+}
+
+//LoadPrivateKey 从文件加载签名私钥
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取私钥%s失败:%w", path, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(buff)))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("私钥%s格式有误", path)
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+//LoadPublicKey 加载受信任的公钥，s为base64编码的公钥或公钥文件路径，为空时依次使用环境变量与默认公钥文件
+func LoadPublicKey(s string) (ed25519.PublicKey, error) {
+	if s == "" {
+		s = os.Getenv(PubKeyEnv)
+	}
+	if s == "" {
+		if _, err := os.Stat(PubKeyFile); err != nil {
+			return nil, fmt.Errorf("未指定受信任的公钥(%s或%s)", PubKeyEnv, PubKeyFile)
+		}
+		s = PubKeyFile
+	}
+	if buff, err := ioutil.ReadFile(s); err == nil {
+		s = string(buff)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("公钥格式有误")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+func getHomeDir() string {
+	if dir, err := os.UserHomeDir(); err == nil {
+		return dir
+	}
+	return "."
+}
@@ -0,0 +1,90 @@
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/security/crc32"
+)
+
+func TestPackageVerify(t *testing.T) {
+	pubPath, keyPath, err := GenerateKey(t.TempDir())
+	assert.Equal(t, nil, err, "1. 生成密钥")
+	key, err := LoadPrivateKey(keyPath)
+	assert.Equal(t, nil, err, "2. 加载私钥")
+	pub, err := LoadPublicKey(pubPath)
+	assert.Equal(t, nil, err, "3. 加载公钥")
+
+	buff := []byte("hydra-package")
+	pkg := NewPackage("http://127.0.0.1/hydra.zip", "1.0.1", crc32.Encrypt(buff))
+	pkg.SHA256 = "3d6d2b8ddc3f7ec4d4e3d0ee0b2e2b4a8f1b2a4a0b8e0d0f4b3e1c0e9a8d7c6b"
+	pkg.SignBy(key)
+	assert.NotEqual(t, nil, pkg.Verify(pub, buff), "4. sha256不一致")
+
+	_, keyPath2, _ := GenerateKey(t.TempDir())
+	key2, _ := LoadPrivateKey(keyPath2)
+	pkg = signedPackage(buff, key2)
+	assert.NotEqual(t, nil, pkg.Verify(pub, buff), "5. 非受信任的私钥签名")
+
+	pkg = signedPackage(buff, key)
+	assert.Equal(t, nil, pkg.Verify(pub, buff), "6. 签名验证通过")
+	pkg.Version = "1.0.2"
+	assert.NotEqual(t, nil, pkg.Verify(pub, buff), "7. 修改版本号后签名验证失败")
+	assert.Equal(t, nil, pkg.Verify(nil, buff), "8. 未指定公钥时仅检查校验值")
+}
+
+func TestPlan(t *testing.T) {
+	p := NewPlan("http://127.0.0.1/package.json", "1.0.1", 1, 30, "", 60)
+	assert.Equal(t, true, p.Acquire("a"), "1. 领取名额")
+	assert.Equal(t, false, p.Acquire("b"), "2. 超过每批节点数")
+	p.Complete("a")
+	assert.Equal(t, true, p.Acquire("b"), "3. 上一批完成后领取")
+	assert.Equal(t, false, p.Acquire("a"), "4. 已升级的节点不再领取")
+	p.Upgrading["b"] = time.Now().Add(-time.Minute * 2).Unix()
+	assert.Equal(t, []string{"b"}, p.GetExpired(time.Now()), "5. 升级超时")
+	p.Fail("b", errTest)
+	assert.Equal(t, PlanHalted, p.Status, "6. 失败后停止升级")
+	assert.Equal(t, true, p.NeedRollback("a"), "7. 已升级的节点回滚")
+	assert.Equal(t, false, p.NeedRollback("b"), "7. 失败的节点在失败时回滚")
+	assert.Equal(t, false, p.Acquire("c"), "8. 停止后不再领取")
+}
+
+var errTest = errors.New("健康检查失败")
+
+func signedPackage(buff []byte, key ed25519.PrivateKey) *Package {
+	pkg := NewPackage("http://127.0.0.1/hydra.zip", "1.0.1", crc32.Encrypt(buff))
+	sum := sha256.Sum256(buff)
+	pkg.SHA256 = hex.EncodeToString(sum[:])
+	pkg.SignBy(key)
+	return pkg
+}
+
+func TestProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	assert.Equal(t, nil, probe(srv.URL, ""), "1. http服务器可访问")
+	assert.Equal(t, nil, probe(srv.URL, "/health"), "2. 健康检查路径返回成功")
+	assert.NotEqual(t, nil, probe(srv.URL, "/none"), "3. 健康检查路径返回失败")
+
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := l.Addr().String()
+	assert.Equal(t, nil, probe("tcp://"+addr, ""), "4. rpc端口可连接")
+	l.Close()
+	assert.NotEqual(t, nil, probe("tcp://"+addr, ""), "5. rpc端口不可连接")
+	assert.NotEqual(t, nil, probe("http://%zz", ""), "6. 地址错误")
+	assert.Equal(t, nil, probe("cron://127.0.0.1", ""), "7. 其它服务器不检查")
+}
@@ -9,7 +9,6 @@ import (
 
 	"github.com/mholt/archiver"
 	"github.com/micro-plat/lib4go/osext"
-	"github.com/micro-plat/lib4go/security/crc32"
 )
 
 type updater struct {
@@ -17,7 +16,7 @@ type updater struct {
 	currentDir   string
 	newDir       string
 	oldDir       string
-	CRC32        uint32
+	verify       func([]byte) error
 	targetName   string
 	tmpPath      string
 	needRollback bool
@@ -40,8 +39,8 @@ type updater struct {
 
 // }
 
-func newUpdater(crc32 uint32, targetName string) (u *updater, err error) {
-	u = &updater{CRC32: crc32, targetName: targetName}
+func newUpdater(verify func([]byte) error, targetName string) (u *updater, err error) {
+	u = &updater{verify: verify, targetName: targetName}
 	u.targetPath, err = osext.Executable()
 	if err != nil {
 		return nil, err
@@ -59,10 +58,9 @@ func (u *updater) Apply(update io.Reader) (err error) {
 	if buff, err = ioutil.ReadAll(update); err != nil {
 		return err
 	}
-	if u.CRC32 > 0 {
-		if v := crc32.Encrypt(buff); v != u.CRC32 {
-			err = fmt.Errorf("文件校验值有误当前[%d]%d", v, u.CRC32)
-			return
+	if u.verify != nil {
+		if err = u.verify(buff); err != nil {
+			return err
 		}
 	}
 	if err := u.write2Tmp(buff); err != nil {
@@ -1,13 +1,18 @@
 package update
 
 import (
+	"crypto/ed25519"
+
 	"github.com/lib4dev/cli/cmds"
 	logs "github.com/lib4dev/cli/logger"
 	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/hydra/cmds/pkgs"
 	"github.com/urfave/cli"
 )
 
 func init() {
+	pkgs.OnStarted(startAgent)
+	pkgs.OnExited(doRestart)
 	cmds.RegisterFunc(func() cli.Command {
 		return cli.Command{
 			Name:  "update",
@@ -23,6 +28,16 @@ func init() {
 					Usage:  "打包安装包。创建压缩包，生成安装配置",
 					Flags:  getBuildFlags(),
 					Action: doBuild,
+				}, {
+					Name:   "keygen",
+					Usage:  "生成安装包签名密钥。私钥用于打包，公钥用于安装时验证",
+					Flags:  getKeygenFlags(),
+					Action: doKeygen,
+				}, {
+					Name:   "rolling",
+					Usage:  "滚动升级集群。按批次升级节点，健康检查失败时停止升级并回滚",
+					Flags:  getRollingFlags(),
+					Action: doRolling,
 				},
 			},
 		}
@@ -50,11 +65,27 @@ func doUpdate(c *cli.Context) (err error) {
 		return err
 	}
 
-	//4.立即更新
-	if err = pkg.Update(logs.Log, func() {
+	//4.加载受信任的公钥
+	pub, err := getPublicKey()
+	if err != nil {
+		logs.Log.Errorf("更新失败：%v", err)
+		return err
+	}
+
+	//5.立即更新
+	if err = pkg.Update(pub, logs.Log, func() {
 		//关闭服务器
 	}); err != nil {
 		return err
 	}
 	return nil
 }
+
+//getPublicKey 获取受信任的公钥，指定insecure时不验证签名
+func getPublicKey() (ed25519.PublicKey, error) {
+	if insecure {
+		logs.Log.Warn("未验证更新包签名")
+		return nil, nil
+	}
+	return LoadPublicKey(pubKey)
+}
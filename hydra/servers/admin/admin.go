@@ -0,0 +1,113 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/micro-plat/hydra/hydra/servers"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//Server 管理服务，用于查看运行时状态并进行暂停、恢复、日志级别调整等操作
+type Server struct {
+	port    string
+	servers *servers.RspServers
+	server  *http.Server
+	log     logger.ILogger
+}
+
+//New 构建管理服务
+func New(port string, rsp *servers.RspServers) *Server {
+	s := &Server{
+		port:    port,
+		servers: rsp,
+		log:     logger.New("admin"),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/confs", s.handle(http.MethodGet, s.confs))
+	mux.HandleFunc("/admin/routes", s.handle(http.MethodGet, s.routes))
+	mux.HandleFunc("/admin/servers", s.handle(http.MethodGet, s.inspect))
+	mux.HandleFunc("/admin/servers/pause", s.handle(http.MethodPost, s.pause))
+	mux.HandleFunc("/admin/servers/resume", s.handle(http.MethodPost, s.resume))
+	mux.HandleFunc("/admin/tasks", s.handle("", s.tasks))
+	mux.HandleFunc("/admin/tasks/pause", s.handle(http.MethodPost, s.pauseTask))
+	mux.HandleFunc("/admin/tasks/resume", s.handle(http.MethodPost, s.resumeTask))
+	mux.HandleFunc("/admin/tasks/delete", s.handle(http.MethodPost, s.deleteTask))
+	mux.HandleFunc("/admin/jobs", s.handle(http.MethodGet, s.job))
+	mux.HandleFunc("/admin/jobs/cancel", s.handle(http.MethodPost, s.cancelJob))
+	mux.HandleFunc("/admin/components", s.handle(http.MethodGet, s.components))
+	mux.HandleFunc("/admin/logger/level", s.handle("", s.level))
+	s.server = &http.Server{
+		Addr:    fmt.Sprintf("0.0.0.0:%s", port),
+		Handler: mux,
+	}
+	return s
+}
+
+//Start 启动管理服务
+func (s *Server) Start() error {
+	if _, err := strconv.ParseInt(s.port, 10, 32); err != nil {
+		return fmt.Errorf("参数：aport/ap错误：%w", err)
+	}
+	errChan := make(chan error, 1)
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChan <- fmt.Errorf("启动管理服务错误：%w", err)
+		}
+	}()
+	select {
+	case err := <-errChan:
+		return err
+	case <-time.After(time.Millisecond * 200):
+		s.log.Infof("启动成功:admin(addr:http://0.0.0.0:%s/admin/)", s.port)
+		return nil
+	}
+}
+
+//Stop 关闭管理服务
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	s.server.Shutdown(ctx)
+}
+
+//handle 检查请求方式与认证信息后执行处理函数
+func (s *Server) handle(method string, h func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if method != "" && r.Method != method {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": fmt.Sprintf("不支持的请求方式:%s", r.Method)})
+			return
+		}
+		if status, err := checkAuth(r); err != nil {
+			if status == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", "Basic realm="+strconv.Quote("Authorization Required"))
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		result, err := h(r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if r.Method != http.MethodGet {
+			s.log.Infof("%s %s?%s", r.Method, r.URL.Path, r.URL.RawQuery)
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	buff, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		buff, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buff)
+}
@@ -0,0 +1,72 @@
+package admin_test
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/micro-plat/hydra/conf/server/auth/basic"
+	"github.com/micro-plat/hydra/conf/server/auth/jwt"
+	"github.com/micro-plat/hydra/creator"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/hydra/servers/admin"
+	"github.com/micro-plat/hydra/mock"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func getFreePort(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err, "获取可用端口")
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+func request(t *testing.T, method string, url string, user string, pwd string) (int, string) {
+	req, _ := http.NewRequest(method, url, nil)
+	if user != "" {
+		req.SetBasicAuth(user, pwd)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.Equal(t, nil, err, "请求管理服务")
+	defer resp.Body.Close()
+	buff, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, string(buff)
+}
+
+func TestServer_Confs(t *testing.T) {
+	defer func(name string) { global.Def.ClusterName = name }(global.Def.ClusterName)
+	secret := "45d25cb71f3bee254c2bc6fc0dc0caf1"
+	conf := creator.New()
+	conf.API("8080").Basic(basic.WithUP("admin", "admin@123")).Jwt(jwt.WithSecret(secret))
+	mock.NewContext("", mock.WithConf(conf), mock.WithClusterName("admin_a"))
+
+	port := getFreePort(t)
+	s := admin.New(port, nil)
+	assert.Equal(t, nil, s.Start(), "1. 启动管理服务")
+	defer s.Stop()
+	url := "http://127.0.0.1:" + port + "/admin/confs"
+
+	status, _ := request(t, http.MethodGet, url, "", "")
+	assert.Equal(t, http.StatusUnauthorized, status, "2. 未认证时拒绝访问")
+
+	status, _ = request(t, http.MethodGet, url, "admin", "123456")
+	assert.Equal(t, http.StatusUnauthorized, status, "3. 密码错误时拒绝访问")
+
+	status, body := request(t, http.MethodGet, url, "admin", "admin@123")
+	assert.Equal(t, http.StatusOK, status, "4. 认证通过")
+	assert.Equal(t, true, strings.Contains(body, `"/auth/basic"`), "4. 返回认证节点")
+	assert.Equal(t, false, strings.Contains(body, "admin@123"), "4. 隐藏basic密码")
+	assert.Equal(t, false, strings.Contains(body, secret), "4. 隐藏jwt密钥")
+
+	status, _ = request(t, http.MethodPost, url, "admin", "admin@123")
+	assert.Equal(t, http.StatusMethodNotAllowed, status, "5. 不支持的请求方式")
+
+	conf = creator.New()
+	conf.API("8080")
+	mock.NewContext("", mock.WithConf(conf), mock.WithClusterName("admin_b"))
+	status, _ = request(t, http.MethodGet, url, "admin", "admin@123")
+	assert.Equal(t, http.StatusForbidden, status, "6. 未配置认证时禁止访问")
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/net"
+)
+
+var errNoAuthConf = errors.New("服务器未配置basic或apikey认证，禁止访问管理服务")
+var errUnauthorized = errors.New("认证失败")
+
+//checkAuth 使用已启动服务器的basic或apikey认证配置验证请求
+//通过invoker调用本地服务进行验证的配置不适用于管理服务，将被忽略
+func checkAuth(r *http.Request) (int, error) {
+	configured := false
+	sign, raw := getSignRaw(r)
+	for _, tp := range global.Def.GetServerTypes() {
+		cnf, err := app.Cache.GetAPPConf(tp)
+		if err != nil {
+			continue
+		}
+		if basic, err := cnf.GetBasicConf(); err == nil && !basic.Disable && basic.Invoker == "" {
+			configured = true
+			if _, ok := basic.Verify(r.Header.Get("Authorization"), nil); ok {
+				return http.StatusOK, nil
+			}
+		}
+		if apikey, err := cnf.GetAPIKeyConf(); err == nil && !apikey.Disable && apikey.Invoker == "" {
+			configured = true
+			if sign != "" && apikey.Verify(raw, sign, nil) == nil {
+				return http.StatusOK, nil
+			}
+		}
+	}
+	if !configured {
+		return http.StatusForbidden, errNoAuthConf
+	}
+	return http.StatusUnauthorized, errUnauthorized
+}
+
+//getSignRaw 获取签名与待签名原串
+func getSignRaw(r *http.Request) (string, string) {
+	query := r.URL.Query()
+	values := net.NewValues()
+	for key := range query {
+		if key == "sign" {
+			continue
+		}
+		values.Set(key, query.Get(key))
+	}
+	values.Sort()
+	return query.Get("sign"), values.Join("", "")
+}
@@ -0,0 +1,269 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/micro-plat/hydra/components"
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/server/task"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/hydra/servers"
+	"github.com/micro-plat/hydra/services"
+)
+
+//confs 获取已加载的服务器配置及版本号,var配置仅返回版本号，认证与密钥配置及敏感字段的值已隐藏
+func (s *Server) confs(r *http.Request) (interface{}, error) {
+	result := make(map[string]interface{})
+	for _, tp := range global.Def.GetServerTypes() {
+		cnf, err := app.Cache.GetAPPConf(tp)
+		if err != nil {
+			continue
+		}
+		subs := make(map[string]interface{})
+		cnf.GetServerConf().Iter(func(path string, raw *conf.RawConf) bool {
+			subs[path] = map[string]interface{}{
+				"version": raw.GetVersion(),
+				"conf":    getRawValue(path, raw),
+			}
+			return true
+		})
+		result[tp] = map[string]interface{}{
+			"path":    cnf.GetServerConf().GetServerPath(),
+			"version": app.Cache.GetCurrentServerVerion(tp),
+			"main":    getRawValue("", cnf.GetServerConf().GetMainConf()),
+			"subs":    subs,
+		}
+	}
+	if varConf, err := app.Cache.GetVarConf(); err == nil {
+		vars := make(map[string]int32)
+		varConf.Iter(func(path string, raw *conf.RawConf) bool {
+			vars[path] = raw.GetVersion()
+			return true
+		})
+		result["var"] = map[string]interface{}{
+			"version": varConf.GetVersion(),
+			"nodes":   vars,
+		}
+	}
+	return result, nil
+}
+
+//routes 获取已注册的路由与服务
+func (s *Server) routes(r *http.Request) (interface{}, error) {
+	result := make(map[string]interface{})
+	for _, tp := range global.Def.GetServerTypes() {
+		routers, err := services.GetRouter(tp).GetRouters()
+		if err != nil {
+			return nil, err
+		}
+		result[tp] = map[string]interface{}{
+			"routers":  routers.GetRouters(),
+			"services": services.Def.GetServices(tp),
+		}
+	}
+	return result, nil
+}
+
+//inspect 获取服务器运行状态及集群节点
+func (s *Server) inspect(r *http.Request) (interface{}, error) {
+	result := make(map[string]interface{})
+	for tp, server := range s.servers.GetServers() {
+		status := make(map[string]interface{})
+		if v, ok := server.(servers.IInspectServer); ok {
+			status = v.Inspect()
+		}
+		if cnf, err := app.Cache.GetAPPConf(tp); err == nil {
+			status["cluster"] = getClusterNodes(cnf.GetServerConf())
+		}
+		result[tp] = status
+	}
+	return result, nil
+}
+
+//pause 暂停服务器
+func (s *Server) pause(r *http.Request) (interface{}, error) {
+	server, err := s.getPauseServer(r.URL.Query().Get("type"))
+	if err != nil {
+		return nil, err
+	}
+	ok, err := server.Pause()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"changed": ok}, nil
+}
+
+//resume 恢复服务器
+func (s *Server) resume(r *http.Request) (interface{}, error) {
+	server, err := s.getPauseServer(r.URL.Query().Get("type"))
+	if err != nil {
+		return nil, err
+	}
+	ok, err := server.Resume()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"changed": ok}, nil
+}
+
+//tasks 获取或保存cron服务器的动态任务
+func (s *Server) tasks(r *http.Request) (interface{}, error) {
+	switch r.Method {
+	case http.MethodGet:
+		return services.CRON.List()
+	case http.MethodPost:
+		t := &task.Task{}
+		if err := json.NewDecoder(r.Body).Decode(t); err != nil {
+			return nil, fmt.Errorf("任务格式有误:%w", err)
+		}
+		if err := services.CRON.Save(t); err != nil {
+			return nil, err
+		}
+		return map[string]string{"name": t.GetUNQ()}, nil
+	default:
+		return nil, fmt.Errorf("不支持的请求方式:%s", r.Method)
+	}
+}
+
+//pauseTask 暂停动态任务
+func (s *Server) pauseTask(r *http.Request) (interface{}, error) {
+	name := r.URL.Query().Get("name")
+	return map[string]string{"name": name}, services.CRON.Pause(name)
+}
+
+//resumeTask 恢复动态任务
+func (s *Server) resumeTask(r *http.Request) (interface{}, error) {
+	name := r.URL.Query().Get("name")
+	return map[string]string{"name": name}, services.CRON.Resume(name)
+}
+
+//deleteTask 删除动态任务
+func (s *Server) deleteTask(r *http.Request) (interface{}, error) {
+	name := r.URL.Query().Get("name")
+	return map[string]string{"name": name}, services.CRON.Delete(name)
+}
+
+//job 获取一次性任务的执行状态
+func (s *Server) job(r *http.Request) (interface{}, error) {
+	return services.CRON.GetJob(r.URL.Query().Get("id"))
+}
+
+//cancelJob 撤销等待执行的一次性任务
+func (s *Server) cancelJob(r *http.Request) (interface{}, error) {
+	id := r.URL.Query().Get("id")
+	return map[string]string{"id": id}, services.CRON.CancelJob(id)
+}
+
+//components 获取组件容器中已创建的组件
+func (s *Server) components(r *http.Request) (interface{}, error) {
+	return components.Def.Container().Keys(), nil
+}
+
+//level 获取或设置日志级别
+func (s *Server) level(r *http.Request) (interface{}, error) {
+	switch r.Method {
+	case http.MethodGet:
+		return map[string]string{"level": getLevel()}, nil
+	case http.MethodPost:
+		if err := setLevel(r.URL.Query().Get("level")); err != nil {
+			return nil, err
+		}
+		return map[string]string{"level": getLevel()}, nil
+	default:
+		return nil, fmt.Errorf("不支持的请求方式:%s", r.Method)
+	}
+}
+
+func (s *Server) getPauseServer(tp string) (servers.IPauseServer, error) {
+	server, ok := s.servers.GetServers()[tp]
+	if !ok {
+		return nil, fmt.Errorf("服务器[%s]未启动", tp)
+	}
+	v, ok := server.(servers.IPauseServer)
+	if !ok {
+		return nil, fmt.Errorf("服务器[%s]不支持暂停与恢复", tp)
+	}
+	return v, nil
+}
+
+func getClusterNodes(cnf conf.IServerConf) []map[string]interface{} {
+	nodes := make([]map[string]interface{}, 0, 2)
+	cluster, err := cnf.GetCluster()
+	if err != nil {
+		return nodes
+	}
+	cluster.Iter(func(node conf.ICNode) bool {
+		nodes = append(nodes, map[string]interface{}{
+			"name":      node.GetName(),
+			"host":      node.GetHost(),
+			"port":      node.GetPort(),
+			"nodeID":    node.GetNodeID(),
+			"index":     node.GetIndex(),
+			"available": node.IsAvailable(),
+			"current":   node.IsCurrent(),
+		})
+		return true
+	})
+	return nodes
+}
+
+//masked 隐藏后的配置值
+const masked = "******"
+
+//sensitiveKeys 需要隐藏值的字段名，不区分大小写，包含即匹配
+var sensitiveKeys = []string{"secret", "password", "pwd", "token", "credential", "private"}
+
+//getRawValue 获取配置值，认证与密钥节点整体隐藏，其它节点隐藏敏感字段的值
+func getRawValue(path string, raw *conf.RawConf) interface{} {
+	if raw == nil {
+		return nil
+	}
+	if isSecretPath(path) {
+		return masked
+	}
+	buff := raw.GetOrigin()
+	var v interface{}
+	if err := json.Unmarshal(buff, &v); err == nil {
+		return maskValue(v)
+	}
+	return string(buff)
+}
+
+//isSecretPath 是否为认证(/auth/*)或密钥节点
+func isSecretPath(path string) bool {
+	path = strings.TrimPrefix(strings.ToLower(path), "/")
+	return strings.HasPrefix(path, "auth") || strings.Contains(path, "secret")
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range sensitiveKeys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+//maskValue 隐藏敏感字段的值
+func maskValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if isSensitiveKey(k) {
+				t[k] = masked
+				continue
+			}
+			t[k] = maskValue(val)
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = maskValue(val)
+		}
+	}
+	return v
+}
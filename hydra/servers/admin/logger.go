@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/micro-plat/lib4go/logger"
+)
+
+var levels = []string{
+	logger.SLevel_ALL,
+	logger.SLevel_Debug,
+	logger.SLevel_Info,
+	logger.SLevel_Warn,
+	logger.SLevel_Error,
+	logger.SLevel_Fatal,
+	logger.SLevel_OFF,
+}
+
+var currentLevel int32 = logger.ILevel_ALL
+
+//levelAppender 按当前日志级别过滤日志的输出器
+type levelAppender struct {
+	logger.IAppender
+}
+
+//Write 低于当前日志级别的日志不输出
+func (a *levelAppender) Write(layout *logger.Layout, event *logger.LogEvent) error {
+	if logger.GetLevel(event.Level) < int(atomic.LoadInt32(&currentLevel)) {
+		return nil
+	}
+	return a.IAppender.Write(layout, event)
+}
+
+//HookLogger 替换file,stdout日志输出器，使日志级别可通过管理服务动态调整
+//须在日志组件写入日志前调用
+func HookLogger() {
+	logger.RemoveAppender("file")
+	logger.AddAppender("file", &levelAppender{IAppender: logger.NewFileAppender()})
+	logger.RemoveAppender("stdout")
+	logger.AddAppender("stdout", &levelAppender{IAppender: logger.NewStudoutAppender()})
+}
+
+func getLevel() string {
+	return levels[atomic.LoadInt32(&currentLevel)]
+}
+
+func setLevel(level string) error {
+	for i, l := range levels {
+		if l == level {
+			atomic.StoreInt32(&currentLevel, int32(i))
+			return nil
+		}
+	}
+	return fmt.Errorf("日志级别只能是%v", levels)
+}
@@ -16,6 +16,7 @@ type CronTask struct {
 	Counter  *Counter
 	Round    *Round
 	schedule cron.Schedule
+	next     time.Time
 	method   string
 	form     map[string]interface{}
 	header   map[string]string
@@ -31,6 +32,12 @@ func NewCronTask(t *task.Task) (r *CronTask, err error) {
 		form:    make(map[string]interface{}),
 		header:  map[string]string{"Client-IP": "127.0.0.1"},
 	}
+	for k, v := range t.Input {
+		r.form[k] = v
+	}
+	for k, v := range t.Header {
+		r.header[k] = v
+	}
 	if t.IsImmediately() {
 		return r, nil
 	}
@@ -74,3 +81,8 @@ func (m *CronTask) NextTime(t time.Time) time.Time {
 	}
 	return m.schedule.Next(t)
 }
+
+//GetNextTime 获取已排定的下次执行时间
+func (m *CronTask) GetNextTime() time.Time {
+	return m.next
+}
@@ -0,0 +1,192 @@
+package cron
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/server/task"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/logger"
+	lregistry "github.com/micro-plat/lib4go/registry"
+)
+
+//DynamicSyncSpan 动态任务全量校对的时间间隔，防止监控事件丢失
+var DynamicSyncSpan = time.Minute
+
+//dynamicTasks 监控注册中心中的动态任务，同步到当前节点的任务列表
+type dynamicTasks struct {
+	processor *Processor
+	store     *task.Store
+	r         registry.IRegistry
+	log       logger.ILogger
+	applied   map[string]*task.Task
+	contents  map[string]string
+	values    map[string]bool
+	lk        sync.Mutex
+	notify    chan struct{}
+	closeCh   chan struct{}
+	once      sync.Once
+}
+
+func newDynamicTasks(r registry.IRegistry, store *task.Store, processor *Processor, log logger.ILogger) *dynamicTasks {
+	return &dynamicTasks{
+		processor: processor,
+		store:     store,
+		r:         r,
+		log:       log,
+		applied:   make(map[string]*task.Task),
+		contents:  make(map[string]string),
+		values:    make(map[string]bool),
+		notify:    make(chan struct{}, 1),
+		closeCh:   make(chan struct{}),
+	}
+}
+
+//Start 加载动态任务并开始监控
+func (d *dynamicTasks) Start() error {
+	ok, err := d.r.Exists(d.store.GetPath())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err := d.r.CreatePersistentNode(d.store.GetPath(), "{}"); err != nil {
+			return err
+		}
+	}
+	if err := d.sync(); err != nil {
+		return err
+	}
+	go d.loop()
+	return nil
+}
+
+//Close 停止监控
+func (d *dynamicTasks) Close() {
+	d.once.Do(func() {
+		close(d.closeCh)
+	})
+}
+
+func (d *dynamicTasks) loop() {
+	if ch, err := d.r.WatchChildren(d.store.GetPath()); err == nil {
+		go d.watchChildren(ch)
+	}
+	tk := time.NewTicker(DynamicSyncSpan)
+	defer tk.Stop()
+	for {
+		select {
+		case <-d.closeCh:
+			return
+		case <-d.notify:
+		case <-tk.C:
+		}
+		if err := d.sync(); err != nil {
+			d.log.Errorf("同步动态任务失败:%v", err)
+		}
+	}
+}
+
+//sync 加载注册中心中的所有动态任务，移除已删除、已暂停或已修改的任务，添加新任务
+func (d *dynamicTasks) sync() error {
+	tasks, err := d.store.List()
+	if err != nil {
+		return err
+	}
+	d.lk.Lock()
+	defer d.lk.Unlock()
+	for name, old := range d.applied {
+		t, ok := tasks[name]
+		if ok && getContent(t) == d.contents[name] {
+			continue
+		}
+		d.processor.Remove(old.GetUNQ())
+		delete(d.applied, name)
+		delete(d.contents, name)
+		d.log.Infof("移除动态任务:%s(%s)", old.Service, old.Cron)
+	}
+	for name, t := range tasks {
+		d.watchValue(name)
+		if _, ok := d.applied[name]; ok {
+			continue
+		}
+		d.contents[name] = getContent(t)
+		d.applied[name] = t
+		if t.Disable {
+			continue
+		}
+		if err := d.processor.Add(t); err != nil {
+			d.log.Errorf("添加动态任务%s(%s)失败:%v", t.Service, t.Cron, err)
+			continue
+		}
+		d.log.Infof("添加动态任务:%s(%s)", t.Service, t.Cron)
+	}
+	return nil
+}
+
+func (d *dynamicTasks) watchChildren(ch chan lregistry.ChildrenWatcher) {
+	for {
+		select {
+		case <-d.closeCh:
+			return
+		case w, ok := <-ch:
+			if !ok {
+				return
+			}
+			if w.GetError() == nil {
+				d.changed()
+			}
+		}
+		var err error
+		if ch, err = d.r.WatchChildren(d.store.GetPath()); err != nil {
+			d.log.Errorf("监控动态任务失败:%v", err)
+			return
+		}
+	}
+}
+
+//watchValue 监控任务节点的值变化，节点删除后停止监控，调用前需加锁
+func (d *dynamicTasks) watchValue(name string) {
+	if d.values[name] {
+		return
+	}
+	path := registry.Join(d.store.GetPath(), name)
+	ch, err := d.r.WatchValue(path)
+	if err != nil {
+		return
+	}
+	d.values[name] = true
+	go func() {
+		defer func() {
+			d.lk.Lock()
+			delete(d.values, name)
+			d.lk.Unlock()
+		}()
+		for {
+			select {
+			case <-d.closeCh:
+				return
+			case w, ok := <-ch:
+				if !ok || w.GetError() != nil {
+					return
+				}
+				d.changed()
+			}
+			if ch, err = d.r.WatchValue(path); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func (d *dynamicTasks) changed() {
+	select {
+	case d.notify <- struct{}{}:
+	default:
+	}
+}
+
+func getContent(t *task.Task) string {
+	buff, _ := json.Marshal(t)
+	return string(buff)
+}
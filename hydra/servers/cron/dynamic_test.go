@@ -0,0 +1,49 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/micro-plat/hydra/conf/server"
+	"github.com/micro-plat/hydra/conf/server/task"
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+func TestDynamicTasks_Sync(t *testing.T) {
+	r := localmemory.NewLocalMemory()
+	store := task.NewStore(r, server.NewServerPub("hydra", "test", "cron", "t"))
+	assert.Equal(t, "/hydra/test/cron/t/tasks", store.GetPath(), "1. 动态任务路径")
+
+	p := NewProcessor()
+	d := newDynamicTasks(r, store, p, logger.New("cron"))
+	assert.Equal(t, nil, d.Start(), "2. 启动监控")
+	defer d.Close()
+
+	t1 := task.NewTask("@every 10s", "/cron/order", task.WithInput(map[string]interface{}{"id": "1"}), task.WithHeader("X-Tag", "a"))
+	t2 := task.NewTask("@every 10s", "/cron/order", task.WithInput(map[string]interface{}{"id": "2"}))
+	assert.NotEqual(t, t1.GetUNQ(), t2.GetUNQ(), "3. 输入参数不同的任务唯一标识不同")
+	assert.Equal(t, nil, store.Save(t1), "4. 保存任务")
+	assert.Equal(t, nil, store.Save(t2), "4. 保存任务")
+	assert.Equal(t, nil, d.sync(), "5. 同步任务")
+	assert.Equal(t, 2, p.TaskCount(), "5. 同步任务")
+
+	ct, _ := NewCronTask(t1)
+	assert.Equal(t, "1", ct.GetForm()["id"], "6. 输入参数作为form")
+	assert.Equal(t, "a", ct.GetHeader()["X-Tag"], "6. 请求头")
+
+	assert.Equal(t, nil, store.Pause(t1.GetUNQ()), "7. 暂停任务")
+	assert.Equal(t, nil, d.sync(), "7. 暂停任务")
+	assert.Equal(t, 1, p.TaskCount(), "7. 暂停任务")
+	tasks, _ := store.List()
+	assert.Equal(t, true, tasks[t1.GetUNQ()].Disable, "7. 暂停任务")
+
+	assert.Equal(t, nil, store.Resume(t1.GetUNQ()), "8. 恢复任务")
+	assert.Equal(t, nil, d.sync(), "8. 恢复任务")
+	assert.Equal(t, 2, p.TaskCount(), "8. 恢复任务")
+
+	assert.Equal(t, nil, store.Delete(t2.GetUNQ()), "9. 删除任务")
+	assert.Equal(t, nil, d.sync(), "9. 删除任务")
+	assert.Equal(t, 1, p.TaskCount(), "9. 删除任务")
+	assert.NotEqual(t, nil, store.Delete(t2.GetUNQ()), "10. 删除不存在的任务")
+}
@@ -0,0 +1,279 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/server/task"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//JobScanSpan 检查一次性任务是否到达执行时间的时间间隔
+var JobScanSpan = time.Second
+
+//JobReloadSpan 重新加载一次性任务的时间间隔，防止监控事件丢失
+var JobReloadSpan = time.Minute
+
+//JobTimeout 任务执行时限，超过时限仍为执行中的任务视为节点异常退出，重新执行
+var JobTimeout = time.Minute * 10
+
+//JobRetryBackoff 任务失败后首次重试的等待时间，之后每次翻倍
+var JobRetryBackoff = time.Second * 10
+
+//JobMaxBackoff 任务重试的最大等待时间
+var JobMaxBackoff = time.Hour
+
+//JobKeepTime 已结束任务的保留时间，超过后删除
+var JobKeepTime = time.Hour * 24 * 7
+
+//jobRunner 在主节点上执行到期的一次性任务
+type jobRunner struct {
+	processor *Processor
+	isMaster  func() bool
+	handle    func(*CronTask) error
+	store     *task.JobStore
+	r         registry.IRegistry
+	log       logger.ILogger
+	jobs      map[string]int64
+	lk        sync.Mutex
+	notify    chan struct{}
+	closeCh   chan struct{}
+	once      sync.Once
+}
+
+func newJobRunner(r registry.IRegistry, store *task.JobStore, processor *Processor, isMaster func() bool, log logger.ILogger) *jobRunner {
+	return &jobRunner{
+		processor: processor,
+		isMaster:  isMaster,
+		handle:    processor.handleJob,
+		store:     store,
+		r:         r,
+		log:       log,
+		jobs:      make(map[string]int64),
+		notify:    make(chan struct{}, 1),
+		closeCh:   make(chan struct{}),
+	}
+}
+
+//Start 加载未结束的任务并开始检查
+func (j *jobRunner) Start() error {
+	ok, err := j.r.Exists(j.store.GetPath())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err := j.r.CreatePersistentNode(j.store.GetPath(), "{}"); err != nil {
+			return err
+		}
+	}
+	if err := j.reload(); err != nil {
+		return err
+	}
+	go j.loop()
+	go j.watch()
+	return nil
+}
+
+//Close 停止检查
+func (j *jobRunner) Close() {
+	j.once.Do(func() {
+		close(j.closeCh)
+	})
+}
+
+func (j *jobRunner) loop() {
+	scan := time.NewTicker(JobScanSpan)
+	reload := time.NewTicker(JobReloadSpan)
+	defer scan.Stop()
+	defer reload.Stop()
+	for {
+		select {
+		case <-j.closeCh:
+			return
+		case <-j.notify:
+			if err := j.reload(); err != nil {
+				j.log.Errorf("加载一次性任务失败:%v", err)
+			}
+		case <-reload.C:
+			if err := j.reload(); err != nil {
+				j.log.Errorf("加载一次性任务失败:%v", err)
+			}
+		case now := <-scan.C:
+			//仅集群选举的主节点执行，未分片时所有节点均运行定时任务，不能以服务器状态判断
+			if !j.isLeader() {
+				continue
+			}
+			for _, id := range j.getDue(now) {
+				j.run(id)
+			}
+		}
+	}
+}
+
+func (j *jobRunner) watch() {
+	for {
+		ch, err := j.r.WatchChildren(j.store.GetPath())
+		if err != nil {
+			j.log.Errorf("监控一次性任务失败:%v", err)
+			return
+		}
+		select {
+		case <-j.closeCh:
+			return
+		case w, ok := <-ch:
+			if !ok {
+				return
+			}
+			if w.GetError() == nil {
+				select {
+				case j.notify <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}
+}
+
+//reload 加载未结束的任务，删除超过保留时间的已结束任务
+func (j *jobRunner) reload() error {
+	jobs, err := j.store.List()
+	if err != nil {
+		return err
+	}
+	expire := time.Now().Add(-JobKeepTime).Unix()
+	pending := make(map[string]int64)
+	for _, job := range jobs {
+		if !job.IsFinished() {
+			pending[job.ID] = job.NextTime
+			continue
+		}
+		if job.Finished < expire && j.isLeader() {
+			j.store.Delete(job.ID)
+		}
+	}
+	j.lk.Lock()
+	j.jobs = pending
+	j.lk.Unlock()
+	return nil
+}
+
+//isLeader 当前节点是否为集群的主节点且服务器未暂停
+func (j *jobRunner) isLeader() bool {
+	return j.processor.status == running && j.isMaster()
+}
+
+func (j *jobRunner) getDue(now time.Time) []string {
+	j.lk.Lock()
+	defer j.lk.Unlock()
+	ids := make([]string, 0, 1)
+	for id, next := range j.jobs {
+		if next <= now.Unix() {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (j *jobRunner) setNext(id string, job *task.Job) {
+	j.lk.Lock()
+	defer j.lk.Unlock()
+	if job == nil || job.IsFinished() {
+		delete(j.jobs, id)
+		return
+	}
+	j.jobs[id] = job.NextTime
+}
+
+//run 将任务修改为执行中后执行，修改失败说明已被其它节点执行或已撤销
+//主节点切换期间可能有两个节点同时检查任务，由Claim按节点版本号保证只有一个节点执行
+func (j *jobRunner) run(id string) {
+	job, version, err := j.store.Get(id)
+	if err != nil {
+		j.setNext(id, nil)
+		return
+	}
+	if !job.IsDue(time.Now()) {
+		j.setNext(id, job)
+		return
+	}
+	//执行中的任务超过执行时限，计为一次失败
+	if job.Status == task.JobRunning {
+		job.Retry++
+		job.Error = "执行超时"
+		if job.Retry > job.MaxRetry {
+			job.Status = task.JobFailed
+			job.Finished = time.Now().Unix()
+			if err := j.store.Update(job, version); err == nil {
+				j.setNext(id, job)
+			}
+			return
+		}
+	}
+	claimed, version, err := j.store.Claim(job, version, JobTimeout)
+	if err != nil {
+		if !errors.Is(err, registry.ErrVersionConflict) {
+			j.log.Errorf("更新任务%s失败:%v", id, err)
+		}
+		return
+	}
+	j.setNext(id, claimed)
+	go j.execute(claimed, version)
+}
+
+func (j *jobRunner) execute(job *task.Job, version int32) {
+	ct, err := NewCronTask(job.GetTask())
+	if err == nil {
+		err = j.handle(ct)
+	}
+	now := time.Now()
+	switch {
+	case err == nil:
+		job.Status = task.JobSuccess
+		job.Error = ""
+		job.Finished = now.Unix()
+	case job.Retry >= job.MaxRetry:
+		job.Status = task.JobFailed
+		job.Error = err.Error()
+		job.Finished = now.Unix()
+		j.log.Errorf("任务%s(%s)执行失败:%v", job.ID, job.Service, err)
+	default:
+		job.Status = task.JobWaiting
+		job.Error = err.Error()
+		job.Retry++
+		job.NextTime = now.Add(getBackoff(job.Retry)).Unix()
+		j.log.Warnf("任务%s(%s)执行失败,%s后重试:%v", job.ID, job.Service, getBackoff(job.Retry), err)
+	}
+	if err := j.store.Update(job, version); err != nil {
+		j.log.Errorf("保存任务%s的执行结果失败:%v", job.ID, err)
+		return
+	}
+	j.setNext(job.ID, job)
+}
+
+//getBackoff 获取第retry次重试的等待时间
+func getBackoff(retry int) time.Duration {
+	d := JobRetryBackoff
+	for i := 1; i < retry && d < JobMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > JobMaxBackoff {
+		return JobMaxBackoff
+	}
+	return d
+}
+
+//handleJob 执行一次性任务，返回执行失败的原因
+func (s *Processor) handleJob(t *CronTask) error {
+	w, err := s.engine.HandleRequest(t)
+	if err != nil {
+		return err
+	}
+	if w.Status() >= http.StatusBadRequest {
+		return fmt.Errorf("%d %s", w.Status(), w.Data())
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package cron
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/server"
+	"github.com/micro-plat/hydra/conf/server/task"
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+func TestJobRunner_Run(t *testing.T) {
+	r := localmemory.NewLocalMemory()
+	store := task.NewJobStore(r, server.NewServerPub("hydra", "test", "cron", "t"))
+	p := NewProcessor()
+	p.status = running
+	runner := newJobRunner(r, store, p, func() bool { return true }, logger.New("cron"))
+	fails := 1
+	runner.handle = func(*CronTask) error {
+		if fails > 0 {
+			fails--
+			return errors.New("执行失败")
+		}
+		return nil
+	}
+	assert.Equal(t, nil, runner.Start(), "1. 启动")
+	defer runner.Close()
+
+	job := task.NewJob(time.Now(), "/cron/order", nil)
+	assert.Equal(t, nil, store.Create(job), "2. 保存任务")
+
+	runner.run(job.ID)
+	j := waitJob(t, store, job.ID, func(j *task.Job) bool { return j.Status == task.JobWaiting && j.Retry == 1 })
+	assert.Equal(t, "执行失败", j.Error, "3. 执行失败后保存结果并等待重试")
+
+	//重试时间已到
+	j.NextTime = time.Now().Unix()
+	_, version, _ := store.Get(job.ID)
+	assert.Equal(t, nil, store.Update(j, version), "4. 修改重试时间")
+	runner.run(job.ID)
+	j = waitJob(t, store, job.ID, func(j *task.Job) bool { return j.IsFinished() })
+	assert.Equal(t, task.JobSuccess, j.Status, "5. 重试成功")
+}
+
+func TestJobRunner_Master(t *testing.T) {
+	r := localmemory.NewLocalMemory()
+	store := task.NewJobStore(r, server.NewServerPub("hydra", "test", "cron", "t"))
+	master := false
+	p := NewProcessor()
+	p.status = running
+	runner := newJobRunner(r, store, p, func() bool { return master }, logger.New("cron"))
+	assert.Equal(t, false, runner.isLeader(), "1. 未分片时服务器处于运行状态，但不是集群主节点")
+	master = true
+	assert.Equal(t, true, runner.isLeader(), "2. 集群主节点")
+	p.status = pause
+	assert.Equal(t, false, runner.isLeader(), "3. 主节点已暂停")
+}
+
+func TestJobRunner_Claim(t *testing.T) {
+	r := localmemory.NewLocalMemory()
+	store := task.NewJobStore(r, server.NewServerPub("hydra", "test", "cron", "t"))
+	var count int32
+	runners := make([]*jobRunner, 0, 4)
+	for i := 0; i < 4; i++ {
+		p := NewProcessor()
+		p.status = running
+		runner := newJobRunner(r, store, p, func() bool { return true }, logger.New("cron"))
+		runner.handle = func(*CronTask) error {
+			atomic.AddInt32(&count, 1)
+			return nil
+		}
+		runners = append(runners, runner)
+	}
+	assert.Equal(t, nil, runners[0].Start(), "1. 启动")
+	defer runners[0].Close()
+
+	job := task.NewJob(time.Now(), "/cron/order", nil)
+	assert.Equal(t, nil, store.Create(job), "2. 保存任务")
+
+	//主节点切换期间多个节点同时检查到期任务
+	var wg sync.WaitGroup
+	for _, runner := range runners {
+		wg.Add(1)
+		go func(runner *jobRunner) {
+			defer wg.Done()
+			runner.run(job.ID)
+		}(runner)
+	}
+	wg.Wait()
+	j := waitJob(t, store, job.ID, func(j *task.Job) bool { return j.IsFinished() })
+	assert.Equal(t, task.JobSuccess, j.Status, "3. 执行成功")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count), "3. 只有一个节点执行任务")
+}
+
+func waitJob(t *testing.T, store *task.JobStore, id string, f func(j *task.Job) bool) *task.Job {
+	for i := 0; i < 100; i++ {
+		j, _, err := store.Get(id)
+		if err == nil && f(j) {
+			return j
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+	t.Fatalf("任务%s未达到预期状态", id)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -21,6 +22,17 @@ const (
 	running   = 4
 )
 
+func getStatusName(status int) string {
+	switch status {
+	case pause:
+		return "paused"
+	case running:
+		return "running"
+	default:
+		return "unstarted"
+	}
+}
+
 //Processor cron管理程序，用于管理多个任务的执行，暂停，恢复，动态添加，移除
 type Processor struct {
 	//*dispatcher.Engine
@@ -118,6 +130,7 @@ func (s *Processor) add(task *CronTask) (offset int, round int, err error) {
 	}
 	offset, round = s.getOffset(now, nextTime)
 	task.Round.Update(round)
+	task.next = nextTime
 	s.slots[offset].Set(utility.GetGUID(), task)
 	return
 }
@@ -129,8 +142,11 @@ func (s *Processor) Remove(name string) {
 	for _, slot := range s.slots {
 		slot.RemoveIterCb(func(k string, value interface{}) bool {
 			task := value.(*CronTask)
+			if task.GetName() != name {
+				return false
+			}
 			task.Disable = true
-			return task.GetName() == name
+			return true
 		})
 	}
 }
@@ -177,6 +193,46 @@ func (s *Processor) TaskCount() int {
 	return count
 }
 
+//TaskStatus 任务运行状态
+type TaskStatus struct {
+	Name     string `json:"name"`
+	Cron     string `json:"cron"`
+	Service  string `json:"service"`
+	Next     string `json:"next"`
+	Executed int    `json:"executed"`
+}
+
+//GetTasks 获取当前启用的任务及下次执行时间
+func (s *Processor) GetTasks() []*TaskStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	tasks := make([]*TaskStatus, 0, 4)
+	for i := range s.slots {
+		for item := range s.slots[i].IterBuffered() {
+			task := item.Val.(*CronTask)
+			if task.Disable {
+				continue
+			}
+			tasks = append(tasks, &TaskStatus{
+				Name:     task.GetName(),
+				Cron:     task.Cron,
+				Service:  task.GetService(),
+				Next:     task.GetNextTime().Format("2006-01-02 15:04:05"),
+				Executed: task.Counter.Get(),
+			})
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Next < tasks[j].Next
+	})
+	return tasks
+}
+
+//GetStatus 获取运行状态
+func (s *Processor) GetStatus() string {
+	return getStatusName(s.status)
+}
+
 //-------------------------------------内部处理------------------------------------
 
 func (s *Processor) getOffset(now time.Time, next time.Time) (pos int, circle int) {
@@ -3,6 +3,7 @@ package cron
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/micro-plat/hydra/conf"
 	"github.com/micro-plat/hydra/conf/app"
@@ -18,11 +19,14 @@ import (
 //Responsive 响应式服务器
 type Responsive struct {
 	*Server
-	conf     app.IAPPConf
-	comparer conf.IComparer
-	pub      pub.IPublisher
-	log      logger.ILogger
-	first    bool
+	conf      app.IAPPConf
+	comparer  conf.IComparer
+	pub       pub.IPublisher
+	log       logger.ILogger
+	first     bool
+	suspended int32
+	dynamic   *dynamicTasks
+	jobs      *jobRunner
 }
 
 //NewResponsive 创建响应式服务器
@@ -70,6 +74,13 @@ func (w *Responsive) Start() (err error) {
 
 	w.subscribe()
 
+	//加载并监控集群的动态任务与一次性任务
+	if err = w.startDynamic(); err != nil {
+		err = fmt.Errorf("%s加载动态任务失败 %w", w.conf.GetServerConf().GetServerType(), err)
+		w.Shutdown()
+		return err
+	}
+
 	//服务启动成功后钩子
 	if err := services.Def.DoStarted(w.conf); err != nil {
 		err = fmt.Errorf("%s启动失败，关闭服务器 %w", w.conf.GetServerConf().GetServerType(), err)
@@ -118,6 +129,39 @@ func (w *Responsive) Notify(c app.IAPPConf) (change bool, err error) {
 	return true, nil
 }
 
+//Pause 暂停服务器，暂停后集群主从切换时不再自动恢复
+func (w *Responsive) Pause() (bool, error) {
+	atomic.StoreInt32(&w.suspended, 1)
+	return w.Server.Pause()
+}
+
+//Resume 恢复服务器，仅主节点恢复执行任务
+func (w *Responsive) Resume() (bool, error) {
+	atomic.StoreInt32(&w.suspended, 0)
+	master, err := w.isMaster()
+	if err != nil || !master {
+		return false, err
+	}
+	return w.Server.Resume()
+}
+
+//isSuspended 是否已通过管理接口暂停
+func (w *Responsive) isSuspended() bool {
+	return atomic.LoadInt32(&w.suspended) == 1
+}
+
+//Inspect 获取服务器运行状态
+func (w *Responsive) Inspect() map[string]interface{} {
+	master, _ := w.isMaster()
+	return map[string]interface{}{
+		"address":   w.Server.GetAddress(),
+		"status":    w.Server.GetStatus(),
+		"suspended": w.isSuspended(),
+		"master":    master,
+		"tasks":     w.Server.GetTasks(),
+	}
+}
+
 func (w *Responsive) subscribe() {
 	//动态监听任务
 	services.CRON.Subscribe(func(t *task.Task) {
@@ -127,9 +171,31 @@ func (w *Responsive) subscribe() {
 	})
 }
 
+func (w *Responsive) startDynamic() error {
+	if w.dynamic != nil {
+		w.dynamic.Close()
+	}
+	if w.jobs != nil {
+		w.jobs.Close()
+	}
+	sc := w.conf.GetServerConf()
+	w.dynamic = newDynamicTasks(sc.GetRegistry(), task.NewStore(sc.GetRegistry(), sc), w.Server.Processor, w.log)
+	if err := w.dynamic.Start(); err != nil {
+		return err
+	}
+	w.jobs = newJobRunner(sc.GetRegistry(), task.NewJobStore(sc.GetRegistry(), sc), w.Server.Processor, w.isJobMaster, w.log)
+	return w.jobs.Start()
+}
+
 //Shutdown 关闭服务器
 func (w *Responsive) Shutdown() {
 	w.log.Infof("关闭[%s]服务...", w.conf.GetServerConf().GetServerType())
+	if w.dynamic != nil {
+		w.dynamic.Close()
+	}
+	if w.jobs != nil {
+		w.jobs.Close()
+	}
 	w.Server.Shutdown()
 	w.pub.Clear()
 	if err := services.Def.DoClosing(w.conf); err != nil {
@@ -48,6 +48,9 @@ LOOP:
 			}
 
 			if server.Sharding == 0 || cluster.Current().IsMaster(server.Sharding) {
+				if w.isSuspended() {
+					continue
+				}
 				ok, err := w.Server.Resume()
 				if err != nil {
 					w.log.Error("恢复服务器失败:", err)
@@ -74,3 +77,28 @@ LOOP:
 		}
 	}
 }
+
+//isMaster 当前节点是否为主节点
+func (w *Responsive) isMaster() (bool, error) {
+	server, err := cron.GetConf(w.conf.GetServerConf())
+	if err != nil {
+		return false, err
+	}
+	if server.Sharding == 0 {
+		return true, nil
+	}
+	cluster, err := w.conf.GetServerConf().GetCluster()
+	if err != nil {
+		return false, err
+	}
+	return cluster.Current().IsMaster(server.Sharding), nil
+}
+
+//isJobMaster 当前节点是否为集群的第一个节点，一次性任务只在该节点执行，与分片数无关
+func (w *Responsive) isJobMaster() bool {
+	cluster, err := w.conf.GetServerConf().GetCluster()
+	if err != nil {
+		return false
+	}
+	return cluster.Current().IsAvailable() && cluster.Current().IsMaster(1)
+}
@@ -34,8 +34,11 @@ func (s *Server) addHttpRouters(routers ...*router.Router) {
 	s.engine.Use(middleware.JwtAuth()) //jwt安全认证
 	s.engine.Use(middlewares...)
 
-	s.engine.Use(middleware.Render())    //响应渲染组件
-	s.engine.Use(middleware.JwtWriter()) //设置jwt回写
+	s.engine.Use(middleware.Script())      //请求钩子脚本
+	s.engine.Use(middleware.Idempotency()) //幂等处理
+	s.engine.Use(middleware.Cache())       //响应缓存
+	s.engine.Use(middleware.Render())      //响应渲染组件
+	s.engine.Use(middleware.JwtWriter())   //设置jwt回写
 
 	s.server.Handler = s.engine
 
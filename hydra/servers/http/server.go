@@ -7,6 +7,7 @@ import (
 	x "net/http"
 	"time"
 
+	"github.com/micro-plat/hydra/conf/server/acl/proxy"
 	"github.com/micro-plat/hydra/conf/server/router"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/adapter"
@@ -129,3 +130,17 @@ func (s *Server) GetAddress(h ...string) string {
 func (s *Server) GetStatus() string {
 	return types.DecodeString(s.running, true, "运行中", "停止")
 }
+
+//GetUpstreamStatus 获取代理上游集群节点的健康状态
+func (s *Server) GetUpstreamStatus() map[string][]*proxy.NodeStatus {
+	return proxy.GetUpClusterStatus()
+}
+
+//Inspect 获取服务器运行状态及代理上游集群节点的健康状态
+func (s *Server) Inspect() map[string]interface{} {
+	return map[string]interface{}{
+		"address":   s.GetAddress(),
+		"status":    s.GetStatus(),
+		"upstreams": s.GetUpstreamStatus(),
+	}
+}
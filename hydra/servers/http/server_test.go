@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/micro-plat/hydra/conf/server/acl/proxy"
 	"github.com/micro-plat/hydra/conf/server/router"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/lib4go/assert"
@@ -86,3 +87,13 @@ func TestNewWSServer(t *testing.T) {
 		assert.Equal(t, time.Duration(o.writeTimeout)*time.Second, gotT.server.WriteTimeout, tt.name)
 	}
 }
+
+func TestServer_Inspect(t *testing.T) {
+	s, err := NewServer("api", "127.0.0.1:8080", nil, WithServerType("api"))
+	assert.Equal(t, nil, err, "1. 构建http服务")
+	status := s.Inspect()
+	assert.Equal(t, "http://127.0.0.1:8080", status["address"], "2. 服务地址")
+	assert.Equal(t, "停止", status["status"], "3. 服务状态")
+	_, ok := status["upstreams"].(map[string][]*proxy.NodeStatus)
+	assert.Equal(t, true, ok, "4. 上游集群节点状态")
+}
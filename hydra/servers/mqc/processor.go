@@ -2,6 +2,8 @@ package mqc
 
 import (
 	"fmt"
+	"net/http"
+	"sort"
 	"sync"
 	"time"
 
@@ -19,6 +21,17 @@ const (
 	running   = 4
 )
 
+func getStatusName(status int) string {
+	switch status {
+	case pause:
+		return "paused"
+	case running:
+		return "running"
+	default:
+		return "unstarted"
+	}
+}
+
 //Processor cron管理程序，用于管理多个任务的执行，暂停，恢复，动态添加，移除
 type Processor struct {
 	//*dispatcher.Engine
@@ -75,6 +88,24 @@ func (s *Processor) QueueItems() map[string]interface{} {
 	return s.queues.Items()
 }
 
+//GetQueues 获取当前订阅的队列
+func (s *Processor) GetQueues() []*queue.Queue {
+	items := s.queues.Items()
+	queues := make([]*queue.Queue, 0, len(items))
+	for _, v := range items {
+		queues = append(queues, v.(*queue.Queue))
+	}
+	sort.Slice(queues, func(i, j int) bool {
+		return queues[i].Queue < queues[j].Queue
+	})
+	return queues
+}
+
+//GetStatus 获取运行状态
+func (s *Processor) GetStatus() string {
+	return getStatusName(s.status)
+}
+
 //Start 所有任务
 func (s *Processor) Start(wait ...bool) error {
 	if err := s.customer.Connect(); err != nil {
@@ -141,6 +172,13 @@ func (s *Processor) Resume() (bool, error) {
 	}
 	return false, nil
 }
+//SetMaster 设置当前节点是否为集群主节点，由主节点移动到期的延迟消息
+func (s *Processor) SetMaster(master bool) {
+	if m, ok := s.customer.(mq.IDelayMover); ok {
+		m.SetMaster(master)
+	}
+}
+
 func (s *Processor) consume(queue *queue.Queue) error {
 	if err := s.customer.Consume(queue.Queue, queue.Concurrency, s.handle(queue)); err != nil {
 		return err
@@ -167,6 +205,19 @@ func (s *Processor) handle(queue *queue.Queue) func(mq.IMQCMessage) {
 		if err != nil {
 			panic(err)
 		}
-		s.engine.HandleRequest(req)
+
+		//处理成功或请求有误(4xx)时确认消息，4xx重新投递也不会成功；
+		//服务器错误(5xx)或未能处理时拒绝消息，kafka、amqp、nats退避后重新投递，超过最大投递次数转入死信队列
+		w, err := s.engine.HandleRequest(req)
+		if !isAck(w, err) {
+			m.Nack()
+			return
+		}
+		m.Ack()
 	}
 }
+
+//isAck 是否确认消息，仅服务器错误或未能处理请求时拒绝消息
+func isAck(w interface{ Status() int }, err error) bool {
+	return err == nil && w.Status() < http.StatusInternalServerError
+}
@@ -0,0 +1,34 @@
+package mqc
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+type status int
+
+func (s status) Status() int {
+	return int(s)
+}
+
+func TestIsAck(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		err    error
+		ack    bool
+	}{
+		{name: "1. 处理成功", status: http.StatusOK, ack: true},
+		{name: "2. 请求参数有误，重新投递也不会成功", status: http.StatusBadRequest, ack: true},
+		{name: "3. 服务不存在", status: http.StatusNotFound, ack: true},
+		{name: "4. 服务器错误，重新投递", status: http.StatusInternalServerError},
+		{name: "5. 服务不可用，重新投递", status: http.StatusServiceUnavailable},
+		{name: "6. 未能处理请求，重新投递", status: http.StatusOK, err: errors.New("处理失败")},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.ack, isAck(status(c.status), c.err), c.name)
+	}
+}
@@ -3,6 +3,7 @@ package mqc
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/micro-plat/hydra/conf"
 	"github.com/micro-plat/hydra/conf/app"
@@ -19,11 +20,12 @@ import (
 //Responsive 响应式服务器
 type Responsive struct {
 	*Server
-	conf     app.IAPPConf
-	comparer conf.IComparer
-	pub      pub.IPublisher
-	log      logger.ILogger
-	first    bool
+	conf      app.IAPPConf
+	comparer  conf.IComparer
+	pub       pub.IPublisher
+	log       logger.ILogger
+	first     bool
+	suspended int32
 }
 
 //NewResponsive 创建响应式服务器
@@ -121,6 +123,39 @@ func (w *Responsive) Notify(c app.IAPPConf) (change bool, err error) {
 	return true, nil
 }
 
+//Pause 暂停服务器，暂停后集群主从切换时不再自动恢复
+func (w *Responsive) Pause() (bool, error) {
+	atomic.StoreInt32(&w.suspended, 1)
+	return w.Server.Pause()
+}
+
+//Resume 恢复服务器，仅主节点恢复消息消费
+func (w *Responsive) Resume() (bool, error) {
+	atomic.StoreInt32(&w.suspended, 0)
+	master, err := w.isMaster()
+	if err != nil || !master {
+		return false, err
+	}
+	return w.Server.Resume()
+}
+
+//isSuspended 是否已通过管理接口暂停
+func (w *Responsive) isSuspended() bool {
+	return atomic.LoadInt32(&w.suspended) == 1
+}
+
+//Inspect 获取服务器运行状态
+func (w *Responsive) Inspect() map[string]interface{} {
+	master, _ := w.isMaster()
+	return map[string]interface{}{
+		"address":   w.Server.GetAddress(),
+		"status":    w.Server.GetStatus(),
+		"suspended": w.isSuspended(),
+		"master":    master,
+		"queues":    w.Server.GetQueues(),
+	}
+}
+
 //Shutdown 关闭服务器
 func (w *Responsive) Shutdown() {
 	w.log.Infof("关闭[%s]服务...", w.conf.GetServerConf().GetServerType())
@@ -42,8 +42,12 @@ LOOP:
 				}
 				continue
 			}
+			w.Server.SetMaster(cluster.Current().IsMaster(1))
 
 			if server.Sharding == 0 || cluster.Current().IsMaster(server.Sharding) {
+				if w.isSuspended() {
+					continue
+				}
 				ok, err := w.Server.Resume()
 				if err != nil {
 					w.log.Error("恢复mqc服务器失败:", err)
@@ -69,3 +73,19 @@ LOOP:
 		}
 	}
 }
+
+//isMaster 当前节点是否为主节点
+func (w *Responsive) isMaster() (bool, error) {
+	server, err := w.conf.GetMQCMainConf()
+	if err != nil {
+		return false, err
+	}
+	if server.Sharding == 0 {
+		return true, nil
+	}
+	cluster, err := w.conf.GetServerConf().GetCluster()
+	if err != nil {
+		return false, err
+	}
+	return cluster.Current().IsMaster(server.Sharding), nil
+}
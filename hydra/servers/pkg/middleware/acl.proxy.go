@@ -7,23 +7,33 @@ import (
 	"strings"
 
 	"github.com/micro-plat/hydra/conf/server/acl/proxy"
+	"github.com/micro-plat/hydra/context"
 )
 
+//proxyUpClusterKey 当前请求转到的上游集群名称
+const proxyUpClusterKey = "__proxy_upcluster__"
+
 //Proxy 代理配置
 func Proxy() Handler {
 	return func(ctx IMiddleContext) {
-		proxy, err := ctx.APPConf().GetProxyConf()
+		proxyConf, err := ctx.APPConf().GetProxyConf()
 		if err != nil {
 			ctx.Response().Abort(http.StatusNotExtended, err)
 			return
 		}
-		if proxy.Disable {
+		if proxyConf.Disable {
 			ctx.Next()
 			return
 		}
 
-		//检查当前请求是否需要进行代理
-		cluster, need, err := proxy.Check()
+		//检查当前请求是否需要进行代理，tengo脚本通过当前goroutine获取请求上下文
+		unbind := context.Bind(ctx)
+		cluster, need, err := proxyConf.Check(&proxy.HTTPRequest{
+			Request:  ctx.Request().GetHTTPRequest(),
+			User:     getProxyUser(ctx),
+			ClientIP: ctx.User().GetClientIP(),
+		})
+		unbind()
 		if err != nil {
 			ctx.Response().AddSpecial("proxy")
 			ctx.Response().Abort(http.StatusBadGateway, err)
@@ -35,10 +45,31 @@ func Proxy() Handler {
 		}
 
 		//获取当前http信息
-		ctx.Response().AddSpecial("proxy")
+		ctx.Response().AddSpecial("proxy", "upcluster:"+cluster.GetName())
+		ctx.Meta().SetValue(proxyUpClusterKey, cluster.GetName())
 		useProxy(ctx, cluster)
 	}
 }
+
+//getProxyUser 获取灰度规则使用的用户。代理在认证中间件之前执行，此时请求未经认证，
+//按jwt、basic配置解析请求中的认证信息，解析失败视为未登录，不中止请求，由认证中间件处理
+func getProxyUser(ctx IMiddleContext) string {
+	if user := getRequestUser(ctx); user != "" {
+		return user
+	}
+	if jwtAuth, err := ctx.APPConf().GetJWTConf(); err == nil && !jwtAuth.Disable {
+		if data, err := jwtAuth.CheckJWT(getToken(ctx, jwtAuth)); err == nil && data != nil {
+			return fmt.Sprint(data)
+		}
+	}
+	if basic, err := ctx.APPConf().GetBasicConf(); err == nil && !basic.Disable {
+		if user, ok := basic.Verify(ctx.Request().Headers().GetString("Authorization"), ctx.Invoke); ok {
+			return user
+		}
+	}
+	return ""
+}
+
 func useProxy(ctx IMiddleContext, cluster *proxy.UpCluster) {
 
 	//检查当前请求
@@ -64,10 +95,11 @@ RETRY:
 		ctx.Response().Abort(http.StatusBadGateway, fmt.Errorf("重试超过服务器限制"))
 		return
 	}
-	//获取服务器列表
+	//获取服务器列表,已摘除的节点不参与选择
 	url, err := cluster.Next()
 	if err != nil {
-		goto RETRY
+		ctx.Response().Abort(http.StatusBadGateway, err)
+		return
 	}
 
 	//转到上游
@@ -85,6 +117,16 @@ RETRY:
 	response := newRWriter(resp)
 	rproxy.ServeHTTP(response, req)
 
+	//上报节点请求结果，用于异常节点摘除
+	switch {
+	case proxyError != nil:
+		cluster.Report(url, proxyError)
+	case response.statusCode >= http.StatusInternalServerError:
+		cluster.Report(url, fmt.Errorf("远程请求返回状态码:%d", response.statusCode))
+	default:
+		cluster.Report(url, nil)
+	}
+
 	//处理重试问题
 	if canRetry {
 		goto RETRY
@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/micro-plat/hydra/conf/server/acl/proxy"
+	xjwt "github.com/micro-plat/hydra/conf/server/auth/jwt"
+	"github.com/micro-plat/hydra/creator"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/hydra/servers/pkg/middleware"
+	"github.com/micro-plat/hydra/mock"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/security/jwt"
+	"github.com/micro-plat/lib4go/types"
+)
+
+func TestProxy_JWTUser(t *testing.T) {
+	defer func(name string) { global.Def.ClusterName = name }(global.Def.ClusterName)
+	secret := "45d25cb71f3bee254c2bc6fc0dc0caf1"
+	conf := creator.New()
+	conf.API("8080").
+		Jwt(xjwt.WithSecret(secret), xjwt.WithHeader()).
+		Canary(proxy.WithRuleList(proxy.NewRule("vip", proxy.WithUser("u1"), proxy.WithUpcluster("canary"))))
+
+	token, err := jwt.Encrypt(secret, xjwt.ModeHS512, "u1", 3600)
+	assert.Equal(t, nil, err, "1. 生成jwt")
+
+	cases := []struct {
+		name    string
+		cluster string
+		token   string
+		next    bool
+	}{
+		{name: "2. jwt用户匹配灰度规则，转到灰度集群", cluster: "proxy_a", token: xjwt.TokenBearerPrefix + token},
+		{name: "3. jwt无效，视为未登录，不转发", cluster: "proxy_b", token: xjwt.TokenBearerPrefix + token + "x", next: true},
+		{name: "4. 未传入jwt，不转发", cluster: "proxy_c", next: true},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/order/query", nil)
+		header := types.XMap{}
+		if c.token != "" {
+			req.Header.Set(xjwt.AuthorizationHeader, c.token)
+			header[xjwt.AuthorizationHeader] = c.token
+		}
+
+		//配置已发布的节点不能重复发布，每个用例使用不同集群
+		ctx := mock.NewContext("", mock.WithConf(conf), mock.WithClusterName(c.cluster), mock.WithURL("/order/query"),
+			mock.WithRequest(req), mock.WithRHeaders(header))
+		m := &middle{}
+		middleware.Proxy()(middleware.NewMiddleContext(ctx, m))
+		assert.Equal(t, c.next, m.next, c.name)
+		if !c.next {
+			assert.Equal(t, "canary", ctx.Meta().GetString("__proxy_upcluster__"), c.name)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/components"
+	"github.com/micro-plat/hydra/components/caches"
+	"github.com/micro-plat/hydra/conf/server/cache"
+)
+
+//cacheEntry 缓存的响应结果
+type cacheEntry struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+	ETag        string `json:"etag"`
+	Expire      int64  `json:"expire"`
+}
+
+func (e *cacheEntry) isFresh() bool {
+	return time.Now().Unix() < e.Expire
+}
+
+//Cache 响应缓存
+func Cache() Handler {
+	return func(ctx IMiddleContext) {
+
+		//获取缓存配置
+		rspCache, err := ctx.APPConf().GetCacheConf()
+		if err != nil {
+			ctx.Response().Abort(http.StatusNotExtended, err)
+			return
+		}
+		if rspCache.Disable {
+			ctx.Next()
+			return
+		}
+
+		//仅缓存GET,HEAD请求
+		method := ctx.Request().Path().GetMethod()
+		if method != http.MethodGet && method != http.MethodHead {
+			ctx.Next()
+			return
+		}
+
+		//判断请求是否指定缓存规则
+		path := ctx.Request().Path().GetRequestPath()
+		enable, rule := rspCache.GetRule(path)
+		if !enable {
+			ctx.Next()
+			return
+		}
+
+		c, err := components.Def.Cache().GetCache(rspCache.Name)
+		if err != nil {
+			ctx.Log().Errorf("获取响应缓存组件[%s]失败:%v", rspCache.Name, err)
+			ctx.Next()
+			return
+		}
+
+		req := ctx.Request().GetHTTPRequest()
+		key := rule.GetKey(method, path, req.URL.Query(), req.Header.Get, getRequestUser(ctx))
+
+		//从缓存中获取响应结果
+		entry, ok := getCacheEntry(c, key)
+		if ok && (entry.isFresh() || !tryRevalidate(c, key, rule)) {
+			ctx.Response().AddSpecial("cache")
+			writeCacheEntry(ctx, entry)
+			return
+		}
+
+		//处理业务，并缓存响应结果
+		ctx.Next()
+		saveCacheEntry(ctx, c, key, rule)
+	}
+}
+
+//tryRevalidate 缓存已过期时，检查当前请求是否负责刷新缓存
+func tryRevalidate(c caches.ICache, key string, rule *cache.Rule) bool {
+	if !rule.AllowStale() {
+		return true
+	}
+	err := c.Add(key+":revalidate", "1", rule.Stale)
+	return err == nil
+}
+
+func getRequestUser(ctx IMiddleContext) string {
+	if name := ctx.User().GetUserName(); name != "" {
+		return name
+	}
+	if v := ctx.User().Auth().Request(); v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+func getCacheEntry(c caches.ICache, key string) (*cacheEntry, bool) {
+	value, err := c.Get(key)
+	if err != nil || value == "" {
+		return nil, false
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal([]byte(value), entry); err != nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+func writeCacheEntry(ctx IMiddleContext, entry *cacheEntry) {
+	ctx.Response().Header("ETag", entry.ETag)
+	if matchETag(ctx.Request().GetHTTPRequest().Header.Get("If-None-Match"), entry.ETag) {
+		ctx.Response().Abort(http.StatusNotModified)
+		return
+	}
+	if entry.ContentType != "" {
+		ctx.Response().ContentType(entry.ContentType)
+	}
+	ctx.Response().Abort(entry.Status, entry.Content)
+}
+
+func saveCacheEntry(ctx IMiddleContext, c caches.ICache, key string, rule *cache.Rule) {
+	status, content, contentType := ctx.Response().GetFinalResponse()
+	if status != http.StatusOK {
+		return
+	}
+	buff := md5.Sum([]byte(content))
+	entry := &cacheEntry{
+		Status:      status,
+		ContentType: contentType,
+		Content:     content,
+		ETag:        fmt.Sprintf(`"%s"`, hex.EncodeToString(buff[:])),
+		Expire:      time.Now().Add(rule.GetExpire()).Unix(),
+	}
+	value, err := json.Marshal(entry)
+	if err != nil {
+		ctx.Log().Errorf("序列化响应缓存失败:%v", err)
+		return
+	}
+	if err := c.Set(key, string(value), rule.GetStoreExpire()); err != nil {
+		ctx.Log().Errorf("保存响应缓存失败:%v", err)
+		return
+	}
+	c.Delete(key + ":revalidate")
+	ctx.Response().Header("ETag", entry.ETag)
+}
+
+func matchETag(inm string, etag string) bool {
+	if inm == "" {
+		return false
+	}
+	for _, v := range strings.Split(inm, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/micro-plat/hydra/components"
+	"github.com/micro-plat/hydra/components/caches"
+	"github.com/micro-plat/hydra/conf/server/idempotency"
+)
+
+const (
+	idemProcessing = "processing"
+	idemDone       = "done"
+)
+
+//idemEntry 幂等键对应的处理状态与响应结果
+type idemEntry struct {
+	State       string            `json:"state"`
+	Status      int               `json:"status,omitempty"`
+	ContentType string            `json:"contentType,omitempty"`
+	Content     string            `json:"content,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
+
+//Idempotency 根据幂等键对写请求进行去重
+func Idempotency() Handler {
+	return func(ctx IMiddleContext) {
+
+		//获取幂等配置
+		idem, err := ctx.APPConf().GetIdempotencyConf()
+		if err != nil {
+			ctx.Response().Abort(http.StatusNotExtended, err)
+			return
+		}
+		if idem.Disable {
+			ctx.Next()
+			return
+		}
+
+		//仅处理写请求
+		method := ctx.Request().Path().GetMethod()
+		if !idempotency.IsMutating(method) {
+			ctx.Next()
+			return
+		}
+
+		//判断请求是否指定幂等规则
+		path := ctx.Request().Path().GetRequestPath()
+		enable, rule := idem.GetRule(path)
+		if !enable {
+			ctx.Next()
+			return
+		}
+
+		//检查幂等键
+		key := ctx.Request().GetHTTPRequest().Header.Get(idem.Header)
+		if key == "" {
+			if rule.Required {
+				ctx.Response().Abort(http.StatusBadRequest, fmt.Errorf("请求头%s不能为空", idem.Header))
+				return
+			}
+			ctx.Next()
+			return
+		}
+
+		c, err := components.Def.Cache().GetCache(idem.Cache)
+		if err != nil {
+			ctx.Response().Abort(http.StatusNotExtended, fmt.Errorf("获取幂等缓存组件[%s]失败:%w", idem.Cache, err))
+			return
+		}
+		ckey := rule.GetKey(method, path, getRequestUser(ctx), key)
+
+		//已处理的请求直接返回保存的结果
+		if replayIdemEntry(ctx, c, ckey) {
+			return
+		}
+
+		//锁定幂等键
+		if rule.DLock {
+			lk, err := components.Def.DLock(strings.ReplaceAll(ckey, ":", "/"))
+			if err != nil {
+				ctx.Response().Abort(http.StatusNotExtended, err)
+				return
+			}
+			if err := lk.TryLock(); err != nil {
+				ctx.Response().AddSpecial("idempotency")
+				ctx.Response().Abort(http.StatusConflict, fmt.Errorf("请求%s正在处理中", key))
+				return
+			}
+			defer lk.Unlock()
+			if replayIdemEntry(ctx, c, ckey) {
+				return
+			}
+		}
+		if err := c.Add(ckey, fmt.Sprintf(`{"state":"%s"}`, idemProcessing), rule.GetTimeout()); err != nil && !rule.DLock {
+			if replayIdemEntry(ctx, c, ckey) {
+				return
+			}
+			ctx.Response().AddSpecial("idempotency")
+			ctx.Response().Abort(http.StatusConflict, fmt.Errorf("请求%s正在处理中", key))
+			return
+		}
+
+		//处理业务，业务异常退出时释放幂等键
+		completed := false
+		defer func() {
+			if !completed {
+				c.Delete(ckey)
+			}
+		}()
+		ctx.Next()
+		completed = saveIdemEntry(ctx, c, ckey, rule)
+	}
+}
+
+//replayIdemEntry 幂等键已处理完成时返回保存的结果，处理中时返回409
+func replayIdemEntry(ctx IMiddleContext, c caches.ICache, ckey string) bool {
+	value, err := c.Get(ckey)
+	if err != nil || value == "" {
+		return false
+	}
+	entry := &idemEntry{}
+	if err := json.Unmarshal([]byte(value), entry); err != nil {
+		return false
+	}
+	ctx.Response().AddSpecial("idempotency")
+	if entry.State != idemDone {
+		ctx.Response().Abort(http.StatusConflict, fmt.Errorf("请求正在处理中"))
+		return true
+	}
+	for k, v := range entry.Headers {
+		ctx.Response().Header(k, v)
+	}
+	if entry.ContentType != "" {
+		ctx.Response().ContentType(entry.ContentType)
+	}
+	ctx.Response().Header("Idempotent-Replayed", "true")
+	ctx.Response().Abort(entry.Status, entry.Content)
+	return true
+}
+
+//saveIdemEntry 保存最终响应结果，服务器错误时不保存，允许客户端重试
+func saveIdemEntry(ctx IMiddleContext, c caches.ICache, ckey string, rule *idempotency.Rule) bool {
+	status, content, contentType := ctx.Response().GetFinalResponse()
+	if status == 0 || status >= http.StatusInternalServerError {
+		return false
+	}
+	headers := make(map[string]string)
+	rheaders := ctx.Response().GetHeaders()
+	for k := range rheaders {
+		if k == "Content-Type" || k == "Content-Length" {
+			continue
+		}
+		headers[k] = rheaders.GetString(k)
+	}
+	entry := &idemEntry{
+		State:       idemDone,
+		Status:      status,
+		ContentType: contentType,
+		Content:     content,
+		Headers:     headers,
+	}
+	value, err := json.Marshal(entry)
+	if err != nil {
+		ctx.Log().Errorf("序列化幂等响应结果失败:%v", err)
+		return false
+	}
+	if err := c.Set(ckey, string(value), rule.GetExpire()); err != nil {
+		ctx.Log().Errorf("保存幂等响应结果失败:%v", err)
+		return false
+	}
+	return true
+}
@@ -96,6 +96,13 @@ func (m *Metric) Handle() Handler {
 			"url", url, "status", fmt.Sprintf("%d", statusCode)) //完成数
 		//7. 对服务处理结果的状态码进行上报
 		metrics.GetOrRegisterMeter(responseName, m.currentRegistry).Mark(1)
+
+		//8. 对转到上游集群的请求按集群上报
+		if upcluster := ctx.Meta().GetString(proxyUpClusterKey); upcluster != "" {
+			proxyName := metrics.MakeName(ctx.APPConf().GetServerConf().GetServerType()+".server.proxy", metrics.METER, "server", ctx.APPConf().GetServerConf().GetServerName(), "host", m.ip,
+				"url", url, "upcluster", upcluster, "status", fmt.Sprintf("%d", statusCode))
+			metrics.GetOrRegisterMeter(proxyName, m.currentRegistry).Mark(1)
+		}
 	}
 
 }
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"net/http"
+
+	"github.com/micro-plat/hydra/context"
 )
 
 //Render 响应结果输出组件
@@ -22,6 +24,8 @@ func Render() Handler {
 			return
 		}
 
+		//tengo脚本通过当前goroutine获取请求上下文
+		defer context.Bind(ctx)()
 		rd, enable, err := render.Get()
 		if err != nil {
 			ctx.Log().Error("render出错:", err)
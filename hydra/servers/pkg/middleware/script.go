@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/d5/tengo/v2"
+	"github.com/micro-plat/hydra/components"
+	"github.com/micro-plat/hydra/components/caches"
+	"github.com/micro-plat/hydra/conf/server/script"
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/tgo"
+)
+
+func init() {
+	for _, m := range getScriptModules() {
+		global.AddHookTGOModule(m.Name, m.Module)
+	}
+}
+
+//Script 请求钩子脚本，按请求路径在业务处理前后执行tengo脚本
+func Script() Handler {
+	return func(ctx IMiddleContext) {
+
+		//获取钩子脚本配置
+		scripts, err := ctx.APPConf().GetScriptConf()
+		if err != nil {
+			ctx.Response().Abort(http.StatusNotExtended, err)
+			return
+		}
+		if scripts.Disable {
+			ctx.Next()
+			return
+		}
+
+		//业务处理前执行，脚本返回结果时不再执行业务处理
+		path := ctx.Request().Path().GetRequestPath()
+		if hook, ok := scripts.GetHook(script.PhaseBefore, path); ok {
+			result, err := runHook(ctx, hook)
+			if err != nil {
+				ctx.Response().AddSpecial("script")
+				ctx.Response().Abort(http.StatusInternalServerError, fmt.Errorf("执行%s前置脚本出错:%w", path, err))
+				return
+			}
+			for k, v := range result.Params {
+				if v == nil {
+					delete(ctx.Request().GetMap(), k)
+					continue
+				}
+				ctx.Request().GetMap()[k] = v
+			}
+			for k, v := range result.Headers {
+				k = http.CanonicalHeaderKey(k)
+				ctx.Request().GetHTTPRequest().Header.Set(k, v)
+				ctx.Request().Headers()[k] = v
+			}
+			if result.Abort {
+				ctx.Response().AddSpecial("script")
+				if result.ContentType != "" {
+					ctx.Response().ContentType(result.ContentType)
+				}
+				ctx.Response().Abort(result.Status, result.Content)
+				return
+			}
+		}
+
+		ctx.Next()
+
+		//业务处理后执行，脚本出错时保留原响应结果
+		hook, ok := scripts.GetHook(script.PhaseAfter, path)
+		if !ok {
+			return
+		}
+		result, err := runHook(ctx, hook)
+		if err != nil {
+			ctx.Log().Errorf("执行%s后置脚本出错:%v", path, err)
+			return
+		}
+		for k, v := range result.Headers {
+			ctx.Response().Header(k, v)
+		}
+		if result.Abort {
+			ctx.Response().AddSpecial("script")
+			if result.ContentType != "" {
+				ctx.Response().ContentType(result.ContentType)
+			}
+			ctx.Response().Write(result.Status, result.Content)
+		}
+	}
+}
+
+//runHook 执行钩子脚本，tengo脚本通过当前goroutine获取请求上下文
+func runHook(ctx IMiddleContext, hook *script.Hook) (*script.Result, error) {
+	defer context.Bind(ctx)()
+	return hook.Run()
+}
+
+//getScriptModules 钩子脚本可调用的组件，只能访问script配置中白名单内的组件
+func getScriptModules() []global.TGOModule {
+	cache := tgo.NewModule("cache").
+		Add("get", func(args ...tengo.Object) (tengo.Object, error) {
+			name, key, err := getScriptArgs(args, 2)
+			if err != nil {
+				return nil, err
+			}
+			c, err := getScriptCache(name)
+			if err != nil {
+				return wrapScriptError(err), nil
+			}
+			v, err := c.Get(key)
+			if err != nil {
+				return wrapScriptError(err), nil
+			}
+			return &tengo.String{Value: v}, nil
+		}).
+		Add("set", func(args ...tengo.Object) (tengo.Object, error) {
+			name, key, err := getScriptArgs(args, 4)
+			if err != nil {
+				return nil, err
+			}
+			value, _ := tengo.ToString(args[2])
+			expire, ok := tengo.ToInt(args[3])
+			if !ok {
+				return nil, tengo.ErrInvalidArgumentType{Name: "expire", Expected: "int", Found: args[3].TypeName()}
+			}
+			c, err := getScriptCache(name)
+			if err != nil {
+				return wrapScriptError(err), nil
+			}
+			return wrapScriptError(c.Set(key, value, expire)), nil
+		}).
+		Add("delete", func(args ...tengo.Object) (tengo.Object, error) {
+			name, key, err := getScriptArgs(args, 2)
+			if err != nil {
+				return nil, err
+			}
+			c, err := getScriptCache(name)
+			if err != nil {
+				return wrapScriptError(err), nil
+			}
+			return wrapScriptError(c.Delete(key)), nil
+		})
+
+	queue := tgo.NewModule("queue").
+		Add("send", func(args ...tengo.Object) (tengo.Object, error) {
+			name, key, err := getScriptArgs(args, 3)
+			if err != nil {
+				return nil, err
+			}
+			conf, err := context.Current().APPConf().GetScriptConf()
+			if err != nil {
+				return wrapScriptError(err), nil
+			}
+			if !conf.AllowQueue(name) {
+				return wrapScriptError(fmt.Errorf("脚本不允许访问消息队列组件:%s", name)), nil
+			}
+			q, err := components.Def.Queue().GetQueue(name)
+			if err != nil {
+				return wrapScriptError(err), nil
+			}
+			return wrapScriptError(q.Send(key, tengo.ToInterface(args[2]), context.Current().User().GetTraceID())), nil
+		})
+	return []global.TGOModule{{Name: "cache", Module: cache}, {Name: "queue", Module: queue}}
+}
+
+//getScriptArgs 检查参数个数，并获取组件名称与键名称
+func getScriptArgs(args []tengo.Object, n int) (name string, key string, err error) {
+	if len(args) != n {
+		return "", "", tengo.ErrWrongNumArguments
+	}
+	name, ok := tengo.ToString(args[0])
+	if !ok {
+		return "", "", tengo.ErrInvalidArgumentType{Name: "name", Expected: "string", Found: args[0].TypeName()}
+	}
+	key, ok = tengo.ToString(args[1])
+	if !ok {
+		return "", "", tengo.ErrInvalidArgumentType{Name: "key", Expected: "string", Found: args[1].TypeName()}
+	}
+	return name, key, nil
+}
+
+//getScriptCache 获取白名单内的缓存组件
+func getScriptCache(name string) (caches.ICache, error) {
+	conf, err := context.Current().APPConf().GetScriptConf()
+	if err != nil {
+		return nil, err
+	}
+	if !conf.AllowCache(name) {
+		return nil, fmt.Errorf("脚本不允许访问缓存组件:%s", name)
+	}
+	return components.Def.Cache().GetCache(name)
+}
+
+//wrapScriptError 转换为脚本中的error对象，脚本通过is_error判断
+func wrapScriptError(err error) tengo.Object {
+	if err == nil {
+		return tengo.TrueValue
+	}
+	return &tengo.Error{Value: &tengo.String{Value: err.Error()}}
+}
@@ -0,0 +1,56 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/micro-plat/hydra/conf/server/script"
+	"github.com/micro-plat/hydra/creator"
+	"github.com/micro-plat/hydra/hydra/servers/pkg/middleware"
+	"github.com/micro-plat/hydra/mock"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+type middle struct {
+	next bool
+}
+
+func (m *middle) Next()                    { m.next = true }
+func (m *middle) Find(path string) bool    { return true }
+func (m *middle) GetRouterPath() string    { return "" }
+func (m *middle) ClearAuth(c ...bool) bool { return false }
+func (m *middle) GetWriter() interface{}   { return nil }
+func (m *middle) SetWriter(w interface{})  {}
+func (m *middle) GetType() string          { return "api" }
+
+func TestScript(t *testing.T) {
+	conf := creator.New()
+	conf.API("8080").Script(
+		script.WithBefore("/order/create", `params := {"source": "hook", "token": undefined}; headers := {"x-hook": "before"}`),
+		script.WithAfter("/order/create", `headers := {"X-After": "after"}`),
+		script.WithBefore("/order/pay", `result := [403, "forbidden"]`))
+
+	req := httptest.NewRequest(http.MethodPost, "/order/create", nil)
+	ctx := mock.NewContext(`{"id":"1","token":"abc"}`, mock.WithConf(conf), mock.WithURL("/order/create"), mock.WithRequest(req))
+	m := &middle{}
+	middleware.Script()(middleware.NewMiddleContext(ctx, m))
+	assert.Equal(t, true, m.next, "1. 未返回结果时执行业务处理")
+	assert.Equal(t, "1", ctx.Request().GetString("id"), "2. 保留未修改的参数")
+	assert.Equal(t, "hook", ctx.Request().GetString("source"), "3. 添加请求参数")
+	_, ok := ctx.Request().GetMap()["token"]
+	assert.Equal(t, false, ok, "4. 删除值为undefined的请求参数")
+	assert.Equal(t, "before", ctx.Request().Headers().GetString("X-Hook"), "5. 修改请求头")
+	assert.Equal(t, "before", req.Header.Get("X-Hook"), "5. 修改请求头")
+	assert.Equal(t, "after", ctx.Response().GetHeaders().GetString("X-After"), "6. 后置脚本修改响应头")
+
+	//配置已发布的节点不能重复发布，使用其它集群
+	req = httptest.NewRequest(http.MethodPost, "/order/pay", nil)
+	ctx = mock.NewContext(`{"id":"1"}`, mock.WithConf(conf), mock.WithClusterName("pay"), mock.WithURL("/order/pay"), mock.WithRequest(req))
+	m = &middle{}
+	middleware.Script()(middleware.NewMiddleContext(ctx, m))
+	assert.Equal(t, false, m.next, "7. 返回结果时不执行业务处理")
+	status, content, _ := ctx.Response().GetFinalResponse()
+	assert.Equal(t, http.StatusForbidden, status, "8. 直接返回脚本设置的响应")
+	assert.Equal(t, "forbidden", content, "8. 直接返回脚本设置的响应")
+}
@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs"
+	"github.com/micro-plat/hydra/registry"
+)
+
+//NodeName 插件在注册中心中的节点名
+const NodeName = "plugins"
+
+const (
+	//defaultMaxCalls 未配置时单次调用的最大函数调用次数
+	defaultMaxCalls = 10000000
+
+	//defaultMemory 未配置时单次调用的最大内存(MB)
+	defaultMemory = 64
+
+	//defaultTimeout 未配置时单次调用的超时时长(毫秒)
+	defaultTimeout = 3000
+)
+
+//Conf 插件配置，保存于注册中心插件节点
+type Conf struct {
+	WASM     string   `json:"wasm,omitempty" label:"base64编码的wasm模块"`
+	NFS      string   `json:"nfs,omitempty" label:"wasm模块在nfs中的文件名"`
+	MaxCalls int64    `json:"max_calls,omitempty" label:"单次调用最大wasm函数调用次数(不限制指令数)"`
+	Memory   uint32   `json:"memory,omitempty" label:"单次调用最大内存(MB)"`
+	Timeout  int      `json:"timeout,omitempty" label:"单次调用超时时长(毫秒)"`
+	Caches   []string `json:"caches,omitempty" label:"插件可访问的缓存组件"`
+	Queues   []string `json:"queues,omitempty" label:"插件可访问的消息队列组件"`
+}
+
+//GetPath 获取插件在注册中心中的路径
+func GetPath(platName string, sysName string, name string) string {
+	return registry.Join(platName, sysName, NodeName, name)
+}
+
+//Pub 发布插件配置，插件节点已存在时更新配置，运行中的插件将重新加载
+func Pub(r registry.IRegistry, path string, c *Conf) error {
+	buff, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	ok, err := r.Exists(path)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return r.Update(path, string(buff))
+	}
+	return r.CreatePersistentNode(path, string(buff))
+}
+
+//GetMaxCalls 获取单次调用最大函数调用次数，未设置时使用默认值
+func (c *Conf) GetMaxCalls() int64 {
+	if c.MaxCalls <= 0 {
+		return defaultMaxCalls
+	}
+	return c.MaxCalls
+}
+
+//GetMemory 获取单次调用最大内存(MB)，未设置时使用默认值
+func (c *Conf) GetMemory() uint32 {
+	if c.Memory == 0 {
+		return defaultMemory
+	}
+	return c.Memory
+}
+
+//GetTimeout 获取单次调用超时时长，未设置时使用默认值
+func (c *Conf) GetTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return defaultTimeout * time.Millisecond
+	}
+	return time.Duration(c.Timeout) * time.Millisecond
+}
+
+//AllowCache 插件是否可访问指定的缓存组件
+func (c *Conf) AllowCache(name string) bool {
+	return contains(c.Caches, name)
+}
+
+//AllowQueue 插件是否可访问指定的消息队列组件
+func (c *Conf) AllowQueue(name string) bool {
+	return contains(c.Queues, name)
+}
+
+//getWASM 获取wasm模块内容，优先使用配置中的模块，未配置时从nfs中下载
+func (c *Conf) getWASM() ([]byte, error) {
+	if c.WASM != "" {
+		buff, err := base64.StdEncoding.DecodeString(c.WASM)
+		if err != nil {
+			return nil, fmt.Errorf("wasm模块不是有效的base64编码:%w", err)
+		}
+		return buff, nil
+	}
+	if c.NFS != "" {
+		return nfs.Download(c.NFS)
+	}
+	return nil, fmt.Errorf("未配置wasm模块")
+}
+
+func contains(list []string, name string) bool {
+	for _, v := range list {
+		if v == name || v == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package plugin
+
+import (
+	r "context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/micro-plat/hydra/components"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+//hostModule 提供给插件的宿主模块名
+const hostModule = "hydra"
+
+//日志级别
+const (
+	levelDebug uint32 = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+type invocationKey struct{}
+
+//invocation 单次调用的请求与响应数据
+type invocation struct {
+	request []byte
+	traceID string
+	log     logger.ILogging
+	conf    *Conf
+	calls   int64
+	status  int
+	content []byte
+	headers map[string]string
+	err     error
+}
+
+func newInvocation(request []byte, traceID string, log logger.ILogging) *invocation {
+	return &invocation{
+		request: request,
+		traceID: traceID,
+		log:     log,
+		headers: make(map[string]string),
+	}
+}
+
+//fail 记录最后一次错误，插件通过last_error获取
+func (v *invocation) fail(err error) int32 {
+	v.err = err
+	return -1
+}
+
+/*
+newHostModule 构建宿主模块，插件通过线性内存中的地址与长度交换数据：
+request(ptr,len) 写入请求json{method,path,params,headers}，返回实际长度，缓冲区不足时不写入
+response_write(status,ptr,len) 设置响应状态码与内容
+response_header(kptr,klen,vptr,vlen) 设置响应头
+log(level,ptr,len) 记录日志，level:0debug 1info 2warn 3error
+cache_get(nptr,nlen,kptr,klen,ptr,len) 获取缓存值，返回实际长度，出错时返回-1
+cache_set(nptr,nlen,kptr,klen,vptr,vlen,expire) 设置缓存，成功返回0，出错时返回-1
+cache_delete(nptr,nlen,kptr,klen) 删除缓存，成功返回0，出错时返回-1
+queue_send(nptr,nlen,kptr,klen,vptr,vlen) 发送消息，成功返回0，出错时返回-1
+last_error(ptr,len) 获取最后一次错误信息，返回实际长度
+*/
+func newHostModule(rt wazero.Runtime) wazero.HostModuleBuilder {
+	return rt.NewHostModuleBuilder(hostModule).
+		NewFunctionBuilder().WithFunc(hostRequest).Export("request").
+		NewFunctionBuilder().WithFunc(hostResponseWrite).Export("response_write").
+		NewFunctionBuilder().WithFunc(hostResponseHeader).Export("response_header").
+		NewFunctionBuilder().WithFunc(hostLog).Export("log").
+		NewFunctionBuilder().WithFunc(hostCacheGet).Export("cache_get").
+		NewFunctionBuilder().WithFunc(hostCacheSet).Export("cache_set").
+		NewFunctionBuilder().WithFunc(hostCacheDelete).Export("cache_delete").
+		NewFunctionBuilder().WithFunc(hostQueueSend).Export("queue_send").
+		NewFunctionBuilder().WithFunc(hostLastError).Export("last_error")
+}
+
+func hostRequest(ctx r.Context, m api.Module, ptr, size uint32) int32 {
+	return write(m, ptr, size, getInvocation(ctx).request)
+}
+
+func hostResponseWrite(ctx r.Context, m api.Module, status, ptr, size uint32) {
+	inv := getInvocation(ctx)
+	inv.status = int(status)
+	inv.content = read(m, ptr, size)
+}
+
+func hostResponseHeader(ctx r.Context, m api.Module, kptr, ksize, vptr, vsize uint32) {
+	getInvocation(ctx).headers[string(read(m, kptr, ksize))] = string(read(m, vptr, vsize))
+}
+
+func hostLog(ctx r.Context, m api.Module, level, ptr, size uint32) {
+	inv := getInvocation(ctx)
+	msg := string(read(m, ptr, size))
+	switch level {
+	case levelDebug:
+		inv.log.Debug(msg)
+	case levelInfo:
+		inv.log.Info(msg)
+	case levelWarn:
+		inv.log.Warn(msg)
+	default:
+		inv.log.Error(msg)
+	}
+}
+
+func hostCacheGet(ctx r.Context, m api.Module, nptr, nsize, kptr, ksize, ptr, size uint32) int32 {
+	inv := getInvocation(ctx)
+	name, key := string(read(m, nptr, nsize)), string(read(m, kptr, ksize))
+	if !inv.conf.AllowCache(name) {
+		return inv.fail(fmt.Errorf("插件不允许访问缓存组件:%s", name))
+	}
+	c, err := components.Def.Cache().GetCache(name)
+	if err != nil {
+		return inv.fail(err)
+	}
+	value, err := c.Get(key)
+	if err != nil {
+		return inv.fail(err)
+	}
+	return write(m, ptr, size, []byte(value))
+}
+
+func hostCacheSet(ctx r.Context, m api.Module, nptr, nsize, kptr, ksize, vptr, vsize uint32, expire int32) int32 {
+	inv := getInvocation(ctx)
+	name, key := string(read(m, nptr, nsize)), string(read(m, kptr, ksize))
+	if !inv.conf.AllowCache(name) {
+		return inv.fail(fmt.Errorf("插件不允许访问缓存组件:%s", name))
+	}
+	c, err := components.Def.Cache().GetCache(name)
+	if err != nil {
+		return inv.fail(err)
+	}
+	if err := c.Set(key, string(read(m, vptr, vsize)), int(expire)); err != nil {
+		return inv.fail(err)
+	}
+	return 0
+}
+
+func hostCacheDelete(ctx r.Context, m api.Module, nptr, nsize, kptr, ksize uint32) int32 {
+	inv := getInvocation(ctx)
+	name, key := string(read(m, nptr, nsize)), string(read(m, kptr, ksize))
+	if !inv.conf.AllowCache(name) {
+		return inv.fail(fmt.Errorf("插件不允许访问缓存组件:%s", name))
+	}
+	c, err := components.Def.Cache().GetCache(name)
+	if err != nil {
+		return inv.fail(err)
+	}
+	if err := c.Delete(key); err != nil {
+		return inv.fail(err)
+	}
+	return 0
+}
+
+func hostQueueSend(ctx r.Context, m api.Module, nptr, nsize, kptr, ksize, vptr, vsize uint32) int32 {
+	inv := getInvocation(ctx)
+	name, key := string(read(m, nptr, nsize)), string(read(m, kptr, ksize))
+	if !inv.conf.AllowQueue(name) {
+		return inv.fail(fmt.Errorf("插件不允许访问消息队列组件:%s", name))
+	}
+	q, err := components.Def.Queue().GetQueue(name)
+	if err != nil {
+		return inv.fail(err)
+	}
+
+	//json格式的消息按对象发送，其它按字符串发送
+	buff := read(m, vptr, vsize)
+	var value interface{}
+	if err := json.Unmarshal(buff, &value); err != nil {
+		value = string(buff)
+	}
+	if err := q.Send(key, value, inv.traceID); err != nil {
+		return inv.fail(err)
+	}
+	return 0
+}
+
+func hostLastError(ctx r.Context, m api.Module, ptr, size uint32) int32 {
+	inv := getInvocation(ctx)
+	if inv.err == nil {
+		return 0
+	}
+	return write(m, ptr, size, []byte(inv.err.Error()))
+}
+
+func getInvocation(ctx r.Context) *invocation {
+	return ctx.Value(invocationKey{}).(*invocation)
+}
+
+//read 从插件内存中复制数据，地址越界时中止执行
+func read(m api.Module, ptr, size uint32) []byte {
+	mem := m.Memory()
+	if mem == nil {
+		panic(fmt.Errorf("插件未导出内存"))
+	}
+	buff, ok := mem.Read(ptr, size)
+	if !ok {
+		panic(fmt.Errorf("读取插件内存越界:%d+%d", ptr, size))
+	}
+	return append([]byte(nil), buff...)
+}
+
+//write 向插件内存写入数据并返回数据长度，缓冲区不足时只返回长度
+func write(m api.Module, ptr, size uint32, data []byte) int32 {
+	if uint32(len(data)) > size {
+		return int32(len(data))
+	}
+	mem := m.Memory()
+	if mem == nil {
+		panic(fmt.Errorf("插件未导出内存"))
+	}
+	if !mem.Write(ptr, data) {
+		panic(fmt.Errorf("写入插件内存越界:%d+%d", ptr, len(data)))
+	}
+	return int32(len(data))
+}
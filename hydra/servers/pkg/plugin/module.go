@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	r "context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+const (
+	//exportHandle 插件导出的处理函数
+	exportHandle = "handle"
+
+	//startInitialize reactor类型模块的初始化函数，每次实例化时执行
+	startInitialize = "_initialize"
+
+	//pagesPerMB 每MB内存的wasm内存页数
+	pagesPerMB = 16
+
+	//maxMemory wasm内存最大值(MB)
+	maxMemory = 4096
+)
+
+var errCallsExceeded = errors.New("超过最大函数调用次数")
+
+//module 已编译的插件模块，每个模块使用独立的运行时，
+//每次调用时创建新的模块实例，调用结束后释放，调用之间不共享内存
+type module struct {
+	conf     *Conf
+	content  string
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	wg       sync.WaitGroup
+}
+
+//newModule 编译wasm模块，使用纯go实现的解释器执行
+func newModule(c *Conf, content string, wasm []byte) (m *module, err error) {
+	if c.Memory > maxMemory {
+		return nil, fmt.Errorf("插件内存限制不能超过%dMB", maxMemory)
+	}
+	ctx := r.Background()
+	cfg := wazero.NewRuntimeConfigInterpreter().WithCloseOnContextDone(true).WithMemoryLimitPages(c.GetMemory() * pagesPerMB)
+	rt := wazero.NewRuntimeWithConfig(ctx, cfg)
+	defer func() {
+		if err != nil {
+			rt.Close(ctx)
+		}
+	}()
+
+	//wasi未配置文件系统、环境变量与标准输出，插件只能通过hydra模块访问外部资源
+	if _, err = wasi_snapshot_preview1.Instantiate(ctx, rt); err != nil {
+		return nil, err
+	}
+	if _, err = newHostModule(rt).Instantiate(ctx); err != nil {
+		return nil, err
+	}
+
+	//函数调用监听器在编译时绑定
+	ctx = r.WithValue(ctx, experimental.FunctionListenerFactoryKey{}, experimental.FunctionListenerFactoryFunc(newCallListener))
+	compiled, err := rt.CompileModule(ctx, wasm)
+	if err != nil {
+		return nil, fmt.Errorf("wasm模块编译失败:%w", err)
+	}
+	if _, ok := compiled.ExportedFunctions()[exportHandle]; !ok {
+		return nil, fmt.Errorf("wasm模块未导出%s函数", exportHandle)
+	}
+	return &module{conf: c, content: content, runtime: rt, compiled: compiled}, nil
+}
+
+//invoke 创建模块实例并执行处理函数，执行时长与函数调用次数超过限制时中止执行
+func (m *module) invoke(inv *invocation) error {
+	inv.conf = m.conf
+	inv.calls = m.conf.GetMaxCalls()
+	ctx, cancel := r.WithTimeout(r.WithValue(r.Background(), invocationKey{}, inv), m.conf.GetTimeout())
+	defer cancel()
+
+	cfg := wazero.NewModuleConfig().WithName("").WithStartFunctions(startInitialize)
+	mod, err := m.runtime.InstantiateModule(ctx, m.compiled, cfg)
+	if err != nil {
+		return m.wrapError(ctx, err)
+	}
+	defer mod.Close(r.Background())
+	if _, err := mod.ExportedFunction(exportHandle).Call(ctx); err != nil {
+		return m.wrapError(ctx, err)
+	}
+	return nil
+}
+
+//close 关闭运行时，释放已编译的模块
+func (m *module) close() {
+	m.runtime.Close(r.Background())
+}
+
+func (m *module) wrapError(ctx r.Context, err error) error {
+	if errors.Is(ctx.Err(), r.DeadlineExceeded) {
+		return fmt.Errorf("执行超时(%v)", m.conf.GetTimeout())
+	}
+	if errors.Is(err, errCallsExceeded) {
+		return fmt.Errorf("%w(%d)", errCallsExceeded, m.conf.GetMaxCalls())
+	}
+	return err
+}
+
+//newCallListener 每次调用模块内的wasm函数时计数，超过最大函数调用次数时中止执行。
+//只统计函数调用而非指令数，不调用其它函数的循环不计数，只能由超时时长中止
+func newCallListener(def api.FunctionDefinition) experimental.FunctionListener {
+	return experimental.FunctionListenerFunc(func(ctx r.Context, _ api.Module, _ api.FunctionDefinition, _ []uint64, _ experimental.StackIterator) {
+		inv, ok := ctx.Value(invocationKey{}).(*invocation)
+		if !ok {
+			return
+		}
+		if inv.calls--; inv.calls < 0 {
+			panic(errCallsExceeded)
+		}
+	})
+}
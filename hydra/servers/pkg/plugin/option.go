@@ -0,0 +1,30 @@
+package plugin
+
+import (
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//Option 配置选项
+type Option func(*Plugin)
+
+//WithRegistry 设置插件所在的注册中心，默认使用当前服务的注册中心
+func WithRegistry(r registry.IRegistry) Option {
+	return func(p *Plugin) {
+		p.rgst = r
+	}
+}
+
+//WithPath 设置插件节点路径，默认为/平台/系统/plugins/插件名
+func WithPath(path string) Option {
+	return func(p *Plugin) {
+		p.path = path
+	}
+}
+
+//WithLogger 设置日志组件
+func WithLogger(log logger.ILogging) Option {
+	return func(p *Plugin) {
+		p.log = log
+	}
+}
@@ -0,0 +1,234 @@
+/*
+插件处理函数，处理逻辑由wasm模块实现，模块保存于注册中心插件节点(/平台/系统/plugins/插件名)或nfs中，
+使用纯go实现的解释器执行。插件节点变化时重新编译模块并替换，正在执行的调用完成后释放旧模块，无需重启服务。
+插件导出handle函数处理请求，通过hydra宿主模块获取请求、设置响应、记录日志并访问白名单中的缓存与消息队列组件；
+每次调用使用新的模块实例，执行时长、函数调用次数(max_calls)与内存受配置限制，未配置时使用默认限制；
+max_calls限制的是wasm函数调用次数而非执行的指令数，不调用其它函数的循环只能由执行时长(timeout)中止。
+使用nfs中的模块时，更新模块需使用新的文件名，以触发插件重新加载。
+*/
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/errs"
+	"github.com/micro-plat/lib4go/logger"
+	lregistry "github.com/micro-plat/lib4go/registry"
+)
+
+//SyncSpan 插件配置全量校对的时间间隔，防止监控事件丢失
+var SyncSpan = time.Minute
+
+//Plugin 插件处理函数，通过services.Def.Custom、API等注册为服务
+type Plugin struct {
+	name      string
+	path      string
+	rgst      registry.IRegistry
+	log       logger.ILogging
+	current   *module
+	err       error
+	lk        sync.RWMutex
+	reloadLk  sync.Mutex
+	startOnce sync.Once
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
+
+//New 构建插件处理函数，首次处理请求时从注册中心加载插件并开始监控
+func New(name string, opts ...Option) *Plugin {
+	p := &Plugin{
+		name:    name,
+		closeCh: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+//Handle 执行插件处理请求
+func (p *Plugin) Handle(ctx context.IContext) interface{} {
+	m, err := p.acquire()
+	if err != nil {
+		return errs.NewError(http.StatusInternalServerError, err)
+	}
+	defer m.wg.Done()
+
+	request, err := json.Marshal(map[string]interface{}{
+		"method":  ctx.Request().Path().GetMethod(),
+		"path":    ctx.Request().Path().GetRequestPath(),
+		"params":  ctx.Request().GetMap(),
+		"headers": ctx.Request().Headers(),
+	})
+	if err != nil {
+		return errs.NewError(http.StatusNotAcceptable, fmt.Errorf("插件%s请求参数有误:%w", p.name, err))
+	}
+	inv := newInvocation(request, ctx.User().GetTraceID(), ctx.Log())
+	if err := m.invoke(inv); err != nil {
+		return errs.NewError(http.StatusInternalServerError, fmt.Errorf("执行插件%s出错:%w", p.name, err))
+	}
+
+	contentType := ""
+	for k, v := range inv.headers {
+		if http.CanonicalHeaderKey(k) == "Content-Type" {
+			contentType = v
+			continue
+		}
+		ctx.Response().Header(k, v)
+	}
+	if inv.status == 0 {
+		inv.status = http.StatusOK
+	}
+	return ctx.Response().Data(inv.status, contentType, string(inv.content))
+}
+
+//Close 停止监控并释放插件模块
+func (p *Plugin) Close() error {
+	p.closeOnce.Do(func() {
+		p.startOnce.Do(func() {})
+		close(p.closeCh)
+		p.swap(nil)
+	})
+	return nil
+}
+
+//acquire 获取当前插件模块，调用完成后需执行wg.Done
+func (p *Plugin) acquire() (*module, error) {
+	p.startOnce.Do(p.start)
+	p.lk.RLock()
+	defer p.lk.RUnlock()
+	if p.current == nil {
+		if p.err != nil {
+			return nil, p.err
+		}
+		return nil, fmt.Errorf("插件%s未加载", p.name)
+	}
+	p.current.wg.Add(1)
+	return p.current, nil
+}
+
+//start 加载插件并开始监控插件节点
+func (p *Plugin) start() {
+	if p.log == nil {
+		p.log = global.Def.Log()
+	}
+	if p.path == "" {
+		p.path = GetPath(global.Def.PlatName, global.Def.SysName, p.name)
+	}
+	if p.rgst == nil {
+		r, err := registry.GetRegistry(global.Def.RegistryAddr, p.log)
+		if err != nil {
+			p.err = fmt.Errorf("插件%s获取注册中心失败:%w", p.name, err)
+			return
+		}
+		p.rgst = r
+	}
+
+	//先监控后加载，防止遗漏加载期间的变化
+	ch := p.watch()
+	p.reload()
+	go p.loop(ch)
+}
+
+func (p *Plugin) loop(ch chan lregistry.ValueWatcher) {
+	tk := time.NewTicker(SyncSpan)
+	defer tk.Stop()
+	for {
+		select {
+		case <-p.closeCh:
+			return
+		case w, ok := <-ch:
+			ch = nil
+			if !ok || w.GetError() != nil {
+				continue
+			}
+		case <-tk.C:
+		}
+		if ch == nil {
+			ch = p.watch()
+		}
+		p.reload()
+	}
+}
+
+//watch 监控插件节点的值变化，节点不存在等原因监控失败时等待全量校对
+func (p *Plugin) watch() chan lregistry.ValueWatcher {
+	ch, err := p.rgst.WatchValue(p.path)
+	if err != nil {
+		return nil
+	}
+	return ch
+}
+
+//reload 插件配置变化时重新编译模块并替换，失败时继续使用原模块
+func (p *Plugin) reload() {
+	p.reloadLk.Lock()
+	defer p.reloadLk.Unlock()
+	buff, _, err := p.rgst.GetValue(p.path)
+	if err != nil {
+		p.fail(fmt.Errorf("获取插件%s配置失败:%w", p.path, err))
+		return
+	}
+	p.lk.RLock()
+	loaded := p.current != nil && p.current.content == string(buff)
+	p.lk.RUnlock()
+	if loaded {
+		return
+	}
+
+	c := &Conf{}
+	if err := json.Unmarshal(buff, c); err != nil {
+		p.fail(fmt.Errorf("插件%s配置有误:%w", p.path, err))
+		return
+	}
+	wasm, err := c.getWASM()
+	if err != nil {
+		p.fail(fmt.Errorf("插件%s获取wasm模块失败:%w", p.path, err))
+		return
+	}
+	m, err := newModule(c, string(buff), wasm)
+	if err != nil {
+		p.fail(fmt.Errorf("插件%s加载失败:%w", p.path, err))
+		return
+	}
+	p.swap(m)
+	p.log.Infof("加载插件:%s", p.path)
+}
+
+//swap 替换当前模块，旧模块在正在执行的调用完成后关闭
+func (p *Plugin) swap(m *module) {
+	select {
+	case <-p.closeCh:
+		if m != nil {
+			m.close()
+			m = nil
+		}
+	default:
+	}
+	p.lk.Lock()
+	old := p.current
+	p.current = m
+	p.err = nil
+	p.lk.Unlock()
+	if old == nil {
+		return
+	}
+	go func() {
+		old.wg.Wait()
+		old.close()
+	}()
+}
+
+func (p *Plugin) fail(err error) {
+	p.lk.Lock()
+	p.err = err
+	p.lk.Unlock()
+	p.log.Error(err)
+}
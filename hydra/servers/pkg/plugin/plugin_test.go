@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/registry"
+	_ "github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//echoV1 调用request获取请求内容并原样输出，设置响应头X-Version:v1
+const echoV1 = "AGFzbQEAAAABFwRgAn9/AX9gA39/fwBgBH9/f38AYAAAAkADBWh5ZHJhB3JlcXVlc3QAAAVoeWRyYQ5yZXNwb25zZV93cml0ZQABBWh5ZHJhD3Jlc3BvbnNlX2hlYWRlcgACAwIBAwUDAQACBxMCBm1lbW9yeQIABmhhbmRsZQADCh8BHQBByAFBgAhBgAhB4NQDEAAQAUEQQQlBIEECEAILCxYCAEEQCwlYLVZlcnNpb24AQSALAnYx"
+
+//echoV2 同echoV1，响应头为X-Version:v2
+const echoV2 = "AGFzbQEAAAABFwRgAn9/AX9gA39/fwBgBH9/f38AYAAAAkADBWh5ZHJhB3JlcXVlc3QAAAVoeWRyYQ5yZXNwb25zZV93cml0ZQABBWh5ZHJhD3Jlc3BvbnNlX2hlYWRlcgACAwIBAwUDAQACBxMCBm1lbW9yeQIABmhhbmRsZQADCh8BHQBByAFBgAhBgAhB4NQDEAAQAUEQQQlBIEECEAILCxYCAEEQCwlYLVZlcnNpb24AQSALAnYy"
+
+//loop 循环调用空函数
+const loop = "AGFzbQEAAAABBAFgAAADAwIAAAUDAQABBxMCBm1lbW9yeQIABmhhbmRsZQABCg4CAgALCQADQBAADAALCw=="
+
+//grow 申请100页内存，失败时执行unreachable
+const grow = "AGFzbQEAAAABBAFgAAADAgEABQMBAAEHEwIGbWVtb3J5AgAGaGFuZGxlAAAKEAEOAEHkAEAAQX9GBEAACws="
+
+func invoke(t *testing.T, c *Conf, request string) (*invocation, error) {
+	wasm, err := c.getWASM()
+	assert.Equal(t, nil, err)
+	m, err := newModule(c, "", wasm)
+	assert.Equal(t, nil, err)
+	defer m.close()
+	inv := newInvocation([]byte(request), "", logger.New("plugin"))
+	return inv, m.invoke(inv)
+}
+
+func TestModule_Invoke(t *testing.T) {
+	inv, err := invoke(t, &Conf{WASM: echoV1}, `{"name":"hydra"}`)
+	assert.Equal(t, nil, err, "1. 获取请求并设置响应")
+	assert.Equal(t, 200, inv.status, "1. 获取请求并设置响应")
+	assert.Equal(t, `{"name":"hydra"}`, string(inv.content), "1. 获取请求并设置响应")
+	assert.Equal(t, "v1", inv.headers["X-Version"], "1. 获取请求并设置响应")
+
+	_, err = invoke(t, &Conf{WASM: loop, MaxCalls: 100}, "")
+	assert.Equal(t, true, errors.Is(err, errCallsExceeded), "2. 超过最大函数调用次数")
+
+	_, err = invoke(t, &Conf{WASM: loop, Timeout: 20}, "")
+	assert.NotEqual(t, nil, err, "3. 执行超时")
+	assert.Equal(t, true, strings.Contains(err.Error(), "超时"), "3. 执行超时")
+
+	_, err = invoke(t, &Conf{WASM: grow, Memory: 1}, "")
+	assert.NotEqual(t, nil, err, "4. 超过最大内存")
+	_, err = invoke(t, &Conf{WASM: grow}, "")
+	assert.Equal(t, nil, err, "5. 未配置内存时使用默认限制")
+	assert.Equal(t, uint32(defaultMemory), (&Conf{}).GetMemory(), "5. 未配置内存时使用默认限制")
+
+	start := time.Now()
+	_, err = invoke(t, &Conf{WASM: loop}, "")
+	assert.NotEqual(t, nil, err, "6. 未配置限制时使用默认的函数调用次数与超时时长")
+	assert.Equal(t, true, time.Since(start) <= defaultTimeout*time.Millisecond+time.Second, "6. 未配置限制时使用默认的函数调用次数与超时时长")
+
+	_, err = newModule(&Conf{}, "", []byte("wasm"))
+	assert.NotEqual(t, nil, err, "7. 无效的wasm模块")
+	_, err = newModule(&Conf{Memory: maxMemory + 1}, "", nil)
+	assert.NotEqual(t, nil, err, "8. 内存限制超过最大值")
+}
+
+func TestPlugin_Reload(t *testing.T) {
+	r, err := registry.GetRegistry("lm://.", logger.New("plugin"))
+	assert.Equal(t, nil, err)
+	path := GetPath("hydra", "plugin", "echo")
+	p := New("echo", WithRegistry(r), WithPath(path), WithLogger(logger.New("plugin")))
+	defer p.Close()
+
+	_, err = p.acquire()
+	assert.NotEqual(t, nil, err, "1. 插件未发布")
+
+	assert.Equal(t, nil, Pub(r, path, &Conf{WASM: echoV1}))
+	p.reload()
+	m, err := p.acquire()
+	assert.Equal(t, nil, err, "2. 加载插件")
+	m.wg.Done()
+
+	assert.Equal(t, nil, Pub(r, path, &Conf{WASM: echoV2}))
+	var inv *invocation
+	for i := 0; i < 50; i++ {
+		time.Sleep(20 * time.Millisecond)
+		if m, err = p.acquire(); err == nil {
+			inv = newInvocation(nil, "", logger.New("plugin"))
+			err = m.invoke(inv)
+			m.wg.Done()
+			if inv.headers["X-Version"] == "v2" {
+				break
+			}
+		}
+	}
+	assert.Equal(t, nil, err, "3. 插件节点变化后替换模块")
+	assert.Equal(t, "v2", inv.headers["X-Version"], "3. 插件节点变化后替换模块")
+
+	assert.Equal(t, nil, Pub(r, path, &Conf{WASM: base64.StdEncoding.EncodeToString([]byte("wasm"))}))
+	p.reload()
+	m, err = p.acquire()
+	assert.Equal(t, nil, err, "4. 加载失败时继续使用原模块")
+	m.wg.Done()
+}
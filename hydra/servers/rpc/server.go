@@ -13,6 +13,7 @@ import (
 	"github.com/micro-plat/hydra/components/rpcs/rpc/pb"
 	"github.com/micro-plat/hydra/conf/server/router"
 	"github.com/micro-plat/lib4go/net"
+	"github.com/micro-plat/lib4go/types"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -132,3 +133,11 @@ func (s *Server) Shutdown() {
 func (s *Server) GetAddress() string {
 	return fmt.Sprintf("tcp://%s", s.addr)
 }
+
+//Inspect 获取服务器运行状态
+func (s *Server) Inspect() map[string]interface{} {
+	return map[string]interface{}{
+		"address": s.GetAddress(),
+		"status":  types.DecodeString(s.running, true, "运行中", "停止"),
+	}
+}
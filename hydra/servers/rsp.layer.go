@@ -0,0 +1,167 @@
+package servers
+
+import (
+	"sync"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/watcher"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//layerWatcher 监控平台层或系统层配置节点下所有子配置的创建、删除与值变化，
+//节点本身的值变化由服务器配置的watcher监控
+type layerWatcher struct {
+	path     string
+	registry registry.IRegistry
+	log      logger.ILogger
+	notify   chan<- string
+	changed  chan struct{}
+	children watcher.IChildWatcher
+	nodes    map[string]*nodeWatcher
+	closeCh  chan struct{}
+	once     sync.Once
+}
+
+//nodeWatcher 子配置节点值监控
+type nodeWatcher struct {
+	watcher watcher.IValueWatcher
+	closeCh chan struct{}
+}
+
+func newLayerWatcher(r registry.IRegistry, path string, notify chan<- string, log logger.ILogger) *layerWatcher {
+	return &layerWatcher{
+		path:     path,
+		registry: r,
+		log:      log,
+		notify:   notify,
+		changed:  make(chan struct{}, 1),
+		nodes:    make(map[string]*nodeWatcher),
+		closeCh:  make(chan struct{}),
+	}
+}
+
+//Start 启动监控，子节点列表变化时重新获取所有子节点并监控节点值，
+//短时间内的多次变化合并为一次通知
+func (w *layerWatcher) Start() error {
+	children, err := watcher.NewChildWatcherByRegistry(w.registry, []string{w.path}, w.log)
+	if err != nil {
+		return err
+	}
+	ch, err := children.Start()
+	if err != nil {
+		return err
+	}
+	w.children = children
+	go w.loop(ch)
+	return nil
+}
+
+func (w *layerWatcher) loop(ch chan *watcher.ChildChangeArgs) {
+	defer func() {
+		for _, n := range w.nodes {
+			n.close()
+		}
+	}()
+	for {
+		select {
+		case <-w.closeCh:
+			return
+		case <-ch:
+			w.sync()
+			w.change()
+		case <-w.changed:
+			select {
+			case w.notify <- w.path:
+			case <-w.closeCh:
+				return
+			}
+		}
+	}
+}
+
+//sync 监控新增的子节点，关闭已删除子节点的监控
+func (w *layerWatcher) sync() {
+	paths := make(map[string]bool)
+	if err := w.getNodes(w.path, paths); err != nil {
+		w.log.Warnf("获取%s的子节点失败:%v", w.path, err)
+		return
+	}
+	for p := range paths {
+		if _, ok := w.nodes[p]; ok {
+			continue
+		}
+		n, err := w.watchNode(p)
+		if err != nil {
+			w.log.Warnf("监控配置节点%s失败:%v", p, err)
+			continue
+		}
+		w.nodes[p] = n
+	}
+	for p, n := range w.nodes {
+		if !paths[p] {
+			n.close()
+			delete(w.nodes, p)
+		}
+	}
+}
+
+//getNodes 获取所有子孙节点
+func (w *layerWatcher) getNodes(path string, paths map[string]bool) error {
+	children, _, err := w.registry.GetChildren(path)
+	if err != nil {
+		return err
+	}
+	for _, c := range children {
+		p := registry.Join(path, c)
+		paths[p] = true
+		if err := w.getNodes(p, paths); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *layerWatcher) watchNode(path string) (*nodeWatcher, error) {
+	vw, err := watcher.NewValueWatcherByRegistry(w.registry, []string{path}, w.log)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := vw.Start()
+	if err != nil {
+		return nil, err
+	}
+	n := &nodeWatcher{watcher: vw, closeCh: make(chan struct{})}
+	go func() {
+		for {
+			select {
+			case <-n.closeCh:
+				return
+			case <-ch:
+				w.change()
+			}
+		}
+	}()
+	return n, nil
+}
+
+func (w *layerWatcher) change() {
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+//Close 关闭监控
+func (w *layerWatcher) Close() {
+	w.once.Do(func() {
+		close(w.closeCh)
+		if w.children != nil {
+			w.children.Close()
+		}
+	})
+}
+
+func (n *nodeWatcher) close() {
+	close(n.closeCh)
+	n.watcher.Close()
+}
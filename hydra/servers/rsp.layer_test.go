@@ -0,0 +1,57 @@
+package servers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	_ "github.com/micro-plat/hydra/registry/watcher/wchild"
+	_ "github.com/micro-plat/hydra/registry/watcher/wvalue"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+func TestLayerWatcher(t *testing.T) {
+	r := localmemory.NewLocalMemory()
+	r.CreatePersistentNode("/hydra/conf/api", `{"status":"start"}`)
+	r.CreatePersistentNode("/hydra/conf/api/router", `{"routers":[]}`)
+
+	notify := make(chan string, 10)
+	w := newLayerWatcher(r, "/hydra/conf/api", notify, logger.New("hydra"))
+	assert.Equal(t, nil, w.Start(), "1. 启动监控")
+	defer w.Close()
+	wait(t, notify, "1. 启动时同步子节点")
+	time.Sleep(time.Millisecond * 100)
+	drain(notify)
+
+	r.CreatePersistentNode("/hydra/conf/api/metric", `{"host":"http://127.0.0.1"}`)
+	wait(t, notify, "2. 新增子配置")
+	time.Sleep(time.Millisecond * 100)
+	drain(notify)
+
+	r.Update("/hydra/conf/api/router", `{"routers":[{"path":"/a"}]}`)
+	wait(t, notify, "3. 子配置值变化")
+	drain(notify)
+
+	r.Delete("/hydra/conf/api/metric")
+	wait(t, notify, "4. 删除子配置")
+}
+
+func wait(t *testing.T, notify chan string, msg string) {
+	select {
+	case p := <-notify:
+		assert.Equal(t, "/hydra/conf/api", p, msg)
+	case <-time.After(time.Second * 3):
+		t.Fatal(msg)
+	}
+}
+
+func drain(notify chan string) {
+	for {
+		select {
+		case <-notify:
+		default:
+			return
+		}
+	}
+}
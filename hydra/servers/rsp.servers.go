@@ -1,49 +1,65 @@
 package servers
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/pkgs/secret"
+	"github.com/micro-plat/hydra/conf/server"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/snapshot"
 	"github.com/micro-plat/hydra/registry/watcher"
 	"github.com/micro-plat/lib4go/logger"
 )
 
 //RspServers 响应式服务管理器,监控配置变更自动创建、停止服务器
 type RspServers struct {
-	registryAddr string
-	registry     registry.IRegistry
-	delayChan    chan string
-	DelayTime    time.Duration
-	path         []string
-	mpath        string
-	notify       chan *watcher.ValueChangeArgs
-	done         bool
-	closeChan    chan struct{}
-	log          logger.ILogger
-	servers      map[string]IResponsiveServer
-	lock         sync.Mutex
+	registryAddr  string
+	registry      registry.IRegistry
+	delayChan     chan string
+	reloadChan    chan struct{}
+	DelayTime     time.Duration
+	path          []string
+	layers        map[string]string
+	mpath         string
+	notify        chan *watcher.ValueChangeArgs
+	layerNotify   chan string
+	layerWatchers []*layerWatcher
+	done          bool
+	closeChan     chan struct{}
+	log           logger.ILogger
+	servers       map[string]IResponsiveServer
+	lock          sync.Mutex
 }
 
 //NewRspServers 构建响应式服务器
 func NewRspServers(registryAddr string, platName, sysName string, serverTypes []string, clusterName string) *RspServers {
-	server := &RspServers{
+	s := &RspServers{
 		registryAddr: registryAddr,
 		delayChan:    make(chan string, 10),
+		reloadChan:   make(chan struct{}, 1),
+		layers:       make(map[string]string),
+		layerNotify:  make(chan string, 10),
 		closeChan:    make(chan struct{}),
 		servers:      make(map[string]IResponsiveServer),
 		log:          logger.New("hydra"),
 		mpath:        registry.Join(platName, sysName, strings.Join(serverTypes, "-"), clusterName, "conf"),
 	}
 	for _, t := range serverTypes {
-		server.path = append(server.path, registry.Join(platName, sysName, t, clusterName, "conf"))
+		path := registry.Join(platName, sysName, t, clusterName, "conf")
+		s.path = append(s.path, path)
+		for _, p := range server.GetLayerPaths(platName, sysName, t) {
+			s.layers[p] = path
+		}
 	}
-	return server
+	return s
 }
 
 //Start 启动服务器
@@ -51,15 +67,19 @@ func (r *RspServers) Start() (err error) {
 
 	r.log.Info("初始化:", r.mpath)
 
-	//初始化注册中心
-	r.registry, err = registry.GetRegistry(r.registryAddr, r.log)
+	//初始化注册中心,注册中心不可用时使用本地快照
+	r.registry, err = snapshot.GetRegistry(r.registryAddr, r.log, r.path...)
 	if err != nil {
 		err = fmt.Errorf("注册中心初始化失败 %w", err)
 		return
 	}
 
-	//监听配置变化
-	watcher, err := watcher.NewValueWatcherByRegistry(r.registry, r.path, r.log)
+	//监听配置变化，平台层与系统层配置变化时通知对应的服务器
+	paths := append([]string{}, r.path...)
+	for p := range r.layers {
+		paths = append(paths, p)
+	}
+	watcher, err := watcher.NewValueWatcherByRegistry(r.registry, paths, r.log)
 	if err != nil {
 		return fmt.Errorf("服务器watcher初始化失败 %s,%w", r.path, err)
 	}
@@ -69,11 +89,56 @@ func (r *RspServers) Start() (err error) {
 	if err != nil {
 		return err
 	}
+
+	//监听平台层与系统层子配置的创建、删除与变化
+	for p := range r.layers {
+		lw := newLayerWatcher(r.registry, p, r.layerNotify, r.log)
+		if err := lw.Start(); err != nil {
+			return fmt.Errorf("配置层watcher初始化失败 %s,%w", p, err)
+		}
+		r.layerWatchers = append(r.layerWatchers, lw)
+	}
+	//密钥或本地覆盖文件变更时与注册中心配置变更一样重新加载配置
+	secret.OnChange(r.reload)
+	go r.watchOverrideFile()
 	go r.freeOSMemory()
 	go r.loopRecvNotify()
 	return nil
 }
 
+func (r *RspServers) reload() {
+	select {
+	case r.reloadChan <- struct{}{}:
+	default:
+	}
+}
+
+//watchOverrideFile 定时检查节点本地覆盖文件是否变化
+func (r *RspServers) watchOverrideFile() {
+	tk := time.NewTicker(time.Second * 5)
+	defer tk.Stop()
+	last := getModTime(server.GetOverrideFile())
+	for {
+		select {
+		case <-r.closeChan:
+			return
+		case <-tk.C:
+			if t := getModTime(server.GetOverrideFile()); !t.Equal(last) {
+				last = t
+				r.log.Info("本地覆盖文件发生变化")
+				r.reload()
+			}
+		}
+	}
+}
+
+func getModTime(path string) time.Time {
+	if info, err := os.Stat(path); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
 func (r *RspServers) freeOSMemory() {
 	tk := time.NewTicker(time.Second * 120)
 	for {
@@ -101,22 +166,51 @@ func (r *RspServers) loopRecvNotify() {
 			if r.done {
 				return
 			}
-			if err := r.checkServer(p); err != nil {
+			if err := r.checkServer(p, false); err != nil {
 				r.log.Error(err)
 			}
+		case <-r.reloadChan:
+			if r.done {
+				return
+			}
+			for _, p := range r.path {
+				if err := r.checkServer(p, true); err != nil {
+					r.log.Error(err)
+				}
+			}
 		case u := <-r.notify:
 			if r.done {
 				return
 			}
-			if err := r.checkServer(u.Path); err != nil {
+			if _, ok := r.layers[u.Path]; ok {
+				r.checkLayer(u.Path)
+				continue
+			}
+			if err := r.checkServer(u.Path, false); err != nil {
 				r.log.Error(err)
 			}
+		case p := <-r.layerNotify:
+			if r.done {
+				return
+			}
+			r.checkLayer(p)
 		}
 	}
 }
 
-//checkServer 通知server配置变更或创建新server
-func (r *RspServers) checkServer(path string) error {
+//checkLayer 平台层或系统层配置变化时通知对应的服务器，服务器配置未安装时由服务器配置的变更通知创建服务器
+func (r *RspServers) checkLayer(layer string) {
+	path := r.layers[layer]
+	if ok, _ := r.registry.Exists(path); !ok {
+		return
+	}
+	if err := r.checkServer(path, false); err != nil {
+		r.log.Error(err)
+	}
+}
+
+//checkServer 通知server配置变更或创建新server，reload表示由引用的密钥或本地覆盖文件变更引起
+func (r *RspServers) checkServer(path string, reload bool) error {
 	defer func() {
 		if err := recover(); err != nil {
 			r.log.Errorf("[Recovery] panic recovered:\n%s\n%s", err, global.GetStack())
@@ -144,9 +238,14 @@ func (r *RspServers) checkServer(path string) error {
 		if err != nil {
 			return err
 		}
-		if !change {
+		switch {
+		case !change && reload:
+			//服务器配置未变化，更新缓存中的var配置使组件使用新的密钥
+			app.Cache.Save(conf)
+			r.log.Info("配置重新加载完成")
+		case !change:
 			r.log.Debug("服务配置未发生变化")
-		} else {
+		default:
 			r.log.Info("配置更新完成")
 		}
 
@@ -169,10 +268,35 @@ func (r *RspServers) checkServer(path string) error {
 		}
 	}
 
+	r.saveSnapshot(conf)
 	return nil
 
 }
 
+//saveSnapshot 保存服务器配置快照，用于注册中心不可用时启动服务器
+func (r *RspServers) saveSnapshot(conf app.IAPPConf) {
+	if registry.GetProto(r.registryAddr) == registry.LocalMemory {
+		return
+	}
+	sc := conf.GetServerConf()
+	layers := server.GetLayerPaths(sc.GetPlatName(), sc.GetSysName(), sc.GetServerType())
+	err := snapshot.Save(r.registry, sc.GetServerPath(), conf.GetVarConf().GetVarPath(), layers...)
+	if err != nil && !errors.Is(err, snapshot.ErrNoKey) {
+		r.log.Warnf("保存配置快照失败:%v", err)
+	}
+}
+
+//GetServers 获取已创建的服务器
+func (r *RspServers) GetServers() map[string]IResponsiveServer {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	servers := make(map[string]IResponsiveServer, len(r.servers))
+	for k, v := range r.servers {
+		servers[k] = v
+	}
+	return servers
+}
+
 //delayPub 延迟启动，当依赖的服务没有正确启动时通过延迟重试进行启动
 func (r *RspServers) delayPub(p string) {
 	go func() {
@@ -194,6 +318,9 @@ func (r *RspServers) delayPub(p string) {
 //Shutdown 关闭所有服务器
 func (r *RspServers) Shutdown() {
 	r.done = true
+	for _, lw := range r.layerWatchers {
+		lw.Close()
+	}
 	r.lock.Lock()
 	defer r.lock.Unlock()
 	cl := make(chan struct{})
@@ -27,6 +27,17 @@ type IResponsiveServer interface {
 	Shutdown()
 }
 
+//IPauseServer 支持暂停与恢复的服务器
+type IPauseServer interface {
+	Pause() (bool, error)
+	Resume() (bool, error)
+}
+
+//IInspectServer 支持输出运行状态的服务器
+type IInspectServer interface {
+	Inspect() map[string]interface{}
+}
+
 var creators = make(map[string]IServerCreator)
 
 //Register 注册服务器生成器
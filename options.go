@@ -122,3 +122,10 @@ func WithTracePort(port string) Option {
 		global.Def.TracePort = port
 	}
 }
+
+//WithAdminPort 管理服务端口，设置后启用管理服务，访问时使用服务器的basic或apikey认证配置
+func WithAdminPort(port string) Option {
+	return func() {
+		global.Def.AdminPort = port
+	}
+}
@@ -0,0 +1,30 @@
+package migrate
+
+import (
+	"github.com/micro-plat/hydra/registry"
+)
+
+//IsTemporary 是否是服务运行时创建的临时节点或序列节点，root为平台根节点
+//注册中心未提供节点类型信息，按hydra创建临时节点的路径判断：
+//服务发布节点(/plat/services/...)、集群节点(/plat/sys/type/cluster/servers/...)
+//及滚动升级节点(/plat/sys/upgrade/cluster/nodes/...)
+func IsTemporary(root string, path string) bool {
+	n := 0
+	if registry.Trim(root) != "" {
+		n = len(registry.Split(root))
+	}
+	rel := registry.Split(path)
+	if len(rel) <= n {
+		return false
+	}
+	rel = rel[n:]
+	switch {
+	case rel[0] == "services":
+		return true
+	case len(rel) >= 4 && rel[1] == "upgrade" && rel[3] == "nodes":
+		return true
+	case len(rel) >= 4 && rel[3] == "servers":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/micro-plat/hydra/registry"
+)
+
+//Snapshot 注册中心节点快照
+type Snapshot struct {
+	Root     string            `json:"root"`
+	Checksum string            `json:"checksum"`
+	Nodes    map[string]string `json:"nodes"`
+}
+
+//Check 检查快照内容与校验码是否一致
+func (s *Snapshot) Check() error {
+	if s.Checksum != Checksum(s.Nodes) {
+		return fmt.Errorf("快照校验码错误，节点数据可能已被修改(root:%s)", s.Root)
+	}
+	return nil
+}
+
+//Export 导出根节点下的所有永久节点，临时节点与序列节点不导出
+func Export(r registry.IRegistry, root string) (*Snapshot, error) {
+	root = registry.Format(root)
+	ok, err := r.Exists(root)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("节点%s不存在", root)
+	}
+	nodes, err := export(r, root, root)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Root: root, Nodes: nodes, Checksum: Checksum(nodes)}, nil
+}
+
+//Import 将快照中的节点导入注册中心，cover为false时已存在的节点保持不变
+func Import(r registry.IRegistry, s *Snapshot, cover bool) error {
+	if err := s.Check(); err != nil {
+		return err
+	}
+	for _, path := range sortPaths(s.Nodes) {
+		if err := write(r, path, s.Nodes[path], cover); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Verify 验证注册中心中的节点值与快照一致
+func Verify(r registry.IRegistry, s *Snapshot) error {
+	nodes := make(map[string]string, len(s.Nodes))
+	diffs := make([]string, 0, 1)
+	for _, path := range sortPaths(s.Nodes) {
+		data, _, err := r.GetValue(path)
+		if err != nil || string(data) != s.Nodes[path] {
+			diffs = append(diffs, path)
+			continue
+		}
+		nodes[path] = string(data)
+	}
+	if len(diffs) == 0 && Checksum(nodes) == s.Checksum {
+		return nil
+	}
+	if len(diffs) > 10 {
+		diffs = append(diffs[:10], "...")
+	}
+	return fmt.Errorf("校验失败，节点值不一致:%s", strings.Join(diffs, ","))
+}
+
+//Migrate 将源注册中心根节点下的永久节点复制到目标注册中心并校验
+func Migrate(from registry.IRegistry, to registry.IRegistry, root string, cover bool) (*Snapshot, error) {
+	s, err := Export(from, root)
+	if err != nil {
+		return nil, err
+	}
+	if err := Import(to, s, cover); err != nil {
+		return nil, err
+	}
+	return s, Verify(to, s)
+}
+
+//Checksum 根据节点路径与值计算校验码
+func Checksum(nodes map[string]string) string {
+	h := sha256.New()
+	for _, path := range sortPaths(nodes) {
+		h.Write([]byte(path))
+		h.Write([]byte{0})
+		h.Write([]byte(nodes[path]))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+//export 获取path及其所有子节点的值
+func export(r registry.IRegistry, root string, path string) (map[string]string, error) {
+	nodes := make(map[string]string)
+	if err := walk(r, root, path, nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func walk(r registry.IRegistry, root string, path string, nodes map[string]string) error {
+	if IsTemporary(root, path) {
+		return nil
+	}
+	data, _, err := r.GetValue(path)
+	if err != nil {
+		return fmt.Errorf("获取节点%s的值失败:%w", path, err)
+	}
+	nodes[path] = string(data)
+	children, _, err := r.GetChildren(path)
+	if err != nil {
+		return fmt.Errorf("获取节点%s的子节点失败:%w", path, err)
+	}
+	for _, name := range children {
+		if err := walk(r, root, registry.Join(path, name), nodes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//write 创建或更新永久节点
+func write(r registry.IRegistry, path string, data string, cover bool) error {
+	ok, err := r.Exists(path)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err := r.CreatePersistentNode(path, data); err != nil {
+			return fmt.Errorf("创建节点%s失败:%w", path, err)
+		}
+		return nil
+	}
+	if !cover {
+		return nil
+	}
+	old, _, err := r.GetValue(path)
+	if err == nil && string(old) == data {
+		return nil
+	}
+	if err := r.Update(path, data); err != nil {
+		return fmt.Errorf("更新节点%s失败:%w", path, err)
+	}
+	return nil
+}
+
+//sortPaths 获取排序后的节点路径，父节点在子节点之前
+func sortPaths(nodes map[string]string) []string {
+	paths := make([]string, 0, len(nodes))
+	for path := range nodes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
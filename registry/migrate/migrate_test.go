@@ -0,0 +1,78 @@
+package migrate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+func TestIsTemporary(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "1. 根节点", path: "/hydra", want: false},
+		{name: "2. 服务器配置", path: "/hydra/apiserver/api/t/conf", want: false},
+		{name: "3. 子配置", path: "/hydra/apiserver/api/t/conf/router", want: false},
+		{name: "4. var配置", path: "/hydra/var/db/db", want: false},
+		{name: "5. 服务发布节点", path: "/hydra/services/api/providers/192.168.0.1:8080", want: true},
+		{name: "6. 集群节点", path: "/hydra/apiserver/api/t/servers", want: true},
+		{name: "7. 滚动升级节点", path: "/hydra/apiserver/upgrade/t/nodes/192.168.0.1", want: true},
+		{name: "8. 以序号结尾的配置", path: "/hydra/var/db/db_2", want: false},
+		{name: "9. 以序号结尾的子配置", path: "/hydra/apiserver/mqc/t/conf/queue_01", want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsTemporary("/hydra", tt.path), tt.name)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	from := localmemory.NewLocalMemory()
+	from.CreatePersistentNode("/hydra/apiserver/api/t/conf", `{"address":":8080"}`)
+	from.CreatePersistentNode("/hydra/var/db/db", `{"provider":"mysql"}`)
+	from.CreateTempNode("/hydra/apiserver/api/t/servers/192.168.0.1", `{}`)
+	from.CreateSeqNode("/hydra/services/api/providers/api_", `{}`)
+
+	to := localmemory.NewLocalMemory()
+	to.CreatePersistentNode("/hydra/var/db/db", `{"provider":"oracle"}`)
+
+	_, err := Migrate(from, to, "/hydra", false)
+	assert.NotEqual(t, nil, err, "1. 不覆盖已存在节点时校验失败")
+
+	s, err := Migrate(from, to, "/hydra", true)
+	assert.Equal(t, nil, err, "2. 覆盖已存在节点")
+	assert.Equal(t, `{"provider":"mysql"}`, s.Nodes["/hydra/var/db/db"], "2. 覆盖已存在节点")
+
+	ok, _ := to.Exists("/hydra/apiserver/api/t/servers/192.168.0.1")
+	assert.Equal(t, false, ok, "3. 不复制临时节点")
+	ok, _ = to.Exists("/hydra/services")
+	assert.Equal(t, false, ok, "4. 不复制服务发布节点")
+
+	s.Nodes["/hydra/var/db/db"] = `{"provider":"redis"}`
+	assert.NotEqual(t, nil, Import(to, s, true), "5. 快照内容被修改")
+}
+
+func TestMirror(t *testing.T) {
+	from := localmemory.NewLocalMemory()
+	from.CreatePersistentNode("/hydra/apiserver/api/t/conf", `{"address":":8080"}`)
+	to := localmemory.NewLocalMemory()
+
+	m := NewMirror(from, to, "/hydra", time.Minute, logger.New("mirror"))
+	assert.Equal(t, nil, m.Start(), "1. 全量同步")
+	defer m.Close()
+	v, _, _ := to.GetValue("/hydra/apiserver/api/t/conf")
+	assert.Equal(t, `{"address":":8080"}`, string(v), "1. 全量同步")
+
+	from.Update("/hydra/apiserver/api/t/conf", `{"address":":8090"}`)
+	from.CreatePersistentNode("/hydra/apiserver/api/t/conf/router", `{}`)
+	time.Sleep(time.Millisecond * 200)
+
+	v, _, _ = to.GetValue("/hydra/apiserver/api/t/conf")
+	assert.Equal(t, `{"address":":8090"}`, string(v), "2. 同步节点值变化")
+	ok, _ := to.Exists("/hydra/apiserver/api/t/conf/router")
+	assert.Equal(t, true, ok, "3. 同步新增节点")
+}
@@ -0,0 +1,195 @@
+package migrate
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/logger"
+	lregistry "github.com/micro-plat/lib4go/registry"
+)
+
+//Mirror 监控源注册中心的节点变化，持续同步到目标注册中心
+type Mirror struct {
+	from     registry.IRegistry
+	to       registry.IRegistry
+	root     string
+	interval time.Duration
+	log      logger.ILogging
+	values   map[string]bool
+	children map[string]bool
+	lk       sync.Mutex
+	notify   chan string
+	closeCh  chan struct{}
+	once     sync.Once
+}
+
+//NewMirror 构建注册中心镜像同步服务,interval为全量校对间隔
+func NewMirror(from registry.IRegistry, to registry.IRegistry, root string, interval time.Duration, log logger.ILogging) *Mirror {
+	return &Mirror{
+		from:     from,
+		to:       to,
+		root:     registry.Format(root),
+		interval: interval,
+		log:      log,
+		values:   make(map[string]bool),
+		children: make(map[string]bool),
+		notify:   make(chan string, 100),
+		closeCh:  make(chan struct{}),
+	}
+}
+
+//Start 全量同步后开始监控节点变化
+func (m *Mirror) Start() error {
+	if err := m.sync(m.root); err != nil {
+		return err
+	}
+	go m.loop()
+	return nil
+}
+
+//Close 停止同步
+func (m *Mirror) Close() {
+	m.once.Do(func() {
+		close(m.closeCh)
+	})
+}
+
+func (m *Mirror) loop() {
+	tk := time.NewTicker(m.interval)
+	defer tk.Stop()
+	for {
+		select {
+		case <-m.closeCh:
+			return
+		case path := <-m.notify:
+			if err := m.sync(path); err != nil {
+				m.log.Errorf("同步节点%s失败:%v", path, err)
+			}
+		case <-tk.C:
+			if err := m.sync(m.root); err != nil {
+				m.log.Errorf("全量同步失败:%v", err)
+			}
+		}
+	}
+}
+
+//sync 同步path及其子节点，删除目标注册中心中源已不存在的节点
+func (m *Mirror) sync(path string) error {
+	src, err := m.export(m.from, path)
+	if err != nil {
+		return err
+	}
+	dst, err := m.export(m.to, path)
+	if err != nil {
+		return err
+	}
+	for _, p := range sortPaths(src) {
+		if v, ok := dst[p]; ok && v == src[p] {
+			continue
+		}
+		if err := write(m.to, p, src[p], true); err != nil {
+			return err
+		}
+		m.log.Infof("同步节点:%s", p)
+	}
+	removed := make([]string, 0, 1)
+	for p := range dst {
+		if _, ok := src[p]; !ok {
+			removed = append(removed, p)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(removed)))
+	for _, p := range removed {
+		if err := m.to.Delete(p); err != nil {
+			return err
+		}
+		m.log.Infof("删除节点:%s", p)
+	}
+	for p := range src {
+		m.watch(p)
+	}
+	return nil
+}
+
+func (m *Mirror) export(r registry.IRegistry, path string) (map[string]string, error) {
+	ok, err := r.Exists(path)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return map[string]string{}, nil
+	}
+	return export(r, m.root, path)
+}
+
+//watch 监控节点值及子节点变化
+func (m *Mirror) watch(path string) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	if !m.values[path] {
+		if ch, err := m.from.WatchValue(path); err == nil {
+			m.values[path] = true
+			go m.watchValue(path, ch)
+		}
+	}
+	if !m.children[path] {
+		if ch, err := m.from.WatchChildren(path); err == nil {
+			m.children[path] = true
+			go m.watchChildren(path, ch)
+		}
+	}
+}
+
+func (m *Mirror) watchValue(path string, ch chan lregistry.ValueWatcher) {
+	defer m.unwatch(m.values, path)
+	for {
+		select {
+		case <-m.closeCh:
+			return
+		case w, ok := <-ch:
+			//节点被删除时由父节点的子节点监控进行同步
+			if !ok || w.GetError() != nil {
+				return
+			}
+			m.changed(path)
+		}
+		var err error
+		if ch, err = m.from.WatchValue(path); err != nil {
+			return
+		}
+	}
+}
+
+func (m *Mirror) watchChildren(path string, ch chan lregistry.ChildrenWatcher) {
+	defer m.unwatch(m.children, path)
+	for {
+		select {
+		case <-m.closeCh:
+			return
+		case w, ok := <-ch:
+			if !ok || w.GetError() != nil {
+				return
+			}
+			m.changed(path)
+		}
+		var err error
+		if ch, err = m.from.WatchChildren(path); err != nil {
+			return
+		}
+	}
+}
+
+func (m *Mirror) changed(path string) {
+	select {
+	case m.notify <- path:
+	case <-m.closeCh:
+	}
+}
+
+func (m *Mirror) unwatch(watching map[string]bool, path string) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	delete(watching, path)
+}
@@ -39,6 +39,8 @@ type IRegistry interface {
 	CreateTempNode(path string, data string) (err error)
 	CreateSeqNode(path string, data string) (rpath string, err error)
 	Update(path string, data string) (err error)
+	UpdateIfVersion(path string, data string, version int32) (err error)
+	Txn(ops ...Op) (err error)
 	Delete(path string) error
 	Exists(path string) (bool, error)
 	Close() error
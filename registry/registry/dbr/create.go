@@ -51,7 +51,8 @@ func (r *DBR) CreateSeqNode(path string, data string) (rpath string, err error)
 
 //CreateStructure 创建表结构
 func (r *DBR) CreateStructure() (err error) {
-	if r.provider == MYSQL {
+	switch r.provider {
+	case MYSQL, POSTGRES, SQLITE:
 		_, err = r.db.Execute(r.sqltexture.createStructure, nil)
 	}
 	return err
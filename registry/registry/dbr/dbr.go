@@ -14,6 +14,8 @@ import (
 	xdb "github.com/micro-plat/hydra/conf/vars/db"
 	"github.com/micro-plat/hydra/conf/vars/db/mysql"
 	"github.com/micro-plat/hydra/conf/vars/db/oracle"
+	"github.com/micro-plat/hydra/conf/vars/db/postgres"
+	"github.com/micro-plat/hydra/conf/vars/db/sqlite"
 	r "github.com/micro-plat/hydra/registry"
 	"github.com/micro-plat/lib4go/db"
 )
@@ -26,6 +28,7 @@ type DBR struct {
 	tmpNodes         *tmpNodeWatchers
 	valueWatchers    *valueWatchers
 	childrenWatchers *childrenWatchers
+	notifyWatchers   *notifyWatchers
 }
 
 func NewDBR(c *xdb.DB, sqltexture *sqltexture, o *r.Options) (*DBR, error) {
@@ -33,7 +36,7 @@ func NewDBR(c *xdb.DB, sqltexture *sqltexture, o *r.Options) (*DBR, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &DBR{
+	r := &DBR{
 		provider: c.Provider,
 		db:       db,
 		// seqValue:         10000,
@@ -41,7 +44,11 @@ func NewDBR(c *xdb.DB, sqltexture *sqltexture, o *r.Options) (*DBR, error) {
 		tmpNodes:         newTmpNodeWatchers(db, sqltexture),
 		valueWatchers:    newValueWatchers(db, sqltexture),
 		childrenWatchers: newChildrenWatchers(db, sqltexture),
-	}, nil
+	}
+	if r.provider == POSTGRES {
+		r.notifyWatchers = newNotifyWatchers(c.ConnString, r.valueWatchers, r.childrenWatchers)
+	}
+	return r, nil
 }
 
 //Close 关闭当前服务
@@ -49,9 +56,14 @@ func (r *DBR) Start() error {
 	if err := r.CreateStructure(); err != nil {
 		return err
 	}
+	go r.tmpNodes.Start()
+
+	//postgres通过LISTEN/NOTIFY获取节点变更，其它数据库定时轮询
+	if r.notifyWatchers != nil {
+		return r.notifyWatchers.Start()
+	}
 	go r.valueWatchers.Start()
 	go r.childrenWatchers.Start()
-	go r.tmpNodes.Start()
 	return nil
 }
 
@@ -59,6 +71,9 @@ func (r *DBR) Start() error {
 func (r *DBR) Close() error {
 	r.valueWatchers.Close()
 	r.childrenWatchers.Close()
+	if r.notifyWatchers != nil {
+		r.notifyWatchers.Close()
+	}
 	r.tmpNodes.Close()
 	return nil
 }
@@ -84,6 +99,13 @@ func (z *dbrFactory) Create(opts ...r.Option) (r.IRegistry, error) {
 	case ORACLE:
 		sqltexture = &oracletexture
 		dbConf = oracle.NewBy(z.opts.Auth.Username, z.opts.Auth.Password, z.opts.Addrs[0], xdb.WithConnect(10, 6, 900))
+	case POSTGRES:
+		sqltexture = &postgrestexture
+		dbConf = postgres.NewBy(z.opts.Auth.Username, z.opts.Auth.Password, z.opts.Addrs[0], z.opts.Metadata["db"], xdb.WithConnect(10, 6, 900))
+	case SQLITE:
+		//sqlite仅允许单个连接写入，避免出现database is locked
+		sqltexture = &sqlitetexture
+		dbConf = sqlite.NewBy(z.opts.Addrs[0], xdb.WithConnect(1, 1, 900))
 	default:
 		return nil, fmt.Errorf("未知类型的数据库注册中心,%s", z.proto)
 
@@ -102,8 +124,12 @@ func (z *dbrFactory) Create(opts ...r.Option) (r.IRegistry, error) {
 
 var MYSQL = "mysql"
 var ORACLE = "oracle"
+var POSTGRES = "postgres"
+var SQLITE = "sqlite"
 
 func init() {
 	r.Register(MYSQL, &dbrFactory{proto: MYSQL, opts: &r.Options{}})
 	r.Register(ORACLE, &dbrFactory{proto: ORACLE, opts: &r.Options{}}) //暂未提供SQL
+	r.Register(POSTGRES, &dbrFactory{proto: POSTGRES, opts: &r.Options{}})
+	r.Register(SQLITE, &dbrFactory{proto: SQLITE, opts: &r.Options{}})
 }
@@ -30,7 +30,7 @@ func newInputBySelectLike(sec int, path string) input {
 
 func newInputBySelectIn(sec int, path ...string) input {
 	return map[string]interface{}{
-		FieldPath: `"` + strings.Join(path, `","`) + `"`,
+		FieldPath: `'` + strings.Join(path, `','`) + `'`,
 		"sec":     sec,
 	}
 }
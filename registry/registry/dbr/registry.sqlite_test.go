@@ -0,0 +1,7 @@
+// +build sqlite
+
+package dbr
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+)
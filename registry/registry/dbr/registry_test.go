@@ -2,9 +2,10 @@ package dbr
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
 	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/mattn/go-oci8"
 	r "github.com/micro-plat/hydra/registry"
 )
 
@@ -16,6 +17,9 @@ func getRegistryForTest(provider string) (r.IRegistry, error) {
 	if provider == "oracle" {
 		return fact.Create(r.WithAuthCreds("ims17_v1_dev", "123456dev"), r.Addrs("orcl136"))
 	}
+	if provider == "sqlite" {
+		return fact.Create(r.Addrs(filepath.Join(os.TempDir(), "hydra_registry_test.db")))
+	}
 
 	return nil, fmt.Errorf("获取注册中心provider错误,%s", provider)
 }
@@ -0,0 +1,162 @@
+package dbr
+
+var postgrestexture sqltexture
+
+func init() {
+
+	postgrestexture.createStructure = `
+	create table if not exists hydra_registry_info (
+		id bigserial not null,
+		path varchar(256) not null,
+		value varchar(4096) not null,
+		is_temp smallint default 0 not null,
+		is_delete smallint default 1 not null,
+		data_version bigint,
+		create_time timestamp default current_timestamp not null,
+		update_time timestamp default current_timestamp not null,
+		constraint pk_hydra_registry_info primary key (id),
+		constraint unq_registry_path unique (path)
+	);
+	comment on table hydra_registry_info is '注册中心';
+	create sequence if not exists seq_hydra_registry_info_seq
+		minvalue 10000
+		start with 10000
+		increment by 1
+		cache 20;
+	create or replace function hydra_registry_notify() returns trigger as $$
+	begin
+		if tg_op = 'INSERT' or new.data_version is distinct from old.data_version or new.is_delete <> old.is_delete then
+			perform pg_notify('hydra_registry_change', new.path);
+		end if;
+		return new;
+	end;
+	$$ language plpgsql;
+	drop trigger if exists trg_hydra_registry_notify on hydra_registry_info;
+	create trigger trg_hydra_registry_notify after insert or update on hydra_registry_info
+		for each row execute procedure hydra_registry_notify();
+	`
+
+	postgrestexture.createNode = `
+	insert into hydra_registry_info(
+		path,
+		value,
+		is_temp,
+		is_delete,
+		data_version,
+		create_time,
+		update_time 
+	)
+	select
+		@path,
+		@value,
+		@is_temp,
+		1,
+		@data_version,
+		now(),
+		now()
+	where not exists(select 1 from hydra_registry_info t where t.path = @path)
+	`
+	postgrestexture.exists = `
+	select count(0)
+	from hydra_registry_info t
+	where t.path like '#path%'
+	and t.is_delete = 1
+	`
+
+	postgrestexture.getValue = `
+	select
+	t.id,
+	t.path,
+	t.value,
+	t.is_temp,
+	t.is_delete,
+	t.data_version,
+	t.create_time,
+	t.update_time 
+	from hydra_registry_info t
+	where t.path = @path
+	and t.is_delete = 1
+	`
+
+	postgrestexture.delete = `
+	update hydra_registry_info
+	set update_time = now(),
+	is_delete = 0
+	where path = @path
+	and is_delete = 1
+	`
+	postgrestexture.clear = `
+	delete from hydra_registry_info
+	where path = @path
+	and (is_delete = 0
+	or (is_temp = 0 and update_time <= now() - interval '30 second'))
+	`
+	postgrestexture.update = `
+	update hydra_registry_info set
+	value = @value,
+	data_version = data_version + 1,
+	update_time = now() 
+	where path = @path 
+	and data_version = @data_version
+	and is_delete = 1
+	`
+
+	postgrestexture.getChildren = `
+	select
+	t.path,
+	t.value,
+	t.is_temp,
+	t.is_delete,
+	t.data_version,
+	t.create_time,
+	t.update_time 
+	from hydra_registry_info t
+	where t.path like '#path%'
+	and t.is_delete = 1
+	`
+	postgrestexture.getValueChange = `
+	select
+	t.path,
+	t.value,
+	t.is_temp,
+	t.is_delete,
+	t.data_version,
+	t.create_time,
+	t.update_time 
+	from hydra_registry_info t
+	where t.path in (#path) 
+	and t.update_time > now() - interval '#sec second'
+	`
+	postgrestexture.getChildrenChange = `
+	select
+	t.path,
+	t.value,
+	t.is_temp,
+	t.is_delete,
+	t.data_version,
+	t.create_time,
+	t.update_time 
+	from hydra_registry_info t
+	where t.path like '#path%'
+	and (t.create_time > now() - interval '#sec second'
+		or(t.is_delete = 0 and t.update_time > now() - interval '#sec second'))
+	`
+
+	postgrestexture.aclUpdate = `
+	update hydra_registry_info set
+	update_time = now() 
+	where path in (#path)
+	and is_delete = 1
+	`
+
+	postgrestexture.clearTmpNode = `
+	update hydra_registry_info
+	set update_time = now(),
+	is_delete = 0
+	where path in (#path)
+	and is_delete = 1
+	and is_temp = 0
+	`
+
+	postgrestexture.getSeq = "select nextval('seq_hydra_registry_info_seq')"
+}
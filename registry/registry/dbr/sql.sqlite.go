@@ -0,0 +1,151 @@
+package dbr
+
+var sqlitetexture sqltexture
+
+func init() {
+
+	sqlitetexture.createStructure = `
+	create table if not exists hydra_registry_info (
+		id integer primary key autoincrement,
+		path varchar(256) not null,
+		value varchar(4096) not null,
+		is_temp tinyint default 0 not null,
+		is_delete tinyint default 1 not null,
+		data_version bigint,
+		create_time datetime default current_timestamp not null,
+		update_time datetime default current_timestamp not null,
+		constraint unq_registry_path unique (path)
+	);
+	create table if not exists hydra_registry_seq (
+		id integer primary key,
+		seq bigint not null
+	);
+	insert into hydra_registry_seq(id, seq)
+	select 1, 10000
+	where not exists(select 1 from hydra_registry_seq);
+	`
+
+	sqlitetexture.createNode = `
+	insert into hydra_registry_info(
+		path,
+		value,
+		is_temp,
+		is_delete,
+		data_version,
+		create_time,
+		update_time 
+	)
+	select
+		@path,
+		@value,
+		@is_temp,
+		1,
+		@data_version,
+		datetime('now'),
+		datetime('now')
+	where not exists(select 1 from hydra_registry_info t where t.path = @path)
+	`
+	sqlitetexture.exists = `
+	select count(0)
+	from hydra_registry_info t
+	where t.path like '#path%'
+	and t.is_delete = 1
+	`
+
+	sqlitetexture.getValue = `
+	select
+	t.id,
+	t.path,
+	t.value,
+	t.is_temp,
+	t.is_delete,
+	t.data_version,
+	t.create_time,
+	t.update_time 
+	from hydra_registry_info t
+	where t.path = @path
+	and t.is_delete = 1
+	`
+
+	sqlitetexture.delete = `
+	update hydra_registry_info
+	set update_time = datetime('now'),
+	is_delete = 0
+	where path = @path
+	and is_delete = 1
+	`
+	sqlitetexture.clear = `
+	delete from hydra_registry_info
+	where path = @path
+	and (is_delete = 0
+	or (is_temp = 0 and update_time <= datetime('now','-30 second')))
+	`
+	sqlitetexture.update = `
+	update hydra_registry_info set
+	value = @value,
+	data_version = data_version + 1,
+	update_time = datetime('now')
+	where path = @path 
+	and data_version = @data_version
+	and is_delete = 1
+	`
+
+	sqlitetexture.getChildren = `
+	select
+	t.path,
+	t.value,
+	t.is_temp,
+	t.is_delete,
+	t.data_version,
+	t.create_time,
+	t.update_time 
+	from hydra_registry_info t
+	where t.path like '#path%'
+	and t.is_delete = 1
+	`
+	sqlitetexture.getValueChange = `
+	select
+	t.path,
+	t.value,
+	t.is_temp,
+	t.is_delete,
+	t.data_version,
+	t.create_time,
+	t.update_time 
+	from hydra_registry_info t
+	where t.path in (#path) 
+	and t.update_time > datetime('now','-#sec second')
+	`
+	sqlitetexture.getChildrenChange = `
+	select
+	t.path,
+	t.value,
+	t.is_temp,
+	t.is_delete,
+	t.data_version,
+	t.create_time,
+	t.update_time 
+	from hydra_registry_info t
+	where t.path like '#path%'
+	and (t.create_time > datetime('now','-#sec second')
+		or(t.is_delete = 0 and t.update_time > datetime('now','-#sec second')))
+	`
+
+	sqlitetexture.aclUpdate = `
+	update hydra_registry_info set
+	update_time = datetime('now')
+	where path in (#path)
+	and is_delete = 1
+	`
+
+	sqlitetexture.clearTmpNode = `
+	update hydra_registry_info
+	set update_time = datetime('now'),
+	is_delete = 0
+	where path in (#path)
+	and is_delete = 1
+	and is_temp = 0
+	`
+
+	sqlitetexture.getSeq = "update hydra_registry_seq set seq = seq + 1 where id = 1 returning seq"
+}
@@ -0,0 +1,129 @@
+// +build sqlite
+
+package dbr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestSqliteNode(t *testing.T) {
+	rgt, err := getRegistryForTest(SQLITE)
+	assert.Equal(t, nil, err, err)
+	defer rgt.Close()
+
+	root := fmt.Sprintf("/TestSqliteNode/%d", time.Now().UnixNano())
+
+	//永久节点
+	err = rgt.CreatePersistentNode(root+"/p", `{"id":100}`)
+	assert.Equal(t, nil, err, err)
+	err = rgt.CreatePersistentNode(root+"/p", `{"id":101}`)
+	assert.Equal(t, true, err != nil, "重复创建节点应返回错误")
+
+	buff, ver, err := rgt.GetValue(root + "/p")
+	assert.Equal(t, nil, err, err)
+	assert.Equal(t, `{"id":100}`, string(buff))
+	assert.Equal(t, int32(1), ver)
+
+	//更新节点
+	err = rgt.Update(root+"/p", `{"id":102}`)
+	assert.Equal(t, nil, err, err)
+	buff, ver, err = rgt.GetValue(root + "/p")
+	assert.Equal(t, nil, err, err)
+	assert.Equal(t, `{"id":102}`, string(buff))
+	assert.Equal(t, int32(2), ver)
+
+	//临时节点与序列节点
+	err = rgt.CreateTempNode(root+"/t", "t")
+	assert.Equal(t, nil, err, err)
+	seq1, err := rgt.CreateSeqNode(root+"/s", "s")
+	assert.Equal(t, nil, err, err)
+	seq2, err := rgt.CreateSeqNode(root+"/s", "s")
+	assert.Equal(t, nil, err, err)
+	assert.Equal(t, true, seq1 != seq2, "序列节点名称不能重复", seq1, seq2)
+	assert.Equal(t, true, strings.HasPrefix(seq1, root+"/s_"), seq1)
+
+	children, _, err := rgt.GetChildren(root)
+	assert.Equal(t, nil, err, err)
+	assert.Equal(t, 4, len(children), children)
+
+	//删除节点
+	err = rgt.Delete(root + "/p")
+	assert.Equal(t, nil, err, err)
+	ok, err := rgt.Exists(root + "/p")
+	assert.Equal(t, nil, err, err)
+	assert.Equal(t, false, ok)
+
+	//删除后可重新创建
+	err = rgt.CreatePersistentNode(root+"/p", `{"id":103}`)
+	assert.Equal(t, nil, err, err)
+}
+
+func TestSqliteTxn(t *testing.T) {
+	rgt, err := getRegistryForTest(SQLITE)
+	assert.Equal(t, nil, err, err)
+	defer rgt.Close()
+
+	root := fmt.Sprintf("/TestSqliteTxn/%d", time.Now().UnixNano())
+	err = rgt.CreatePersistentNode(root+"/p", `{"id":100}`)
+	assert.Equal(t, nil, err, err)
+	_, ver, err := rgt.GetValue(root + "/p")
+	assert.Equal(t, nil, err, err)
+
+	//版本号一致时更新
+	err = rgt.UpdateIfVersion(root+"/p", `{"id":101}`, ver)
+	assert.Equal(t, nil, err, err)
+	err = rgt.UpdateIfVersion(root+"/p", `{"id":102}`, ver)
+	assert.Equal(t, true, errors.Is(err, r.ErrVersionConflict), err)
+
+	//任一操作失败时回滚所有操作
+	err = rgt.Txn(r.CreateOp(root+"/a", "a"), r.UpdateOp(root+"/p", `{"id":103}`, ver))
+	assert.Equal(t, true, errors.Is(err, r.ErrVersionConflict), err)
+	ok, err := rgt.Exists(root + "/a")
+	assert.Equal(t, nil, err, err)
+	assert.Equal(t, false, ok)
+
+	err = rgt.Txn(r.CreateOp(root+"/a", "a"), r.UpdateOp(root+"/p", `{"id":103}`, r.AnyVersion), r.DeleteOp(root+"/p", r.AnyVersion))
+	assert.Equal(t, nil, err, err)
+	ok, _ = rgt.Exists(root + "/a")
+	assert.Equal(t, true, ok)
+	ok, _ = rgt.Exists(root + "/p")
+	assert.Equal(t, false, ok)
+}
+
+func TestSqliteWatchValue(t *testing.T) {
+	rgt, err := getRegistryForTest(SQLITE)
+	assert.Equal(t, nil, err, err)
+	defer rgt.Close()
+
+	//通过另一个注册中心实例修改节点，验证轮询监控
+	other, err := getRegistryForTest(SQLITE)
+	assert.Equal(t, nil, err, err)
+	defer other.Close()
+
+	path := fmt.Sprintf("/TestSqliteWatchValue/%d", time.Now().UnixNano())
+	err = rgt.CreatePersistentNode(path, "1")
+	assert.Equal(t, nil, err, err)
+
+	ch, err := rgt.WatchValue(path)
+	assert.Equal(t, nil, err, err)
+
+	err = other.Update(path, "2")
+	assert.Equal(t, nil, err, err)
+
+	select {
+	case w := <-ch:
+		assert.Equal(t, nil, w.GetError())
+		value, version := w.GetValue()
+		assert.Equal(t, "2", string(value))
+		assert.Equal(t, int32(2), version)
+	case <-time.After(time.Second * 5):
+		t.Error("未收到节点值变更通知")
+	}
+}
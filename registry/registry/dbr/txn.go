@@ -0,0 +1,92 @@
+package dbr
+
+import (
+	"fmt"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/db"
+	"github.com/micro-plat/lib4go/errs"
+)
+
+//UpdateIfVersion 节点版本号与version一致时更新节点值
+func (r *DBR) UpdateIfVersion(path string, data string, version int32) error {
+	return r.Txn(registry.UpdateOp(path, data, version))
+}
+
+//Txn 在同一数据库事务中执行所有操作，任一操作失败时回滚
+func (r *DBR) Txn(ops ...registry.Op) (err error) {
+	if len(ops) == 0 {
+		return nil
+	}
+	trans, err := r.db.Begin()
+	if err != nil {
+		return errs.New("开启事务失败%w", err)
+	}
+	versions := make([]int32, len(ops))
+	for i, op := range ops {
+		if versions[i], err = r.execOp(trans, op); err != nil {
+			trans.Rollback()
+			return err
+		}
+	}
+	if err = trans.Commit(); err != nil {
+		return errs.New("提交事务失败%w", err)
+	}
+
+	//通知变更
+	for i, op := range ops {
+		switch op.Type {
+		case registry.OpCreate:
+			r.notifyParentChange(op.Path, 1)
+		case registry.OpUpdate:
+			r.notifyValueChange(op.Path, op.Data, versions[i])
+		case registry.OpDelete:
+			r.notifyParentChange(op.Path, 0)
+		}
+	}
+	return nil
+}
+
+//execOp 执行单个操作，返回操作前节点的版本号
+func (r *DBR) execOp(trans db.IDBTrans, op registry.Op) (int32, error) {
+	if op.Type == registry.OpCreate {
+		trans.Execute(r.sqltexture.clear, newInput(op.Path))
+		count, err := trans.Execute(r.sqltexture.createNode, newInputByInsert(op.Path, op.Data, false))
+		if err != nil {
+			return 0, errs.New("创建节点错误%w", err)
+		}
+		if count == 0 {
+			return 0, errs.New("创建节点错误,节点已存在(%s)", op.Path)
+		}
+		return 0, nil
+	}
+
+	datas, err := trans.Query(r.sqltexture.getValue, newInput(op.Path))
+	if err != nil {
+		return 0, err
+	}
+	if datas.IsEmpty() {
+		return 0, fmt.Errorf("节点[%s]不存在", op.Path)
+	}
+	version := datas.Get(0).GetInt32(FieldDataVersion)
+	if err := registry.CheckVersion(op.Version, version); err != nil {
+		return 0, fmt.Errorf("节点[%s]%w", op.Path, err)
+	}
+
+	var count int64
+	switch op.Type {
+	case registry.OpUpdate:
+		count, err = trans.Execute(r.sqltexture.update, newInputByUpdate(op.Path, op.Data, version))
+	case registry.OpDelete:
+		count, err = trans.Execute(r.sqltexture.delete, newInput(op.Path))
+	default:
+		return version, nil
+	}
+	if err != nil {
+		return 0, errs.New("修改节点错误(%s)%w", op.Path, err)
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("节点[%s]%w", op.Path, registry.ErrVersionConflict)
+	}
+	return version, nil
+}
@@ -33,22 +33,25 @@ func (v *childrenWatchers) Start() {
 	for {
 		select {
 		case <-tk:
-			path := v.pths.Keys()
-			for _, p := range path {
-				data, err := v.db.Query(v.sqltexture.getChildrenChange, newInputBySelectLike(3, p))
-				if err == nil {
-					for _, r := range data {
-						go v.Notify(r.GetString(FieldPath), r.GetInt32(FieldDataVersion), r.GetString(FieldValue))
-					}
-				}
-			}
-
+			v.check(3, v.pths.Keys()...)
 		case <-v.closeCh:
 			return
 		}
 	}
 }
 
+//check 查询指定时间内发生变化的子节点并通知订阅者
+func (v *childrenWatchers) check(sec int, path ...string) {
+	for _, p := range path {
+		data, err := v.db.Query(v.sqltexture.getChildrenChange, newInputBySelectLike(sec, p))
+		if err == nil {
+			for _, r := range data {
+				go v.Notify(r.GetString(FieldPath), r.GetInt32(FieldDataVersion), r.GetString(FieldValue))
+			}
+		}
+	}
+}
+
 //监控节点的值变化
 func (v *childrenWatchers) Watch(path string) chan registry.ChildrenWatcher {
 	v.lk.Lock()
@@ -0,0 +1,98 @@
+package dbr
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+//notifyChannel postgres节点变更通知的频道名称
+const notifyChannel = "hydra_registry_change"
+
+//notifyWatchers 通过postgres LISTEN/NOTIFY接收节点变更，替代定时轮询
+type notifyWatchers struct {
+	connString string
+	values     *valueWatchers
+	children   *childrenWatchers
+	listener   *pq.Listener
+	lost       time.Time
+	lk         sync.Mutex
+	closeCh    chan struct{}
+	once       sync.Once
+}
+
+func newNotifyWatchers(connString string, values *valueWatchers, children *childrenWatchers) *notifyWatchers {
+	return &notifyWatchers{
+		connString: connString,
+		values:     values,
+		children:   children,
+		closeCh:    make(chan struct{}),
+	}
+}
+
+//Start 订阅节点变更通知
+func (n *notifyWatchers) Start() error {
+	n.listener = pq.NewListener(n.connString, time.Second, time.Minute, n.event)
+	if err := n.listener.Listen(notifyChannel); err != nil {
+		n.listener.Close()
+		return err
+	}
+	go n.loop()
+	return nil
+}
+
+func (n *notifyWatchers) loop() {
+	tk := time.NewTicker(time.Minute)
+	defer tk.Stop()
+	for {
+		select {
+		case <-n.closeCh:
+			return
+		case m := <-n.listener.Notify:
+			//重连成功后通知为nil，补偿断线期间的变更
+			if m == nil {
+				n.recover()
+				continue
+			}
+			n.notify(m.Extra)
+		case <-tk.C:
+			go n.listener.Ping()
+		}
+	}
+}
+
+//notify 通知值订阅者及父节点的子节点订阅者
+func (n *notifyWatchers) notify(path string) {
+	if n.values.pths.Has(path) {
+		n.values.check(3, path)
+	}
+	n.children.Notify(filepath.Dir(path), 0, path)
+}
+
+//recover 查询断线期间发生变化的节点
+func (n *notifyWatchers) recover() {
+	n.lk.Lock()
+	sec := int(time.Since(n.lost).Seconds()) + 3
+	n.lk.Unlock()
+	n.values.check(sec, n.values.pths.Keys()...)
+	n.children.check(sec, n.children.pths.Keys()...)
+}
+
+func (n *notifyWatchers) event(ev pq.ListenerEventType, err error) {
+	if ev == pq.ListenerEventDisconnected {
+		n.lk.Lock()
+		n.lost = time.Now()
+		n.lk.Unlock()
+	}
+}
+
+func (n *notifyWatchers) Close() {
+	n.once.Do(func() {
+		close(n.closeCh)
+		if n.listener != nil {
+			n.listener.Close()
+		}
+	})
+}
@@ -33,19 +33,23 @@ func (v *valueWatchers) Start() {
 	for {
 		select {
 		case <-tk:
-			path := v.pths.Keys()
-			data, err := v.db.Query(v.sqltexture.getValueChange, newInputBySelectIn(3, path...))
-			if err == nil {
-				for _, r := range data {
-					go v.Notify(r.GetString(FieldPath), r.GetInt32(FieldDataVersion), r.GetString(FieldValue))
-				}
-			}
+			v.check(3, v.pths.Keys()...)
 		case <-v.closeCh:
 			return
 		}
 	}
 }
 
+//check 查询指定时间内发生变化的节点并通知订阅者
+func (v *valueWatchers) check(sec int, path ...string) {
+	data, err := v.db.Query(v.sqltexture.getValueChange, newInputBySelectIn(sec, path...))
+	if err == nil {
+		for _, r := range data {
+			go v.Notify(r.GetString(FieldPath), r.GetInt32(FieldDataVersion), r.GetString(FieldValue))
+		}
+	}
+}
+
 //监控节点的值变化
 func (v *valueWatchers) Watch(path string) chan registry.ValueWatcher {
 	v.lk.Lock()
@@ -40,6 +40,7 @@ type fs struct {
 	watchLock           sync.Mutex
 	tempNodes           map[string]bool
 	tempNodeLock        sync.Mutex
+	txnLock             sync.Mutex
 	closeCh             chan struct{}
 	rootDir             string
 	done                bool
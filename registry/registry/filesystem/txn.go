@@ -0,0 +1,126 @@
+package filesystem
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	r "github.com/micro-plat/hydra/registry"
+)
+
+//UpdateIfVersion 节点版本号与version一致时更新节点值
+func (l *fs) UpdateIfVersion(path string, data string, version int32) error {
+	return l.Txn(r.UpdateOp(path, data, version))
+}
+
+//Txn 检查所有节点的版本号后依次执行操作，任一操作失败时恢复已修改的节点
+func (l *fs) Txn(ops ...r.Op) (err error) {
+	l.txnLock.Lock()
+	defer l.txnLock.Unlock()
+	for _, op := range ops {
+		if err := l.checkOp(op); err != nil {
+			return err
+		}
+	}
+
+	undo := make([]func(), 0, len(ops))
+	backups := make([]string, 0, 1)
+	defer func() {
+		if err != nil {
+			for i := len(undo) - 1; i >= 0; i-- {
+				undo[i]()
+			}
+			return
+		}
+		for _, p := range backups {
+			os.RemoveAll(p)
+		}
+	}()
+	for _, op := range ops {
+		rpath := l.replaceColon(l.formatPath(op.Path))
+		switch op.Type {
+		case r.OpCreate:
+			dir := getMissingDir(rpath)
+			if err = l.CreatePersistentNode(op.Path, op.Data); err != nil {
+				return err
+			}
+			undo = append(undo, func() { os.RemoveAll(dir) })
+		case r.OpUpdate:
+			dataPath := l.getDataPath(rpath)
+			old, _ := ioutil.ReadFile(dataPath)
+			info, _ := os.Stat(dataPath)
+			if err = ioutil.WriteFile(dataPath, []byte(op.Data), fileMode); err != nil {
+				return err
+			}
+			undo = append(undo, func() {
+				ioutil.WriteFile(dataPath, old, fileMode)
+				if info != nil {
+					os.Chtimes(dataPath, info.ModTime(), info.ModTime())
+				}
+			})
+			if info != nil {
+				if err = touch(dataPath, info.ModTime()); err != nil {
+					return err
+				}
+			}
+		case r.OpDelete:
+			//先移动到以~开头的备份目录(获取子节点时忽略)，提交后再删除
+			bak := filepath.Join(filepath.Dir(rpath), fmt.Sprintf("~%s.%d", filepath.Base(rpath), time.Now().UnixNano()))
+			if err = os.Rename(rpath, bak); err != nil {
+				return err
+			}
+			backups = append(backups, bak)
+			undo = append(undo, func() { os.Rename(bak, rpath) })
+		}
+	}
+	return nil
+}
+
+//checkOp 检查节点是否存在及版本号是否一致
+func (l *fs) checkOp(op r.Op) error {
+	exists, _ := l.Exists(op.Path)
+	if op.Type == r.OpCreate {
+		if exists {
+			return fmt.Errorf("节点[%s]已存在", op.Path)
+		}
+		return nil
+	}
+	if !exists {
+		return fmt.Errorf("节点[%s]不存在", op.Path)
+	}
+	_, version, err := l.GetValue(op.Path)
+	if err != nil {
+		return err
+	}
+	if err := r.CheckVersion(op.Version, version); err != nil {
+		return fmt.Errorf("节点[%s]%w", op.Path, err)
+	}
+	return nil
+}
+
+//touch 版本号为修改时间(秒)，同一秒内多次修改时将修改时间推后，保证每次修改后版本号变化
+func touch(path string, last time.Time) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Unix() > last.Unix() {
+		return nil
+	}
+	t := time.Unix(last.Unix()+1, 0)
+	return os.Chtimes(path, t, t)
+}
+
+//getMissingDir 获取不存在的最上级目录，回滚时连同创建的上级节点一起删除
+func getMissingDir(path string) string {
+	missing := path
+	for p := filepath.Dir(path); p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+		if _, err := os.Stat(p); err == nil {
+			break
+		}
+		missing = p
+	}
+	return missing
+}
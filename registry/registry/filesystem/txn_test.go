@@ -0,0 +1,57 @@
+package filesystem
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestFS_Txn(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hydra")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+	l, err := NewFileSystem(dir)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, nil, l.Txn(r.CreateOp("/hydra/api/conf", "1"), r.CreateOp("/hydra/c", "2")), "1. 提交事务并创建上级节点")
+	v, _, _ := l.GetValue("/hydra/api/conf")
+	assert.Equal(t, "1", string(v), "1. 提交事务并创建上级节点")
+	ok, _ := l.Exists("/hydra/api")
+	assert.Equal(t, true, ok, "1. 提交事务并创建上级节点")
+	_, vc, _ := l.GetValue("/hydra/c")
+
+	cases := []struct {
+		name string
+		ops  []r.Op
+	}{
+		{name: "2. 版本号不一致", ops: []r.Op{r.CreateOp("/hydra/x/y", "1"), r.UpdateOp("/hydra/c", "3", vc+1)}},
+		{name: "3. 节点已存在", ops: []r.Op{r.CreateOp("/hydra/x/y", "1"), r.UpdateOp("/hydra/c", "3", vc), r.CreateOp("/hydra/api/conf", "3")}},
+		{name: "4. 节点不存在", ops: []r.Op{r.CreateOp("/hydra/x/y", "1"), r.DeleteOp("/hydra/c", vc), r.DeleteOp("/hydra/z", r.AnyVersion)}},
+		{name: "5. 检查版本号失败", ops: []r.Op{r.CreateOp("/hydra/x/y", "1"), r.CheckOp("/hydra/c", vc+1)}},
+		{name: "6. 执行失败时回滚已执行的操作", ops: []r.Op{r.CreateOp("/hydra/x/y", "1"), r.UpdateOp("/hydra/c", "3", vc), r.CreateOp("/hydra/c/.init/z", "1")}},
+	}
+	for _, c := range cases {
+		assert.NotEqual(t, nil, l.Txn(c.ops...), c.name)
+		_, err := os.Stat(filepath.Join(dir, "hydra", "x"))
+		assert.Equal(t, true, os.IsNotExist(err), c.name+"，不创建任何节点")
+		v, version, _ := l.GetValue("/hydra/c")
+		assert.Equal(t, "2", string(v), c.name+"，不修改任何节点")
+		assert.Equal(t, vc, version, c.name+"，不修改任何节点")
+	}
+
+	assert.Equal(t, nil, l.UpdateIfVersion("/hydra/c", "4", vc), "7. 按版本号更新")
+	_, version, _ := l.GetValue("/hydra/c")
+	assert.NotEqual(t, vc, version, "8. 同一秒内修改后版本号变化")
+	err = l.UpdateIfVersion("/hydra/c", "5", vc)
+	assert.Equal(t, true, errors.Is(err, r.ErrVersionConflict), "9. 使用旧版本号更新")
+	assert.Equal(t, nil, l.UpdateIfVersion("/hydra/c", "5", version), "10. 使用新版本号更新")
+
+	assert.Equal(t, nil, l.Txn(r.DeleteOp("/hydra/api", r.AnyVersion), r.CreateOp("/hydra/d", "1")), "11. 删除节点")
+	ok, _ = l.Exists("/hydra/api/conf")
+	assert.Equal(t, false, ok, "11. 删除节点")
+}
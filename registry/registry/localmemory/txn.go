@@ -0,0 +1,78 @@
+package localmemory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/micro-plat/hydra/registry"
+)
+
+//UpdateIfVersion 节点版本号与version一致时更新节点值
+func (l *localMemory) UpdateIfVersion(path string, data string, version int32) error {
+	return l.Txn(registry.UpdateOp(path, data, version))
+}
+
+//Txn 在同一个锁内检查并执行所有操作，任一操作失败时不修改任何节点
+func (l *localMemory) Txn(ops ...registry.Op) error {
+	l.lock.Lock()
+	nodes := make(map[string]*value, len(l.nodes))
+	for k, v := range l.nodes {
+		nodes[k] = v
+	}
+	for _, op := range ops {
+		path := registry.Format(op.Path)
+		v, ok := nodes[path]
+		if op.Type != registry.OpCreate && !ok {
+			l.lock.Unlock()
+			return fmt.Errorf("节点[%s]不存在", path)
+		}
+		if ok {
+			if err := registry.CheckVersion(op.Version, v.version); err != nil {
+				l.lock.Unlock()
+				return fmt.Errorf("节点[%s]%w", path, err)
+			}
+		}
+		switch op.Type {
+		case registry.OpCreate:
+			if ok {
+				l.lock.Unlock()
+				return fmt.Errorf("节点[%s]已存在", path)
+			}
+			for _, p := range l.getPaths(path) {
+				if _, ok := nodes[p]; !ok {
+					nodes[p] = newValue("{}")
+				}
+			}
+			nodes[path] = newValue(op.Data)
+		case registry.OpUpdate:
+			nodes[path] = newValue(op.Data)
+		case registry.OpDelete:
+			for k := range nodes {
+				if k == path || strings.HasPrefix(k, path+"/") {
+					delete(nodes, k)
+				}
+			}
+		}
+	}
+	l.nodes = nodes
+	l.lock.Unlock()
+
+	//通知节点变化
+	for _, op := range ops {
+		path := registry.Format(op.Path)
+		switch op.Type {
+		case registry.OpCreate:
+			if v, ok := nodes[path]; ok {
+				l.notifyParentChange(path, v.version)
+			}
+		case registry.OpUpdate:
+			if v, ok := nodes[path]; ok {
+				l.notifyValueChange(path, v)
+			}
+		case registry.OpDelete:
+			l.notifyValueChange(path, &value{})
+			l.notifyParentChange(path, 0)
+		}
+	}
+	return nil
+}
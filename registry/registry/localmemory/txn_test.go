@@ -0,0 +1,48 @@
+package localmemory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestLocalMemory_Txn(t *testing.T) {
+	l := NewLocalMemory()
+
+	assert.Equal(t, nil, l.Txn(registry.CreateOp("/hydra/api/conf", "1"), registry.CreateOp("/hydra/c", "2")), "1. 提交事务并创建上级节点")
+	v, _, _ := l.GetValue("/hydra/api/conf")
+	assert.Equal(t, "1", string(v), "1. 提交事务并创建上级节点")
+	children, _, _ := l.GetChildren("/hydra")
+	assert.Equal(t, 2, len(children), "1. 提交事务并创建上级节点")
+	_, vc, _ := l.GetValue("/hydra/c")
+
+	cases := []struct {
+		name string
+		ops  []registry.Op
+	}{
+		{name: "2. 版本号不一致", ops: []registry.Op{registry.CreateOp("/hydra/x/y", "1"), registry.UpdateOp("/hydra/c", "3", vc+1)}},
+		{name: "3. 节点已存在", ops: []registry.Op{registry.CreateOp("/hydra/x/y", "1"), registry.UpdateOp("/hydra/c", "3", vc), registry.CreateOp("/hydra/api/conf", "3")}},
+		{name: "4. 节点不存在", ops: []registry.Op{registry.CreateOp("/hydra/x/y", "1"), registry.DeleteOp("/hydra/c", vc), registry.DeleteOp("/hydra/z", registry.AnyVersion)}},
+		{name: "5. 检查版本号失败", ops: []registry.Op{registry.CreateOp("/hydra/x/y", "1"), registry.CheckOp("/hydra/c", vc+1)}},
+	}
+	for _, c := range cases {
+		assert.NotEqual(t, nil, l.Txn(c.ops...), c.name)
+		ok, _ := l.Exists("/hydra/x")
+		assert.Equal(t, false, ok, c.name+"，不创建任何节点")
+		v, version, _ := l.GetValue("/hydra/c")
+		assert.Equal(t, "2", string(v), c.name+"，不修改任何节点")
+		assert.Equal(t, vc, version, c.name+"，不修改任何节点")
+	}
+
+	assert.Equal(t, nil, l.UpdateIfVersion("/hydra/c", "4", vc), "6. 按版本号更新")
+	_, version, _ := l.GetValue("/hydra/c")
+	assert.NotEqual(t, vc, version, "7. 修改后版本号变化")
+	err := l.UpdateIfVersion("/hydra/c", "5", vc)
+	assert.Equal(t, true, errors.Is(err, registry.ErrVersionConflict), "8. 使用旧版本号更新")
+
+	assert.Equal(t, nil, l.Txn(registry.DeleteOp("/hydra/api", registry.AnyVersion), registry.CreateOp("/hydra/d", "1")), "9. 删除节点")
+	ok, _ := l.Exists("/hydra/api/conf")
+	assert.Equal(t, false, ok, "9. 删除节点")
+}
@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"fmt"
+
+	"github.com/go-redis/redis"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/redis/internal"
+)
+
+//UpdateIfVersion 节点版本号与version一致时更新节点值
+func (r *Redis) UpdateIfVersion(path string, data string, version int32) error {
+	return r.Txn(registry.UpdateOp(path, data, version))
+}
+
+//Txn 通过WATCH/MULTI执行所有操作，提交前节点被其它程序修改时放弃全部操作。
+//redis集群模式下WATCH/MULTI的所有key须位于同一个hash slot，节点路径未使用hash tag，
+//集群模式下按顺序逐个执行操作，每个操作单独检查版本号，某个操作失败时已执行的操作不回滚
+func (r *Redis) Txn(ops ...registry.Op) error {
+	if _, ok := r.client.UniversalClient.(*redis.ClusterClient); ok && len(ops) > 1 {
+		for _, op := range ops {
+			if err := r.txn(op); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return r.txn(ops...)
+}
+
+func (r *Redis) txn(ops ...registry.Op) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(ops))
+	for _, op := range ops {
+		keys = append(keys, internal.SwapKey(op.Path))
+	}
+	values := make(map[string]*value, len(ops))
+	err := r.client.Watch(func(tx *redis.Tx) error {
+		olds := make(map[string]*value, len(ops))
+		for i, op := range ops {
+			buff, err := tx.Get(keys[i]).Result()
+			if err != nil && err != redis.Nil {
+				return err
+			}
+			if err == redis.Nil {
+				if op.Type != registry.OpCreate {
+					return fmt.Errorf("节点[%s]不存在", op.Path)
+				}
+				continue
+			}
+			if op.Type == registry.OpCreate {
+				return fmt.Errorf("节点[%s]已存在", op.Path)
+			}
+			ovalue, err := newValueByJSON(buff)
+			if err != nil {
+				return err
+			}
+			if err := registry.CheckVersion(op.Version, ovalue.Version); err != nil {
+				return fmt.Errorf("节点[%s]%w", op.Path, err)
+			}
+			olds[keys[i]] = ovalue
+		}
+		_, err := tx.TxPipelined(func(p redis.Pipeliner) error {
+			for i, op := range ops {
+				key := keys[i]
+				switch op.Type {
+				case registry.OpCreate:
+					values[key] = newValue(op.Data, false)
+					p.Set(key, values[key].String(), r.maxExpiration)
+				case registry.OpUpdate:
+					exp := r.maxExpiration
+					if olds[key].IsTemp {
+						exp = r.tmpExpiration
+					}
+					values[key] = newValue(op.Data, olds[key].IsTemp)
+					p.Set(key, values[key].String(), exp)
+				case registry.OpDelete:
+					p.Del(key)
+				}
+			}
+			return nil
+		})
+		return err
+	}, keys...)
+	if err == redis.TxFailedErr {
+		return fmt.Errorf("提交事务失败%w", registry.ErrVersionConflict)
+	}
+	if err != nil {
+		return err
+	}
+
+	//通知节点变化
+	for i, op := range ops {
+		switch op.Type {
+		case registry.OpCreate, registry.OpDelete:
+			r.notifyParentChange(keys[i], 0)
+		case registry.OpUpdate:
+			r.notifyValueChange(op.Path, values[keys[i]])
+		}
+	}
+	return nil
+}
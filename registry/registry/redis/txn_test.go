@@ -0,0 +1,239 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+//server 用于测试的redis服务，只支持事务相关的命令
+type server struct {
+	ln       net.Listener
+	lk       sync.Mutex
+	data     map[string]string
+	versions map[string]int
+
+	//afterWatch 执行WATCH命令后调用，模拟提交事务前其它程序修改节点
+	afterWatch func()
+}
+
+func newServer(t *testing.T) *server {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err)
+	s := &server{ln: ln, data: make(map[string]string), versions: make(map[string]int)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	watched := make(map[string]int)
+	var queue [][]string
+	multi := false
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(args[0])
+		switch {
+		case cmd == "MULTI":
+			multi, queue = true, nil
+			io.WriteString(conn, "+OK\r\n")
+		case cmd == "EXEC":
+			s.lk.Lock()
+			changed := false
+			for k, v := range watched {
+				changed = changed || s.versions[k] != v
+			}
+			if changed {
+				io.WriteString(conn, "*-1\r\n")
+			} else {
+				fmt.Fprintf(conn, "*%d\r\n", len(queue))
+				for _, q := range queue {
+					io.WriteString(conn, s.exec(q))
+				}
+			}
+			s.lk.Unlock()
+			multi, queue, watched = false, nil, make(map[string]int)
+		case multi:
+			queue = append(queue, args)
+			io.WriteString(conn, "+QUEUED\r\n")
+		case cmd == "WATCH":
+			s.lk.Lock()
+			for _, k := range args[1:] {
+				watched[k] = s.versions[k]
+			}
+			f := s.afterWatch
+			s.afterWatch = nil
+			s.lk.Unlock()
+			if f != nil {
+				f()
+			}
+			io.WriteString(conn, "+OK\r\n")
+		case cmd == "UNWATCH":
+			watched = make(map[string]int)
+			io.WriteString(conn, "+OK\r\n")
+		default:
+			s.lk.Lock()
+			io.WriteString(conn, s.exec(args))
+			s.lk.Unlock()
+		}
+	}
+}
+
+func (s *server) exec(args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		v, ok := s.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case "SET":
+		s.data[args[1]] = args[2]
+		s.versions[args[1]]++
+		return "+OK\r\n"
+	case "DEL":
+		n := 0
+		for _, k := range args[1:] {
+			if _, ok := s.data[k]; ok {
+				delete(s.data, k)
+				s.versions[k]++
+				n++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "EXISTS":
+		_, ok := s.data[args[1]]
+		if ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "SCAN":
+		keys := make([]string, 0, 1)
+		for k := range s.data {
+			if strings.HasPrefix(k, strings.TrimSuffix(args[3], "*")) {
+				keys = append(keys, fmt.Sprintf("$%d\r\n%s\r\n", len(k), k))
+			}
+		}
+		return fmt.Sprintf("*2\r\n$1\r\n0\r\n*%d\r\n%s", len(keys), strings.Join(keys, ""))
+	case "PUBLISH":
+		return ":0\r\n"
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+//set 模拟其它程序修改节点
+func (s *server) set(key string, value string) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.exec([]string{"SET", key, value})
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, errors.New("不支持的请求格式")
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buff := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buff); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buff[:size]))
+	}
+	return args, nil
+}
+
+func TestRedis_Txn(t *testing.T) {
+	s := newServer(t)
+	defer s.ln.Close()
+	r, err := NewRedisBy("", "", []string{s.ln.Addr().String()}, 0, 1)
+	assert.Equal(t, nil, err)
+	defer r.Close()
+
+	assert.Equal(t, nil, r.Txn(registry.CreateOp("/hydra/a", "1"), registry.CreateOp("/hydra/b", "2")), "1. 提交事务")
+	a, va, _ := r.GetValue("/hydra/a")
+	b, vb, _ := r.GetValue("/hydra/b")
+	assert.Equal(t, "1", string(a), "1. 提交事务")
+	assert.Equal(t, "2", string(b), "1. 提交事务")
+
+	cases := []struct {
+		name string
+		ops  []registry.Op
+	}{
+		{name: "2. 版本号不一致", ops: []registry.Op{registry.UpdateOp("/hydra/a", "3", va), registry.UpdateOp("/hydra/b", "3", vb+1)}},
+		{name: "3. 节点已存在", ops: []registry.Op{registry.UpdateOp("/hydra/a", "3", va), registry.CreateOp("/hydra/b", "3")}},
+		{name: "4. 节点不存在", ops: []registry.Op{registry.UpdateOp("/hydra/a", "3", va), registry.DeleteOp("/hydra/c", registry.AnyVersion)}},
+		{name: "5. 检查版本号失败", ops: []registry.Op{registry.DeleteOp("/hydra/a", va), registry.CheckOp("/hydra/b", vb+1)}},
+	}
+	for _, c := range cases {
+		assert.NotEqual(t, nil, r.Txn(c.ops...), c.name)
+		v, version, _ := r.GetValue("/hydra/a")
+		assert.Equal(t, "1", string(v), c.name+"，已检查的操作全部回滚")
+		assert.Equal(t, va, version, c.name+"，已检查的操作全部回滚")
+	}
+
+	assert.Equal(t, nil, r.UpdateIfVersion("/hydra/a", "4", va), "6. 按版本号更新")
+	err = r.UpdateIfVersion("/hydra/a", "5", va)
+	assert.Equal(t, true, errors.Is(err, registry.ErrVersionConflict), "7. 使用旧版本号更新")
+	a, _, _ = r.GetValue("/hydra/a")
+	assert.Equal(t, "4", string(a), "7. 使用旧版本号更新")
+
+	assert.Equal(t, nil, r.Txn(registry.DeleteOp("/hydra/a", registry.AnyVersion), registry.CheckOp("/hydra/b", vb)), "8. 删除节点")
+	ok, _ := r.Exists("/hydra/a")
+	assert.Equal(t, false, ok, "8. 删除节点")
+
+	s.set("hydra:b", `{"data":"Ng==","version":1}`)
+	err = r.UpdateIfVersion("/hydra/b", "7", vb)
+	assert.Equal(t, true, errors.Is(err, registry.ErrVersionConflict), "9. 节点被其它程序修改")
+
+	_, vb, _ = r.GetValue("/hydra/b")
+	s.lk.Lock()
+	s.afterWatch = func() { s.set("hydra:b", `{"data":"OA==","version":1}`) }
+	s.lk.Unlock()
+	err = r.Txn(registry.CreateOp("/hydra/d", "1"), registry.UpdateOp("/hydra/b", "9", vb))
+	assert.Equal(t, true, errors.Is(err, registry.ErrVersionConflict), "10. 提交前节点被其它程序修改")
+	ok, _ = r.Exists("/hydra/d")
+	assert.Equal(t, false, ok, "10. 提交前节点被其它程序修改")
+	b, _, _ = r.GetValue("/hydra/b")
+	assert.Equal(t, "8", string(b), "10. 提交前节点被其它程序修改")
+}
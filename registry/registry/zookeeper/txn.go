@@ -0,0 +1,149 @@
+package zookeeper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/encoding"
+	gozk "github.com/samuel/go-zookeeper/zk"
+)
+
+//UpdateIfVersion 节点数据版本号与version一致时更新节点值
+func (z *zookeeper) UpdateIfVersion(path string, data string, version int32) error {
+	conn, err := z.getConn()
+	if err != nil {
+		return err
+	}
+	buff, err := encoding.Encode(data, "gbk")
+	if err != nil {
+		return err
+	}
+	_, err = conn.Set(path, buff, version)
+	return getError(path, err)
+}
+
+//Txn 通过zookeeper multi请求执行所有操作，新节点不存在的上级节点在同一请求中创建，
+//所有操作全部成功或全部失败
+func (z *zookeeper) Txn(ops ...registry.Op) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	conn, err := z.getConn()
+	if err != nil {
+		return err
+	}
+	paths, requests, err := getRequests(func(path string) (bool, error) {
+		ok, _, err := conn.Exists(path)
+		return ok, err
+	}, z.ACL, ops...)
+	if err != nil {
+		return err
+	}
+	rs, err := conn.Multi(requests...)
+	if err == nil {
+		return nil
+	}
+	for i, r := range rs {
+		switch r.Error {
+		case gozk.ErrBadVersion, gozk.ErrNodeExists, gozk.ErrNoNode:
+			return getError(paths[i], r.Error)
+		}
+	}
+	return fmt.Errorf("执行事务失败:%w", err)
+}
+
+//getRequests 按操作顺序构建multi请求，新节点不存在的上级节点在新节点前创建，
+//上级节点由事务中靠后的操作创建时，将该操作提前执行
+func getRequests(exists func(string) (bool, error), acl []gozk.ACL, ops ...registry.Op) (paths []string, requests []interface{}, err error) {
+	creating := make(map[string]int)
+	for i, op := range ops {
+		if op.Type == registry.OpCreate {
+			creating[op.Path] = i
+		}
+	}
+	added := make(map[int]bool)
+	checked := make(map[string]bool)
+	var add func(i int) error
+	add = func(i int) error {
+		if added[i] {
+			return nil
+		}
+		added[i] = true
+		op := ops[i]
+		if op.Type == registry.OpCreate {
+			for _, p := range getParents(op.Path) {
+				if checked[p] {
+					continue
+				}
+				if j, ok := creating[p]; ok {
+					if err := add(j); err != nil {
+						return err
+					}
+					continue
+				}
+				checked[p] = true
+				ok, err := exists(p)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					paths = append(paths, p)
+					requests = append(requests, &gozk.CreateRequest{Path: p, Data: []byte{}, Acl: acl})
+				}
+			}
+			checked[op.Path] = true
+		}
+		request, err := newRequest(op, acl)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, op.Path)
+		requests = append(requests, request)
+		return nil
+	}
+	for i := range ops {
+		if err := add(i); err != nil {
+			return nil, nil, err
+		}
+	}
+	return paths, requests, nil
+}
+
+func newRequest(op registry.Op, acl []gozk.ACL) (interface{}, error) {
+	switch op.Type {
+	case registry.OpCreate:
+		buff, err := encoding.Encode(op.Data, "gbk")
+		if err != nil {
+			return nil, err
+		}
+		return &gozk.CreateRequest{Path: op.Path, Data: buff, Acl: acl}, nil
+	case registry.OpUpdate:
+		buff, err := encoding.Encode(op.Data, "gbk")
+		if err != nil {
+			return nil, err
+		}
+		return &gozk.SetDataRequest{Path: op.Path, Data: buff, Version: op.Version}, nil
+	case registry.OpDelete:
+		return &gozk.DeleteRequest{Path: op.Path, Version: op.Version}, nil
+	case registry.OpCheck:
+		return &gozk.CheckVersionRequest{Path: op.Path, Version: op.Version}, nil
+	default:
+		return nil, fmt.Errorf("不支持的操作类型:%d", op.Type)
+	}
+}
+
+func getError(path string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gozk.ErrBadVersion):
+		return fmt.Errorf("节点[%s]%w", path, registry.ErrVersionConflict)
+	case errors.Is(err, gozk.ErrNodeExists):
+		return fmt.Errorf("节点[%s]已存在", path)
+	case errors.Is(err, gozk.ErrNoNode):
+		return fmt.Errorf("节点[%s]不存在", path)
+	default:
+		return err
+	}
+}
@@ -28,7 +28,7 @@ func (z *zookeeperFactory) Create(opts ...registry.Option) (registry.IRegistry,
 		return nil, err
 	}
 	err = zclient.Connect()
-	return zclient, err
+	return newZookeeper(zclient, z.opts), err
 }
 
 func init() {
@@ -0,0 +1,89 @@
+package zookeeper
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/encoding"
+	"github.com/micro-plat/lib4go/zk"
+	gozk "github.com/samuel/go-zookeeper/zk"
+)
+
+var _ registry.IRegistry = &zookeeper{}
+
+//zookeeper 在lib4go zk客户端基础上提供按版本号更新及事务操作。
+//lib4go客户端未公开连接对象，读取节点版本号与执行事务使用独立的连接，
+//GetValue返回节点的数据版本号(stat.Version)，与UpdateIfVersion、CheckOp使用的版本号一致
+type zookeeper struct {
+	*zk.ZookeeperClient
+	opts *registry.Options
+	conn *gozk.Conn
+	lk   sync.Mutex
+}
+
+func newZookeeper(client *zk.ZookeeperClient, opts *registry.Options) *zookeeper {
+	return &zookeeper{ZookeeperClient: client, opts: opts}
+}
+
+//GetValue 获取节点的值与数据版本号
+func (z *zookeeper) GetValue(path string) ([]byte, int32, error) {
+	conn, err := z.getConn()
+	if err != nil {
+		return nil, 0, err
+	}
+	data, stat, err := conn.Get(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get node:%s error(err:%v)", path, err)
+	}
+	value, err := encoding.DecodeBytes(data, "gbk")
+	if err != nil {
+		return nil, 0, fmt.Errorf("get node 编码转换失败:%s error(err:%v)", path, err)
+	}
+	return value, stat.Version, nil
+}
+
+//getConn 获取读取版本号与执行事务使用的连接，连接断开或会话过期时由go-zookeeper自动重连
+func (z *zookeeper) getConn() (*gozk.Conn, error) {
+	z.lk.Lock()
+	defer z.lk.Unlock()
+	if z.conn != nil {
+		return z.conn, nil
+	}
+	conn, _, err := gozk.Connect(z.opts.Addrs, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetLogger(z.Log)
+	if z.opts.Auth.Username != "" {
+		if err := conn.AddAuth("digest", []byte(fmt.Sprintf("%s:%s", z.opts.Auth.Username, z.opts.Auth.Password))); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	z.conn = conn
+	return conn, nil
+}
+
+//Close 关闭zk客户端
+func (z *zookeeper) Close() error {
+	z.lk.Lock()
+	if z.conn != nil {
+		z.conn.Close()
+		z.conn = nil
+	}
+	z.lk.Unlock()
+	return z.ZookeeperClient.Close()
+}
+
+//getParents 获取所有上级节点，按由上至下的顺序排列
+func getParents(path string) []string {
+	nodes := strings.Split(strings.Trim(path, "/"), "/")
+	paths := make([]string, 0, len(nodes))
+	for i := 1; i < len(nodes); i++ {
+		paths = append(paths, "/"+strings.Join(nodes[:i], "/"))
+	}
+	return paths
+}
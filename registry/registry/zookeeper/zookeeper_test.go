@@ -0,0 +1,48 @@
+package zookeeper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/assert"
+	gozk "github.com/samuel/go-zookeeper/zk"
+)
+
+func TestGetParents(t *testing.T) {
+	assert.Equal(t, []string{"/hydra", "/hydra/apiserver"}, getParents("/hydra/apiserver/conf"), "1. 多级节点")
+	assert.Equal(t, []string{}, getParents("/hydra"), "2. 根节点下的节点")
+}
+
+func TestGetRequests(t *testing.T) {
+	nodes := map[string]bool{"/hydra": true}
+	exists := func(path string) (bool, error) { return nodes[path], nil }
+
+	cases := []struct {
+		name  string
+		ops   []registry.Op
+		paths []string
+	}{
+		{name: "1. 创建不存在的上级节点", ops: []registry.Op{registry.CreateOp("/hydra/api/conf/router", "1")},
+			paths: []string{"/hydra/api", "/hydra/api/conf", "/hydra/api/conf/router"}},
+		{name: "2. 子节点在上级节点之前时提前创建上级节点", ops: []registry.Op{registry.CreateOp("/hydra/api/conf/router", "1"), registry.CreateOp("/hydra/api/conf", "2")},
+			paths: []string{"/hydra/api", "/hydra/api/conf", "/hydra/api/conf/router"}},
+		{name: "3. 保持其它操作的顺序", ops: []registry.Op{registry.CheckOp("/hydra", 3), registry.CreateOp("/hydra/api", "1"), registry.UpdateOp("/hydra/api", "2", 0)},
+			paths: []string{"/hydra", "/hydra/api", "/hydra/api"}},
+	}
+	for _, c := range cases {
+		paths, requests, err := getRequests(exists, nil, c.ops...)
+		assert.Equal(t, nil, err, c.name)
+		assert.Equal(t, c.paths, paths, c.name)
+		assert.Equal(t, len(paths), len(requests), c.name)
+	}
+
+	_, requests, err := getRequests(exists, nil, registry.UpdateOp("/hydra", "1", 3), registry.DeleteOp("/hydra/api", registry.AnyVersion), registry.CheckOp("/hydra", 3))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int32(3), requests[0].(*gozk.SetDataRequest).Version, "4. 使用节点数据版本号更新")
+	assert.Equal(t, int32(-1), requests[1].(*gozk.DeleteRequest).Version, "5. 不检查版本号")
+	assert.Equal(t, int32(3), requests[2].(*gozk.CheckVersionRequest).Version, "6. 检查节点数据版本号")
+
+	assert.Equal(t, true, errors.Is(getError("/hydra", gozk.ErrBadVersion), registry.ErrVersionConflict), "7. 版本号不一致")
+	assert.Equal(t, nil, getError("/hydra", nil), "8. 执行成功")
+}
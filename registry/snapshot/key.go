@@ -0,0 +1,75 @@
+package snapshot
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//KeyEnvName 快照密钥环境变量，值为base64编码的密钥，用于签名及派生加密密钥
+const KeyEnvName = "HYDRA_SNAPSHOT_KEY"
+
+//KeyFileEnvName 快照密钥文件路径环境变量，未设置时使用~/.hydra/snapshot.key
+const KeyFileEnvName = "HYDRA_SNAPSHOT_KEYFILE"
+
+//minKeyLen 密钥最小长度
+const minKeyLen = 16
+
+//ErrNoKey 未配置快照密钥，不保存也不加载快照
+var ErrNoKey = errors.New("未配置快照密钥")
+
+var signKey []byte
+var keyLock sync.RWMutex
+
+//SetKey 设置快照密钥，设置后不再从环境变量与密钥文件加载，nil表示清除
+func SetKey(key []byte) error {
+	if key != nil && len(key) < minKeyLen {
+		return fmt.Errorf("快照密钥长度不能小于%d字节", minKeyLen)
+	}
+	keyLock.Lock()
+	defer keyLock.Unlock()
+	signKey = key
+	return nil
+}
+
+//getKey 获取密钥，依次使用已设置的密钥、环境变量及密钥文件
+func getKey() ([]byte, error) {
+	keyLock.RLock()
+	key := signKey
+	keyLock.RUnlock()
+	if key != nil {
+		return key, nil
+	}
+	s := os.Getenv(KeyEnvName)
+	if s == "" {
+		path := os.Getenv(KeyFileEnvName)
+		if path == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, ErrNoKey
+			}
+			path = filepath.Join(home, ".hydra", "snapshot.key")
+		}
+		buff, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			return nil, ErrNoKey
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取快照密钥%s失败:%w", path, err)
+		}
+		s = string(buff)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("快照密钥不是有效的base64编码:%w", err)
+	}
+	if len(key) < minKeyLen {
+		return nil, fmt.Errorf("快照密钥长度不能小于%d字节", minKeyLen)
+	}
+	return key, nil
+}
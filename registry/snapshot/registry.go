@@ -0,0 +1,405 @@
+package snapshot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/logger"
+	lregistry "github.com/micro-plat/lib4go/registry"
+)
+
+//ReconnectSpan 降级模式下检查注册中心是否恢复的时间间隔
+var ReconnectSpan = time.Second * 5
+
+var errRecovered = fmt.Errorf("注册中心已恢复")
+
+var _ registry.IRegistry = &Registry{}
+
+//Registry 降级模式注册中心，注册中心不可用时由本地快照提供配置，恢复后切换到注册中心
+type Registry struct {
+	key      string
+	addr     string
+	probe    string
+	log      logger.ILogging
+	nodes    map[string]*node
+	seq      int32
+	remote   registry.IRegistry
+	values   map[string]chan lregistry.ValueWatcher
+	children map[string]chan lregistry.ChildrenWatcher
+	lk       sync.RWMutex
+	closeCh  chan struct{}
+	once     sync.Once
+}
+
+//degraded 降级模式的注册中心，注册中心恢复或关闭后移除
+var degraded = make(map[string]*Registry)
+var degradedLock sync.Mutex
+
+//GetCurrent 获取当前应用的注册中心，注册中心不可用时使用本地快照
+func GetCurrent() (registry.IRegistry, error) {
+	paths := make([]string, 0, len(global.Def.ServerTypes))
+	for _, tp := range global.Def.ServerTypes {
+		paths = append(paths, registry.Join(global.Def.PlatName, global.Def.SysName, tp, global.Def.ClusterName, "conf"))
+	}
+	return GetRegistry(global.Def.RegistryAddr, global.Def.Log(), paths...)
+}
+
+//GetRegistry 获取注册中心，注册中心不可用时加载服务器的本地快照以降级模式启动
+func GetRegistry(addr string, log logger.ILogging, serverPaths ...string) (registry.IRegistry, error) {
+	degradedLock.Lock()
+	defer degradedLock.Unlock()
+	key := addr + strings.Join(serverPaths, ",")
+	if r, ok := degraded[key]; ok {
+		return r, nil
+	}
+	rgst, err := registry.GetRegistry(addr, log)
+	if err == nil && len(serverPaths) > 0 {
+		_, err = rgst.Exists(serverPaths[0])
+	}
+	if err == nil || len(serverPaths) == 0 {
+		return rgst, err
+	}
+	r, lerr := newRegistry(addr, log, serverPaths...)
+	if lerr != nil {
+		return nil, fmt.Errorf("注册中心不可用 %w,且无法使用本地快照:%v", err, lerr)
+	}
+	log.Warnf("注册中心不可用(%v),使用本地快照启动,进入降级模式", err)
+	r.key = key
+	degraded[key] = r
+	go r.loopConnect()
+	return r, nil
+}
+
+func newRegistry(addr string, log logger.ILogging, serverPaths ...string) (*Registry, error) {
+	r := &Registry{
+		addr:     addr,
+		probe:    serverPaths[0],
+		log:      log,
+		nodes:    make(map[string]*node),
+		values:   make(map[string]chan lregistry.ValueWatcher),
+		children: make(map[string]chan lregistry.ChildrenWatcher),
+		closeCh:  make(chan struct{}),
+	}
+	for _, path := range serverPaths {
+		f, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+		log.Warnf("加载本地快照:%s(保存时间:%s)", path, time.Unix(f.Time, 0).Format("2006-01-02 15:04:05"))
+		for k, v := range f.Nodes {
+			r.nodes[k] = v
+		}
+	}
+	return r, nil
+}
+
+//IsDegraded 是否处于降级模式
+func (r *Registry) IsDegraded() bool {
+	return r.getRemote() == nil
+}
+
+//loopConnect 定时检查注册中心，恢复后补发临时节点并通知所有监控者重新拉取配置
+func (r *Registry) loopConnect() {
+	tk := time.NewTicker(ReconnectSpan)
+	defer tk.Stop()
+	for {
+		select {
+		case <-r.closeCh:
+			return
+		case <-tk.C:
+			rgst, err := registry.GetRegistry(r.addr, r.log)
+			if err != nil {
+				continue
+			}
+			if _, err := rgst.Exists(r.probe); err != nil {
+				continue
+			}
+			r.recover(rgst)
+			return
+		}
+	}
+}
+
+func (r *Registry) recover(rgst registry.IRegistry) {
+	r.lk.Lock()
+	r.remote = rgst
+	nodes, values, children := r.nodes, r.values, r.children
+	r.nodes = nil
+	r.values = nil
+	r.children = nil
+	r.lk.Unlock()
+
+	r.evict()
+	r.log.Info("注册中心已恢复,退出降级模式")
+	for path, n := range nodes {
+		if !n.temp {
+			continue
+		}
+		if err := rgst.CreateTempNode(path, n.Data); err != nil {
+			r.log.Errorf("发布临时节点%s失败:%v", path, err)
+		}
+	}
+	for path, ch := range values {
+		select {
+		case ch <- &valueEvent{path: path, err: errRecovered}:
+		default:
+		}
+	}
+	for path, ch := range children {
+		select {
+		case ch <- &childrenEvent{path: path, err: errRecovered}:
+		default:
+		}
+	}
+}
+
+func (r *Registry) getRemote() registry.IRegistry {
+	r.lk.RLock()
+	defer r.lk.RUnlock()
+	return r.remote
+}
+
+//WatchValue 监控节点值变化，降级模式下仅在注册中心恢复时通知
+func (r *Registry) WatchValue(path string) (chan lregistry.ValueWatcher, error) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	if r.remote != nil {
+		return r.remote.WatchValue(path)
+	}
+	if ch, ok := r.values[path]; ok {
+		return ch, nil
+	}
+	ch := make(chan lregistry.ValueWatcher, 1)
+	r.values[path] = ch
+	return ch, nil
+}
+
+//WatchChildren 监控子节点变化，降级模式下仅在注册中心恢复时通知
+func (r *Registry) WatchChildren(path string) (chan lregistry.ChildrenWatcher, error) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	if r.remote != nil {
+		return r.remote.WatchChildren(path)
+	}
+	if ch, ok := r.children[path]; ok {
+		return ch, nil
+	}
+	ch := make(chan lregistry.ChildrenWatcher, 1)
+	r.children[path] = ch
+	return ch, nil
+}
+
+//GetValue 获取节点值
+func (r *Registry) GetValue(path string) ([]byte, int32, error) {
+	if rgst := r.getRemote(); rgst != nil {
+		return rgst.GetValue(path)
+	}
+	r.lk.RLock()
+	defer r.lk.RUnlock()
+	n, ok := r.nodes[registry.Format(path)]
+	if !ok {
+		return nil, 0, fmt.Errorf("节点[%s]不存在", path)
+	}
+	return []byte(n.Data), n.Version, nil
+}
+
+//GetChildren 获取子节点
+func (r *Registry) GetChildren(path string) ([]string, int32, error) {
+	if rgst := r.getRemote(); rgst != nil {
+		return rgst.GetChildren(path)
+	}
+	r.lk.RLock()
+	defer r.lk.RUnlock()
+	return r.getChildren(registry.Format(path)), 0, nil
+}
+
+//Exists 节点是否存在
+func (r *Registry) Exists(path string) (bool, error) {
+	if rgst := r.getRemote(); rgst != nil {
+		return rgst.Exists(path)
+	}
+	r.lk.RLock()
+	defer r.lk.RUnlock()
+	path = registry.Format(path)
+	if _, ok := r.nodes[path]; ok {
+		return true, nil
+	}
+	return len(r.getChildren(path)) > 0, nil
+}
+
+//CreatePersistentNode 创建永久节点，降级模式下仅保存在本地
+func (r *Registry) CreatePersistentNode(path string, data string) error {
+	if rgst := r.getRemote(); rgst != nil {
+		return rgst.CreatePersistentNode(path, data)
+	}
+	return r.set(path, data, false)
+}
+
+//CreateTempNode 创建临时节点，降级模式下在注册中心恢复后发布
+func (r *Registry) CreateTempNode(path string, data string) error {
+	if rgst := r.getRemote(); rgst != nil {
+		return rgst.CreateTempNode(path, data)
+	}
+	return r.set(path, data, true)
+}
+
+//CreateSeqNode 创建序列节点，降级模式下在注册中心恢复后发布
+func (r *Registry) CreateSeqNode(path string, data string) (string, error) {
+	if rgst := r.getRemote(); rgst != nil {
+		return rgst.CreateSeqNode(path, data)
+	}
+	r.lk.Lock()
+	r.seq++
+	rpath := fmt.Sprintf("%s_%d", path, r.seq)
+	r.lk.Unlock()
+	return rpath, r.set(rpath, data, true)
+}
+
+//Update 更新节点值
+func (r *Registry) Update(path string, data string) error {
+	if rgst := r.getRemote(); rgst != nil {
+		return rgst.Update(path, data)
+	}
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	n, ok := r.nodes[registry.Format(path)]
+	if !ok {
+		return fmt.Errorf("节点[%s]不存在", path)
+	}
+	n.Data = data
+	n.Version++
+	return nil
+}
+
+//UpdateIfVersion 节点版本号与version一致时更新节点值
+func (r *Registry) UpdateIfVersion(path string, data string, version int32) error {
+	return r.Txn(registry.UpdateOp(path, data, version))
+}
+
+//Txn 执行事务操作，降级模式下仅修改本地节点
+func (r *Registry) Txn(ops ...registry.Op) error {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	if r.remote != nil {
+		return r.remote.Txn(ops...)
+	}
+	for _, op := range ops {
+		n, ok := r.nodes[registry.Format(op.Path)]
+		switch {
+		case op.Type == registry.OpCreate && ok:
+			return fmt.Errorf("节点[%s]已存在", op.Path)
+		case op.Type == registry.OpCreate:
+		case !ok:
+			return fmt.Errorf("节点[%s]不存在", op.Path)
+		default:
+			if err := registry.CheckVersion(op.Version, n.Version); err != nil {
+				return fmt.Errorf("节点[%s]%w", op.Path, err)
+			}
+		}
+	}
+	for _, op := range ops {
+		path := registry.Format(op.Path)
+		switch op.Type {
+		case registry.OpCreate:
+			r.nodes[path] = &node{Data: op.Data}
+		case registry.OpUpdate:
+			r.nodes[path].Data = op.Data
+			r.nodes[path].Version++
+		case registry.OpDelete:
+			r.delete(path)
+		}
+	}
+	return nil
+}
+
+//Delete 删除节点及其子节点
+func (r *Registry) Delete(path string) error {
+	if rgst := r.getRemote(); rgst != nil {
+		return rgst.Delete(path)
+	}
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	r.delete(registry.Format(path))
+	return nil
+}
+
+//Close 停止检查注册中心
+func (r *Registry) Close() error {
+	r.once.Do(func() {
+		close(r.closeCh)
+		r.evict()
+	})
+	return nil
+}
+
+//evict 从降级模式的注册中心中移除，之后获取注册中心时重新连接
+func (r *Registry) evict() {
+	degradedLock.Lock()
+	defer degradedLock.Unlock()
+	if degraded[r.key] == r {
+		delete(degraded, r.key)
+	}
+}
+
+func (r *Registry) set(path string, data string, temp bool) error {
+	r.lk.Lock()
+	rgst := r.remote
+	if rgst == nil {
+		r.nodes[registry.Format(path)] = &node{Data: data, temp: temp}
+	}
+	r.lk.Unlock()
+	switch {
+	case rgst == nil:
+		return nil
+	case temp:
+		return rgst.CreateTempNode(path, data)
+	default:
+		return rgst.CreatePersistentNode(path, data)
+	}
+}
+
+func (r *Registry) delete(path string) {
+	for k := range r.nodes {
+		if k == path || strings.HasPrefix(k, path+"/") {
+			delete(r.nodes, k)
+		}
+	}
+}
+
+func (r *Registry) getChildren(path string) []string {
+	children := make([]string, 0, 1)
+	exists := make(map[string]bool)
+	for k := range r.nodes {
+		if !strings.HasPrefix(k, path+"/") {
+			continue
+		}
+		name := strings.SplitN(k[len(path)+1:], "/", 2)[0]
+		if !exists[name] {
+			exists[name] = true
+			children = append(children, name)
+		}
+	}
+	return children
+}
+
+type valueEvent struct {
+	path string
+	err  error
+}
+
+func (v *valueEvent) GetValue() ([]byte, int32) { return nil, 0 }
+func (v *valueEvent) GetError() error           { return v.err }
+func (v *valueEvent) GetPath() string           { return v.path }
+
+type childrenEvent struct {
+	path string
+	err  error
+}
+
+func (v *childrenEvent) GetValue() ([]string, int32) { return nil, 0 }
+func (v *childrenEvent) GetError() error             { return v.err }
+func (v *childrenEvent) GetPath() string             { return v.path }
@@ -0,0 +1,189 @@
+package snapshot
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/registry"
+)
+
+//Dir 本地快照的存储目录
+var Dir = getDefaultDir()
+
+type node struct {
+	Data    string `json:"data"`
+	Version int32  `json:"version"`
+	temp    bool
+}
+
+//file 快照文件，节点中包含数据库、注册中心等凭据，加密后保存在Data中
+type file struct {
+	Time  int64            `json:"time"`
+	Data  string           `json:"data"`
+	Sign  string           `json:"sign"`
+	Nodes map[string]*node `json:"-"`
+}
+
+//Save 将服务器配置、var配置及平台层、系统层配置加密保存为本地快照，降级模式下不保存，
+//未配置密钥时返回ErrNoKey
+func Save(r registry.IRegistry, serverPath string, varPath string, layerPaths ...string) error {
+	if v, ok := r.(*Registry); ok && v.IsDegraded() {
+		return nil
+	}
+	key, err := getKey()
+	if err != nil {
+		return err
+	}
+	f := &file{Time: time.Now().Unix(), Nodes: make(map[string]*node)}
+	for _, root := range append([]string{serverPath, varPath}, layerPaths...) {
+		ok, err := r.Exists(root)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := walk(r, root, f.Nodes); err != nil {
+			return err
+		}
+	}
+	buff, err := json.Marshal(f.Nodes)
+	if err != nil {
+		return err
+	}
+	if f.Data, err = encrypt(key, buff); err != nil {
+		return fmt.Errorf("加密快照失败:%w", err)
+	}
+	f.Sign = sign(key, f)
+	if buff, err = json.Marshal(f); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(Dir, 0755); err != nil {
+		return fmt.Errorf("创建快照目录%s失败:%w", Dir, err)
+	}
+	path := getFileName(serverPath)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buff, 0600); err != nil {
+		return fmt.Errorf("保存快照%s失败:%w", path, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+//load 加载服务器的本地快照，验证签名后解密
+func load(serverPath string) (*file, error) {
+	key, err := getKey()
+	if err != nil {
+		return nil, err
+	}
+	path := getFileName(serverPath)
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取快照%s失败:%w", path, err)
+	}
+	f := &file{}
+	if err := json.Unmarshal(buff, f); err != nil {
+		return nil, fmt.Errorf("快照%s格式错误:%w", path, err)
+	}
+	if !hmac.Equal([]byte(f.Sign), []byte(sign(key, f))) {
+		return nil, fmt.Errorf("快照%s签名错误", path)
+	}
+	data, err := decrypt(key, f.Data)
+	if err != nil {
+		return nil, fmt.Errorf("解密快照%s失败:%w", path, err)
+	}
+	if err := json.Unmarshal(data, &f.Nodes); err != nil {
+		return nil, fmt.Errorf("快照%s格式错误:%w", path, err)
+	}
+	return f, nil
+}
+
+func walk(r registry.IRegistry, path string, nodes map[string]*node) error {
+	data, version, err := r.GetValue(path)
+	if err != nil {
+		return fmt.Errorf("获取节点%s的值失败:%w", path, err)
+	}
+	nodes[path] = &node{Data: string(data), Version: version}
+	children, _, err := r.GetChildren(path)
+	if err != nil {
+		return fmt.Errorf("获取节点%s的子节点失败:%w", path, err)
+	}
+	for _, name := range children {
+		if err := walk(r, registry.Join(path, name), nodes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//sign 使用保存时间及加密后的节点计算签名
+func sign(key []byte, f *file) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(strconv.FormatInt(f.Time, 10)))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(f.Data))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+//encrypt 使用由密钥派生的AES-256-GCM密钥加密，返回base64编码的随机数与密文
+func encrypt(key []byte, data []byte) (string, error) {
+	aead, err := getAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, data, nil)), nil
+}
+
+//decrypt 解密encrypt返回的数据
+func decrypt(key []byte, data string) ([]byte, error) {
+	buff, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := getAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(buff) < aead.NonceSize() {
+		return nil, fmt.Errorf("密文长度错误")
+	}
+	return aead.Open(nil, buff[:aead.NonceSize()], buff[aead.NonceSize():], nil)
+}
+
+//getAEAD 由密钥派生加密密钥，与签名使用不同的密钥
+func getAEAD(key []byte) (cipher.AEAD, error) {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte("hydra snapshot encryption"))
+	block, err := aes.NewCipher(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func getFileName(serverPath string) string {
+	return filepath.Join(Dir, strings.Replace(registry.Trim(serverPath), "/", "_", -1)+".snapshot")
+}
+
+func getDefaultDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "hydra", "snapshot")
+	}
+	return filepath.Join(os.TempDir(), "hydra", "snapshot")
+}
@@ -0,0 +1,115 @@
+package snapshot
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+const serverPath = "/hydra/test/api/t/conf"
+
+var testKey = []byte("0123456789abcdef")
+
+func TestSaveAndLoad(t *testing.T) {
+	Dir = t.TempDir()
+	SetKey(testKey)
+	defer SetKey(nil)
+	src := localmemory.NewLocalMemory()
+	src.CreatePersistentNode(serverPath, `{"address":":8080"}`)
+	src.CreatePersistentNode(serverPath+"/router", `{"routers":[]}`)
+	src.CreatePersistentNode("/hydra/var/db/db", `{"provider":"mysql","connString":"hydra:123456@tcp(127.0.0.1)/hydra"}`)
+	assert.Equal(t, nil, Save(src, serverPath, "/hydra/var"), "1. 保存快照")
+
+	r, err := newRegistry("lm://.", logger.New("snapshot"), serverPath)
+	assert.Equal(t, nil, err, "2. 加载快照")
+	_, sv, _ := src.GetValue(serverPath)
+	data, version, err := r.GetValue(serverPath)
+	assert.Equal(t, nil, err, "3. 获取主配置")
+	assert.Equal(t, `{"address":":8080"}`, string(data), "3. 获取主配置")
+	assert.Equal(t, sv, version, "3. 版本号与注册中心一致")
+	children, _, _ := r.GetChildren(serverPath)
+	assert.Equal(t, []string{"router"}, children, "4. 获取子配置")
+	ok, _ := r.Exists("/hydra/var/db")
+	assert.Equal(t, true, ok, "5. var配置存在")
+
+	path := getFileName(serverPath)
+	buff, _ := ioutil.ReadFile(path)
+	assert.Equal(t, false, strings.Contains(string(buff), "123456"), "6. 快照中的凭据已加密")
+	assert.Equal(t, false, strings.Contains(string(buff), "mysql"), "6. 快照中的配置已加密")
+
+	f := &file{}
+	json.Unmarshal(buff, f)
+	data, _ = base64.StdEncoding.DecodeString(f.Data)
+	data[len(data)-1] ^= 1
+	ioutil.WriteFile(path, []byte(strings.Replace(string(buff), f.Data, base64.StdEncoding.EncodeToString(data), 1)), 0600)
+	_, err = newRegistry("lm://.", logger.New("snapshot"), serverPath)
+	assert.NotEqual(t, nil, err, "7. 快照被修改后签名错误")
+}
+
+func TestRecover(t *testing.T) {
+	Dir = t.TempDir()
+	SetKey(testKey)
+	defer SetKey(nil)
+	src := localmemory.NewLocalMemory()
+	src.CreatePersistentNode(serverPath, `{"address":":8080"}`)
+	assert.Equal(t, nil, Save(src, serverPath, "/hydra/var"), "1. 保存快照")
+
+	r, err := newRegistry("lm://.", logger.New("snapshot"), serverPath)
+	assert.Equal(t, nil, err, "2. 加载快照")
+	assert.Equal(t, true, r.IsDegraded(), "2. 降级模式")
+	r.key = "lm://." + serverPath
+	degraded[r.key] = r
+	ch, _ := r.WatchValue(serverPath)
+	npath, err := r.CreateSeqNode("/hydra/test/api/t/servers/192.168.0.1_", "{}")
+	assert.Equal(t, nil, err, "3. 降级模式下创建临时节点")
+
+	r.recover(src)
+	assert.Equal(t, false, r.IsDegraded(), "4. 注册中心恢复")
+	w := <-ch
+	assert.NotEqual(t, nil, w.GetError(), "5. 通知监控者重新拉取配置")
+	ok, _ := src.Exists(npath)
+	assert.Equal(t, true, ok, "6. 发布降级期间创建的临时节点")
+	ok, _ = r.Exists(npath)
+	assert.Equal(t, true, ok, "7. 使用注册中心")
+	_, ok = degraded[r.key]
+	assert.Equal(t, false, ok, "8. 恢复后不再缓存降级模式的注册中心")
+	rgst, err := GetRegistry("lm://.", logger.New("snapshot"), serverPath)
+	assert.Equal(t, nil, err, "9. 重新获取注册中心")
+	_, ok = rgst.(*Registry)
+	assert.Equal(t, false, ok, "9. 重新获取注册中心")
+}
+
+func TestSignKey(t *testing.T) {
+	Dir = t.TempDir()
+	os.Unsetenv(KeyEnvName)
+	os.Setenv(KeyFileEnvName, filepath.Join(Dir, "snapshot.key"))
+	defer os.Unsetenv(KeyFileEnvName)
+	src := localmemory.NewLocalMemory()
+	src.CreatePersistentNode(serverPath, `{"address":":8080"}`)
+	assert.Equal(t, ErrNoKey, Save(src, serverPath, "/hydra/var"), "1. 未配置密钥时不保存快照")
+
+	SetKey(testKey)
+	defer SetKey(nil)
+	assert.Equal(t, nil, Save(src, serverPath, "/hydra/var"), "2. 保存快照")
+	SetKey(nil)
+	_, err := newRegistry("lm://.", logger.New("snapshot"), serverPath)
+	assert.Equal(t, ErrNoKey, err, "3. 未配置密钥时不加载快照")
+
+	SetKey([]byte("fedcba9876543210"))
+	_, err = newRegistry("lm://.", logger.New("snapshot"), serverPath)
+	assert.NotEqual(t, nil, err, "4. 使用其它密钥签名的快照")
+
+	ioutil.WriteFile(filepath.Join(Dir, "snapshot.key"), []byte(base64.StdEncoding.EncodeToString(testKey)), 0600)
+	SetKey(nil)
+	_, err = newRegistry("lm://.", logger.New("snapshot"), serverPath)
+	assert.Equal(t, nil, err, "5. 从密钥文件加载密钥")
+	assert.NotEqual(t, nil, SetKey([]byte("short")), "6. 密钥长度不足")
+}
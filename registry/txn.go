@@ -0,0 +1,62 @@
+package registry
+
+import "errors"
+
+//AnyVersion 不检查节点版本号
+const AnyVersion int32 = -1
+
+//ErrVersionConflict 节点版本号与期望的版本号不一致
+var ErrVersionConflict = errors.New("版本号不一致,已被其它程序修改")
+
+//OpType 事务操作类型
+type OpType int
+
+const (
+	//OpCreate 创建永久节点
+	OpCreate OpType = iota + 1
+
+	//OpUpdate 更新节点值
+	OpUpdate
+
+	//OpDelete 删除节点
+	OpDelete
+
+	//OpCheck 检查节点版本号
+	OpCheck
+)
+
+//Op 事务操作，Version为GetValue返回的版本号，AnyVersion表示不检查版本号
+type Op struct {
+	Type    OpType
+	Path    string
+	Data    string
+	Version int32
+}
+
+//CreateOp 创建永久节点
+func CreateOp(path string, data string) Op {
+	return Op{Type: OpCreate, Path: path, Data: data, Version: AnyVersion}
+}
+
+//UpdateOp 节点版本号与version一致时更新节点值
+func UpdateOp(path string, data string, version int32) Op {
+	return Op{Type: OpUpdate, Path: path, Data: data, Version: version}
+}
+
+//DeleteOp 节点版本号与version一致时删除节点
+func DeleteOp(path string, version int32) Op {
+	return Op{Type: OpDelete, Path: path, Version: version}
+}
+
+//CheckOp 检查节点版本号与version一致
+func CheckOp(path string, version int32) Op {
+	return Op{Type: OpCheck, Path: path, Version: version}
+}
+
+//CheckVersion 检查版本号是否与期望的版本号一致
+func CheckVersion(expected int32, version int32) error {
+	if expected != AnyVersion && expected != version {
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -6,7 +6,9 @@ package services
 
 import (
 	"sync"
+	"time"
 
+	"github.com/micro-plat/hydra/conf/app"
 	"github.com/micro-plat/hydra/conf/server/task"
 	"github.com/micro-plat/hydra/global"
 )
@@ -25,6 +27,30 @@ var _ ICRON = CRON
 type ICRON interface {
 	Add(cron string, service string) ICRON
 	Remove(cron string, service string) ICRON
+
+	//Save 将任务保存到注册中心，集群中的所有节点同步执行，重启后仍然有效
+	Save(t *task.Task) error
+
+	//List 获取注册中心中的任务，返回任务名称与任务
+	List() (map[string]*task.Task, error)
+
+	//Pause 暂停注册中心中的任务
+	Pause(name string) error
+
+	//Resume 恢复注册中心中的任务
+	Resume(name string) error
+
+	//Delete 删除注册中心中的任务
+	Delete(name string) error
+
+	//Schedule 添加在at时执行一次的任务，由集群的主节点执行，失败后按退避时间重试，返回任务编号
+	Schedule(at time.Time, service string, input map[string]interface{}, opts ...task.JobOption) (string, error)
+
+	//GetJob 获取一次性任务及执行状态
+	GetJob(id string) (*task.Job, error)
+
+	//CancelJob 撤销等待执行的一次性任务
+	CancelJob(id string) error
 }
 
 type cron struct {
@@ -92,6 +118,100 @@ func (c *cron) Remove(cron string, service string) ICRON {
 	return c
 }
 
+//Save 将任务保存到注册中心
+func (c *cron) Save(t *task.Task) error {
+	store, err := c.getStore()
+	if err != nil {
+		return err
+	}
+	return store.Save(t)
+}
+
+//List 获取注册中心中的任务
+func (c *cron) List() (map[string]*task.Task, error) {
+	store, err := c.getStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.List()
+}
+
+//Pause 暂停注册中心中的任务
+func (c *cron) Pause(name string) error {
+	store, err := c.getStore()
+	if err != nil {
+		return err
+	}
+	return store.Pause(name)
+}
+
+//Resume 恢复注册中心中的任务
+func (c *cron) Resume(name string) error {
+	store, err := c.getStore()
+	if err != nil {
+		return err
+	}
+	return store.Resume(name)
+}
+
+//Delete 删除注册中心中的任务
+func (c *cron) Delete(name string) error {
+	store, err := c.getStore()
+	if err != nil {
+		return err
+	}
+	return store.Delete(name)
+}
+
+//Schedule 添加在at时执行一次的任务
+func (c *cron) Schedule(at time.Time, service string, input map[string]interface{}, opts ...task.JobOption) (string, error) {
+	store, err := c.getJobStore()
+	if err != nil {
+		return "", err
+	}
+	job := task.NewJob(at, service, input, opts...)
+	return job.ID, store.Create(job)
+}
+
+//GetJob 获取一次性任务及执行状态
+func (c *cron) GetJob(id string) (*task.Job, error) {
+	store, err := c.getJobStore()
+	if err != nil {
+		return nil, err
+	}
+	job, _, err := store.Get(id)
+	return job, err
+}
+
+//CancelJob 撤销等待执行的一次性任务
+func (c *cron) CancelJob(id string) error {
+	store, err := c.getJobStore()
+	if err != nil {
+		return err
+	}
+	return store.Cancel(id)
+}
+
+//getStore 根据当前cron服务器配置获取任务存储
+func (c *cron) getStore() (*task.Store, error) {
+	cnf, err := app.Cache.GetAPPConf(global.CRON)
+	if err != nil {
+		return nil, err
+	}
+	sc := cnf.GetServerConf()
+	return task.NewStore(sc.GetRegistry(), sc), nil
+}
+
+//getJobStore 根据当前cron服务器配置获取一次性任务存储
+func (c *cron) getJobStore() (*task.JobStore, error) {
+	cnf, err := app.Cache.GetAPPConf(global.CRON)
+	if err != nil {
+		return nil, err
+	}
+	sc := cnf.GetServerConf()
+	return task.NewJobStore(sc.GetRegistry(), sc), nil
+}
+
 //Subscribe 订阅任务
 func (c *cron) Subscribe(callback func(t *task.Task)) {
 
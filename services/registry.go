@@ -336,6 +336,14 @@ func (s *regist) GetHandler(serverType string, service string) (context.IHandler
 	return s.get(serverType).GetHandlers(service)
 }
 
+//GetServices 获取服务器已注册的服务名称
+func (s *regist) GetServices(serverType string) []string {
+	if v, ok := s.servers[serverType]; ok {
+		return v.GetServices()
+	}
+	return []string{}
+}
+
 //GetGroup 获取服务的分组信息
 func (s *regist) GetGroup(serverType string, service string, method ...string) string {
 	if len(method) == 0 {
@@ -3,6 +3,7 @@ package services
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/micro-plat/hydra/conf/server/router"
@@ -98,6 +99,16 @@ func (s *metaServices) GetHandlers(service string) (h context.IHandler, ok bool)
 	return
 }
 
+//GetServices 获取所有已注册的服务名称
+func (s *metaServices) GetServices() []string {
+	services := make([]string, 0, len(s.handlers))
+	for service := range s.handlers {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
+}
+
 //GetGroup 获取服务的分组信息
 func (s *metaServices) GetGroup(service string) string {
 	return s.groups[service]